@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -28,10 +29,14 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
@@ -43,51 +48,74 @@ import (
 	"github.com/fluxcd/flagger/pkg/canary"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	informers "github.com/fluxcd/flagger/pkg/client/informers/externalversions"
+	flaggerinformers "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/controller"
+	"github.com/fluxcd/flagger/pkg/incident"
 	"github.com/fluxcd/flagger/pkg/logger"
+	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
 	"github.com/fluxcd/flagger/pkg/notifier"
 	"github.com/fluxcd/flagger/pkg/router"
 	"github.com/fluxcd/flagger/pkg/server"
 	"github.com/fluxcd/flagger/pkg/signals"
+	"github.com/fluxcd/flagger/pkg/state"
 	"github.com/fluxcd/flagger/pkg/version"
 )
 
 var (
-	masterURL                string
-	kubeconfig               string
-	kubeconfigQPS            int
-	kubeconfigBurst          int
-	metricsServer            string
-	controlLoopInterval      time.Duration
-	logLevel                 string
-	port                     string
-	msteamsURL               string
-	msteamsProxyURL          string
-	includeLabelPrefix       string
-	slackURL                 string
-	slackToken               string
-	slackProxyURL            string
-	slackUser                string
-	slackChannel             string
-	eventWebhook             string
-	threadiness              int
-	zapReplaceGlobals        bool
-	zapEncoding              string
-	namespace                string
-	meshProvider             string
-	selectorLabels           string
-	ingressAnnotationsPrefix string
-	ingressClass             string
-	enableLeaderElection     bool
-	leaderElectionNamespace  string
-	enableConfigTracking     bool
-	ver                      bool
-	kubeconfigServiceMesh    string
-	clusterName              string
-	noCrossNamespaceRefs     bool
+	masterURL                   string
+	kubeconfig                  string
+	kubeconfigQPS               int
+	kubeconfigBurst             int
+	metricsServer               string
+	controlLoopInterval         time.Duration
+	logLevel                    string
+	port                        string
+	msteamsURL                  string
+	msteamsProxyURL             string
+	includeLabelPrefix          string
+	slackURL                    string
+	slackToken                  string
+	slackProxyURL               string
+	slackUser                   string
+	slackChannel                string
+	eventWebhook                string
+	threadiness                 int
+	zapReplaceGlobals           bool
+	zapEncoding                 string
+	namespace                   string
+	meshProvider                string
+	selectorLabels              string
+	ingressAnnotationsPrefix    string
+	ingressClass                string
+	enableLeaderElection        bool
+	leaderElectionNamespace     string
+	enableConfigTracking        bool
+	ver                         bool
+	kubeconfigServiceMesh       string
+	clusterName                 string
+	metricsExtendedLabels       bool
+	noCrossNamespaceRefs        bool
+	namespacedRBAC              bool
+	remoteKubeconfigSecrets     string
+	meshRemoteKubeconfigSecrets string
+	meshEastWestGateway         string
+	meshNetwork                 string
+	settingsConfigMap           string
+	maxConcurrentCanaries       int
+	exportState                 string
+	exportStateSecrets          bool
+	importState                 string
+	incidentProvider            string
+	incidentURL                 string
+	incidentToken               string
+	incidentSeverities          string
+	incidentPollInterval        time.Duration
 )
 
+// remoteRetryInterval is the delay between the connection attempts to an unreachable remote cluster
+const remoteRetryInterval = time.Minute
+
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flag.IntVar(&kubeconfigQPS, "kubeconfig-qps", 100, "Set QPS for kubeconfig.")
@@ -110,7 +138,7 @@ func init() {
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
-	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, skipper, traefik, apisix, osm or kuma.")
+	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be istio, linkerd, appmesh, contour, gloo, nginx, haproxy, skipper, traefik, apisix, openshift, emissary, knative, gke, externaldns, osm, kuma, kubernetes or selector-switch.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for NGINX ingresses.")
 	flag.StringVar(&ingressClass, "ingress-class", "", "Ingress class used for annotating HTTPProxy objects.")
@@ -120,7 +148,23 @@ func init() {
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
 	flag.StringVar(&clusterName, "cluster-name", "", "Cluster name to be included in alert msgs.")
+	flag.BoolVar(&metricsExtendedLabels, "metrics-extended-labels", false, "When set to true, the canary metrics are labeled with the cluster name and the metric analysis with the canary name and interval.")
 	flag.BoolVar(&noCrossNamespaceRefs, "no-cross-namespace-refs", false, "When set to true, Flagger can only refer to resources in the same namespace.")
+	flag.BoolVar(&namespacedRBAC, "namespaced-rbac", false, "When set to true, Flagger doesn't query the cluster scoped APIs such as namespaces, requires -namespace.")
+	flag.StringVar(&meshRemoteKubeconfigSecrets, "mesh-remote-kubeconfig-secrets", "", "List of secrets in the namespace/name format containing the kubeconfig of the other primary clusters in an Istio multi-primary mesh.")
+	flag.StringVar(&meshEastWestGateway, "mesh-east-west-gateway", "", "Address of the east-west gateway of this cluster, when set Flagger creates service entries for the primary and canary services in the other primary clusters of the mesh.")
+	flag.StringVar(&meshNetwork, "mesh-network", "", "Istio network of this cluster, used for the endpoints of the service entries created in the other primary clusters.")
+	flag.StringVar(&remoteKubeconfigSecrets, "remote-kubeconfig-secrets", "", "List of secrets in the namespace/name format containing the kubeconfig of remote clusters where Flagger manages canaries.")
+	flag.StringVar(&settingsConfigMap, "settings-configmap", "", "ConfigMap in the namespace/name format containing settings that override the flags and are reloaded on change.")
+	flag.IntVar(&maxConcurrentCanaries, "max-concurrent-canaries", 0, "Max number of canaries that can run analysis at the same time in a namespace, zero means unlimited.")
+	flag.StringVar(&exportState, "export-state", "", "Path to a file where the state of the canaries is exported, Flagger exits after the export.")
+	flag.BoolVar(&exportStateSecrets, "export-state-secrets", false, "Include the data of the primary Secrets in the exported state, the data is redacted by default.")
+	flag.StringVar(&importState, "import-state", "", "Path to a file from which the state of the canaries is imported, Flagger exits after the import.")
+	flag.StringVar(&incidentProvider, "incident-provider", "", "Incident management tool polled to hold the traffic shifting while an incident is open, can be statuspage, incidentio or pagerduty.")
+	flag.StringVar(&incidentURL, "incident-url", "", "Address of the status page or of the incident management API.")
+	flag.StringVar(&incidentToken, "incident-token", "", "API token of the incident management tool.")
+	flag.StringVar(&incidentSeverities, "incident-severities", "", "List of incident severities that hold the traffic shifting, all severities if empty.")
+	flag.DurationVar(&incidentPollInterval, "incident-poll-interval", time.Minute, "Interval at which the incident management tool is polled.")
 }
 
 func main() {
@@ -155,12 +199,18 @@ func main() {
 
 	cfg.QPS = float32(kubeconfigQPS)
 	cfg.Burst = kubeconfigBurst
+	instrumentConfig(cfg)
 
 	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
 		logger.Fatalf("Error building kubernetes clientset: %v", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building dynamic client: %v", err)
+	}
+
 	flaggerClient, err := clientset.NewForConfig(cfg)
 	if err != nil {
 		logger.Fatalf("Error building flagger clientset: %s", err.Error())
@@ -177,14 +227,31 @@ func main() {
 
 	serviceMeshCfg.QPS = float32(kubeconfigQPS)
 	serviceMeshCfg.Burst = kubeconfigBurst
+	instrumentConfig(serviceMeshCfg)
 
 	meshClient, err := clientset.NewForConfig(serviceMeshCfg)
 	if err != nil {
 		logger.Fatalf("Error building mesh clientset: %v", err)
 	}
 
+	istioClient, err := istioclientset.NewForConfig(serviceMeshCfg)
+	if err != nil {
+		logger.Fatalf("Error building istio clientset: %v", err)
+	}
+
 	verifyCRDs(flaggerClient, logger)
 	verifyKubernetesVersion(kubeClient, logger)
+
+	// export or import the canaries state for cluster migrations and exit
+	if exportState != "" {
+		exportStateToFile(exportState, kubeClient, flaggerClient, logger)
+		return
+	}
+	if importState != "" {
+		importStateFromFile(importState, kubeClient, flaggerClient, logger)
+		return
+	}
+
 	infos := startInformers(flaggerClient, logger, stopCh)
 
 	labels := strings.Split(selectorLabels, ",")
@@ -196,6 +263,10 @@ func main() {
 		logger.Infof("Watching namespace %s", namespace)
 	}
 
+	if namespacedRBAC && namespace == "" {
+		logger.Fatalf("The -namespaced-rbac flag requires the -namespace flag to be set")
+	}
+
 	observerFactory, err := observers.NewFactory(metricsServer)
 	if err != nil {
 		logger.Fatalf("Error building prometheus client: %s", err.Error())
@@ -208,12 +279,16 @@ func main() {
 		logger.Errorf("Metrics server %s unreachable %v", metricsServer, err)
 	}
 
+	settings := defaultSettings()
+
 	// setup Slack or MS Teams notifications
-	notifierClient := initNotifier(logger)
+	notifierClient := initNotifier(settings, logger)
 
 	// start HTTP server
 	go server.ListenAndServe(port, 3*time.Second, logger, stopCh)
 
+	incidents := initIncidentWatcher(logger, stopCh)
+
 	setOwnerRefs := true
 	// Router shouldn't set OwnerRefs on resources that they create since the
 	// service mesh/ingress controller is in a different cluster.
@@ -221,14 +296,35 @@ func main() {
 		setOwnerRefs = false
 	}
 
-	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient, setOwnerRefs)
+	// replicate the Istio routing objects to the other primary clusters of the mesh
+	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient, istioClient, setOwnerRefs, nil)
+	routerFactory.SetMeshEastWestGateway(meshEastWestGateway, meshNetwork)
+	for _, ref := range splitSecretRefs(meshRemoteKubeconfigSecrets) {
+		ref := ref
+		connectRemote(ref, func() error {
+			remoteCfg, _, err := remoteConfig(ref, kubeClient)
+			if err != nil {
+				return err
+			}
+			remoteIstioClient, err := istioclientset.NewForConfig(remoteCfg)
+			if err != nil {
+				return fmt.Errorf("error building remote istio clientset %s: %w", ref, err)
+			}
+			routerFactory.AddRemoteIstioClient(remoteIstioClient)
+			return nil
+		}, logger, stopCh)
+	}
+
+	scalableMapper := canary.NewScalableMapper(kubeClient.Discovery())
 
 	var configTracker canary.Tracker
 	if enableConfigTracking {
 		configTracker = &canary.ConfigTracker{
-			Logger:        logger,
-			KubeClient:    kubeClient,
-			FlaggerClient: flaggerClient,
+			Logger:         logger,
+			KubeClient:     kubeClient,
+			DynamicClient:  dynamicClient,
+			ScalableMapper: scalableMapper,
+			FlaggerClient:  flaggerClient,
 		}
 	} else {
 		configTracker = &canary.NopTracker{}
@@ -236,7 +332,7 @@ func main() {
 
 	includeLabelPrefixArray := strings.Split(includeLabelPrefix, ",")
 
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, labels, includeLabelPrefixArray, logger)
+	canaryFactory := canary.NewFactory(kubeClient, dynamicClient, scalableMapper, flaggerClient, configTracker, labels, includeLabelPrefixArray, logger)
 
 	c := controller.NewController(
 		kubeClient,
@@ -250,9 +346,13 @@ func main() {
 		observerFactory,
 		meshProvider,
 		version.VERSION,
-		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		settings.EventWebhook,
 		clusterName,
+		metricsExtendedLabels,
 		noCrossNamespaceRefs,
+		namespacedRBAC,
+		settings.MaxConcurrentCanaries,
+		incidents,
 	)
 
 	// leader election context
@@ -268,8 +368,41 @@ func main() {
 		cancel()
 	}()
 
+	// let the canaries reference the previous promotion ring in the other clusters
+	controller.LinkRingClusters(c)
+
+	// reload the global settings of all controllers when the settings ConfigMap changes
+	var settingsWatcher *controller.SettingsWatcher
+	if settingsConfigMap != "" {
+		cmNamespace, cmName, found := strings.Cut(settingsConfigMap, "/")
+		if !found {
+			logger.Fatalf("Invalid settings ConfigMap %s, expected format namespace/name", settingsConfigMap)
+		}
+		settingsWatcher = controller.WatchSettings(kubeClient, cmNamespace, cmName, settings, logger, stopCh, c)
+	}
+
 	// wrap controller run
 	runController := func() {
+		// create a control loop for each remote cluster, the unreachable clusters are retried in the background
+		for _, ref := range splitSecretRefs(remoteKubeconfigSecrets) {
+			ref := ref
+			connectRemote(ref, func() error {
+				rc, err := newRemoteController(ctx, ref, kubeClient, observerFactory, notifierClient, incidents, labels, includeLabelPrefixArray, logger, stopCh)
+				if err != nil {
+					return err
+				}
+				controller.JoinRingClusters(rc, c)
+				if settingsWatcher != nil {
+					settingsWatcher.Add(rc)
+				}
+				go func() {
+					if err := rc.Run(threadiness, stopCh); err != nil {
+						logger.Errorf("Error running remote controller %s: %v", ref, err)
+					}
+				}()
+				return nil
+			}, logger, stopCh)
+		}
 		if err := c.Run(threadiness, stopCh); err != nil {
 			logger.Fatalf("Error running controller: %v", err)
 		}
@@ -311,11 +444,198 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	// Helm doesn't upgrade the CRDs, the informers of the CRDs added after the first
+	// release are started only when the CRDs are registered
+	var approvalInformer flaggerinformers.CanaryApprovalInformer
+	_, err := flaggerClient.FlaggerV1beta1().CanaryApprovals(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	switch {
+	case apierrors.IsNotFound(err):
+		logger.Warn("CanaryApproval CRD is not registered, the canaries requiring approval can't be promoted")
+	case err != nil:
+		logger.Fatalf("CanaryApproval list query error: %v", err)
+	default:
+		logger.Info("Waiting for canary approval informer cache to sync")
+		approvalInformer = flaggerInformerFactory.Flagger().V1beta1().CanaryApprovals()
+		go approvalInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, approvalInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
+	var defaultInformer flaggerinformers.CanaryDefaultInformer
+	_, err = flaggerClient.FlaggerV1beta1().CanaryDefaults(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	switch {
+	case apierrors.IsNotFound(err):
+		logger.Warn("CanaryDefault CRD is not registered, the canary defaults are not applied")
+	case err != nil:
+		logger.Fatalf("CanaryDefault list query error: %v", err)
+	default:
+		logger.Info("Waiting for canary defaults informer cache to sync")
+		defaultInformer = flaggerInformerFactory.Flagger().V1beta1().CanaryDefaults()
+		go defaultInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, defaultInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
 	return controller.Informers{
-		CanaryInformer: canaryInformer,
-		MetricInformer: metricInformer,
-		AlertInformer:  alertInformer,
+		CanaryInformer:   canaryInformer,
+		MetricInformer:   metricInformer,
+		AlertInformer:    alertInformer,
+		ApprovalInformer: approvalInformer,
+		DefaultInformer:  defaultInformer,
+	}
+}
+
+// remoteConfig builds the client config from the kubeconfig stored in the specified secret
+func remoteConfig(secretRef string, kubeClient kubernetes.Interface) (*rest.Config, string, error) {
+	secretNamespace, secretName, found := strings.Cut(secretRef, "/")
+	if !found {
+		return nil, "", fmt.Errorf("invalid remote kubeconfig secret %s, expected format namespace/name", secretRef)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(secretNamespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("error fetching remote kubeconfig secret %s: %w", secretRef, err)
+	}
+	data, ok := secret.Data["kubeconfig"]
+	if !ok {
+		return nil, "", fmt.Errorf("remote kubeconfig secret %s does not contain the kubeconfig key", secretRef)
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("error building remote kubeconfig %s: %w", secretRef, err)
+	}
+
+	cfg.QPS = float32(kubeconfigQPS)
+	cfg.Burst = kubeconfigBurst
+	instrumentConfig(cfg)
+	return cfg, secretName, nil
+}
+
+// connectRemote calls connect and, if the remote cluster can't be reached, logs the error
+// and keeps retrying in the background so that the other clusters are not affected
+func connectRemote(secretRef string, connect func() error, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	err := connect()
+	if err == nil {
+		return
+	}
+	logger.Errorf("Remote cluster %s unavailable, retrying in %s: %v", secretRef, remoteRetryInterval, err)
+
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(remoteRetryInterval):
+			}
+			err := connect()
+			if err == nil {
+				return
+			}
+			logger.Errorf("Remote cluster %s unavailable, retrying in %s: %v", secretRef, remoteRetryInterval, err)
+		}
+	}()
+}
+
+// instrumentConfig records the Kubernetes API requests sent by the clients built from the config
+func instrumentConfig(cfg *rest.Config) {
+	cfg.Wrap(metrics.NewClientRecorder("flagger", true).WrapTransport)
+}
+
+// splitSecretRefs returns the non-empty secret references from a comma-separated list
+func splitSecretRefs(refs string) []string {
+	var result []string
+	for _, ref := range strings.Split(refs, ",") {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			result = append(result, ref)
+		}
 	}
+	return result
+}
+
+// newRemoteController creates a controller for the cluster with the kubeconfig stored in the
+// specified secret, the secret name is used as the cluster name
+func newRemoteController(ctx context.Context, secretRef string, kubeClient kubernetes.Interface,
+	observerFactory *observers.Factory, notifierClient notifier.Interface, incidents *incident.Watcher,
+	labels []string, includeLabelPrefix []string, logger *zap.SugaredLogger, stopCh <-chan struct{}) (*controller.Controller, error) {
+	cfg, secretName, err := remoteConfig(secretRef, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Wrap(transport.ContextCanceller(ctx, fmt.Errorf("the leader is shutting down")))
+
+	remoteKubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building remote kubernetes clientset %s: %w", secretRef, err)
+	}
+
+	remoteDynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building remote dynamic client %s: %w", secretRef, err)
+	}
+
+	remoteFlaggerClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building remote flagger clientset %s: %w", secretRef, err)
+	}
+
+	remoteIstioClient, err := istioclientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building remote istio clientset %s: %w", secretRef, err)
+	}
+
+	remoteLogger := logger.With("cluster", secretName)
+	remoteLogger.Infof("Connecting to remote cluster %s", cfg.Host)
+
+	if err := checkCRDs(remoteFlaggerClient); err != nil {
+		return nil, err
+	}
+	if err := checkKubernetesVersion(remoteKubeClient, remoteLogger); err != nil {
+		return nil, err
+	}
+	infos := startInformers(remoteFlaggerClient, remoteLogger, stopCh)
+
+	routerFactory := router.NewFactory(cfg, remoteKubeClient, remoteFlaggerClient, ingressAnnotationsPrefix, ingressClass, remoteLogger, remoteFlaggerClient, remoteIstioClient, true, nil)
+
+	remoteScalableMapper := canary.NewScalableMapper(remoteKubeClient.Discovery())
+
+	var configTracker canary.Tracker
+	if enableConfigTracking {
+		configTracker = &canary.ConfigTracker{
+			Logger:         remoteLogger,
+			KubeClient:     remoteKubeClient,
+			DynamicClient:  remoteDynamicClient,
+			ScalableMapper: remoteScalableMapper,
+			FlaggerClient:  remoteFlaggerClient,
+		}
+	} else {
+		configTracker = &canary.NopTracker{}
+	}
+
+	canaryFactory := canary.NewFactory(remoteKubeClient, remoteDynamicClient, remoteScalableMapper, remoteFlaggerClient, configTracker, labels, includeLabelPrefix, remoteLogger)
+
+	return controller.NewController(
+		remoteKubeClient,
+		remoteFlaggerClient,
+		infos,
+		controlLoopInterval,
+		remoteLogger,
+		notifierClient,
+		canaryFactory,
+		routerFactory,
+		observerFactory,
+		meshProvider,
+		version.VERSION,
+		defaultSettings().EventWebhook,
+		secretName,
+		metricsExtendedLabels,
+		noCrossNamespaceRefs,
+		namespacedRBAC,
+		maxConcurrentCanaries,
+		incidents,
+	), nil
 }
 
 func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
@@ -365,20 +685,32 @@ func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient
 	})
 }
 
-func initNotifier(logger *zap.SugaredLogger) (client notifier.Interface) {
-	provider := "slack"
-	token := fromEnv("SLACK_TOKEN", slackToken)
-	notifierURL := fromEnv("SLACK_URL", slackURL)
-	notifierProxyURL := fromEnv("SLACK_PROXY_URL", slackProxyURL)
-	if msteamsURL != "" || os.Getenv("MSTEAMS_URL") != "" {
-		provider = "msteams"
-		notifierURL = fromEnv("MSTEAMS_URL", msteamsURL)
-		notifierProxyURL = fromEnv("MSTEAMS_PROXY_URL", msteamsProxyURL)
+// defaultSettings returns the global controller settings set with flags and env vars
+func defaultSettings() controller.Settings {
+	return controller.Settings{
+		MetricsServer:         metricsServer,
+		ControlLoopInterval:   controlLoopInterval,
+		MeshProvider:          meshProvider,
+		EventWebhook:          fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
+		SlackURL:              fromEnv("SLACK_URL", slackURL),
+		SlackToken:            fromEnv("SLACK_TOKEN", slackToken),
+		SlackProxyURL:         fromEnv("SLACK_PROXY_URL", slackProxyURL),
+		SlackUser:             slackUser,
+		SlackChannel:          slackChannel,
+		MSTeamsURL:            fromEnv("MSTEAMS_URL", msteamsURL),
+		MSTeamsProxyURL:       fromEnv("MSTEAMS_PROXY_URL", msteamsProxyURL),
+		MaxConcurrentCanaries: maxConcurrentCanaries,
+	}
+}
+
+func initNotifier(settings controller.Settings, logger *zap.SugaredLogger) (client notifier.Interface) {
+	notifierURL := settings.SlackURL
+	if settings.MSTeamsURL != "" {
+		notifierURL = settings.MSTeamsURL
 	}
-	notifierFactory := notifier.NewFactory(notifierURL, token, notifierProxyURL, slackUser, slackChannel)
 
 	var err error
-	client, err = notifierFactory.Notifier(provider)
+	client, err = settings.Notifier()
 	if err != nil {
 		logger.Errorf("Notifier %v", err)
 	} else if len(notifierURL) > 30 {
@@ -387,6 +719,23 @@ func initNotifier(logger *zap.SugaredLogger) (client notifier.Interface) {
 	return
 }
 
+// initIncidentWatcher starts polling the incident management tool if one is configured
+func initIncidentWatcher(logger *zap.SugaredLogger, stopCh <-chan struct{}) *incident.Watcher {
+	if incidentProvider == "" {
+		return nil
+	}
+
+	provider, err := incident.NewProvider(incidentProvider, incidentURL, fromEnv("INCIDENT_TOKEN", incidentToken))
+	if err != nil {
+		logger.Fatalf("Error building incident provider: %v", err)
+	}
+	watcher := incident.NewWatcher(provider, incidentPollInterval, strings.Split(incidentSeverities, ","), logger)
+	go watcher.Run(stopCh)
+
+	logger.Infof("Traffic shifting is held during %s incidents", incidentProvider)
+	return watcher
+}
+
 func fromEnv(envVar string, defaultVal string) string {
 	if v := os.Getenv(envVar); v != "" {
 		return v
@@ -395,26 +744,39 @@ func fromEnv(envVar string, defaultVal string) string {
 }
 
 func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
+	if err := checkCRDs(flaggerClient); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func checkCRDs(flaggerClient clientset.Interface) error {
 	_, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
 	if err != nil {
-		logger.Fatalf("Canary CRD is not registered %v", err)
+		return fmt.Errorf("Canary CRD is not registered %v", err)
 	}
 
 	_, err = flaggerClient.FlaggerV1beta1().MetricTemplates(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
 	if err != nil {
-		logger.Fatalf("MetricTemplate CRD is not registered %v", err)
+		return fmt.Errorf("MetricTemplate CRD is not registered %v", err)
 	}
 
 	_, err = flaggerClient.FlaggerV1beta1().AlertProviders(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
 	if err != nil {
-		logger.Fatalf("AlertProvider CRD is not registered %v", err)
+		return fmt.Errorf("AlertProvider CRD is not registered %v", err)
 	}
+	return nil
 }
 
 func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
+	if err := checkKubernetesVersion(kubeClient, logger); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func checkKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) error {
 	ver, err := kubeClient.Discovery().ServerVersion()
 	if err != nil {
-		logger.Fatalf("Error calling Kubernetes API: %v", err)
+		return fmt.Errorf("Error calling Kubernetes API: %v", err)
 	}
 
 	k8sVersionConstraint := "^1.11.0"
@@ -425,17 +787,50 @@ func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.Sugare
 	// exists in our constraint.
 	semverConstraint, err := semver.NewConstraint(k8sVersionConstraint + "-alpha.1")
 	if err != nil {
-		logger.Fatalf("Error parsing kubernetes version constraint: %v", err)
+		return fmt.Errorf("Error parsing kubernetes version constraint: %v", err)
 	}
 
 	k8sSemver, err := semver.NewVersion(ver.GitVersion)
 	if err != nil {
-		logger.Fatalf("Error parsing kubernetes version as a semantic version: %v", err)
+		return fmt.Errorf("Error parsing kubernetes version as a semantic version: %v", err)
 	}
 
 	if !semverConstraint.Check(k8sSemver) {
-		logger.Fatalf("Unsupported version of kubernetes detected.  Expected %s, got %v", k8sVersionConstraint, ver)
+		return fmt.Errorf("Unsupported version of kubernetes detected.  Expected %s, got %v", k8sVersionConstraint, ver)
 	}
 
 	logger.Infof("Connected to Kubernetes API %s", ver)
+	return nil
+}
+
+// exportStateToFile writes the state of the canaries in the watched namespace to the file
+func exportStateToFile(path string, kubeClient kubernetes.Interface, flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
+	snapshot, err := state.Export(kubeClient, flaggerClient, namespace, version.VERSION, exportStateSecrets)
+	if err != nil {
+		logger.Fatalf("Error exporting state: %v", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		logger.Fatalf("Error marshalling state: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		logger.Fatalf("Error writing state to %s: %v", path, err)
+	}
+	logger.Infof("Exported the state of %d canaries to %s", len(snapshot.Canaries), path)
+}
+
+// importStateFromFile restores the state of the canaries from the file
+func importStateFromFile(path string, kubeClient kubernetes.Interface, flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Fatalf("Error reading state from %s: %v", path, err)
+	}
+	var snapshot state.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		logger.Fatalf("Error unmarshalling state from %s: %v", path, err)
+	}
+	if err := state.Import(kubeClient, flaggerClient, &snapshot, logger); err != nil {
+		logger.Fatalf("Error importing state: %v", err)
+	}
+	logger.Infof("Imported the state of %d canaries exported by Flagger %s", len(snapshot.Canaries), snapshot.Version)
 }
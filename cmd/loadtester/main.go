@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 	"regexp"
 	"time"
 
@@ -34,8 +35,10 @@ var (
 	port              string
 	timeout           time.Duration
 	namespaceRegexp   string
+	peers             string
 	zapReplaceGlobals bool
 	zapEncoding       string
+	launchDarklyURL   string
 )
 
 func init() {
@@ -43,8 +46,10 @@ func init() {
 	flag.StringVar(&port, "port", "9090", "Port to listen on.")
 	flag.DurationVar(&timeout, "timeout", time.Hour, "Load test exec timeout.")
 	flag.StringVar(&namespaceRegexp, "namespace-regexp", "", "Restrict access to canaries in matching namespaces.")
+	flag.StringVar(&peers, "peers", "", "Headless service address (host:port) of the load tester replicas, distributed tasks are disabled when empty.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+	flag.StringVar(&launchDarklyURL, "launchdarkly-url", "https://app.launchdarkly.com", "LaunchDarkly API address used by the feature flag gate.")
 }
 
 func main() {
@@ -69,12 +74,14 @@ func main() {
 	logger.Infof("Starting load tester v%s API on port %s", VERSION, port)
 
 	gateStorage := loadtester.NewGateStorage("in-memory")
+	flagGate := loadtester.NewFeatureFlagGate(launchDarklyURL, os.Getenv("LAUNCHDARKLY_ACCESS_TOKEN"))
+	slackInteractions := loadtester.NewSlackInteractions(os.Getenv("SLACK_SIGNING_SECRET"))
 
 	var namespaceRegexpCompiled *regexp.Regexp
 	if namespaceRegexp != "" {
 		namespaceRegexpCompiled = regexp.MustCompile(namespaceRegexp)
 	}
-	authorizer := loadtester.NewAuthorizer(namespaceRegexpCompiled)
+	authorizer := loadtester.NewAuthorizer(namespaceRegexpCompiled, peers)
 
-	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, authorizer, stopCh)
+	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, flagGate, slackInteractions, authorizer, stopCh)
 }
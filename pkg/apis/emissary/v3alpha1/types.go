@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Mapping routes the requests matching a host and a prefix to a service,
+// mappings that share the same host and prefix split the traffic according to their weight
+type Mapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MappingSpec `json:"spec"`
+}
+
+// MappingSpec defines the matching rules and the upstream service of a mapping
+type MappingSpec struct {
+	// AmbassadorID selects the Emissary installations that serve the mapping
+	// +optional
+	AmbassadorID []string `json:"ambassador_id,omitempty"`
+
+	// Hostname is the glob matched against the request host, defaults to *
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Prefix is the URL prefix matched by the mapping
+	Prefix string `json:"prefix"`
+
+	// +optional
+	PrefixRegex *bool `json:"prefix_regex,omitempty"`
+
+	// +optional
+	PrefixExact *bool `json:"prefix_exact,omitempty"`
+
+	// +optional
+	CaseSensitive *bool `json:"case_sensitive,omitempty"`
+
+	// Headers that must be present on the request for the mapping to match
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// +optional
+	RegexHeaders map[string]string `json:"regex_headers,omitempty"`
+
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Rewrite replaces the matched prefix, defaults to /
+	// +optional
+	Rewrite *string `json:"rewrite,omitempty"`
+
+	// +optional
+	HostRewrite string `json:"host_rewrite,omitempty"`
+
+	// Service is the upstream in the format [scheme://]name[.namespace][:port]
+	Service string `json:"service"`
+
+	// Weight is the percentage of the matched traffic routed to this mapping
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+
+	// +optional
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+
+	// +optional
+	IdleTimeoutMs int `json:"idle_timeout_ms,omitempty"`
+
+	// +optional
+	ConnectTimeoutMs int `json:"connect_timeout_ms,omitempty"`
+
+	// +optional
+	BypassAuth bool `json:"bypass_auth,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MappingList is a collection of mappings
+type MappingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Mapping `json:"items"`
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpoint is a set of DNS records that external-dns publishes to the DNS provider
+type DNSEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DNSEndpointSpec   `json:"spec"`
+	Status DNSEndpointStatus `json:"status,omitempty"`
+}
+
+// DNSEndpointSpec holds the records managed by external-dns
+type DNSEndpointSpec struct {
+	Endpoints []*Endpoint `json:"endpoints,omitempty"`
+}
+
+// Endpoint is a DNS record
+type Endpoint struct {
+	// DNSName is the hostname of the DNS record
+	DNSName string `json:"dnsName,omitempty"`
+
+	// Targets are the record values, e.g. IP addresses for A records or host names for CNAME records
+	Targets []string `json:"targets,omitempty"`
+
+	// RecordType is the type of the record, e.g. A, AAAA or CNAME
+	RecordType string `json:"recordType,omitempty"`
+
+	// SetIdentifier distinguishes the records of a weighted set that share the same name and type
+	// +optional
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+
+	// RecordTTL is the TTL of the record in seconds
+	// +optional
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+
+	// Labels stores the labels of the record
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ProviderSpecific stores the DNS provider specific properties of the record, e.g. aws/weight
+	// +optional
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty holds the name and value of a configuration which is specific to the DNS provider
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// DNSEndpointStatus is the status of the DNSEndpoint
+type DNSEndpointStatus struct {
+	// ObservedGeneration is the generation observed by external-dns
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DNSEndpointList is a list of DNSEndpoint resources
+type DNSEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []DNSEndpoint `json:"items"`
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryApprovalKind = "CanaryApproval"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryApproval records the approval of a canary revision promotion
+type CanaryApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryApprovalSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryApprovalList is a list of canary approval resources
+type CanaryApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryApproval `json:"items"`
+}
+
+// CanaryApprovalSpec is the specification of an approved canary revision
+type CanaryApprovalSpec struct {
+	// Reference to the canary in the same namespace
+	CanaryRef corev1.LocalObjectReference `json:"canaryRef"`
+
+	// Revision of the canary target as reported in the canary status lastAppliedSpec
+	Revision string `json:"revision"`
+
+	// Name of the approver, informational only as it is set by the
+	// client creating the approval and is not verified by Flagger
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+
+	// Reason of the approval
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
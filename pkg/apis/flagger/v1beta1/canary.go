@@ -18,10 +18,12 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
 	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -33,6 +35,30 @@ const (
 	PrimaryReadyThreshold   = 100
 	CanaryReadyThreshold    = 100
 	MetricInterval          = "1m"
+	// GitCommitAnnotation holds the Git commit SHA of the canary target revision
+	GitCommitAnnotation = "flagger.app/git-commit"
+	// RollbackRevisionAnnotation requests the rollback of the primary to the revision with the given hash
+	RollbackRevisionAnnotation = "flagger.app/rollback-revision"
+	// RerunAnalysisAnnotation requests a new analysis of the current spec after a failed run
+	RerunAnalysisAnnotation = "flagger.app/rerun-analysis"
+	// MaxConcurrentCanariesAnnotation set on a namespace overrides the max number of canaries
+	// that can run analysis at the same time in that namespace
+	MaxConcurrentCanariesAnnotation = "flagger.app/max-concurrent-canaries"
+	// IgnoreIncidentsAnnotation set to true lets the canary advance while an incident is open
+	IgnoreIncidentsAnnotation = "flagger.app/ignore-incidents"
+	// SkipSidecarCheckAnnotation set to true disables the sidecar checks for meshes running without sidecars
+	SkipSidecarCheckAnnotation = "flagger.app/skip-sidecar-check"
+	// KnativePrimaryRevisionAnnotation set on a Knative service holds the name of the primary revision
+	KnativePrimaryRevisionAnnotation = "flagger.app/primary-revision"
+)
+
+const (
+	// BuiltinMetricRequestSuccessRate is the percentage of non 5xx responses,
+	// the query is generated based on the mesh or ingress provider
+	BuiltinMetricRequestSuccessRate = "request-success-rate"
+	// BuiltinMetricRequestDuration is the P99 request duration in milliseconds,
+	// the query is generated based on the mesh or ingress provider
+	BuiltinMetricRequestDuration = "request-duration"
 )
 
 // +genclient
@@ -76,6 +102,10 @@ type CanarySpec struct {
 	// +optional
 	AutoscalerRef *AutoscalerRefernce `json:"autoscalerRef,omitempty"`
 
+	// TargetGroup references the Deployments that are promoted and rolled back together with the target
+	// +optional
+	TargetGroup []CanaryTargetGroupMember `json:"targetGroup,omitempty"`
+
 	// Reference to NGINX ingress resource
 	// +optional
 	IngressRef *LocalObjectReference `json:"ingressRef,omitempty"`
@@ -116,6 +146,102 @@ type CanarySpec struct {
 	// Canary is suspended during an analysis, its paused until the Canary is unsuspended.
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
+
+	// RevisionHistoryLimit is the number of replaced primary revisions kept for rollbacks
+	// +optional
+	RevisionHistoryLimit int `json:"revisionHistoryLimit,omitempty"`
+
+	// Group is the name of the canary group, the canaries in the same namespace
+	// and group are analysed and promoted in lockstep
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// GroupFailurePolicy defines how this canary reacts when another canary of the group
+	// or dependsOn chain fails, can be Abort or Continue (default Abort)
+	// +optional
+	GroupFailurePolicy GroupFailurePolicy `json:"groupFailurePolicy,omitempty"`
+
+	// DependsOn is the list of canaries in the same namespace that must finish their rollout
+	// before this canary starts its own, the group failure policy applies to the whole chain
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// PrimaryStrategy overrides the update strategy copied from the target
+	// deployment to the primary deployment on promotion
+	// +optional
+	PrimaryStrategy *PrimaryStrategy `json:"primaryStrategy,omitempty"`
+
+	// PreviousRing references the canary of the previous promotion ring,
+	// this canary starts the analysis of a new revision only after the
+	// previous ring canary has promoted the same images
+	// +optional
+	PreviousRing *CanaryRingReference `json:"previousRing,omitempty"`
+}
+
+// CanaryRingReference references a canary in a local or remote cluster
+type CanaryRingReference struct {
+	// Cluster is the name of the remote cluster kubeconfig secret,
+	// defaults to the cluster of this canary
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+
+	// Namespace of the canary, defaults to the namespace of this canary
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the canary
+	Name string `json:"name"`
+}
+
+// PrimaryStrategyType is the update strategy type of the primary deployment
+type PrimaryStrategyType string
+
+const (
+	// PrimaryStrategyRollingUpdate replaces the primary pods gradually
+	PrimaryStrategyRollingUpdate PrimaryStrategyType = "RollingUpdate"
+	// PrimaryStrategyRecreate kills all the primary pods before creating the new ones
+	PrimaryStrategyRecreate PrimaryStrategyType = "Recreate"
+)
+
+// PrimaryStrategy defines how the primary deployment is updated on promotion
+type PrimaryStrategy struct {
+	// Type of the update strategy, can be RollingUpdate or Recreate (default RollingUpdate)
+	// +optional
+	Type PrimaryStrategyType `json:"type,omitempty"`
+
+	// MaxSurge overrides the target rolling update max surge
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable overrides the target rolling update max unavailable
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// GroupFailurePolicy defines how a canary reacts to the failure of another canary of its group
+type GroupFailurePolicy string
+
+const (
+	// GroupFailurePolicyAbort rolls back the canary when another canary of the group fails
+	GroupFailurePolicyAbort GroupFailurePolicy = "Abort"
+	// GroupFailurePolicyContinue keeps analysing the canary when another canary of the group fails
+	GroupFailurePolicyContinue GroupFailurePolicy = "Continue"
+)
+
+// CanaryTargetGroupMember references a Deployment of the canary target group
+type CanaryTargetGroupMember struct {
+	// Name of the Deployment
+	Name string `json:"name"`
+
+	// Port of the member Kubernetes services
+	// Defaults to CanaryService.Port
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Target port number or name of the member Kubernetes services
+	// Defaults to the member port
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -155,6 +281,11 @@ type CanaryService struct {
 	// +optional
 	Gateways []string `json:"gateways,omitempty"`
 
+	// Gateway settings of the Istio gateway generated for the virtual service hosts,
+	// used when no gateways are specified
+	// +optional
+	Gateway *IstioGateway `json:"gateway,omitempty"`
+
 	// Gateways that the HTTPRoute needs to attach itself to.
 	// Must be specified while using the Gateway API as a provider.
 	// +optional
@@ -175,6 +306,11 @@ type CanaryService struct {
 	// +optional
 	TrafficPolicy *istiov1alpha3.TrafficPolicy `json:"trafficPolicy,omitempty"`
 
+	// Subsets enables the routing by Istio destination rule subsets of the apex service,
+	// the primary and canary services are not generated when subsets are enabled
+	// +optional
+	Subsets *IstioSubsets `json:"subsets,omitempty"`
+
 	// URI match conditions for the generated service
 	// +optional
 	Match []istiov1alpha3.HTTPMatchRequest `json:"match,omitempty"`
@@ -216,11 +352,47 @@ type CanaryService struct {
 	Canary *CustomMetadata `json:"canary,omitempty"`
 }
 
+// IstioGateway defines the Istio gateway generated by Flagger
+type IstioGateway struct {
+	// Selector of the gateway pods
+	// Defaults to istio: ingressgateway
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// Port number of the gateway server
+	// Defaults to 80 or to 443 when TLS is enabled
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// TLS settings of the gateway server
+	// +optional
+	TLS *istiov1alpha3.ServerTLSSettings `json:"tls,omitempty"`
+}
+
+// IstioSubsets defines the apex service pod selector used with destination rule subsets
+type IstioSubsets struct {
+	// Selector of the apex service, must match the labels
+	// shared by the primary and the canary pods
+	Selector map[string]string `json:"selector"`
+}
+
 // CanaryAnalysis is used to describe how the analysis should be done
 type CanaryAnalysis struct {
 	// Schedule interval for this canary analysis
 	Interval string `json:"interval"`
 
+	// Max duration of the rollout after which the canary is rolled back
+	// +optional
+	MaxDuration string `json:"maxDuration,omitempty"`
+
+	// Duration without weight or phase changes after which the canary is reported as stuck
+	// +optional
+	StuckDuration string `json:"stuckDuration,omitempty"`
+
+	// Require a CanaryApproval of the canary revision before promotion
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+
 	// Number of checks to run for A/B Testing and Blue/Green
 	// +optional
 	Iterations int `json:"iterations,omitempty"`
@@ -233,6 +405,10 @@ type CanaryAnalysis struct {
 	// +optional
 	MirrorWeight int `json:"mirrorWeight,omitempty"`
 
+	// Run a copy of the primary workload scaled as the canary during the analysis
+	// +optional
+	Baseline bool `json:"baseline,omitempty"`
+
 	// Max traffic weight routed to canary
 	// +optional
 	MaxWeight int `json:"maxWeight,omitempty"`
@@ -252,12 +428,26 @@ type CanaryAnalysis struct {
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
+	// Max percentage of failed checks relative to the checks needed to complete the analysis,
+	// takes precedence over the threshold
+	// +optional
+	ThresholdPercentage int `json:"thresholdPercentage,omitempty"`
+
+	// Number of consecutive successful checks after which the failed checks counter is decremented
+	// +optional
+	FailedChecksDecay int `json:"failedChecksDecay,omitempty"`
+
 	// Percentage of pods that need to be available to consider primary as ready
 	PrimaryReadyThreshold *int `json:"primaryReadyThreshold,omitempty"`
 
 	// Percentage of pods that need to be available to consider canary as ready
 	CanaryReadyThreshold *int `json:"canaryReadyThreshold,omitempty"`
 
+	// PrimaryReadinessGate holds the first traffic shift of the analysis and the promotion
+	// until the primary pods are registered as ready endpoints of the primary service
+	// +optional
+	PrimaryReadinessGate *CanaryReadinessGate `json:"primaryReadinessGate,omitempty"`
+
 	// Alert list for this canary analysis
 	Alerts []CanaryAlert `json:"alerts,omitempty"`
 
@@ -276,6 +466,158 @@ type CanaryAnalysis struct {
 	// SessionAffinity represents the session affinity settings for a canary run.
 	// +optional
 	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// Localities the canary traffic is shifted for, one after the other
+	// +optional
+	Localities []CanaryLocality `json:"localities,omitempty"`
+
+	// RollbackWindow allows rolling back the primary to the previous revision after promotion
+	// +optional
+	RollbackWindow *CanaryRollbackWindow `json:"rollbackWindow,omitempty"`
+
+	// Report of the analysis produced when the rollout completes
+	// +optional
+	Report *CanaryReport `json:"report,omitempty"`
+
+	// Bandit adjusts the traffic weight based on the rewards of the canary
+	// compared to the primary instead of fixed steps (experimental)
+	// +optional
+	Bandit *CanaryBandit `json:"bandit,omitempty"`
+
+	// Soak holds the canary at a fixed weight for a duration before promotion
+	// +optional
+	Soak *CanarySoak `json:"soak,omitempty"`
+
+	// RecordingRules generates Prometheus recording rules for the builtin metrics
+	// and reads the recorded series during the analysis
+	// +optional
+	RecordingRules *CanaryRecordingRules `json:"recordingRules,omitempty"`
+}
+
+// CanaryRecordingRules describes the generated PrometheusRule
+type CanaryRecordingRules struct {
+	// Evaluation interval of the rule group (default Prometheus global interval)
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels added to the PrometheusRule, used to match the Prometheus rule selector
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CanaryReport describes where the analysis report is published
+type CanaryReport struct {
+	// ConfigMap stores the report in the <canary>-analysis-report ConfigMap of the canary namespace
+	// +optional
+	ConfigMap bool `json:"configMap,omitempty"`
+
+	// URL address where the report is posted
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Request timeout for posting the report
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// SecretRef references a secret in the canary namespace containing the address
+	// and bearer token, or the client certificate and key used to post the report
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Regression compares the steady-state metrics of the canary with the
+	// metrics of the previous successful release
+	// +optional
+	Regression *CanaryRegression `json:"regression,omitempty"`
+}
+
+// CanaryRegression describes the comparison of the canary metrics with the previous successful release
+type CanaryRegression struct {
+	// Tolerance is the change in percent of a metric value allowed
+	// before it is flagged as a regression (default 10)
+	// +optional
+	Tolerance int `json:"tolerance,omitempty"`
+
+	// Halt the advancement while a regression is detected,
+	// by default the regressions are only reported with events and alerts
+	// +optional
+	Halt bool `json:"halt,omitempty"`
+}
+
+// GetTolerance returns the regression tolerance in percent (default 10)
+func (r *CanaryRegression) GetTolerance() int {
+	if r.Tolerance <= 0 {
+		return 10
+	}
+	return r.Tolerance
+}
+
+// CanaryReadinessGate describes the wait condition of the primary endpoints
+type CanaryReadinessGate struct {
+	// Time to wait after the primary endpoints are ready for the mesh to program the proxies
+	// +optional
+	Delay string `json:"delay,omitempty"`
+}
+
+// CanarySoak describes the time-based promotion
+type CanarySoak struct {
+	// Traffic weight routed to the canary during the soak
+	Weight int `json:"weight"`
+
+	// Duration of the soak after which the canary is promoted
+	Duration string `json:"duration"`
+}
+
+// CanaryBandit describes the multi-armed bandit traffic allocation
+type CanaryBandit struct {
+	// Rewards compared between the canary and the primary
+	Rewards []CanaryBanditReward `json:"rewards"`
+
+	// Lowest traffic weight routed to the canary to keep exploring it (default stepWeight)
+	// +optional
+	MinWeight int `json:"minWeight,omitempty"`
+}
+
+// BanditGoal defines if a reward is better when higher or lower
+type BanditGoal string
+
+const (
+	// BanditGoalMaximize prefers the version with the highest reward
+	BanditGoalMaximize BanditGoal = "Maximize"
+	// BanditGoalMinimize prefers the version with the lowest reward
+	BanditGoalMinimize BanditGoal = "Minimize"
+)
+
+// CanaryBanditReward references an analysis metric used as reward
+type CanaryBanditReward struct {
+	// Name of the analysis metric, the metric must reference a metric template
+	Metric string `json:"metric"`
+
+	// Goal of the reward, can be Maximize or Minimize (default Maximize)
+	// +optional
+	Goal BanditGoal `json:"goal,omitempty"`
+
+	// Weight of the reward in the score (default 1)
+	// +optional
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// CanaryRollbackWindow describes the post-promotion checks
+type CanaryRollbackWindow struct {
+	// Duration of the window after promotion in which the primary can be rolled back
+	Duration string `json:"duration"`
+
+	// Metric checks run against the primary during the rollback window
+	// +optional
+	Metrics []CanaryMetric `json:"metrics,omitempty"`
+}
+
+// CanaryLocality selects the requests originating from a region or zone
+type CanaryLocality struct {
+	// Name of the locality
+	Name string `json:"name"`
+
+	// Match conditions of the requests coming from this locality
+	Match []istiov1alpha3.HTTPMatchRequest `json:"match"`
 }
 
 type SessionAffinity struct {
@@ -296,6 +638,11 @@ type CanaryMetric struct {
 	// Interval represents the windows size
 	Interval string `json:"interval,omitempty"`
 
+	// Windows is a list of window sizes the metric is evaluated over,
+	// the check passes only if the metric is within range in all windows
+	// +optional
+	Windows []string `json:"windows,omitempty"`
+
 	// Deprecated: Max value accepted for this metric (replaced by ThresholdRange)
 	Threshold float64 `json:"threshold,omitempty"`
 
@@ -303,6 +650,19 @@ type CanaryMetric struct {
 	// +optional
 	ThresholdRange *CanaryThresholdRange `json:"thresholdRange,omitempty"`
 
+	// StepThresholds overrides the range accepted for this metric based on the canary weight
+	// +optional
+	StepThresholds []CanaryStepThreshold `json:"stepThresholds,omitempty"`
+
+	// SLO derives the accepted range from a service level objective,
+	// the metric must return a success rate in percent
+	// +optional
+	SLO *CanarySLO `json:"slo,omitempty"`
+
+	// SampleSize holds the analysis until the metric interval contains enough samples
+	// +optional
+	SampleSize *CanaryMetricSampleSize `json:"sampleSize,omitempty"`
+
 	// Deprecated: Prometheus query for this metric (replaced by TemplateRef)
 	// +optional
 	Query string `json:"query,omitempty"`
@@ -327,6 +687,43 @@ type CanaryThresholdRange struct {
 	Max *float64 `json:"max,omitempty"`
 }
 
+// CanaryStepThreshold defines the range accepted for a metric starting from a canary weight
+type CanaryStepThreshold struct {
+	// Weight from which the range applies
+	Weight int `json:"weight"`
+
+	// Range value accepted for this metric
+	ThresholdRange CanaryThresholdRange `json:"thresholdRange"`
+}
+
+// CanaryMetricSampleSize defines the minimum number of samples required to evaluate a metric
+type CanaryMetricSampleSize struct {
+	// Min number of samples e.g. requests
+	Min float64 `json:"min"`
+
+	// TemplateRef references a metric template that returns the number of samples
+	TemplateRef CrossNamespaceObjectReference `json:"templateRef"`
+}
+
+// CanarySLO defines the service level objective used for metrics validation
+type CanarySLO struct {
+	// Objective is the availability target in percent e.g. 99.9
+	Objective float64 `json:"objective"`
+
+	// MaxBurnRate is the error budget burn rate at which the analysis is halted,
+	// computed over the metric interval, defaults to 1
+	// +optional
+	MaxBurnRate float64 `json:"maxBurnRate,omitempty"`
+}
+
+// GetMaxBurnRate returns the max burn rate (default 1)
+func (s *CanarySLO) GetMaxBurnRate() float64 {
+	if s.MaxBurnRate <= 0 {
+		return 1
+	}
+	return s.MaxBurnRate
+}
+
 // AlertSeverity defines alert filtering based on severity levels
 type AlertSeverity string
 
@@ -390,6 +787,17 @@ type CanaryWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// Weights restricts a confirm-traffic-increase gate to the steps that
+	// set the canary weight to one of the listed values
+	// +optional
+	Weights []int `json:"weights,omitempty"`
+
+	// SecretRef references a secret in the canary namespace containing the webhook
+	// address and bearer token, or the client certificate and key (tls.crt, tls.key)
+	// and the CA (ca.crt) used for mutual TLS
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
 // CanaryWebhookPayload holds the deployment info and metadata sent to webhooks
@@ -403,8 +811,17 @@ type CanaryWebhookPayload struct {
 	// Phase of the canary analysis
 	Phase CanaryPhase `json:"phase"`
 
+	// CanaryWeight is the traffic weight routed to the canary when the webhook was called
+	CanaryWeight int `json:"canaryWeight,omitempty"`
+
+	// NextWeight is the traffic weight the canary will receive if a confirm-traffic-increase gate passes
+	NextWeight int `json:"nextWeight,omitempty"`
+
 	// Metadata (key-value pairs) for this webhook
 	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Metrics holds the last value measured for each metric of the current analysis
+	Metrics map[string]float64 `json:"metrics,omitempty"`
 }
 
 // CrossNamespaceObjectReference contains enough information to let you locate the
@@ -439,6 +856,15 @@ type LocalObjectReference struct {
 
 	// Name of the referent
 	Name string `json:"name"`
+
+	// Scalable opts in a custom kind that implements the scale subresource
+	// +optional
+	Scalable bool `json:"scalable,omitempty"`
+}
+
+// IsKnativeService returns true if the reference targets a Knative Serving service
+func (r LocalObjectReference) IsKnativeService() bool {
+	return r.Kind == "Service" && strings.HasPrefix(r.APIVersion, "serving.knative.dev/")
 }
 
 type AutoscalerRefernce struct {
@@ -535,14 +961,132 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 	return interval
 }
 
+// GetAnalysisMaxDuration returns the max rollout duration (zero when not set)
+func (c *Canary) GetAnalysisMaxDuration() time.Duration {
+	if c.GetAnalysis().MaxDuration == "" {
+		return 0
+	}
+
+	maxDuration, err := time.ParseDuration(c.GetAnalysis().MaxDuration)
+	if err != nil {
+		return 0
+	}
+
+	return maxDuration
+}
+
+// GetPrimaryReadinessDelay returns the delay after the primary endpoints are ready,
+// zero is returned if the delay isn't configured
+func (c *Canary) GetPrimaryReadinessDelay() (time.Duration, error) {
+	gate := c.GetAnalysis().PrimaryReadinessGate
+	if gate == nil || gate.Delay == "" {
+		return 0, nil
+	}
+
+	delay, err := time.ParseDuration(gate.Delay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid primary readiness delay %q: %w", gate.Delay, err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("invalid primary readiness delay %q: can't be negative", gate.Delay)
+	}
+
+	return delay, nil
+}
+
+// GetAnalysisStuckDuration returns the duration after which a stalled rollout is reported (zero when not set)
+func (c *Canary) GetAnalysisStuckDuration() time.Duration {
+	if c.GetAnalysis().StuckDuration == "" {
+		return 0
+	}
+
+	stuckDuration, err := time.ParseDuration(c.GetAnalysis().StuckDuration)
+	if err != nil {
+		return 0
+	}
+
+	return stuckDuration
+}
+
+// GetRollbackWindow returns the post-promotion rollback window duration
+func (c *Canary) GetRollbackWindow() time.Duration {
+	if c.GetAnalysis().RollbackWindow == nil {
+		return 0
+	}
+
+	window, err := time.ParseDuration(c.GetAnalysis().RollbackWindow.Duration)
+	if err != nil {
+		return 0
+	}
+
+	return window
+}
+
 // GetAnalysisThreshold returns the canary threshold (default 1)
 func (c *Canary) GetAnalysisThreshold() int {
+	// a failed check during the soak prevents the promotion
+	if c.GetAnalysis().Soak != nil {
+		return 1
+	}
+	// roll back once the failed checks exceed the percentage of the planned checks
+	if percentage := c.GetAnalysis().ThresholdPercentage; percentage > 0 {
+		return percentage*c.GetAnalysisChecks()/100 + 1
+	}
 	if c.GetAnalysis().Threshold > 0 {
 		return c.GetAnalysis().Threshold
 	}
 	return 1
 }
 
+// GetAnalysisChecks returns the number of successful checks needed to complete the analysis
+func (c *Canary) GetAnalysisChecks() int {
+	analysis := c.GetAnalysis()
+	checks := 1
+	switch {
+	case analysis.Iterations > 0:
+		checks = analysis.Iterations
+	case len(analysis.StepWeights) > 0:
+		checks = len(analysis.StepWeights)
+	case analysis.StepWeight > 0:
+		maxWeight := analysis.MaxWeight
+		if maxWeight <= 0 {
+			maxWeight = 100
+		}
+		checks = (maxWeight + analysis.StepWeight - 1) / analysis.StepWeight
+	}
+
+	// the traffic shifting restarts for each locality
+	if len(analysis.Localities) > 1 {
+		checks *= len(analysis.Localities)
+	}
+	return checks
+}
+
+// GetAnalysisSoakDuration returns the canary soak duration,
+// zero is returned if the soak isn't configured
+func (c *Canary) GetAnalysisSoakDuration() (time.Duration, error) {
+	if c.GetAnalysis().Soak == nil {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(c.GetAnalysis().Soak.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid soak duration %q: %w", c.GetAnalysis().Soak.Duration, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid soak duration %q: must be positive", c.GetAnalysis().Soak.Duration)
+	}
+
+	return duration, nil
+}
+
+// IsSoaking returns true if the canary is held at the soak weight
+func (c *Canary) IsSoaking() bool {
+	soak := c.GetAnalysis().Soak
+	return soak != nil && c.Status.Phase == CanaryPhaseProgressing &&
+		c.Status.CanaryWeight > 0 && c.Status.CanaryWeight == soak.Weight
+}
+
 // GetAnalysisPrimaryReadyThreshold returns the canary primaryReadyThreshold (default 100)
 func (c *Canary) GetAnalysisPrimaryReadyThreshold() int {
 	if c.GetAnalysis().PrimaryReadyThreshold != nil {
@@ -572,3 +1116,11 @@ func (c *Canary) SkipAnalysis() bool {
 	}
 	return c.Spec.SkipAnalysis
 }
+
+// GetGroupFailurePolicy returns the group failure policy (default Abort)
+func (c *Canary) GetGroupFailurePolicy() GroupFailurePolicy {
+	if c.Spec.GroupFailurePolicy == "" {
+		return GroupFailurePolicyAbort
+	}
+	return c.Spec.GroupFailurePolicy
+}
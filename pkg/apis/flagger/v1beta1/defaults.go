@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryDefaultKind = "CanaryDefault"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryDefault holds the analysis defaults inherited by the canaries in the same namespace
+type CanaryDefault struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CanaryDefaultSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryDefaultList is a list of canary default resources
+type CanaryDefaultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryDefault `json:"items"`
+}
+
+// CanaryDefaultSpec is the specification of the analysis defaults,
+// a canary inherits each value unless it sets its own
+type CanaryDefaultSpec struct {
+	// Schedule interval used when the canary analysis has no interval
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Number of checks before rollback used when the canary analysis has no threshold
+	// +optional
+	Threshold int `json:"threshold,omitempty"`
+
+	// Metric checks added to the canary analysis unless a metric with the same name exists
+	// +optional
+	Metrics []CanaryMetric `json:"metrics,omitempty"`
+
+	// Enforce replaces the canary metrics with the default metrics of the same name
+	// +optional
+	Enforce bool `json:"enforce,omitempty"`
+
+	// Alert list added to the canary analysis unless an alert with the same name exists
+	// +optional
+	Alerts []CanaryAlert `json:"alerts,omitempty"`
+
+	// Webhook list added to the canary analysis unless a webhook with the same name exists
+	// +optional
+	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
+}
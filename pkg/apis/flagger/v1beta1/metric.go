@@ -88,6 +88,7 @@ type MetricTemplateModel struct {
 	Service   string            `json:"service"`
 	Ingress   string            `json:"ingress"`
 	Route     string            `json:"route"`
+	Baseline  string            `json:"baseline"`
 	Interval  string            `json:"interval"`
 	Variables map[string]string `json:"variables"`
 }
@@ -101,6 +102,7 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"service":   func() string { return mtm.Service },
 		"ingress":   func() string { return mtm.Ingress },
 		"route":     func() string { return mtm.Route },
+		"baseline":  func() string { return mtm.Baseline },
 		"interval":  func() string { return mtm.Interval },
 		"variables": func() map[string]string { return mtm.Variables },
 	}
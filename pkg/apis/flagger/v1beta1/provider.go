@@ -15,4 +15,17 @@ const (
 	OsmProvider        string = "osm"
 	KumaProvider       string = "kuma"
 	GatewayAPIProvider string = "gatewayapi"
+	OpenShiftProvider  string = "openshift"
+	// HAProxyProvider shifts the traffic with the blue/green balance of the HAProxy ingress controller
+	HAProxyProvider string = "haproxy"
+	// EmissaryProvider shifts the traffic with weighted Emissary-ingress Mappings
+	EmissaryProvider string = "emissary"
+	// KnativeProvider splits the traffic between the primary and the latest revision of a Knative service
+	KnativeProvider string = "knative"
+	// GKEProvider manages Gateway API HTTPRoutes waiting for the GKE Gateway controller to reconcile the route changes
+	GKEProvider string = "gke"
+	// ExternalDNSProvider shifts the traffic with weighted DNS records managed by external-dns
+	ExternalDNSProvider string = "externaldns"
+	// SelectorSwitchProvider switches the apex service selector between the primary and canary pods
+	SelectorSwitchProvider string = "selector-switch"
 )
@@ -51,6 +51,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MetricTemplateList{},
 		&AlertProvider{},
 		&AlertProviderList{},
+		&CanaryApproval{},
+		&CanaryApprovalList{},
+		&CanaryDefault{},
+		&CanaryDefaultList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CanaryAnalysisReport holds the outcome of a rollout, it is stored in a ConfigMap
+// and posted to the report URL when the canary is promoted or rolled back
+type CanaryAnalysisReport struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Namespace of the canary
+	Namespace string `json:"namespace"`
+
+	// Revision of the canary target
+	Revision string `json:"revision"`
+
+	// Phase the rollout ended with
+	Phase CanaryPhase `json:"phase"`
+
+	// StartTime of the rollout
+	StartTime metav1.Time `json:"startTime"`
+
+	// EndTime of the rollout
+	EndTime metav1.Time `json:"endTime"`
+
+	// Duration of the rollout
+	Duration string `json:"duration"`
+
+	// Incomplete is set when Flagger restarted during the rollout and the first steps are missing
+	Incomplete bool `json:"incomplete,omitempty"`
+
+	// Steps of the rollout in the order they ran
+	Steps []CanaryAnalysisStep `json:"steps"`
+
+	// Summary holds the mean of each metric measured at the highest canary weight of the analysis
+	// +optional
+	Summary map[string]float64 `json:"summary,omitempty"`
+}
+
+// CanaryAnalysisBaseline holds the summarized metrics of the last successful rollout,
+// the metrics of the next rollouts are compared with the baseline to detect regressions
+type CanaryAnalysisBaseline struct {
+	// Revision of the canary target
+	Revision string `json:"revision"`
+
+	// Images of the canary target containers
+	// +optional
+	Images []CanaryImage `json:"images,omitempty"`
+
+	// EndTime of the rollout
+	EndTime metav1.Time `json:"endTime"`
+
+	// Metrics holds the mean of each metric measured at the highest canary weight of the analysis
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// CanaryAnalysisStep holds the metric samples and webhook outcomes of a rollout step
+type CanaryAnalysisStep struct {
+	// Phase of the canary during the step
+	Phase CanaryPhase `json:"phase"`
+
+	// CanaryWeight is the traffic weight routed to the canary during the step
+	CanaryWeight int `json:"canaryWeight"`
+
+	// Iterations is the number of iterations run before the step
+	Iterations int `json:"iterations"`
+
+	// StartTime of the step
+	StartTime metav1.Time `json:"startTime"`
+
+	// Duration of the step
+	Duration string `json:"duration"`
+
+	// Metrics samples collected during the step
+	// +optional
+	Metrics []CanaryMetricSample `json:"metrics,omitempty"`
+
+	// Webhooks called during the step
+	// +optional
+	Webhooks []CanaryWebhookOutcome `json:"webhooks,omitempty"`
+}
+
+// CanaryMetricSample is a metric value measured during the analysis
+type CanaryMetricSample struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Value of the metric
+	Value float64 `json:"value"`
+
+	// Time the value was measured
+	Time metav1.Time `json:"time"`
+}
+
+// CanaryWebhookOutcome is the result of the calls made to a webhook during a step
+type CanaryWebhookOutcome struct {
+	// Name of the webhook
+	Name string `json:"name"`
+
+	// Type of the webhook
+	Type HookType `json:"type"`
+
+	// Passed is false if the last call failed
+	Passed bool `json:"passed"`
+
+	// Error returned by the last call
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Attempts is the number of consecutive calls with the same outcome
+	Attempts int `json:"attempts"`
+
+	// Time of the last call
+	Time metav1.Time `json:"time"`
+}
@@ -11,8 +11,34 @@ type CanaryConditionType string
 const (
 	// PromotedType refers to the result of the last canary analysis
 	PromotedType CanaryConditionType = "Promoted"
+
+	// HealthyType refers to the rollout health, it is true only when
+	// no analysis is running and the primary serves the latest revision
+	HealthyType CanaryConditionType = "Healthy"
+
+	// MeshAvailableType is a preflight check of the mesh or ingress API availability
+	MeshAvailableType CanaryConditionType = "MeshAvailable"
+
+	// MetricsAvailableType is a preflight check of the metrics providers availability
+	MetricsAvailableType CanaryConditionType = "MetricsAvailable"
+
+	// SidecarInjectedType is a preflight check of the mesh sidecar injection
+	SidecarInjectedType CanaryConditionType = "SidecarInjected"
+
+	// SidecarRunningType reports whether the canary pods run the mesh proxy during the analysis
+	SidecarRunningType CanaryConditionType = "SidecarRunning"
+
+	// WebhooksReachableType is a preflight check of the webhooks connectivity
+	WebhooksReachableType CanaryConditionType = "WebhooksReachable"
+
+	// SpecValidType reports the validation errors of the canary spec
+	SpecValidType CanaryConditionType = "SpecValid"
 )
 
+// SidecarMissingReason is the SidecarRunning condition reason set when
+// the canary pods don't run the mesh proxy and the analysis is halted
+const SidecarMissingReason = "SidecarMissing"
+
 // CanaryCondition is a status condition for a Canary
 type CanaryCondition struct {
 	// Type of this condition
@@ -74,6 +100,8 @@ type CanaryStatus struct {
 	CanaryWeight int         `json:"canaryWeight"`
 	Iterations   int         `json:"iterations"`
 	// +optional
+	Locality int `json:"locality,omitempty"`
+	// +optional
 	PreviousSessionAffinityCookie string `json:"previousSessionAffinityCookie,omitempty"`
 	// +optional
 	SessionAffinityCookie string `json:"sessionAffinityCookie,omitempty"`
@@ -85,6 +113,62 @@ type CanaryStatus struct {
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// LastProgressTime is the time of the last canary weight or phase change
+	// +optional
+	LastProgressTime metav1.Time `json:"lastProgressTime,omitempty"`
+	// Images of the canary target containers for the last applied spec
+	// +optional
+	Images []CanaryImage `json:"images,omitempty"`
+	// Commit is the Git commit SHA of the canary target for the last applied spec
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// Regression holds the steady-state metrics of the current analysis
+	// compared with the previous successful release
+	// +optional
+	Regression *CanaryRegressionStatus `json:"regression,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
 }
+
+// CanaryRegressionStatus holds the metrics measured at the steady-state weight of the analysis
+type CanaryRegressionStatus struct {
+	// CanaryWeight the metrics were measured at
+	CanaryWeight int `json:"canaryWeight"`
+
+	// Metrics measured at the canary weight
+	// +optional
+	Metrics []CanaryRegressionMetric `json:"metrics,omitempty"`
+}
+
+// CanaryRegressionMetric is the mean of the samples of a metric
+type CanaryRegressionMetric struct {
+	// Name of the metric
+	Name string `json:"name"`
+
+	// Mean of the metric samples
+	Mean float64 `json:"mean"`
+
+	// Samples is the number of metric samples
+	Samples int `json:"samples"`
+
+	// Regressed is set once the regression of the metric has been reported
+	// +optional
+	Regressed bool `json:"regressed,omitempty"`
+}
+
+// CanaryImage is the image of a canary target container
+type CanaryImage struct {
+	// Container name
+	Container string `json:"container"`
+
+	// Image reference
+	Image string `json:"image"`
+
+	// Tag of the image
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest of the image
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
@@ -26,6 +26,7 @@ import (
 	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -242,6 +243,11 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.PrimaryReadinessGate != nil {
+		in, out := &in.PrimaryReadinessGate, &out.PrimaryReadinessGate
+		*out = new(CanaryReadinessGate)
+		**out = **in
+	}
 	if in.Alerts != nil {
 		in, out := &in.Alerts, &out.Alerts
 		*out = make([]CanaryAlert, len(*in))
@@ -249,69 +255,695 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
-		*out = make([]CanaryMetric, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+		*out = make([]CanaryMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(SessionAffinity)
+		**out = **in
+	}
+	if in.Localities != nil {
+		in, out := &in.Localities, &out.Localities
+		*out = make([]CanaryLocality, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollbackWindow != nil {
+		in, out := &in.RollbackWindow, &out.RollbackWindow
+		*out = new(CanaryRollbackWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(CanaryReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bandit != nil {
+		in, out := &in.Bandit, &out.Bandit
+		*out = new(CanaryBandit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Soak != nil {
+		in, out := &in.Soak, &out.Soak
+		*out = new(CanarySoak)
+		**out = **in
+	}
+	if in.RecordingRules != nil {
+		in, out := &in.RecordingRules, &out.RecordingRules
+		*out = new(CanaryRecordingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
+func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisBaseline) DeepCopyInto(out *CanaryAnalysisBaseline) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]CanaryImage, len(*in))
+		copy(*out, *in)
+	}
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisBaseline.
+func (in *CanaryAnalysisBaseline) DeepCopy() *CanaryAnalysisBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisReport) DeepCopyInto(out *CanaryAnalysisReport) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CanaryAnalysisStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisReport.
+func (in *CanaryAnalysisReport) DeepCopy() *CanaryAnalysisReport {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisStep) DeepCopyInto(out *CanaryAnalysisStep) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhookOutcome, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisStep.
+func (in *CanaryAnalysisStep) DeepCopy() *CanaryAnalysisStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryApproval) DeepCopyInto(out *CanaryApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryApproval.
+func (in *CanaryApproval) DeepCopy() *CanaryApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryApprovalList) DeepCopyInto(out *CanaryApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryApprovalList.
+func (in *CanaryApprovalList) DeepCopy() *CanaryApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryApprovalSpec) DeepCopyInto(out *CanaryApprovalSpec) {
+	*out = *in
+	out.CanaryRef = in.CanaryRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryApprovalSpec.
+func (in *CanaryApprovalSpec) DeepCopy() *CanaryApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryBandit) DeepCopyInto(out *CanaryBandit) {
+	*out = *in
+	if in.Rewards != nil {
+		in, out := &in.Rewards, &out.Rewards
+		*out = make([]CanaryBanditReward, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryBandit.
+func (in *CanaryBandit) DeepCopy() *CanaryBandit {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryBandit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryBanditReward) DeepCopyInto(out *CanaryBanditReward) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryBanditReward.
+func (in *CanaryBanditReward) DeepCopy() *CanaryBanditReward {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryBanditReward)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
+func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDefault) DeepCopyInto(out *CanaryDefault) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDefault.
+func (in *CanaryDefault) DeepCopy() *CanaryDefault {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryDefault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryDefault) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDefaultList) DeepCopyInto(out *CanaryDefaultList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryDefault, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDefaultList.
+func (in *CanaryDefaultList) DeepCopy() *CanaryDefaultList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryDefaultList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryDefaultList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryDefaultSpec) DeepCopyInto(out *CanaryDefaultSpec) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = make([]CanaryAlert, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryDefaultSpec.
+func (in *CanaryDefaultSpec) DeepCopy() *CanaryDefaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryDefaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryImage) DeepCopyInto(out *CanaryImage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryImage.
+func (in *CanaryImage) DeepCopy() *CanaryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Canary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
+func (in *CanaryList) DeepCopy() *CanaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryLocality) DeepCopyInto(out *CanaryLocality) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryLocality.
+func (in *CanaryLocality) DeepCopy() *CanaryLocality {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryLocality)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ThresholdRange != nil {
+		in, out := &in.ThresholdRange, &out.ThresholdRange
+		*out = new(CanaryThresholdRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StepThresholds != nil {
+		in, out := &in.StepThresholds, &out.StepThresholds
+		*out = make([]CanaryStepThreshold, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(CanarySLO)
+		**out = **in
+	}
+	if in.SampleSize != nil {
+		in, out := &in.SampleSize, &out.SampleSize
+		*out = new(CanaryMetricSampleSize)
+		**out = **in
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.TemplateVariables != nil {
+		in, out := &in.TemplateVariables, &out.TemplateVariables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
+func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricSample) DeepCopyInto(out *CanaryMetricSample) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricSample.
+func (in *CanaryMetricSample) DeepCopy() *CanaryMetricSample {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricSampleSize) DeepCopyInto(out *CanaryMetricSampleSize) {
+	*out = *in
+	out.TemplateRef = in.TemplateRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricSampleSize.
+func (in *CanaryMetricSampleSize) DeepCopy() *CanaryMetricSampleSize {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricSampleSize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryReadinessGate) DeepCopyInto(out *CanaryReadinessGate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryReadinessGate.
+func (in *CanaryReadinessGate) DeepCopy() *CanaryReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRecordingRules) DeepCopyInto(out *CanaryRecordingRules) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRecordingRules.
+func (in *CanaryRecordingRules) DeepCopy() *CanaryRecordingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRecordingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRegression) DeepCopyInto(out *CanaryRegression) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRegression.
+func (in *CanaryRegression) DeepCopy() *CanaryRegression {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRegression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRegressionMetric) DeepCopyInto(out *CanaryRegressionMetric) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRegressionMetric.
+func (in *CanaryRegressionMetric) DeepCopy() *CanaryRegressionMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRegressionMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRegressionStatus) DeepCopyInto(out *CanaryRegressionStatus) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryRegressionMetric, len(*in))
+		copy(*out, *in)
 	}
-	if in.Webhooks != nil {
-		in, out := &in.Webhooks, &out.Webhooks
-		*out = make([]CanaryWebhook, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRegressionStatus.
+func (in *CanaryRegressionStatus) DeepCopy() *CanaryRegressionStatus {
+	if in == nil {
+		return nil
 	}
-	if in.Match != nil {
-		in, out := &in.Match, &out.Match
-		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(CanaryRegressionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryReport) DeepCopyInto(out *CanaryReport) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
 	}
-	if in.SessionAffinity != nil {
-		in, out := &in.SessionAffinity, &out.SessionAffinity
-		*out = new(SessionAffinity)
+	if in.Regression != nil {
+		in, out := &in.Regression, &out.Regression
+		*out = new(CanaryRegression)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
-func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryReport.
+func (in *CanaryReport) DeepCopy() *CanaryReport {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryAnalysis)
+	out := new(CanaryReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+func (in *CanaryRingReference) DeepCopyInto(out *CanaryRingReference) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
-func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRingReference.
+func (in *CanaryRingReference) DeepCopy() *CanaryRingReference {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryCondition)
+	out := new(CanaryRingReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+func (in *CanaryRollbackWindow) DeepCopyInto(out *CanaryRollbackWindow) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Canary, len(*in))
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetric, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -319,53 +951,28 @@ func (in *CanaryList) DeepCopyInto(out *CanaryList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
-func (in *CanaryList) DeepCopy() *CanaryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRollbackWindow.
+func (in *CanaryRollbackWindow) DeepCopy() *CanaryRollbackWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryList)
+	out := new(CanaryRollbackWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CanaryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+func (in *CanarySLO) DeepCopyInto(out *CanarySLO) {
 	*out = *in
-	if in.ThresholdRange != nil {
-		in, out := &in.ThresholdRange, &out.ThresholdRange
-		*out = new(CanaryThresholdRange)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TemplateRef != nil {
-		in, out := &in.TemplateRef, &out.TemplateRef
-		*out = new(CrossNamespaceObjectReference)
-		**out = **in
-	}
-	if in.TemplateVariables != nil {
-		in, out := &in.TemplateVariables, &out.TemplateVariables
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
-func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySLO.
+func (in *CanarySLO) DeepCopy() *CanarySLO {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryMetric)
+	out := new(CanarySLO)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -379,6 +986,11 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(IstioGateway)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.GatewayRefs != nil {
 		in, out := &in.GatewayRefs, &out.GatewayRefs
 		*out = make([]gatewayapiv1beta1.ParentReference, len(*in))
@@ -396,6 +1008,11 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 		*out = new(v1alpha3.TrafficPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Subsets != nil {
+		in, out := &in.Subsets, &out.Subsets
+		*out = new(IstioSubsets)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
@@ -411,7 +1028,7 @@ func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	if in.Retries != nil {
 		in, out := &in.Retries, &out.Retries
 		*out = new(v1alpha3.HTTPRetry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
@@ -456,6 +1073,22 @@ func (in *CanaryService) DeepCopy() *CanaryService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySoak) DeepCopyInto(out *CanarySoak) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySoak.
+func (in *CanarySoak) DeepCopy() *CanarySoak {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySoak)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 	*out = *in
@@ -465,6 +1098,11 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(AutoscalerRefernce)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TargetGroup != nil {
+		in, out := &in.TargetGroup, &out.TargetGroup
+		*out = make([]CanaryTargetGroupMember, len(*in))
+		copy(*out, *in)
+	}
 	if in.IngressRef != nil {
 		in, out := &in.IngressRef, &out.IngressRef
 		*out = new(LocalObjectReference)
@@ -496,6 +1134,21 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrimaryStrategy != nil {
+		in, out := &in.PrimaryStrategy, &out.PrimaryStrategy
+		*out = new(PrimaryStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreviousRing != nil {
+		in, out := &in.PreviousRing, &out.PreviousRing
+		*out = new(CanaryRingReference)
+		**out = **in
+	}
 	return
 }
 
@@ -524,6 +1177,17 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 		}
 	}
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.LastProgressTime.DeepCopyInto(&out.LastProgressTime)
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]CanaryImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regression != nil {
+		in, out := &in.Regression, &out.Regression
+		*out = new(CanaryRegressionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]CanaryCondition, len(*in))
@@ -544,6 +1208,40 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStepThreshold) DeepCopyInto(out *CanaryStepThreshold) {
+	*out = *in
+	in.ThresholdRange.DeepCopyInto(&out.ThresholdRange)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStepThreshold.
+func (in *CanaryStepThreshold) DeepCopy() *CanaryStepThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStepThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTargetGroupMember) DeepCopyInto(out *CanaryTargetGroupMember) {
+	*out = *in
+	out.TargetPort = in.TargetPort
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTargetGroupMember.
+func (in *CanaryTargetGroupMember) DeepCopy() *CanaryTargetGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTargetGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryThresholdRange) DeepCopyInto(out *CanaryThresholdRange) {
 	*out = *in
@@ -584,6 +1282,16 @@ func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 			}
 		}
 	}
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -597,6 +1305,23 @@ func (in *CanaryWebhook) DeepCopy() *CanaryWebhook {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookOutcome) DeepCopyInto(out *CanaryWebhookOutcome) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookOutcome.
+func (in *CanaryWebhookOutcome) DeepCopy() *CanaryWebhookOutcome {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookOutcome)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhookPayload) DeepCopyInto(out *CanaryWebhookPayload) {
 	*out = *in
@@ -607,6 +1332,13 @@ func (in *CanaryWebhookPayload) DeepCopyInto(out *CanaryWebhookPayload) {
 			(*out)[key] = val
 		}
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -666,6 +1398,57 @@ func (in *CustomMetadata) DeepCopy() *CustomMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioGateway) DeepCopyInto(out *IstioGateway) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(v1alpha3.ServerTLSSettings)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioGateway.
+func (in *IstioGateway) DeepCopy() *IstioGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioSubsets) DeepCopyInto(out *IstioSubsets) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioSubsets.
+func (in *IstioSubsets) DeepCopy() *IstioSubsets {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioSubsets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalObjectReference) DeepCopyInto(out *LocalObjectReference) {
 	*out = *in
@@ -845,6 +1628,32 @@ func (in *MetricTemplateStatus) DeepCopy() *MetricTemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrimaryStrategy) DeepCopyInto(out *PrimaryStrategy) {
+	*out = *in
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrimaryStrategy.
+func (in *PrimaryStrategy) DeepCopy() *PrimaryStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(PrimaryStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalerReplicas) DeepCopyInto(out *ScalerReplicas) {
 	*out = *in
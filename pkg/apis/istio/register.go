@@ -1,5 +0,0 @@
-package istio
-
-const (
-	GroupName = "networking.istio.io"
-)
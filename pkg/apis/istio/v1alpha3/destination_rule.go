@@ -1,19 +1,6 @@
 // proto: https://github.com/istio/api/blob/master/networking/v1alpha3/destination_rule.pb.go
 package v1alpha3
 
-import (
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-)
-
-// +genclient
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
-// DestinationRule
-type DestinationRule struct {
-	metav1.TypeMeta   `json:",inline"`
-	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Spec              DestinationRuleSpec `json:"spec"`
-}
-
 // DestinationRule defines policies that apply to traffic intended for a
 // service after routing has occurred. These rules specify configuration
 // for load balancing, connection pool size from the sidecar, and outlier
@@ -738,11 +725,3 @@ const (
 	// used, all other fields in `TLSSettings` should be empty.
 	TLSmodeIstioMutual TLSmode = "ISTIO_MUTUAL"
 )
-
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
-// DestinationRuleList is a list of DestinationRule resources
-type DestinationRuleList struct {
-	metav1.TypeMeta `json:",inline"`
-	metav1.ListMeta `json:"metadata"`
-	Items           []DestinationRule `json:"items"`
-}
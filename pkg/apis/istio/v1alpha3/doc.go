@@ -1,7 +1,5 @@
-// Api versions allow the api contract for a resource to be changed while keeping
-// backward compatibility by support multiple concurrent versions
-// of the same resource
+// Package v1alpha3 contains the Istio routing settings embedded in the Canary API,
+// the Istio router converts them to the istio.io/api networking types
 
 // +k8s:deepcopy-gen=package
-// +groupName=networking.istio.io
 package v1alpha3
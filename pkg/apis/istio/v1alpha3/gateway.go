@@ -0,0 +1,17 @@
+// proto: https://github.com/istio/api/blob/master/networking/v1alpha3/gateway.proto
+package v1alpha3
+
+// ServerTLSSettings defines the TLS settings of a gateway server.
+type ServerTLSSettings struct {
+	// If set to true, the load balancer will send a 301 redirect for
+	// all http connections, asking the clients to use HTTPS.
+	HttpsRedirect bool `json:"httpsRedirect,omitempty"`
+
+	// Optional: Indicates whether connections to this port should be
+	// secured using TLS. The value of this field determines how TLS is
+	// enforced. One of PASSTHROUGH|SIMPLE|MUTUAL|AUTO_PASSTHROUGH|ISTIO_MUTUAL.
+	Mode string `json:"mode,omitempty"`
+
+	// The name of the secret that holds the TLS certs including the CA certificates.
+	CredentialName string `json:"credentialName,omitempty"`
+}
@@ -3,20 +3,8 @@ package v1alpha3
 
 import (
 	"github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// +genclient
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
-
-// VirtualService
-type VirtualService struct {
-	metav1.TypeMeta   `json:",inline"`
-	metav1.ObjectMeta `json:"metadata,omitempty"`
-
-	Spec VirtualServiceSpec `json:"spec"`
-}
-
 // VirtualServiceSpec defines a set of traffic routing rules to apply when a host is
 // addressed. Each routing rule defines matching criteria for traffic of a specific
 // protocol. If the traffic is matched, then it is sent to a named destination service
@@ -132,6 +120,13 @@ type VirtualServiceSpec struct {
 	// be applied to any port that is not a HTTP or TLS port. The first rule
 	// matching an incoming request is used.
 	Tcp []TCPRoute `json:"tcp,omitempty"`
+
+	// A list of namespaces to which this virtual service is exported. Exporting a
+	// virtual service allows it to be used by sidecars and gateways defined in
+	// other namespaces. The value "." is reserved and defines an export to the same
+	// namespace that the virtual service is declared in. Similarly the value "*"
+	// is reserved and defines an export to all namespaces.
+	ExportTo []string `json:"exportTo,omitempty"`
 }
 
 // Destination indicates the network addressable service to which the
@@ -486,6 +481,13 @@ type HTTPMatchRequest struct {
 	// **Note:** The keys `uri`, `scheme`, `method`, and `authority` will be ignored.
 	Headers map[string]v1alpha1.StringMatch `json:"headers,omitempty"`
 
+	// JWT claims of the authenticated request principal, e.g. _groups_.
+	// Nested claims are separated with dots.
+	//
+	// **Note:** This field is not part of the Istio API, Flagger renders the claims
+	// into `@request.auth.claims.<name>` header match conditions.
+	Claims map[string]v1alpha1.StringMatch `json:"claims,omitempty"`
+
 	// Specifies the ports on the host that is being addressed. Many services
 	// only expose a single port or label ports with the protocols they support,
 	// in these cases it is not required to explicitly select the port.
@@ -530,6 +532,10 @@ type HTTPMatchRequest struct {
 	// If the VirtualService has a list of gateways specified in the top-level `gateways` field,
 	// it must include the reserved gateway `mesh` for this field to be applicable.
 	SourceNamespace string `json:"sourceNamespace,omitempty"`
+
+	// The human readable prefix to use when emitting statistics for this route.
+	// The statistics are generated with prefix route.<stat_prefix>.
+	StatPrefix string `json:"statPrefix,omitempty"`
 }
 
 type HTTPRouteDestination struct {
@@ -660,6 +666,17 @@ type HTTPRedirect struct {
 	// On a redirect, overwrite the Authority/Host portion of the URL with
 	// this value.
 	Authority string `json:"authority,omitempty"`
+
+	// On a redirect, overwrite the port portion of the URL with this value.
+	Port uint32 `json:"port,omitempty"`
+
+	// On a redirect, overwrite the scheme portion of the URL with this value.
+	// For example, `http` or `https`.
+	Scheme string `json:"scheme,omitempty"`
+
+	// On a redirect, Specifies the HTTP status code to use in the redirect
+	// response. The default response code is MOVED_PERMANENTLY (301).
+	RedirectCode uint32 `json:"redirectCode,omitempty"`
 }
 
 // HTTPRewrite can be used to rewrite specific parts of a HTTP request
@@ -736,6 +753,9 @@ type HTTPRetry struct {
 	// <https://www.envoyproxy.io/docs/envoy/latest/configuration/http_filters/router_filter#x-envoy-retry-on>
 	// and <https://www.envoyproxy.io/docs/envoy/latest/configuration/http_filters/router_filter#x-envoy-retry-grpc-on>
 	RetryOn string `json:"retryOn,omitempty"`
+
+	// Flag to specify whether the retries should retry to other localities.
+	RetryRemoteLocalities *bool `json:"retryRemoteLocalities,omitempty"`
 }
 
 // Describes the Cross-Origin Resource Sharing (CORS) policy, for a given
@@ -896,13 +916,3 @@ type InjectAbort struct {
 	// REQUIRED. HTTP status code to use to abort the Http request.
 	HttpStatus int `json:"httpStatus"`
 }
-
-// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
-
-// VirtualServiceList is a list of VirtualService resources
-type VirtualServiceList struct {
-	metav1.TypeMeta `json:",inline"`
-	metav1.ListMeta `json:"metadata"`
-
-	Items []VirtualService `json:"items"`
-}
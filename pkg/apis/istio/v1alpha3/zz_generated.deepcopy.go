@@ -23,7 +23,6 @@ package v1alpha3
 
 import (
 	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -141,66 +140,6 @@ func (in *Destination) DeepCopy() *Destination {
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DestinationRule) DeepCopyInto(out *DestinationRule) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRule.
-func (in *DestinationRule) DeepCopy() *DestinationRule {
-	if in == nil {
-		return nil
-	}
-	out := new(DestinationRule)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DestinationRule) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DestinationRuleList) DeepCopyInto(out *DestinationRuleList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]DestinationRule, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRuleList.
-func (in *DestinationRuleList) DeepCopy() *DestinationRuleList {
-	if in == nil {
-		return nil
-	}
-	out := new(DestinationRuleList)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DestinationRuleList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DestinationRuleSpec) DeepCopyInto(out *DestinationRuleSpec) {
 	*out = *in
@@ -340,6 +279,13 @@ func (in *HTTPMatchRequest) DeepCopyInto(out *HTTPMatchRequest) {
 			(*out)[key] = val
 		}
 	}
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make(map[string]v1alpha1.StringMatch, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.SourceLabels != nil {
 		in, out := &in.SourceLabels, &out.SourceLabels
 		*out = make(map[string]string, len(*in))
@@ -398,6 +344,11 @@ func (in *HTTPRedirect) DeepCopy() *HTTPRedirect {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPRetry) DeepCopyInto(out *HTTPRetry) {
 	*out = *in
+	if in.RetryRemoteLocalities != nil {
+		in, out := &in.RetryRemoteLocalities, &out.RetryRemoteLocalities
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -457,7 +408,7 @@ func (in *HTTPRoute) DeepCopyInto(out *HTTPRoute) {
 	if in.Retries != nil {
 		in, out := &in.Retries, &out.Retries
 		*out = new(HTTPRetry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Fault != nil {
 		in, out := &in.Fault, &out.Fault
@@ -810,6 +761,22 @@ func (in *PortTrafficPolicy) DeepCopy() *PortTrafficPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerTLSSettings) DeepCopyInto(out *ServerTLSSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerTLSSettings.
+func (in *ServerTLSSettings) DeepCopy() *ServerTLSSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerTLSSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subset) DeepCopyInto(out *Subset) {
 	*out = *in
@@ -942,66 +909,6 @@ func (in *TrafficPolicy) DeepCopy() *TrafficPolicy {
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualService) DeepCopyInto(out *VirtualService) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualService.
-func (in *VirtualService) DeepCopy() *VirtualService {
-	if in == nil {
-		return nil
-	}
-	out := new(VirtualService)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualService) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualServiceList) DeepCopyInto(out *VirtualServiceList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]VirtualService, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualServiceList.
-func (in *VirtualServiceList) DeepCopy() *VirtualServiceList {
-	if in == nil {
-		return nil
-	}
-	out := new(VirtualServiceList)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualServiceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualServiceSpec) DeepCopyInto(out *VirtualServiceSpec) {
 	*out = *in
@@ -1029,6 +936,11 @@ func (in *VirtualServiceSpec) DeepCopyInto(out *VirtualServiceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExportTo != nil {
+		in, out := &in.ExportTo, &out.ExportTo
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Service manages the revisions created from its template
+// and the traffic split between them
+type Service struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceSpec   `json:"spec"`
+	Status ServiceStatus `json:"status,omitempty"`
+}
+
+// ServiceSpec holds the template of the revisions and the traffic targets
+type ServiceSpec struct {
+	// Template is stamped out into a new revision on every change
+	Template RevisionTemplateSpec `json:"template"`
+
+	// Traffic splits the requests between the revisions
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+}
+
+// RevisionTemplateSpec describes the revisions created by the service
+type RevisionTemplateSpec struct {
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec RevisionSpec `json:"spec,omitempty"`
+}
+
+// RevisionSpec holds the pod spec of a revision
+type RevisionSpec struct {
+	corev1.PodSpec `json:",inline"`
+
+	// ContainerConcurrency is the maximum number of concurrent requests per container
+	// +optional
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+
+	// TimeoutSeconds is the maximum duration of a request
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// TrafficTarget routes a percentage of the requests to a revision
+type TrafficTarget struct {
+	// Tag exposes the revision under a dedicated URL
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// RevisionName of the targeted revision
+	// +optional
+	RevisionName string `json:"revisionName,omitempty"`
+
+	// LatestRevision targets the latest ready revision when true
+	// +optional
+	LatestRevision *bool `json:"latestRevision,omitempty"`
+
+	// Percent of the requests routed to the target
+	// +optional
+	Percent *int64 `json:"percent,omitempty"`
+
+	// URL of the tagged target, set by the controller
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// ServiceStatus reports the revisions and the traffic observed by the Knative controller
+type ServiceStatus struct {
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// LatestReadyRevisionName is the last revision that became ready
+	// +optional
+	LatestReadyRevisionName string `json:"latestReadyRevisionName,omitempty"`
+
+	// LatestCreatedRevisionName is the last revision stamped out from the template
+	// +optional
+	LatestCreatedRevisionName string `json:"latestCreatedRevisionName,omitempty"`
+
+	// +optional
+	Traffic []TrafficTarget `json:"traffic,omitempty"`
+
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// Condition defines a readiness condition of the service
+type Condition struct {
+	Type   string                 `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceList is a collection of services
+type ServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Service `json:"items"`
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PrometheusRule defines recording and alerting rules loaded by Prometheus
+type PrometheusRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PrometheusRuleSpec `json:"spec"`
+}
+
+// PrometheusRuleSpec contains the rule groups
+type PrometheusRuleSpec struct {
+	Groups []RuleGroup `json:"groups,omitempty"`
+}
+
+// RuleGroup is a list of sequentially evaluated rules
+type RuleGroup struct {
+	// Name of the rule group
+	Name string `json:"name"`
+
+	// Interval at which the rules are evaluated
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Rules of the group
+	Rules []Rule `json:"rules"`
+}
+
+// Rule describes a recording or an alerting rule
+type Rule struct {
+	// Record is the name of the time series the expression result is recorded to
+	// +optional
+	Record string `json:"record,omitempty"`
+
+	// Alert is the name of the alert
+	// +optional
+	Alert string `json:"alert,omitempty"`
+
+	// Expr is the PromQL expression evaluated by the rule
+	Expr intstr.IntOrString `json:"expr"`
+
+	// For is the duration the alert condition must hold before firing
+	// +optional
+	For string `json:"for,omitempty"`
+
+	// Labels added to the recorded series or the alert
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations added to the alert
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PrometheusRuleList is a list of PrometheusRule resources
+type PrometheusRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PrometheusRule `json:"items"`
+}
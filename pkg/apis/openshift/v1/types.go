@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Route exposes a service at a host name so that external clients can reach it by name,
+// the router splits the traffic between the service in spec.to and the alternate backends
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouteSpec   `json:"spec"`
+	Status RouteStatus `json:"status,omitempty"`
+}
+
+// RouteSpec describes the host name and the backends of the route
+type RouteSpec struct {
+	// Host is an alias/DNS that points to the service, if empty a host name is generated
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Path that the router watches for, to route traffic for to the service
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// To is the service that serves the route, its weight is relative to the alternate backends
+	To RouteTargetReference `json:"to"`
+
+	// AlternateBackends are up to 3 additional backends that share the route traffic
+	// +optional
+	AlternateBackends []RouteTargetReference `json:"alternateBackends,omitempty"`
+
+	// Port of the service the router targets
+	// +optional
+	Port *RoutePort `json:"port,omitempty"`
+
+	// TLS configures the termination of the route
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// WildcardPolicy if any for the route, defaults to None
+	// +optional
+	WildcardPolicy string `json:"wildcardPolicy,omitempty"`
+}
+
+// RouteTargetReference specifies the target that resolve into endpoints
+type RouteTargetReference struct {
+	// Kind of the referent, only Service is allowed
+	Kind string `json:"kind"`
+
+	// Name of the service
+	Name string `json:"name"`
+
+	// Weight as an integer between 0 and 256, 0 means no traffic is routed to the backend
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// RoutePort defines a port mapping from a router to an endpoint in the service endpoints
+type RoutePort struct {
+	// TargetPort is the name or number of the service port
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+// TLSConfig defines the TLS termination of the route
+type TLSConfig struct {
+	// Termination indicates the termination type: edge, passthrough or reencrypt
+	Termination string `json:"termination"`
+
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// +optional
+	CACertificate string `json:"caCertificate,omitempty"`
+
+	// +optional
+	DestinationCACertificate string `json:"destinationCACertificate,omitempty"`
+
+	// InsecureEdgeTerminationPolicy indicates the desired behavior for insecure connections: Allow, Disable or Redirect
+	// +optional
+	InsecureEdgeTerminationPolicy string `json:"insecureEdgeTerminationPolicy,omitempty"`
+}
+
+// RouteStatus provides the status of the route admission by the routers
+type RouteStatus struct {
+	// +optional
+	Ingress []RouteIngress `json:"ingress,omitempty"`
+}
+
+// RouteIngress holds information about the places where a route is exposed
+type RouteIngress struct {
+	// Host is the host string under which the route is exposed
+	Host string `json:"host,omitempty"`
+
+	// RouterName is the name of the router that admitted the route
+	RouterName string `json:"routerName,omitempty"`
+
+	// Conditions is the state of the route, may be empty
+	// +optional
+	Conditions []RouteIngressCondition `json:"conditions,omitempty"`
+}
+
+// RouteIngressCondition contains details for the current condition of the route on a router
+type RouteIngressCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RouteList is a collection of routes
+type RouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Route `json:"items"`
+}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -36,9 +37,11 @@ import (
 
 // ConfigTracker is managing the operations for Kubernetes ConfigMaps and Secrets
 type ConfigTracker struct {
-	KubeClient    kubernetes.Interface
-	FlaggerClient clientset.Interface
-	Logger        *zap.SugaredLogger
+	KubeClient     kubernetes.Interface
+	DynamicClient  dynamic.Interface
+	ScalableMapper *ScalableMapper
+	FlaggerClient  clientset.Interface
+	Logger         *zap.SugaredLogger
 }
 
 type ConfigRefType string
@@ -144,8 +147,25 @@ func (ct *ConfigTracker) GetTargetConfigs(cd *flaggerv1.Canary) (map[string]Conf
 		vs = targetDae.Spec.Template.Spec.Volumes
 		cs = targetDae.Spec.Template.Spec.Containers
 		cs = append(cs, targetDae.Spec.Template.Spec.InitContainers...)
+	case "StatefulSet":
+		targetSts, err := ct.KubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		}
+		vs = targetSts.Spec.Template.Spec.Volumes
+		cs = targetSts.Spec.Template.Spec.Containers
+		cs = append(cs, targetSts.Spec.Template.Spec.InitContainers...)
 	default:
-		return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
+		if !cd.Spec.TargetRef.Scalable || ct.DynamicClient == nil || ct.ScalableMapper == nil {
+			return nil, fmt.Errorf("TargetRef.Kind invalid: %s", cd.Spec.TargetRef.Kind)
+		}
+		_, template, err := getWorkloadTarget(ct.ScalableMapper, ct.DynamicClient, cd)
+		if err != nil {
+			return nil, err
+		}
+		vs = template.Spec.Volumes
+		cs = template.Spec.Containers
+		cs = append(cs, template.Spec.InitContainers...)
 	}
 
 	secretNames := make(map[string]bool)
@@ -28,6 +28,8 @@ type Controller interface {
 	SetStatusFailedChecks(canary *flaggerv1.Canary, val int) error
 	SetStatusWeight(canary *flaggerv1.Canary, val int) error
 	SetStatusIterations(canary *flaggerv1.Canary, val int) error
+	SetStatusLocality(canary *flaggerv1.Canary, val int) error
+	SetStatusRegression(canary *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error
 	SetStatusPhase(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error
 	Initialize(canary *flaggerv1.Canary) error
 	Promote(canary *flaggerv1.Canary) error
@@ -36,4 +38,7 @@ type Controller interface {
 	ScaleToZero(canary *flaggerv1.Canary) error
 	ScaleFromZero(canary *flaggerv1.Canary) error
 	Finalize(canary *flaggerv1.Canary) error
+	ReconcileBaseline(canary *flaggerv1.Canary) error
+	DeleteBaseline(canary *flaggerv1.Canary) error
+	RollbackPrimary(canary *flaggerv1.Canary, revision string) error
 }
@@ -99,18 +99,14 @@ func (c *DaemonSetController) Initialize(cd *flaggerv1.Canary) (err error) {
 		return fmt.Errorf("createPrimaryDaemonSet failed: %w", err)
 	}
 
+	// wait for all the primary replicas to be ready, the target is scaled
+	// down by the scheduler once the traffic is routed to the primary
 	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 		if !cd.SkipAnalysis() {
-			if err := c.IsPrimaryReady(cd); err != nil {
+			if err := c.isPrimaryReady(cd, 100); err != nil {
 				return fmt.Errorf("%w", err)
 			}
 		}
-
-		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down DaemonSet %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		if err := c.ScaleToZero(cd); err != nil {
-			return fmt.Errorf("ScaleToZero failed: %w", err)
-		}
 	}
 	return nil
 }
@@ -120,6 +116,7 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
 	primaryName := fmt.Sprintf("%s-primary", targetName)
 
+	var previousTemplate corev1.PodTemplateSpec
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
 		if err != nil {
@@ -146,6 +143,7 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
 		}
 
+		previousTemplate = primary.Spec.Template
 		primaryCopy := primary.DeepCopy()
 		primaryCopy.Spec.MinReadySeconds = canary.Spec.MinReadySeconds
 		primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
@@ -187,9 +185,52 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 			primaryName, cd.Namespace, err)
 	}
 
+	// record the replaced primary template for post-promotion rollbacks
+	if err := saveRevision(c.kubeClient, cd, previousTemplate); err != nil {
+		return fmt.Errorf("saving daemonset %s.%s revision failed: %w", primaryName, cd.Namespace, err)
+	}
+
 	return nil
 }
 
+// RollbackPrimary restores the primary pod template of the given revision,
+// an empty revision selects the one replaced by the last promotion
+func (c *DaemonSetController) RollbackPrimary(cd *flaggerv1.Canary, revision string) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	revisions, err := getRevisions(c.kubeClient, cd)
+	if err != nil {
+		return err
+	}
+	index, err := findRevision(revisions, revision)
+	if err != nil {
+		return fmt.Errorf("daemonset %s.%s rollback failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	var replaced corev1.PodTemplateSpec
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+
+		replaced = primary.Spec.Template
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.Template = revisions[index].Template
+		_, err = c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rolling back daemonset %s.%s template spec failed: %w",
+			primaryName, cd.Namespace, err)
+	}
+
+	if err := setRevisions(c.kubeClient, cd, swapRevision(cd, revisions, index, replaced)); err != nil {
+		return err
+	}
+	return setStatusLastPromotedSpec(c.flaggerClient, cd, revisions[index].Hash)
+}
+
 // HasTargetChanged returns true if the canary DaemonSet pod spec has changed
 func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
@@ -313,6 +354,10 @@ func (c *DaemonSetController) createPrimaryDaemonSet(cd *flaggerv1.Canary, inclu
 
 		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Infof("DaemonSet %s.%s created", primaryDae.GetName(), cd.Namespace)
 	}
+
+	if err := reconcilePrimaryNetworkPolicies(c.kubeClient, c.logger, cd, canaryDae.Spec.Template.Labels, label, labelValue); err != nil {
+		return fmt.Errorf("reconcilePrimaryNetworkPolicies failed: %w", err)
+	}
 	return nil
 }
 
@@ -341,3 +386,12 @@ func (c *DaemonSetController) Finalize(cd *flaggerv1.Canary) error {
 	}
 	return nil
 }
+
+// ReconcileBaseline is a no-op for daemonsets as the primary runs on every node
+func (c *DaemonSetController) ReconcileBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *DaemonSetController) DeleteBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
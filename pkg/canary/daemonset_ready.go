@@ -30,13 +30,17 @@ import (
 // IsPrimaryReady checks the primary daemonset status and returns an error if
 // the daemonset is in the middle of a rolling update
 func (c *DaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	return c.isPrimaryReady(cd, cd.GetAnalysisPrimaryReadyThreshold())
+}
+
+func (c *DaemonSetController) isPrimaryReady(cd *flaggerv1.Canary, readyThreshold int) error {
 	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
 	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
 
-	_, err = c.isDaemonSetReady(cd, primary, cd.GetAnalysisPrimaryReadyThreshold())
+	_, err = c.isDaemonSetReady(cd, primary, readyThreshold)
 	if err != nil {
 		return fmt.Errorf("primary daemonset %s.%s not ready: %w", primaryName, cd.Namespace, err)
 	}
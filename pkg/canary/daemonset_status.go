@@ -49,6 +49,8 @@ func (c *DaemonSetController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.
 
 	return syncCanaryStatus(c.flaggerClient, cd, status, dae.Spec.Template, func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
+		cdCopy.Status.Images = PodImages(dae.Spec.Template.Spec)
+		cdCopy.Status.Commit = GitCommit(dae.Spec.Template.Annotations, dae.Annotations)
 	})
 }
 
@@ -67,6 +69,16 @@ func (c *DaemonSetController) SetStatusIterations(cd *flaggerv1.Canary, val int)
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusLocality updates the canary status locality index
+func (c *DaemonSetController) SetStatusLocality(cd *flaggerv1.Canary, val int) error {
+	return setStatusLocality(c.flaggerClient, cd, val)
+}
+
+// SetStatusRegression updates the canary status regression metrics
+func (c *DaemonSetController) SetStatusRegression(cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	return setStatusRegression(c.flaggerClient, cd, val)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DaemonSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 
@@ -35,6 +36,9 @@ import (
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
 
+// baselineLabel marks the pods of the baseline deployment
+const baselineLabel = "flagger.app/baseline"
+
 // DeploymentController is managing the operations for Kubernetes Deployment kind
 type DeploymentController struct {
 	kubeClient         kubernetes.Interface
@@ -52,18 +56,14 @@ func (c *DeploymentController) Initialize(cd *flaggerv1.Canary) (err error) {
 		return fmt.Errorf("createPrimaryDeployment failed: %w", err)
 	}
 
+	// wait for all the primary replicas to be ready, the target is scaled
+	// down by the scheduler once the traffic is routed to the primary
 	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 		if !cd.SkipAnalysis() {
-			if err := c.IsPrimaryReady(cd); err != nil {
+			if err := c.isPrimaryReady(cd, 100); err != nil {
 				return fmt.Errorf("%w", err)
 			}
 		}
-
-		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
-			Infof("Scaling down Deployment %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		if err := c.ScaleToZero(cd); err != nil {
-			return fmt.Errorf("scaling down canary deployment %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
-		}
 	}
 
 	return nil
@@ -74,6 +74,12 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
 	primaryName := fmt.Sprintf("%s-primary", targetName)
 
+	// the baseline runs the previous primary version
+	if err := c.DeleteBaseline(cd); err != nil {
+		return err
+	}
+
+	var previousTemplate corev1.PodTemplateSpec
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
 		if err != nil {
@@ -100,11 +106,15 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
 		}
 
+		previousTemplate = primary.Spec.Template
 		primaryCopy := primary.DeepCopy()
 		primaryCopy.Spec.ProgressDeadlineSeconds = canary.Spec.ProgressDeadlineSeconds
 		primaryCopy.Spec.MinReadySeconds = canary.Spec.MinReadySeconds
 		primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
-		primaryCopy.Spec.Strategy = canary.Spec.Strategy
+		primaryCopy.Spec.Strategy, err = getPrimaryDeploymentStrategy(cd, canary)
+		if err != nil {
+			return err
+		}
 		// update replica if hpa isn't set
 		if cd.Spec.AutoscalerRef == nil {
 			primaryCopy.Spec.Replicas = canary.Spec.Replicas
@@ -141,9 +151,72 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 			primaryName, cd.Namespace, err)
 	}
 
+	// record the replaced primary template for post-promotion rollbacks
+	if err := saveRevision(c.kubeClient, cd, previousTemplate); err != nil {
+		return fmt.Errorf("saving deployment %s.%s revision failed: %w", primaryName, cd.Namespace, err)
+	}
+
 	return nil
 }
 
+// RollbackPrimary restores the primary pod template of the given revision,
+// an empty revision selects the one replaced by the last promotion
+func (c *DeploymentController) RollbackPrimary(cd *flaggerv1.Canary, revision string) error {
+	hash, err := c.rollbackPrimaryTemplate(cd, revision)
+	if err != nil {
+		return err
+	}
+	return setStatusLastPromotedSpec(c.flaggerClient, cd, hash)
+}
+
+// findPrimaryRevision returns the primary revisions and the index of the given revision
+func (c *DeploymentController) findPrimaryRevision(cd *flaggerv1.Canary, revision string) ([]primaryRevision, int, error) {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	revisions, err := getRevisions(c.kubeClient, cd)
+	if err != nil {
+		return nil, -1, err
+	}
+	index, err := findRevision(revisions, revision)
+	if err != nil {
+		return nil, -1, fmt.Errorf("deployment %s.%s rollback failed: %w", primaryName, cd.Namespace, err)
+	}
+	return revisions, index, nil
+}
+
+// rollbackPrimaryTemplate restores the primary pod template and returns the hash of the restored revision
+func (c *DeploymentController) rollbackPrimaryTemplate(cd *flaggerv1.Canary, revision string) (string, error) {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	revisions, index, err := c.findPrimaryRevision(cd, revision)
+	if err != nil {
+		return "", err
+	}
+
+	var replaced corev1.PodTemplateSpec
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+
+		replaced = primary.Spec.Template
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.Template = revisions[index].Template
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("rolling back deployment %s.%s template spec failed: %w",
+			primaryName, cd.Namespace, err)
+	}
+
+	if err := setRevisions(c.kubeClient, cd, swapRevision(cd, revisions, index, replaced)); err != nil {
+		return "", err
+	}
+	return revisions[index].Hash, nil
+}
+
 // HasTargetChanged returns true if the canary deployment pod spec has changed
 func (c *DeploymentController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
@@ -288,6 +361,11 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 			replicas = *canaryDep.Spec.Replicas
 		}
 
+		strategy, err := getPrimaryDeploymentStrategy(cd, canaryDep)
+		if err != nil {
+			return err
+		}
+
 		// create primary deployment
 		primaryDep = &appsv1.Deployment{
 			ObjectMeta: metav1.ObjectMeta{
@@ -308,7 +386,7 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 				MinReadySeconds:         canaryDep.Spec.MinReadySeconds,
 				RevisionHistoryLimit:    canaryDep.Spec.RevisionHistoryLimit,
 				Replicas:                int32p(replicas),
-				Strategy:                canaryDep.Spec.Strategy,
+				Strategy:                strategy,
 				Selector: &metav1.LabelSelector{
 					MatchLabels: map[string]string{
 						label: primaryLabelValue,
@@ -334,6 +412,10 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 			Infof("Deployment %s.%s created", primaryDep.GetName(), cd.Namespace)
 	}
 
+	if err := reconcilePrimaryNetworkPolicies(c.kubeClient, c.logger, cd, canaryDep.Spec.Template.Labels, label, labelValue); err != nil {
+		return fmt.Errorf("reconcilePrimaryNetworkPolicies failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -488,6 +570,186 @@ func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
 	return nil
 }
 
+// ReconcileBaseline creates the baseline deployment and service from the primary pod template,
+// scales it to the same number of replicas as the canary and returns an error until the baseline is ready
+func (c *DeploymentController) ReconcileBaseline(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	baselineName := fmt.Sprintf("%s-baseline", targetName)
+
+	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	replicas := int32Default(canaryDep.Spec.Replicas)
+
+	baselineDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), baselineName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+
+		// the baseline pods get their own selector label value so that the primary service
+		// and the primary HPA don't select them, traffic reaches them through the baseline service
+		label, labelValue, err := c.getSelectorLabel(canaryDep)
+		if err != nil {
+			return fmt.Errorf("getSelectorLabel failed: %w", err)
+		}
+		baselineLabelValue := fmt.Sprintf("%s-baseline", labelValue)
+
+		selector := primaryDep.Spec.Selector.DeepCopy()
+		if selector.MatchLabels == nil {
+			selector.MatchLabels = make(map[string]string)
+		}
+		selector.MatchLabels[label] = baselineLabelValue
+		selector.MatchLabels[baselineLabel] = "true"
+
+		template := primaryDep.Spec.Template.DeepCopy()
+		if template.Labels == nil {
+			template.Labels = make(map[string]string)
+		}
+		template.Labels[label] = baselineLabelValue
+		template.Labels[baselineLabel] = "true"
+
+		baselineDep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        baselineName,
+				Namespace:   cd.Namespace,
+				Labels:      primaryDep.Labels,
+				Annotations: filterMetadata(primaryDep.Annotations),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				ProgressDeadlineSeconds: primaryDep.Spec.ProgressDeadlineSeconds,
+				MinReadySeconds:         primaryDep.Spec.MinReadySeconds,
+				RevisionHistoryLimit:    primaryDep.Spec.RevisionHistoryLimit,
+				Replicas:                int32p(replicas),
+				Strategy:                primaryDep.Spec.Strategy,
+				Selector:                selector,
+				Template:                *template,
+			},
+		}
+
+		_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Create(context.TODO(), baselineDep, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating deployment %s.%s failed: %w", baselineName, cd.Namespace, err)
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("Deployment %s.%s created", baselineName, cd.Namespace)
+
+		if err := c.reconcileBaselineService(cd, label, baselineLabelValue); err != nil {
+			return err
+		}
+		return fmt.Errorf("baseline deployment %s.%s not ready: waiting for rollout to finish", baselineName, cd.Namespace)
+	} else if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", baselineName, cd.Namespace, err)
+	}
+
+	if int32Default(baselineDep.Spec.Replicas) != replicas {
+		depCopy := baselineDep.DeepCopy()
+		depCopy.Spec.Replicas = int32p(replicas)
+		baselineDep, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("scaling %s.%s to %v failed: %w", baselineName, cd.Namespace, replicas, err)
+		}
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canaryDep)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+	if err := c.reconcileBaselineService(cd, label, fmt.Sprintf("%s-baseline", labelValue)); err != nil {
+		return err
+	}
+
+	// hold the analysis until the baseline pods can receive traffic
+	if _, err := c.isDeploymentReady(baselineDep, cd.GetProgressDeadlineSeconds(), cd.GetAnalysisPrimaryReadyThreshold()); err != nil {
+		return fmt.Errorf("baseline deployment %s.%s not ready: %w", baselineName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// reconcileBaselineService creates a ClusterIP service named after the baseline deployment
+// that selects the baseline pods and exposes the same ports as the primary service
+func (c *DeploymentController) reconcileBaselineService(cd *flaggerv1.Canary, label string, labelValue string) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	_, primaryName, _ := cd.GetServiceNames()
+
+	_, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), baselineName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("service %s.%s get query error: %w", baselineName, cd.Namespace, err)
+	}
+
+	primarySvc, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(primarySvc.Spec.Ports))
+	for _, port := range primarySvc.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+			TargetPort:  port.TargetPort,
+		})
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baselineName,
+			Namespace: cd.Namespace,
+			Labels:    map[string]string{label: labelValue},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{label: labelValue},
+			Ports:    ports,
+		},
+	}
+
+	_, err = c.kubeClient.CoreV1().Services(cd.Namespace).Create(context.TODO(), svc, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating service %s.%s failed: %w", baselineName, cd.Namespace, err)
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Service %s.%s created", baselineName, cd.Namespace)
+	return nil
+}
+
+// DeleteBaseline removes the baseline deployment and service if present
+func (c *DeploymentController) DeleteBaseline(cd *flaggerv1.Canary) error {
+	baselineName := fmt.Sprintf("%s-baseline", cd.Spec.TargetRef.Name)
+	err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Delete(context.TODO(), baselineName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting deployment %s.%s failed: %w", baselineName, cd.Namespace, err)
+	}
+	err = c.kubeClient.CoreV1().Services(cd.Namespace).Delete(context.TODO(), baselineName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting service %s.%s failed: %w", baselineName, cd.Namespace, err)
+	}
+	return nil
+}
+
 // Scale sets the canary deployment replicas
 func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error {
 	targetName := cd.Spec.TargetRef.Name
@@ -529,6 +791,52 @@ func (c *DeploymentController) getPrimaryDeploymentTemplateSpec(canaryDep *appsv
 	return spec
 }
 
+// getPrimaryDeploymentStrategy returns the target deployment strategy
+// with the canary primary strategy overrides applied
+func getPrimaryDeploymentStrategy(cd *flaggerv1.Canary, canaryDep *appsv1.Deployment) (appsv1.DeploymentStrategy, error) {
+	strategy := *canaryDep.Spec.Strategy.DeepCopy()
+	override := cd.Spec.PrimaryStrategy
+	if override == nil {
+		return strategy, nil
+	}
+
+	if override.Type == flaggerv1.PrimaryStrategyRecreate {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}, nil
+	}
+
+	if strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		strategy = appsv1.DeploymentStrategy{}
+	}
+	strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	if strategy.RollingUpdate == nil {
+		strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+	}
+	if override.MaxSurge != nil {
+		maxSurge := *override.MaxSurge
+		strategy.RollingUpdate.MaxSurge = &maxSurge
+	}
+	if override.MaxUnavailable != nil {
+		maxUnavailable := *override.MaxUnavailable
+		strategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+	}
+
+	// the API server rejects a rolling update that can neither add nor remove pods
+	if isZeroIntOrPercent(strategy.RollingUpdate.MaxSurge) && isZeroIntOrPercent(strategy.RollingUpdate.MaxUnavailable) {
+		return strategy, fmt.Errorf("invalid primary strategy of %s.%s: maxSurge and maxUnavailable can't both be 0",
+			cd.Name, cd.Namespace)
+	}
+	return strategy, nil
+}
+
+// isZeroIntOrPercent returns true if the value is set to 0 or 0%
+func isZeroIntOrPercent(value *intstr.IntOrString) bool {
+	if value == nil {
+		return false
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	return err == nil && scaled == 0
+}
+
 func (c *DeploymentController) appendPrimarySuffixToValuesIfNeeded(labelSelector *metav1.LabelSelector, canaryDep *appsv1.Deployment) {
 	if labelSelector != nil {
 		for _, matchExpression := range labelSelector.MatchExpressions {
@@ -24,10 +24,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
@@ -285,3 +288,272 @@ func TestDeploymentController_AntiAffinityAndTopologySpreadConstraints(t *testin
 		assert.False(t, strings.HasSuffix(value, "-primary"))
 	})
 }
+
+func TestDeploymentController_NetworkPolicies(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+
+	port := intstr.FromInt(9898)
+	policies := []*networkingv1.NetworkPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-ingress", Namespace: "default"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"name": "podinfo"}},
+				Ingress:     []networkingv1.NetworkPolicyIngressRule{{Ports: []networkingv1.NetworkPolicyPort{{Port: &port}}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "default"},
+			Spec:       networkingv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{}},
+		},
+	}
+	for _, np := range policies {
+		_, err := mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Create(context.TODO(), np, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	mocks.initializeCanary(t)
+
+	primary, err := mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-ingress-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", primary.Spec.PodSelector.MatchLabels["name"])
+	assert.Equal(t, policies[0].Spec.Ingress, primary.Spec.Ingress)
+	assert.True(t, metav1.IsControlledBy(primary, mocks.canary))
+
+	_, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "default-deny-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	// update the canary policy and check that the clone is updated
+	policies[0].Spec.Ingress = nil
+	_, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Update(context.TODO(), policies[0], metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	primary, err = mocks.kubeClient.NetworkingV1().NetworkPolicies("default").Get(context.TODO(), "podinfo-ingress-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, primary.Spec.Ingress)
+}
+
+func TestDeploymentController_Baseline(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	err := mocks.controller.ScaleFromZero(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.CoreV1().Services("default").Create(context.TODO(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-primary", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 9898, TargetPort: intstr.FromString("http")}},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// the analysis waits for the baseline rollout
+	err = mocks.controller.ReconcileBaseline(mocks.canary)
+	require.Error(t, err)
+
+	baseline, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *baseline.Spec.Replicas)
+	assert.Equal(t, "podinfo-baseline", baseline.Spec.Template.Labels[dc.label])
+	assert.Equal(t, "podinfo-baseline", baseline.Spec.Selector.MatchLabels[dc.label])
+	assert.Equal(t, "true", baseline.Spec.Template.Labels[baselineLabel])
+	assert.Equal(t, "true", baseline.Spec.Selector.MatchLabels[baselineLabel])
+
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-baseline", svc.Spec.Selector[dc.label])
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, int32(9898), svc.Spec.Ports[0].Port)
+
+	baseline.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").UpdateStatus(context.TODO(), baseline, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.ReconcileBaseline(mocks.canary)
+	require.NoError(t, err)
+
+	// scale the baseline with the canary
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Replicas = int32p(3)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.ReconcileBaseline(mocks.canary)
+	require.Error(t, err)
+
+	baseline, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *baseline.Spec.Replicas)
+
+	// promotion removes the baseline
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-baseline", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDeploymentController_RollbackPrimary(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	mocks.canary.Spec.Analysis.RollbackWindow = &flaggerv1.CanaryRollbackWindow{Duration: "30m"}
+	mocks.canary.Status.LastPromotedSpec = "v1"
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	previousImage := depPrimary.Spec.Template.Spec.Containers[0].Image
+
+	dep2 := newDeploymentControllerTestV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	revisions, err := getRevisions(mocks.kubeClient, mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "v1", revisions[0].Hash)
+
+	// the promotion is finalised with the new spec hash
+	mocks.canary.Status.LastPromotedSpec = "v2"
+
+	err = mocks.controller.RollbackPrimary(mocks.canary, "")
+	require.NoError(t, err)
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, previousImage, depPrimary.Spec.Template.Spec.Containers[0].Image)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", c.Status.LastPromotedSpec)
+
+	// the replaced revision is kept so the rollback can be reverted
+	revisions, err = getRevisions(mocks.kubeClient, mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "v2", revisions[0].Hash)
+
+	err = mocks.controller.RollbackPrimary(mocks.canary, "v3")
+	require.Error(t, err)
+}
+
+func TestDeploymentController_RevisionHistoryLimit(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	mocks.canary.Spec.RevisionHistoryLimit = 2
+
+	// promote three revisions on top of v1
+	mocks.canary.Status.LastPromotedSpec = "v1"
+	for _, hash := range []string{"v2", "v3", "v4"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:" + hash
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		err = mocks.controller.Promote(mocks.canary)
+		require.NoError(t, err)
+		mocks.canary.Status.LastPromotedSpec = hash
+	}
+
+	revisions, err := getRevisions(mocks.kubeClient, mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "v3", revisions[0].Hash)
+	assert.Equal(t, "v2", revisions[1].Hash)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:v2", revisions[1].Template.Spec.Containers[0].Image)
+
+	err = mocks.controller.RollbackPrimary(mocks.canary, "v2")
+	require.NoError(t, err)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:v2", depPrimary.Spec.Template.Spec.Containers[0].Image)
+
+	revisions, err = getRevisions(mocks.kubeClient, mocks.canary)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "v4", revisions[0].Hash)
+	assert.Equal(t, "v3", revisions[1].Hash)
+}
+
+func TestDeploymentController_PrimaryStrategy(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	maxSurge := intstr.FromString("25%")
+	dep2 := newDeploymentControllerTestV2()
+	dep2.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge: &maxSurge,
+		},
+	}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// override the rolling update parameters
+	zero := intstr.FromInt(0)
+	one := intstr.FromInt(1)
+	mocks.canary.Spec.PrimaryStrategy = &flaggerv1.PrimaryStrategy{
+		MaxSurge:       &zero,
+		MaxUnavailable: &one,
+	}
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	depPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, depPrimary.Spec.Strategy.Type)
+	require.NotNil(t, depPrimary.Spec.Strategy.RollingUpdate)
+	assert.Equal(t, zero, *depPrimary.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, one, *depPrimary.Spec.Strategy.RollingUpdate.MaxUnavailable)
+
+	// the target deployment strategy is left untouched
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, maxSurge, *dep.Spec.Strategy.RollingUpdate.MaxSurge)
+
+	// switch to recreate
+	mocks.canary.Spec.PrimaryStrategy = &flaggerv1.PrimaryStrategy{Type: flaggerv1.PrimaryStrategyRecreate}
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, depPrimary.Spec.Strategy.Type)
+	assert.Nil(t, depPrimary.Spec.Strategy.RollingUpdate)
+
+	// without override the target strategy is copied
+	mocks.canary.Spec.PrimaryStrategy = nil
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	depPrimary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, maxSurge, *depPrimary.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Nil(t, depPrimary.Spec.Strategy.RollingUpdate.MaxUnavailable)
+
+	// the override merged with the target strategy can't block the rolling update
+	dep2.Spec.Strategy.RollingUpdate.MaxUnavailable = &zero
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.canary.Spec.PrimaryStrategy = &flaggerv1.PrimaryStrategy{MaxSurge: &zero}
+	err = mocks.controller.Promote(mocks.canary)
+	require.Error(t, err)
+}
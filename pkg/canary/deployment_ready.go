@@ -31,13 +31,17 @@ import (
 // the deployment is in the middle of a rolling update or if the pods are unhealthy
 // it will return a non retryable error if the rolling update is stuck
 func (c *DeploymentController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	return c.isPrimaryReady(cd, cd.GetAnalysisPrimaryReadyThreshold())
+}
+
+func (c *DeploymentController) isPrimaryReady(cd *flaggerv1.Canary, readyThreshold int) error {
 	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
 	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
 
-	_, err = c.isDeploymentReady(primary, cd.GetProgressDeadlineSeconds(), cd.GetAnalysisPrimaryReadyThreshold())
+	_, err = c.isDeploymentReady(primary, cd.GetProgressDeadlineSeconds(), readyThreshold)
 	if err != nil {
 		return fmt.Errorf("%s.%s not ready: %w", primaryName, cd.Namespace, err)
 	}
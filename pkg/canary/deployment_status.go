@@ -39,6 +39,8 @@ func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1
 
 	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec.Template, func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
+		cdCopy.Status.Images = PodImages(dep.Spec.Template.Spec)
+		cdCopy.Status.Commit = GitCommit(dep.Spec.Template.Annotations, dep.Annotations)
 	})
 }
 
@@ -57,6 +59,16 @@ func (c *DeploymentController) SetStatusIterations(cd *flaggerv1.Canary, val int
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusLocality updates the canary status locality index
+func (c *DeploymentController) SetStatusLocality(cd *flaggerv1.Canary, val int) error {
+	return setStatusLocality(c.flaggerClient, cd, val)
+}
+
+// SetStatusRegression updates the canary status regression metrics
+func (c *DeploymentController) SetStatusRegression(cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	return setStatusRegression(c.flaggerClient, cd, val)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DeploymentController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
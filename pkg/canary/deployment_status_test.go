@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -49,6 +50,10 @@ func TestDeploymentController_SyncStatus(t *testing.T) {
 	secret := newDeploymentControllerTestSecret()
 	_, exists := configs["secret/"+secret.GetName()]
 	assert.True(t, exists, "Secret %s not found in status", secret.GetName())
+
+	require.Len(t, res.Status.Images, 1)
+	assert.Equal(t, "podinfo", res.Status.Images[0].Container)
+	assert.NotEmpty(t, res.Status.Images[0].Tag)
 }
 
 func TestDeploymentController_SetFailedChecks(t *testing.T) {
@@ -76,3 +81,39 @@ func TestDeploymentController_SetState(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, res.Status.Phase)
 }
+
+func TestDeploymentController_SetStateConditions(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	tests := []struct {
+		phase  flaggerv1.CanaryPhase
+		status corev1.ConditionStatus
+	}{
+		{flaggerv1.CanaryPhaseProgressing, corev1.ConditionUnknown},
+		{flaggerv1.CanaryPhaseSucceeded, corev1.ConditionTrue},
+		{flaggerv1.CanaryPhaseFailed, corev1.ConditionFalse},
+	}
+
+	for _, tt := range tests {
+		cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		err = mocks.controller.SetStatusPhase(cd, tt.phase)
+		require.NoError(t, err)
+
+		res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		promoted := getStatusCondition(res.Status, flaggerv1.PromotedType)
+		require.NotNil(t, promoted)
+		assert.Equal(t, tt.status, promoted.Status)
+		assert.Equal(t, string(tt.phase), promoted.Reason)
+
+		healthy := getStatusCondition(res.Status, flaggerv1.HealthyType)
+		require.NotNil(t, healthy)
+		assert.Equal(t, tt.status, healthy.Status)
+		assert.Equal(t, string(tt.phase), healthy.Reason)
+	}
+}
@@ -18,13 +18,17 @@ package canary
 
 import (
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
 
 type Factory struct {
 	kubeClient         kubernetes.Interface
+	dynamicClient      dynamic.Interface
+	scalableMapper     *ScalableMapper
 	flaggerClient      clientset.Interface
 	logger             *zap.SugaredLogger
 	configTracker      Tracker
@@ -33,6 +37,8 @@ type Factory struct {
 }
 
 func NewFactory(kubeClient kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	scalableMapper *ScalableMapper,
 	flaggerClient clientset.Interface,
 	configTracker Tracker,
 	labels []string,
@@ -40,6 +46,8 @@ func NewFactory(kubeClient kubernetes.Interface,
 	logger *zap.SugaredLogger) *Factory {
 	return &Factory{
 		kubeClient:         kubeClient,
+		dynamicClient:      dynamicClient,
+		scalableMapper:     scalableMapper,
 		flaggerClient:      flaggerClient,
 		logger:             logger,
 		configTracker:      configTracker,
@@ -48,7 +56,7 @@ func NewFactory(kubeClient kubernetes.Interface,
 	}
 }
 
-func (factory *Factory) Controller(kind string) Controller {
+func (factory *Factory) Controller(targetRef flaggerv1.LocalObjectReference) Controller {
 	deploymentCtrl := &DeploymentController{
 		logger:             factory.logger,
 		kubeClient:         factory.kubeClient,
@@ -65,25 +73,74 @@ func (factory *Factory) Controller(kind string) Controller {
 		configTracker:      factory.configTracker,
 		includeLabelPrefix: factory.includeLabelPrefix,
 	}
+	statefulSetCtrl := &StatefulSetController{
+		logger:             factory.logger,
+		kubeClient:         factory.kubeClient,
+		flaggerClient:      factory.flaggerClient,
+		labels:             factory.labels,
+		configTracker:      factory.configTracker,
+		includeLabelPrefix: factory.includeLabelPrefix,
+	}
+	scalableCtrl := &ScalableController{
+		logger:             factory.logger,
+		kubeClient:         factory.kubeClient,
+		dynamicClient:      factory.dynamicClient,
+		mapper:             factory.scalableMapper,
+		flaggerClient:      factory.flaggerClient,
+		labels:             factory.labels,
+		configTracker:      factory.configTracker,
+		includeLabelPrefix: factory.includeLabelPrefix,
+	}
 	serviceCtrl := &ServiceController{
 		logger:             factory.logger,
 		kubeClient:         factory.kubeClient,
 		flaggerClient:      factory.flaggerClient,
 		includeLabelPrefix: factory.includeLabelPrefix,
 	}
+	knativeCtrl := &KnativeController{
+		logger:        factory.logger,
+		flaggerClient: factory.flaggerClient,
+	}
 
-	switch kind {
+	switch targetRef.Kind {
 	case "DaemonSet":
 		return daemonSetCtrl
+	case "StatefulSet":
+		return statefulSetCtrl
 	case "Deployment":
 		return deploymentCtrl
 	case "Service":
+		if targetRef.IsKnativeService() {
+			return knativeCtrl
+		}
 		return serviceCtrl
 	default:
+		// custom workloads that implement the scale subresource are opted in explicitly
+		if targetRef.Scalable {
+			return scalableCtrl
+		}
 		return deploymentCtrl
 	}
 }
 
+// TargetGroupController returns the controller of the canary target, the Deployments
+// of the target group are managed together with the target when the group is set
+func (factory *Factory) TargetGroupController(cd *flaggerv1.Canary) Controller {
+	if len(cd.Spec.TargetGroup) == 0 {
+		return factory.Controller(cd.Spec.TargetRef)
+	}
+	return &TargetGroupController{
+		DeploymentController: &DeploymentController{
+			logger:             factory.logger,
+			kubeClient:         factory.kubeClient,
+			flaggerClient:      factory.flaggerClient,
+			labels:             factory.labels,
+			configTracker:      factory.configTracker,
+			includeLabelPrefix: factory.includeLabelPrefix,
+		},
+	}
+}
+
 func (factory *Factory) ScalerReconciler(kind string) ScalerReconciler {
 	hpaReconciler := &HPAReconciler{
 		logger:             factory.logger,
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// GitCommit returns the Git commit SHA from the first annotations holding it
+func GitCommit(annotations ...map[string]string) string {
+	for _, a := range annotations {
+		if commit, ok := a[flaggerv1.GitCommitAnnotation]; ok {
+			return commit
+		}
+	}
+	return ""
+}
+
+// PodImages returns the images of the pod containers with their tag and digest
+func PodImages(spec corev1.PodSpec) []flaggerv1.CanaryImage {
+	var images []flaggerv1.CanaryImage
+	for _, container := range spec.Containers {
+		image := ParseImage(container.Image)
+		image.Container = container.Name
+		images = append(images, image)
+	}
+	return images
+}
+
+// ParseImage extracts the tag and the digest from an image reference
+// e.g. ghcr.io/stefanprodan/podinfo:6.0.1@sha256:... or localhost:5000/podinfo:6.0.1
+func ParseImage(ref string) flaggerv1.CanaryImage {
+	image := flaggerv1.CanaryImage{Image: ref}
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		image.Digest = name[i+1:]
+		name = name[:i]
+	}
+	// the tag separator is after the last path component so that a registry port is not taken as a tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		image.Tag = name[i+1:]
+	}
+	return image
+}
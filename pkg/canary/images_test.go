@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := map[string]flaggerv1.CanaryImage{
+		"podinfo": {Image: "podinfo"},
+		"ghcr.io/stefanprodan/podinfo:6.0.1": {
+			Image: "ghcr.io/stefanprodan/podinfo:6.0.1",
+			Tag:   "6.0.1",
+		},
+		"localhost:5000/podinfo": {Image: "localhost:5000/podinfo"},
+		"localhost:5000/podinfo:6.0.1@sha256:4f5c2b0e4f1b": {
+			Image:  "localhost:5000/podinfo:6.0.1@sha256:4f5c2b0e4f1b",
+			Tag:    "6.0.1",
+			Digest: "sha256:4f5c2b0e4f1b",
+		},
+		"podinfo@sha256:4f5c2b0e4f1b": {
+			Image:  "podinfo@sha256:4f5c2b0e4f1b",
+			Digest: "sha256:4f5c2b0e4f1b",
+		},
+	}
+
+	for ref, expected := range tests {
+		assert.Equal(t, expected, ParseImage(ref), ref)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// KnativeController is managing the operations for Knative service kind,
+// the primary is the revision recorded in the primary revision annotation
+// and the canary is the latest revision created from the service template
+type KnativeController struct {
+	flaggerClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *KnativeController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *KnativeController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *KnativeController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusLocality updates the canary status locality index
+func (c *KnativeController) SetStatusLocality(cd *flaggerv1.Canary, val int) error {
+	return setStatusLocality(c.flaggerClient, cd, val)
+}
+
+// SetStatusRegression updates the canary status regression metrics
+func (c *KnativeController) SetStatusRegression(cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	return setStatusRegression(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *KnativeController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
+
+// GetMetadata returns nothing, the Knative revisions are not selected by labels
+func (c *KnativeController) GetMetadata(_ *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	return "", "", nil, nil
+}
+
+// Initialize records the latest ready revision as the primary revision
+func (c *KnativeController) Initialize(cd *flaggerv1.Canary) error {
+	service, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if service.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation] != "" {
+		return nil
+	}
+
+	if service.Status.LatestReadyRevisionName == "" {
+		return fmt.Errorf("knative service %s.%s has no ready revision", cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+
+	if err := c.setPrimaryRevision(cd, service.Status.LatestReadyRevisionName); err != nil {
+		return err
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Knative service %s.%s primary revision set to %s",
+			cd.Spec.TargetRef.Name, cd.Namespace, service.Status.LatestReadyRevisionName)
+	return nil
+}
+
+// Promote records the latest ready revision as the primary revision
+func (c *KnativeController) Promote(cd *flaggerv1.Canary) error {
+	service, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if service.Status.LatestReadyRevisionName == "" {
+		return fmt.Errorf("knative service %s.%s has no ready revision", cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+
+	return c.setPrimaryRevision(cd, service.Status.LatestReadyRevisionName)
+}
+
+// HasTargetChanged returns true if the service template has changed,
+// every template change is stamped out by Knative into a new revision
+func (c *KnativeController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	service, err := c.getService(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, service.Spec.Template)
+}
+
+// ScaleToZero does nothing, Knative scales the revisions based on the traffic they receive
+func (c *KnativeController) ScaleToZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *KnativeController) ScaleFromZero(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *KnativeController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	service, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, service.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.Commit = GitCommit(service.Spec.Template.Annotations, service.Annotations)
+	})
+}
+
+func (c *KnativeController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
+	return false, nil
+}
+
+// IsPrimaryReady checks that a primary revision has been recorded
+func (c *KnativeController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	service, err := c.getService(cd)
+	if err != nil {
+		return err
+	}
+
+	if service.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation] == "" {
+		return fmt.Errorf("knative service %s.%s primary revision not found", cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+	return nil
+}
+
+// IsCanaryReady checks that the latest created revision is ready,
+// it returns a non retriable error if Knative failed to create the revision
+func (c *KnativeController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	service, err := c.getService(cd)
+	if err != nil {
+		return true, err
+	}
+
+	if service.Generation > service.Status.ObservedGeneration {
+		return true, fmt.Errorf("knative service %s.%s not ready: waiting for the new revision to be created",
+			cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+
+	for _, condition := range service.Status.Conditions {
+		if condition.Type == "ConfigurationsReady" && condition.Status == corev1.ConditionFalse {
+			return false, fmt.Errorf("knative service %s.%s not ready: %s",
+				cd.Spec.TargetRef.Name, cd.Namespace, condition.Message)
+		}
+	}
+
+	if service.Status.LatestCreatedRevisionName != service.Status.LatestReadyRevisionName {
+		return true, fmt.Errorf("knative service %s.%s not ready: waiting for revision %s to be ready",
+			cd.Spec.TargetRef.Name, cd.Namespace, service.Status.LatestCreatedRevisionName)
+	}
+	return true, nil
+}
+
+func (c *KnativeController) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *KnativeController) ReconcileBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *KnativeController) DeleteBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// RollbackPrimary is not supported for Knative targets
+func (c *KnativeController) RollbackPrimary(cd *flaggerv1.Canary, _ string) error {
+	return fmt.Errorf("rollback of the primary revision of %s.%s is not supported", cd.Spec.TargetRef.Name, cd.Namespace)
+}
+
+func (c *KnativeController) getService(cd *flaggerv1.Canary) (*knativev1.Service, error) {
+	service, err := c.flaggerClient.KnativeV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("knative service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+	return service, nil
+}
+
+func (c *KnativeController) setPrimaryRevision(cd *flaggerv1.Canary, revision string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		service, err := c.getService(cd)
+		if err != nil {
+			return err
+		}
+
+		serviceCopy := service.DeepCopy()
+		if serviceCopy.Annotations == nil {
+			serviceCopy.Annotations = make(map[string]string)
+		}
+		serviceCopy.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation] = revision
+
+		_, err = c.flaggerClient.KnativeV1().Services(cd.Namespace).Update(context.TODO(), serviceCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating knative service %s.%s primary revision failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+	return nil
+}
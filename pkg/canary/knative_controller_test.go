@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func newKnativeTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.LocalObjectReference{
+				Name:       "podinfo",
+				APIVersion: "serving.knative.dev/v1",
+				Kind:       "Service",
+			},
+		},
+	}
+}
+
+func newKnativeTestService() *knativev1.Service {
+	return &knativev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "podinfo",
+			Generation: 1,
+		},
+		Status: knativev1.ServiceStatus{
+			ObservedGeneration:        1,
+			LatestCreatedRevisionName: "podinfo-00001",
+			LatestReadyRevisionName:   "podinfo-00001",
+		},
+	}
+}
+
+func TestKnativeController_Promote(t *testing.T) {
+	cd := newKnativeTestCanary()
+	flaggerClient := fakeFlagger.NewSimpleClientset(cd, newKnativeTestService())
+	log, _ := logger.NewLogger("debug")
+	ctrl := NewFactory(nil, nil, nil, flaggerClient, nil, nil, nil, log).Controller(cd.Spec.TargetRef)
+	require.IsType(t, &KnativeController{}, ctrl)
+
+	err := ctrl.Initialize(cd)
+	require.NoError(t, err)
+	require.NoError(t, ctrl.IsPrimaryReady(cd))
+
+	svc, err := flaggerClient.KnativeV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00001", svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation])
+
+	// a new revision is created but not ready yet
+	svc.Status.LatestCreatedRevisionName = "podinfo-00002"
+	_, err = flaggerClient.KnativeV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	retriable, err := ctrl.IsCanaryReady(cd)
+	require.Error(t, err)
+	assert.True(t, retriable)
+
+	svc.Status.LatestReadyRevisionName = "podinfo-00002"
+	_, err = flaggerClient.KnativeV1().Services("default").Update(context.TODO(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, err = ctrl.IsCanaryReady(cd)
+	require.NoError(t, err)
+
+	// the primary revision is kept on initialization
+	err = ctrl.Initialize(cd)
+	require.NoError(t, err)
+	svc, err = flaggerClient.KnativeV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00001", svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation])
+
+	err = ctrl.Promote(cd)
+	require.NoError(t, err)
+	svc, err = flaggerClient.KnativeV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00002", svc.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation])
+}
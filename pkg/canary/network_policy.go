@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// reconcilePrimaryNetworkPolicies clones the network policies that select the canary pods
+// but not the primary pods, the clones select the primary pods by their selector label value
+func reconcilePrimaryNetworkPolicies(kubeClient kubernetes.Interface, logger *zap.SugaredLogger, cd *flaggerv1.Canary,
+	podLabels map[string]string, label string, labelValue string) error {
+	policies, err := kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		if errors.IsForbidden(err) {
+			logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Debugf("Skipping NetworkPolicy cloning, list query is forbidden")
+			return nil
+		}
+		return fmt.Errorf("NetworkPolicy list query error: %w", err)
+	}
+
+	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	primaryLabels := k8slabels.Set(makePrimaryLabels(podLabels, primaryLabelValue, label))
+
+	for _, np := range policies.Items {
+		if metav1.IsControlledBy(&np, cd) {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&np.Spec.PodSelector)
+		if err != nil || selector.Empty() || !selector.Matches(k8slabels.Set(podLabels)) || selector.Matches(primaryLabels) {
+			continue
+		}
+
+		podSelector := np.Spec.PodSelector.DeepCopy()
+		for key, value := range podSelector.MatchLabels {
+			if key == label && value == labelValue {
+				podSelector.MatchLabels[key] = primaryLabelValue
+			}
+		}
+		for _, expression := range podSelector.MatchExpressions {
+			if expression.Key == label && expression.Operator == metav1.LabelSelectorOpIn {
+				for i := range expression.Values {
+					if expression.Values[i] == labelValue {
+						expression.Values[i] = primaryLabelValue
+					}
+				}
+			}
+		}
+
+		primarySelector, err := metav1.LabelSelectorAsSelector(podSelector)
+		if err != nil || !primarySelector.Matches(primaryLabels) {
+			logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+				Warnf("NetworkPolicy %s.%s selects the canary pods but can't be cloned for the primary pods", np.Name, np.Namespace)
+			continue
+		}
+
+		spec := np.Spec.DeepCopy()
+		spec.PodSelector = *podSelector
+		if err := reconcilePrimaryNetworkPolicy(kubeClient, logger, cd, np, *spec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func reconcilePrimaryNetworkPolicy(kubeClient kubernetes.Interface, logger *zap.SugaredLogger, cd *flaggerv1.Canary,
+	np networkingv1.NetworkPolicy, spec networkingv1.NetworkPolicySpec) error {
+	primaryName := fmt.Sprintf("%s-primary", np.Name)
+	primary, err := kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		primary = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        primaryName,
+				Namespace:   cd.Namespace,
+				Labels:      filterMetadata(np.Labels),
+				Annotations: filterMetadata(np.Annotations),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: spec,
+		}
+
+		_, err = kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Create(context.TODO(), primary, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating NetworkPolicy %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+		logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("NetworkPolicy %s.%s created", primaryName, cd.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("NetworkPolicy %s.%s get query failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	// only update the clones managed by this canary
+	if !metav1.IsControlledBy(primary, cd) {
+		return nil
+	}
+
+	if diff := cmp.Diff(spec, primary.Spec); diff != "" {
+		primaryClone := primary.DeepCopy()
+		primaryClone.Spec = spec
+		_, err = kubeClient.NetworkingV1().NetworkPolicies(cd.Namespace).Update(context.TODO(), primaryClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("updating NetworkPolicy %s.%s failed: %w", primaryName, cd.Namespace, err)
+		}
+		logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("NetworkPolicy %s.%s updated", primaryName, cd.Namespace)
+	}
+
+	return nil
+}
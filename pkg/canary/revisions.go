@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const revisionsKey = "revisions"
+
+// primaryRevision is a snapshot of the primary pod template replaced by a promotion
+type primaryRevision struct {
+	Hash       string                 `json:"hash"`
+	ReplacedAt metav1.Time            `json:"replacedAt"`
+	Template   corev1.PodTemplateSpec `json:"template"`
+}
+
+func revisionsName(cd *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-primary-revisions", cd.Spec.TargetRef.Name)
+}
+
+// getRevisions returns the primary revisions ordered from the most recent one
+func getRevisions(kubeClient kubernetes.Interface, cd *flaggerv1.Canary) ([]primaryRevision, error) {
+	name := revisionsName(cd)
+	cm, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("configmap %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	var revisions []primaryRevision
+	if data, ok := cm.Data[revisionsKey]; ok {
+		if err := json.Unmarshal([]byte(data), &revisions); err != nil {
+			return nil, fmt.Errorf("configmap %s.%s unmarshal error: %w", name, cd.Namespace, err)
+		}
+	}
+	return revisions, nil
+}
+
+// setRevisions creates or updates the config map holding the primary revisions
+func setRevisions(kubeClient kubernetes.Interface, cd *flaggerv1.Canary, revisions []primaryRevision) error {
+	name := revisionsName(cd)
+	data, err := json.Marshal(revisions)
+	if err != nil {
+		return fmt.Errorf("configmap %s.%s marshal error: %w", name, cd.Namespace, err)
+	}
+
+	cm, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cd.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Data: map[string]string{revisionsKey: string(data)},
+		}
+		_, err = kubeClient.CoreV1().ConfigMaps(cd.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("configmap %s.%s create error: %w", name, cd.Namespace, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("configmap %s.%s get query error: %w", name, cd.Namespace, err)
+	}
+
+	cmCopy := cm.DeepCopy()
+	cmCopy.Data = map[string]string{revisionsKey: string(data)}
+	_, err = kubeClient.CoreV1().ConfigMaps(cd.Namespace).Update(context.TODO(), cmCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("configmap %s.%s update error: %w", name, cd.Namespace, err)
+	}
+	return nil
+}
+
+// revisionHistoryLimit returns the number of replaced primary revisions to keep,
+// the rollback window and the group-wide abort require at least the last one
+func revisionHistoryLimit(cd *flaggerv1.Canary) int {
+	if cd.Spec.RevisionHistoryLimit > 0 {
+		return cd.Spec.RevisionHistoryLimit
+	}
+	if cd.GetAnalysis().RollbackWindow != nil {
+		return 1
+	}
+	if (cd.Spec.Group != "" || len(cd.Spec.DependsOn) > 0) &&
+		cd.GetGroupFailurePolicy() == flaggerv1.GroupFailurePolicyAbort {
+		return 1
+	}
+	return 0
+}
+
+// saveRevision records the primary pod template before it gets replaced by a promotion
+func saveRevision(kubeClient kubernetes.Interface, cd *flaggerv1.Canary, template corev1.PodTemplateSpec) error {
+	limit := revisionHistoryLimit(cd)
+	if limit == 0 {
+		return nil
+	}
+
+	revisions, err := getRevisions(kubeClient, cd)
+	if err != nil {
+		return err
+	}
+
+	revision := primaryRevision{
+		Hash:       cd.Status.LastPromotedSpec,
+		ReplacedAt: metav1.Now(),
+		Template:   template,
+	}
+	revisions = append([]primaryRevision{revision}, revisions...)
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+	return setRevisions(kubeClient, cd, revisions)
+}
+
+// findRevision returns the index of the revision matching the hash,
+// an empty hash selects the most recent revision
+func findRevision(revisions []primaryRevision, hash string) (int, error) {
+	if len(revisions) == 0 {
+		return -1, fmt.Errorf("no previous revision found")
+	}
+	if hash == "" {
+		return 0, nil
+	}
+	for i, revision := range revisions {
+		if revision.Hash == hash {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("revision %s not found", hash)
+}
+
+// swapRevision removes the restored revision from the history and records
+// the primary pod template it replaced, so the rollback can be reverted
+func swapRevision(cd *flaggerv1.Canary, revisions []primaryRevision, index int, replaced corev1.PodTemplateSpec) []primaryRevision {
+	// the primary runs the canary spec until the promotion is completed
+	hash := cd.Status.LastPromotedSpec
+	if cd.Status.Phase == flaggerv1.CanaryPhasePromoting || cd.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		hash = cd.Status.LastAppliedSpec
+	}
+
+	var result []primaryRevision
+	if hash != "" {
+		result = append(result, primaryRevision{
+			Hash:       hash,
+			ReplacedAt: metav1.Now(),
+			Template:   replaced,
+		})
+	}
+	result = append(result, revisions[:index]...)
+	result = append(result, revisions[index+1:]...)
+	if limit := revisionHistoryLimit(cd); limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,559 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// ScalableController is managing the operations for custom workload kinds that implement the scale subresource,
+// the workload must have a pod template at spec.template and a label selector at spec.selector.matchLabels
+type ScalableController struct {
+	kubeClient         kubernetes.Interface
+	dynamicClient      dynamic.Interface
+	mapper             *ScalableMapper
+	flaggerClient      clientset.Interface
+	logger             *zap.SugaredLogger
+	configTracker      Tracker
+	labels             []string
+	includeLabelPrefix []string
+}
+
+// Initialize creates the primary workload if it does not exist
+// and waits for the primary pods to be ready
+func (c *ScalableController) Initialize(cd *flaggerv1.Canary) (err error) {
+	if err := c.createPrimaryWorkload(cd, c.includeLabelPrefix); err != nil {
+		return fmt.Errorf("createPrimaryWorkload failed: %w", err)
+	}
+
+	// wait for all the primary replicas to be ready, the target is scaled
+	// down by the scheduler once the traffic is routed to the primary
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() {
+			if err := c.isPrimaryReady(cd, 100); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Promote copies the pod template, secrets and config maps from canary to primary
+func (c *ScalableController) Promote(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	var previousTemplate corev1.PodTemplateSpec
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		canary, err := client.Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("%s %s.%s get query error: %w", kind, targetName, cd.Namespace, err)
+		}
+
+		label, labelValue, err := c.getSelectorLabel(canary)
+		primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+		if err != nil {
+			return fmt.Errorf("getSelectorLabel failed: %w", err)
+		}
+
+		primary, err := client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("%s %s.%s get query error: %w", kind, primaryName, cd.Namespace, err)
+		}
+
+		// promote secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+
+		canaryTemplate, err := getWorkloadTemplate(canary)
+		if err != nil {
+			return err
+		}
+		previousTemplate, err = getWorkloadTemplate(primary)
+		if err != nil {
+			return err
+		}
+
+		// update pod annotations to ensure a rolling update
+		annotations, err := makeAnnotations(canaryTemplate.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+
+		primaryCopy := primary.DeepCopy()
+		// update spec with primary secrets and config maps
+		err = setWorkloadTemplate(primaryCopy, corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      makePrimaryLabels(canaryTemplate.Labels, primaryLabelValue, label),
+				Annotations: annotations,
+			},
+			Spec: c.configTracker.ApplyPrimaryConfigs(canaryTemplate.Spec, configRefs),
+		})
+		if err != nil {
+			return err
+		}
+
+		// update workload annotations and labels
+		primaryCopy.SetAnnotations(includeLabelsByPrefix(canary.GetAnnotations(), c.includeLabelPrefix))
+		filteredLabels := includeLabelsByPrefix(canary.GetLabels(), c.includeLabelPrefix)
+		primaryCopy.SetLabels(makePrimaryLabels(filteredLabels, primaryLabelValue, label))
+
+		// apply update
+		_, err = client.Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating %s %s.%s template spec failed: %w",
+			kind, primaryName, cd.Namespace, err)
+	}
+
+	// update replicas if hpa isn't set
+	if cd.Spec.AutoscalerRef == nil {
+		replicas, err := getScaleReplicas(client, targetName)
+		if err != nil {
+			return fmt.Errorf("%s %s.%s scale query error: %w", kind, targetName, cd.Namespace, err)
+		}
+		if err := setScaleReplicas(client, primaryName, replicas); err != nil {
+			return fmt.Errorf("scaling %s %s.%s failed: %w", kind, primaryName, cd.Namespace, err)
+		}
+	}
+
+	// record the replaced primary template for post-promotion rollbacks
+	if err := saveRevision(c.kubeClient, cd, previousTemplate); err != nil {
+		return fmt.Errorf("saving %s %s.%s revision failed: %w", kind, primaryName, cd.Namespace, err)
+	}
+
+	return nil
+}
+
+// RollbackPrimary restores the primary pod template of the given revision,
+// an empty revision selects the one replaced by the last promotion
+func (c *ScalableController) RollbackPrimary(cd *flaggerv1.Canary, revision string) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := getRevisions(c.kubeClient, cd)
+	if err != nil {
+		return err
+	}
+	index, err := findRevision(revisions, revision)
+	if err != nil {
+		return fmt.Errorf("%s %s.%s rollback failed: %w", kind, primaryName, cd.Namespace, err)
+	}
+
+	var replaced corev1.PodTemplateSpec
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		primary, err := client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("%s %s.%s get query error: %w", kind, primaryName, cd.Namespace, err)
+		}
+
+		replaced, err = getWorkloadTemplate(primary)
+		if err != nil {
+			return err
+		}
+		primaryCopy := primary.DeepCopy()
+		if err := setWorkloadTemplate(primaryCopy, revisions[index].Template); err != nil {
+			return err
+		}
+		_, err = client.Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rolling back %s %s.%s template spec failed: %w",
+			kind, primaryName, cd.Namespace, err)
+	}
+
+	if err := setRevisions(c.kubeClient, cd, swapRevision(cd, revisions, index, replaced)); err != nil {
+		return err
+	}
+	return setStatusLastPromotedSpec(c.flaggerClient, cd, revisions[index].Hash)
+}
+
+// HasTargetChanged returns true if the canary workload pod template has changed
+func (c *ScalableController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	_, template, err := getWorkloadTarget(c.mapper, c.dynamicClient, cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, template)
+}
+
+// ScaleToZero sets the canary workload replicas to zero with the scale subresource
+func (c *ScalableController) ScaleToZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	if err := setScaleReplicas(client, targetName, 0); err != nil {
+		return fmt.Errorf("%s %s.%s scale query error: %w",
+			strings.ToLower(cd.Spec.TargetRef.Kind), targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// ScaleFromZero sets the canary workload replicas to the primary replicas with the scale subresource
+func (c *ScalableController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	replicas, err := getScaleReplicas(client, targetName)
+	if err != nil {
+		return fmt.Errorf("%s %s.%s scale query error: %w", kind, targetName, cd.Namespace, err)
+	}
+	if replicas > 0 {
+		return nil
+	}
+
+	replicas = 1
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primaryReplicas, err := getScaleReplicas(client, primaryName)
+	if err != nil {
+		return fmt.Errorf("%s %s.%s scale query error: %w", kind, primaryName, cd.Namespace, err)
+	}
+	if primaryReplicas > 0 {
+		replicas = primaryReplicas
+	}
+
+	if err := setScaleReplicas(client, targetName, replicas); err != nil {
+		return fmt.Errorf("scaling up %s %s.%s failed: %w", kind, targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *ScalableController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	canary, template, err := getWorkloadTarget(c.mapper, c.dynamicClient, cd)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canary)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, template.Spec.Containers)
+	}
+	return label, labelValue, ports, nil
+}
+
+func (c *ScalableController) createPrimaryWorkload(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	canary, canaryTemplate, err := getWorkloadTarget(c.mapper, c.dynamicClient, cd)
+	if err != nil {
+		return err
+	}
+
+	// Create the labels map but filter unwanted labels
+	labels := includeLabelsByPrefix(canary.GetLabels(), includeLabelPrefix)
+
+	label, labelValue, err := c.getSelectorLabel(canary)
+	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	_, err = client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// create primary secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+		annotations, err := makeAnnotations(canaryTemplate.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+
+		// the primary spec is a copy of the target spec with its own selector and pod template
+		spec, _, err := unstructured.NestedMap(canary.Object, "spec")
+		if err != nil {
+			return fmt.Errorf("%s %s.%s spec is invalid: %w", kind, targetName, cd.Namespace, err)
+		}
+		primary := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		primary.SetAPIVersion(canary.GetAPIVersion())
+		primary.SetKind(canary.GetKind())
+		primary.SetName(primaryName)
+		primary.SetNamespace(cd.Namespace)
+		primary.SetLabels(makePrimaryLabels(labels, primaryLabelValue, label))
+		primary.SetAnnotations(filterMetadata(canary.GetAnnotations()))
+		primary.SetOwnerReferences([]metav1.OwnerReference{
+			*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+				Group:   flaggerv1.SchemeGroupVersion.Group,
+				Version: flaggerv1.SchemeGroupVersion.Version,
+				Kind:    flaggerv1.CanaryKind,
+			}),
+		})
+
+		// the target may have been scaled to zero by a previous canary
+		if replicas, found, _ := unstructured.NestedInt64(spec, "replicas"); found && replicas == 0 {
+			if err := unstructured.SetNestedField(primary.Object, int64(1), "spec", "replicas"); err != nil {
+				return err
+			}
+		}
+		selector := map[string]string{label: primaryLabelValue}
+		if err := unstructured.SetNestedStringMap(primary.Object, selector, "spec", "selector", "matchLabels"); err != nil {
+			return err
+		}
+		// update spec with the primary secrets and config maps
+		err = setWorkloadTemplate(primary, corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      makePrimaryLabels(canaryTemplate.Labels, primaryLabelValue, label),
+				Annotations: annotations,
+			},
+			Spec: c.configTracker.ApplyPrimaryConfigs(canaryTemplate.Spec, configRefs),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = client.Create(context.TODO(), primary, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating %s %s.%s failed: %w", kind, primaryName, cd.Namespace, err)
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("%s %s.%s created", cd.Spec.TargetRef.Kind, primaryName, cd.Namespace)
+	} else if err != nil {
+		return fmt.Errorf("%s %s.%s get query error: %w", kind, primaryName, cd.Namespace, err)
+	}
+
+	if err := reconcilePrimaryNetworkPolicies(c.kubeClient, c.logger, cd, canaryTemplate.Labels, label, labelValue); err != nil {
+		return fmt.Errorf("reconcilePrimaryNetworkPolicies failed: %w", err)
+	}
+	return nil
+}
+
+// getSelectorLabel returns the selector match label
+func (c *ScalableController) getSelectorLabel(workload *unstructured.Unstructured) (string, string, error) {
+	matchLabels, _, _ := unstructured.NestedStringMap(workload.Object, "spec", "selector", "matchLabels")
+	for _, l := range c.labels {
+		if _, ok := matchLabels[l]; ok {
+			return l, matchLabels[l], nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"%s %s.%s spec.selector.matchLabels must contain one of %v'",
+		strings.ToLower(workload.GetKind()), workload.GetName(), workload.GetNamespace(), c.labels,
+	)
+}
+
+func (c *ScalableController) resourceClient(cd *flaggerv1.Canary) (dynamic.ResourceInterface, error) {
+	gvr, err := c.mapper.Resource(cd.Spec.TargetRef)
+	if err != nil {
+		return nil, err
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(cd.Namespace), nil
+}
+
+func (c *ScalableController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return c.configTracker.HasConfigChanged(cd)
+}
+
+// Finalize scale the reference instance from zero
+func (c *ScalableController) Finalize(cd *flaggerv1.Canary) error {
+	if err := c.ScaleFromZero(cd); err != nil {
+		return fmt.Errorf("ScaleFromZero failed: %w", err)
+	}
+	return nil
+}
+
+// ReconcileBaseline is a no-op for custom workloads
+func (c *ScalableController) ReconcileBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *ScalableController) DeleteBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// ScalableMapper resolves the API resources of the custom workload kinds,
+// the discovery results are cached and refreshed only when a kind is not found
+type ScalableMapper struct {
+	discovery discovery.CachedDiscoveryInterface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+}
+
+// NewScalableMapper returns a ScalableMapper backed by an in-memory discovery cache
+func NewScalableMapper(discoveryClient discovery.DiscoveryInterface) *ScalableMapper {
+	cached := memory.NewMemCacheClient(discoveryClient)
+	return &ScalableMapper{
+		discovery: cached,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(cached),
+	}
+}
+
+// Resource returns the API resource of the target kind
+// and verifies that it implements the scale subresource
+func (m *ScalableMapper) Resource(ref flaggerv1.LocalObjectReference) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("targetRef apiVersion %s is invalid: %w", ref.APIVersion, err)
+	}
+
+	gk := schema.GroupKind{Group: gv.Group, Kind: ref.Kind}
+	mapping, err := m.mapper.RESTMapping(gk, gv.Version)
+	if meta.IsNoMatchError(err) {
+		// the kind may have been installed after the cache was filled
+		m.mapper.Reset()
+		mapping, err = m.mapper.RESTMapping(gk, gv.Version)
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("kind %s not found in %s: %w", ref.Kind, gv.String(), err)
+	}
+
+	list, err := m.discovery.ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("discovery of %s failed: %w", gv.String(), err)
+	}
+	for _, r := range list.APIResources {
+		if r.Name == mapping.Resource.Resource+"/scale" {
+			return mapping.Resource, nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("kind %s of %s does not implement the scale subresource", ref.Kind, gv.String())
+}
+
+// getWorkloadTarget returns the canary target and its pod template
+func getWorkloadTarget(mapper *ScalableMapper, dynamicClient dynamic.Interface, cd *flaggerv1.Canary) (*unstructured.Unstructured, corev1.PodTemplateSpec, error) {
+	targetName := cd.Spec.TargetRef.Name
+	gvr, err := mapper.Resource(cd.Spec.TargetRef)
+	if err != nil {
+		return nil, corev1.PodTemplateSpec{}, err
+	}
+
+	workload, err := dynamicClient.Resource(gvr).Namespace(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, corev1.PodTemplateSpec{}, fmt.Errorf("%s %s.%s get query error: %w",
+			strings.ToLower(cd.Spec.TargetRef.Kind), targetName, cd.Namespace, err)
+	}
+
+	template, err := getWorkloadTemplate(workload)
+	if err != nil {
+		return nil, corev1.PodTemplateSpec{}, err
+	}
+	return workload, template, nil
+}
+
+// getWorkloadTemplate decodes the pod template found at spec.template
+func getWorkloadTemplate(workload *unstructured.Unstructured) (corev1.PodTemplateSpec, error) {
+	var template corev1.PodTemplateSpec
+	obj, found, err := unstructured.NestedMap(workload.Object, "spec", "template")
+	if err != nil || !found {
+		return template, fmt.Errorf("%s %s.%s spec.template not found",
+			strings.ToLower(workload.GetKind()), workload.GetName(), workload.GetNamespace())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &template); err != nil {
+		return template, fmt.Errorf("%s %s.%s spec.template is invalid: %w",
+			strings.ToLower(workload.GetKind()), workload.GetName(), workload.GetNamespace(), err)
+	}
+	return template, nil
+}
+
+// setWorkloadTemplate encodes the pod template at spec.template
+func setWorkloadTemplate(workload *unstructured.Unstructured, template corev1.PodTemplateSpec) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	if err != nil {
+		return fmt.Errorf("pod template encoding failed: %w", err)
+	}
+	return unstructured.SetNestedMap(workload.Object, obj, "spec", "template")
+}
+
+// getScaleReplicas returns the desired replicas from the scale subresource
+func getScaleReplicas(client dynamic.ResourceInterface, name string) (int32, error) {
+	scale, err := client.Get(context.TODO(), name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return 0, err
+	}
+	replicas, _, err := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	if err != nil {
+		return 0, err
+	}
+	return int32(replicas), nil
+}
+
+// setScaleReplicas updates the desired replicas with the scale subresource
+func setScaleReplicas(client dynamic.ResourceInterface, name string, replicas int32) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := client.Get(context.TODO(), name, metav1.GetOptions{}, "scale")
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas"); err != nil {
+			return err
+		}
+		_, err = client.Update(context.TODO(), scale, metav1.UpdateOptions{}, "scale")
+		return err
+	})
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+var rolloutResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+
+func newScalableFixture(t *testing.T) (*ScalableController, *flaggerv1.Canary) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.LocalObjectReference{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				Name:       "podinfo",
+				Scalable:   true,
+			},
+			SkipAnalysis: true,
+			Analysis:     &flaggerv1.CanaryAnalysis{},
+		},
+	}
+	flaggerClient := fakeFlagger.NewSimpleClientset(cd)
+
+	kubeClient := fake.NewSimpleClientset(newStatefulSetControllerTestConfigMap("red"))
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "argoproj.io/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Name: "rollouts", Kind: "Rollout", Namespaced: true},
+				{Name: "rollouts/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+	}
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), newScalableTestRollout("stefanprodan/podinfo:3.1.0"))
+	// the fake tracker doesn't implement the scale subresource
+	dynamicClient.PrependReactor("get", "rollouts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := dynamicClient.Tracker().Get(rolloutResource, action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		replicas, _, _ := unstructured.NestedInt64(obj.(*unstructured.Unstructured).Object, "spec", "replicas")
+		scale := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata":   map[string]interface{}{"name": action.(k8stesting.GetAction).GetName(), "namespace": action.GetNamespace()},
+			"spec":       map[string]interface{}{"replicas": replicas},
+		}}
+		return true, scale, nil
+	})
+	dynamicClient.PrependReactor("update", "rollouts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		obj, err := dynamicClient.Tracker().Get(rolloutResource, action.GetNamespace(), scale.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		rollout := obj.(*unstructured.Unstructured).DeepCopy()
+		replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		_ = unstructured.SetNestedField(rollout.Object, replicas, "spec", "replicas")
+		return true, scale, dynamicClient.Tracker().Update(rolloutResource, rollout, action.GetNamespace())
+	})
+
+	log, err := logger.NewLogger("debug")
+	require.NoError(t, err)
+
+	mapper := NewScalableMapper(kubeClient.Discovery())
+	ctrl := &ScalableController{
+		kubeClient:    kubeClient,
+		dynamicClient: dynamicClient,
+		mapper:        mapper,
+		flaggerClient: flaggerClient,
+		logger:        log,
+		labels:        []string{"app", "name"},
+		configTracker: &ConfigTracker{
+			Logger:         log,
+			KubeClient:     kubeClient,
+			DynamicClient:  dynamicClient,
+			ScalableMapper: mapper,
+			FlaggerClient:  flaggerClient,
+		},
+	}
+	return ctrl, cd
+}
+
+func newScalableTestRollout(image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]interface{}{
+			"name":      "podinfo",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "podinfo"},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "podinfo"},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "podinfo",
+							"image": image,
+							"envFrom": []interface{}{
+								map[string]interface{}{
+									"configMapRef": map[string]interface{}{"name": "podinfo-config-env"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestScalableController_Initialize(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	err := ctrl.Initialize(cd)
+	require.NoError(t, err)
+
+	primary, err := ctrl.dynamicClient.Resource(rolloutResource).Namespace("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	matchLabels, _, _ := unstructured.NestedStringMap(primary.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, "podinfo-primary", matchLabels["app"])
+	assert.Equal(t, "Canary", primary.GetOwnerReferences()[0].Kind)
+
+	template, err := getWorkloadTemplate(primary)
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", template.Labels["app"])
+	assert.Equal(t, "podinfo-config-env-primary", template.Spec.Containers[0].EnvFrom[0].ConfigMapRef.Name)
+
+	_, err = ctrl.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestScalableController_Promote(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	err := ctrl.Initialize(cd)
+	require.NoError(t, err)
+
+	rollouts := ctrl.dynamicClient.Resource(rolloutResource).Namespace("default")
+	_, err = rollouts.Update(context.TODO(), newScalableTestRollout("stefanprodan/podinfo:3.1.1"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	err = setScaleReplicas(rollouts, "podinfo", 4)
+	require.NoError(t, err)
+
+	err = ctrl.Promote(cd)
+	require.NoError(t, err)
+
+	primary, err := rollouts.Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	template, err := getWorkloadTemplate(primary)
+	require.NoError(t, err)
+	assert.Equal(t, "stefanprodan/podinfo:3.1.1", template.Spec.Containers[0].Image)
+
+	replicas, err := getScaleReplicas(rollouts, "podinfo-primary")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), replicas)
+}
+
+func TestScalableController_Scale(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	err := ctrl.Initialize(cd)
+	require.NoError(t, err)
+
+	rollouts := ctrl.dynamicClient.Resource(rolloutResource).Namespace("default")
+
+	err = ctrl.ScaleToZero(cd)
+	require.NoError(t, err)
+	replicas, err := getScaleReplicas(rollouts, "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), replicas)
+
+	err = ctrl.ScaleFromZero(cd)
+	require.NoError(t, err)
+	replicas, err = getScaleReplicas(rollouts, "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), replicas)
+}
+
+func TestScalableController_HasTargetChanged(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	err := ctrl.Initialize(cd)
+	require.NoError(t, err)
+
+	err = ctrl.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized})
+	require.NoError(t, err)
+
+	cd, err = ctrl.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	changed, err := ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	rollouts := ctrl.dynamicClient.Resource(rolloutResource).Namespace("default")
+	_, err = rollouts.Update(context.TODO(), newScalableTestRollout("stefanprodan/podinfo:3.1.1"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestScalableController_NoScaleSubresource(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	ctrl.kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources[0].APIResources =
+		[]metav1.APIResource{{Name: "rollouts", Kind: "Rollout", Namespaced: true}}
+
+	err := ctrl.Initialize(cd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scale subresource")
+}
+
+func TestScalableMapper_Resource(t *testing.T) {
+	ctrl, cd := newScalableFixture(t)
+	fakeDiscovery := ctrl.kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
+
+	gvr, err := ctrl.mapper.Resource(cd.Spec.TargetRef)
+	require.NoError(t, err)
+	assert.Equal(t, rolloutResource, gvr)
+
+	// the discovery results are cached
+	calls := len(fakeDiscovery.Actions())
+	_, err = ctrl.mapper.Resource(cd.Spec.TargetRef)
+	require.NoError(t, err)
+	assert.Equal(t, calls, len(fakeDiscovery.Actions()))
+
+	// the cache is refreshed when a kind is not found
+	fakeDiscovery.Resources[0].APIResources = append(fakeDiscovery.Resources[0].APIResources,
+		metav1.APIResource{Name: "workers", Kind: "Worker", Namespaced: true},
+		metav1.APIResource{Name: "workers/scale", Kind: "Scale", Namespaced: true},
+	)
+	gvr, err = ctrl.mapper.Resource(flaggerv1.LocalObjectReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Worker", Name: "podinfo"})
+	require.NoError(t, err)
+	assert.Equal(t, "workers", gvr.Resource)
+}
+
+func TestFactory_ScalableOptIn(t *testing.T) {
+	factory := NewFactory(nil, nil, nil, nil, nil, nil, nil, nil)
+	ref := flaggerv1.LocalObjectReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "podinfo"}
+
+	_, ok := factory.Controller(ref).(*DeploymentController)
+	assert.True(t, ok)
+
+	ref.Scalable = true
+	_, ok = factory.Controller(ref).(*ScalableController)
+	assert.True(t, ok)
+}
+
+func TestScalableController_isWorkloadReady(t *testing.T) {
+	ctrl, _ := newScalableFixture(t)
+	cd := &flaggerv1.Canary{}
+
+	// observed generation is less than desired generation
+	workload := newScalableTestRollout("stefanprodan/podinfo:3.1.0")
+	workload.SetGeneration(2)
+	workload.Object["status"] = map[string]interface{}{"observedGeneration": int64(1)}
+	retryable, err := ctrl.isWorkloadReady(cd, workload, 2, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// succeeded
+	workload.Object["status"] = map[string]interface{}{
+		"observedGeneration": int64(2),
+		"updatedReplicas":    int64(2),
+		"readyReplicas":      int64(2),
+	}
+	retryable, err = ctrl.isWorkloadReady(cd, workload, 2, 100)
+	require.NoError(t, err)
+	require.True(t, retryable)
+
+	// deadline exceeded
+	workload.Object["status"] = map[string]interface{}{"updatedReplicas": int64(1)}
+	cd.Status.LastTransitionTime = metav1.Now()
+	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
+	retryable, err = ctrl.isWorkloadReady(cd, workload, 2, 100)
+	require.Error(t, err)
+	require.False(t, retryable)
+}
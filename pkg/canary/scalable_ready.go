@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// IsPrimaryReady checks the primary workload status and returns an error if
+// the workload is in the middle of a rolling update
+func (c *ScalableController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	return c.isPrimaryReady(cd, cd.GetAnalysisPrimaryReadyThreshold())
+}
+
+func (c *ScalableController) isPrimaryReady(cd *flaggerv1.Canary, readyThreshold int) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return err
+	}
+
+	primary, err := client.Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("%s %s.%s get query error: %w", kind, primaryName, cd.Namespace, err)
+	}
+	replicas, err := getScaleReplicas(client, primaryName)
+	if err != nil {
+		return fmt.Errorf("%s %s.%s scale query error: %w", kind, primaryName, cd.Namespace, err)
+	}
+
+	_, err = c.isWorkloadReady(cd, primary, replicas, readyThreshold)
+	if err != nil {
+		return fmt.Errorf("primary %s %s.%s not ready: %w", kind, primaryName, cd.Namespace, err)
+	}
+
+	if replicas == 0 {
+		return fmt.Errorf("halt %s.%s advancement: primary %s is scaled to zero",
+			cd.Name, cd.Namespace, kind)
+	}
+	return nil
+}
+
+// IsCanaryReady checks the canary workload status and returns an error if
+// the workload is in the middle of a rolling update or if the pods are unhealthy
+// it will return a non retriable error if the rollout exceeded the progress deadline
+func (c *ScalableController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	kind := strings.ToLower(cd.Spec.TargetRef.Kind)
+	client, err := c.resourceClient(cd)
+	if err != nil {
+		return true, err
+	}
+
+	canary, err := client.Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return true, fmt.Errorf("%s %s.%s get query error: %w", kind, targetName, cd.Namespace, err)
+	}
+	replicas, err := getScaleReplicas(client, targetName)
+	if err != nil {
+		return true, fmt.Errorf("%s %s.%s scale query error: %w", kind, targetName, cd.Namespace, err)
+	}
+
+	retryable, err := c.isWorkloadReady(cd, canary, replicas, cd.GetAnalysisCanaryReadyThreshold())
+	if err != nil {
+		return retryable, fmt.Errorf("canary %s %s.%s not ready: %w",
+			kind, targetName, cd.Namespace, err)
+	}
+	return true, nil
+}
+
+// isWorkloadReady determines if a workload is ready by checking the status fields that follow
+// the apps/v1 conventions: observedGeneration, updatedReplicas and readyReplicas,
+// the fields missing from the workload status are not taken into account
+func (c *ScalableController) isWorkloadReady(cd *flaggerv1.Canary, workload *unstructured.Unstructured, replicas int32, readyThreshold int) (bool, error) {
+	observedGeneration, found, _ := unstructured.NestedInt64(workload.Object, "status", "observedGeneration")
+	if found && workload.GetGeneration() > observedGeneration {
+		return true, fmt.Errorf("waiting for rollout to finish: observed %s generation less than desired generation",
+			strings.ToLower(workload.GetKind()))
+	}
+
+	updatedReplicas, hasUpdated, _ := unstructured.NestedInt64(workload.Object, "status", "updatedReplicas")
+	readyReplicas, _, _ := unstructured.NestedInt64(workload.Object, "status", "readyReplicas")
+
+	readyThresholdRatio := float32(readyThreshold) / float32(100)
+	readyThresholdReplicas := int32(float32(replicas) * readyThresholdRatio)
+
+	// calculate conditions
+	newCond := hasUpdated && int32(updatedReplicas) < replicas
+	readyCond := int32(readyReplicas) < readyThresholdReplicas
+	if !newCond && !readyCond {
+		return true, nil
+	}
+
+	// check if deadline exceeded
+	from := cd.Status.LastTransitionTime
+	delta := time.Duration(cd.GetProgressDeadlineSeconds()) * time.Second
+	if from.Add(delta).Before(time.Now()) {
+		return false, fmt.Errorf("exceeded its progressDeadlineSeconds: %d", cd.GetProgressDeadlineSeconds())
+	}
+
+	// retryable
+	if newCond {
+		return true, fmt.Errorf("waiting for rollout to finish: %d out of %d new pods have been updated",
+			updatedReplicas, replicas)
+	}
+	return true, fmt.Errorf("waiting for rollout to finish: %d of %d (readyThreshold %d%%) pods are ready",
+		readyReplicas, readyThresholdReplicas, readyThreshold)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// SyncStatus encodes the canary pod template and updates the canary status
+func (c *ScalableController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	target, template, err := getWorkloadTarget(c.mapper, c.dynamicClient, cd)
+	if err != nil {
+		return err
+	}
+
+	configs, err := c.configTracker.GetConfigRefs(cd)
+	if err != nil {
+		return fmt.Errorf("GetConfigRefs failed: %w", err)
+	}
+
+	return syncCanaryStatus(c.flaggerClient, cd, status, template, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.TrackedConfigs = configs
+		cdCopy.Status.Images = PodImages(template.Spec)
+		cdCopy.Status.Commit = GitCommit(template.Annotations, target.GetAnnotations())
+	})
+}
+
+// SetStatusFailedChecks updates the canary failed checks counter
+func (c *ScalableController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusFailedChecks(c.flaggerClient, cd, val)
+}
+
+// SetStatusWeight updates the canary status weight value
+func (c *ScalableController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	return setStatusWeight(c.flaggerClient, cd, val)
+}
+
+// SetStatusIterations updates the canary status iterations value
+func (c *ScalableController) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	return setStatusIterations(c.flaggerClient, cd, val)
+}
+
+// SetStatusLocality updates the canary status locality index
+func (c *ScalableController) SetStatusLocality(cd *flaggerv1.Canary, val int) error {
+	return setStatusLocality(c.flaggerClient, cd, val)
+}
+
+// SetStatusRegression updates the canary status regression metrics
+func (c *ScalableController) SetStatusRegression(cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	return setStatusRegression(c.flaggerClient, cd, val)
+}
+
+// SetStatusPhase updates the canary status phase
+func (c *ScalableController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	return setStatusPhase(c.flaggerClient, cd, phase)
+}
@@ -55,6 +55,16 @@ func (c *ServiceController) SetStatusIterations(cd *flaggerv1.Canary, val int) e
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusLocality updates the canary status locality index
+func (c *ServiceController) SetStatusLocality(cd *flaggerv1.Canary, val int) error {
+	return setStatusLocality(c.flaggerClient, cd, val)
+}
+
+// SetStatusRegression updates the canary status regression metrics
+func (c *ServiceController) SetStatusRegression(cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	return setStatusRegression(c.flaggerClient, cd, val)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *ServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -90,12 +100,12 @@ func (c *ServiceController) Initialize(cd *flaggerv1.Canary) (err error) {
 func (c *ServiceController) reconcileCanaryService(canary *flaggerv1.Canary, name string, src *corev1.Service) error {
 	current, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		return c.createService(canary, name, src)
+		return c.createService(canary, name, src, canary.Spec.Service.Canary)
 	} else if err != nil {
 		return fmt.Errorf("service %s.%s get query error: %w", name, canary.Namespace, err)
 	}
 
-	ns := buildService(canary, name, src)
+	ns := buildService(canary, name, src, canary.Spec.Service.Canary)
 
 	if ns.Spec.Type == "ClusterIP" {
 		// We can't change this immutable field
@@ -120,15 +130,15 @@ func (c *ServiceController) reconcileCanaryService(canary *flaggerv1.Canary, nam
 func (c *ServiceController) reconcilePrimaryService(canary *flaggerv1.Canary, name string, src *corev1.Service) error {
 	_, err := c.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
-		return c.createService(canary, name, src)
+		return c.createService(canary, name, src, canary.Spec.Service.Primary)
 	} else if err != nil {
 		return fmt.Errorf("service %s.%s get query error: %w", name, canary.Namespace, err)
 	}
 	return nil
 }
 
-func (c *ServiceController) createService(canary *flaggerv1.Canary, name string, src *corev1.Service) error {
-	svc := buildService(canary, name, src)
+func (c *ServiceController) createService(canary *flaggerv1.Canary, name string, src *corev1.Service, metadata *flaggerv1.CustomMetadata) error {
+	svc := buildService(canary, name, src, metadata)
 
 	if svc.Spec.Type == "ClusterIP" {
 		// Reset and let K8s assign the IP. Otherwise we get an error due to the IP is already assigned
@@ -148,7 +158,7 @@ func (c *ServiceController) createService(canary *flaggerv1.Canary, name string,
 	return nil
 }
 
-func buildService(canary *flaggerv1.Canary, name string, src *corev1.Service) *corev1.Service {
+func buildService(canary *flaggerv1.Canary, name string, src *corev1.Service, metadata *flaggerv1.CustomMetadata) *corev1.Service {
 	svc := src.DeepCopy()
 	svc.ObjectMeta.Name = name
 	svc.ObjectMeta.Namespace = canary.Namespace
@@ -165,9 +175,30 @@ func buildService(canary *flaggerv1.Canary, name string, src *corev1.Service) *c
 		//   Operation cannot be fulfilled on services "mysvc-canary": the object has been modified; please apply your changes to the latest version and try again
 		delete(svc.ObjectMeta.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
 	}
+	applyCustomMetadata(&svc.ObjectMeta, metadata)
 	return svc
 }
 
+// applyCustomMetadata merges the labels and annotations specified in the canary
+// service spec into the object metadata, the custom values take precedence
+func applyCustomMetadata(meta *metav1.ObjectMeta, metadata *flaggerv1.CustomMetadata) {
+	if metadata == nil {
+		return
+	}
+	if len(metadata.Labels) > 0 && meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	for k, v := range filterMetadata(metadata.Labels) {
+		meta.Labels[k] = v
+	}
+	if len(metadata.Annotations) > 0 && meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	for k, v := range filterMetadata(metadata.Annotations) {
+		meta.Annotations[k] = v
+	}
+}
+
 // Promote copies target's spec from canary to primary
 func (c *ServiceController) Promote(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
@@ -204,6 +235,7 @@ func (c *ServiceController) Promote(cd *flaggerv1.Canary) error {
 		for k, v := range filteredLabels {
 			primaryCopy.ObjectMeta.Labels[k] = v
 		}
+		applyCustomMetadata(&primaryCopy.ObjectMeta, cd.Spec.Service.Primary)
 
 		// apply update
 		_, err = c.kubeClient.CoreV1().Services(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
@@ -242,7 +274,9 @@ func (c *ServiceController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.Ca
 		return fmt.Errorf("service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec, func(cdCopy *flaggerv1.Canary) {})
+	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.Commit = GitCommit(dep.Annotations)
+	})
 }
 
 func (c *ServiceController) HaveDependenciesChanged(_ *flaggerv1.Canary) (bool, error) {
@@ -260,3 +294,16 @@ func (c *ServiceController) IsCanaryReady(_ *flaggerv1.Canary) (bool, error) {
 func (c *ServiceController) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
+
+func (c *ServiceController) ReconcileBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *ServiceController) DeleteBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// RollbackPrimary is not supported for Service targets
+func (c *ServiceController) RollbackPrimary(cd *flaggerv1.Canary, _ string) error {
+	return fmt.Errorf("rollback of the primary service %s.%s is not supported", cd.Spec.TargetRef.Name, cd.Namespace)
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// StatefulSetController is managing the operations for Kubernetes StatefulSet kind
+type StatefulSetController struct {
+	kubeClient         kubernetes.Interface
+	flaggerClient      clientset.Interface
+	logger             *zap.SugaredLogger
+	configTracker      Tracker
+	labels             []string
+	includeLabelPrefix []string
+}
+
+// Initialize creates the primary StatefulSet if it does not exist
+// and waits for the primary pods to be ready
+func (c *StatefulSetController) Initialize(cd *flaggerv1.Canary) (err error) {
+	if err := c.createPrimaryStatefulSet(cd, c.includeLabelPrefix); err != nil {
+		return fmt.Errorf("createPrimaryStatefulSet failed: %w", err)
+	}
+
+	// wait for all the primary replicas to be ready, the target is scaled
+	// down by the scheduler once the traffic is routed to the primary
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		if !cd.SkipAnalysis() {
+			if err := c.isPrimaryReady(cd, 100); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Promote copies the pod spec, secrets and config maps from canary to primary,
+// the partition of the primary is reset so that all the ordinals are updated
+func (c *StatefulSetController) Promote(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+
+	var previousTemplate corev1.PodTemplateSpec
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		canary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+		}
+
+		label, labelValue, err := c.getSelectorLabel(canary)
+		primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+		if err != nil {
+			return fmt.Errorf("getSelectorLabel failed: %w", err)
+		}
+
+		primary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+
+		// promote secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+
+		previousTemplate = primary.Spec.Template
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.MinReadySeconds = canary.Spec.MinReadySeconds
+		primaryCopy.Spec.RevisionHistoryLimit = canary.Spec.RevisionHistoryLimit
+		primaryCopy.Spec.UpdateStrategy = getPrimaryStatefulSetStrategy(canary)
+		// update replica if hpa isn't set
+		if cd.Spec.AutoscalerRef == nil {
+			primaryCopy.Spec.Replicas = canary.Spec.Replicas
+		}
+
+		// update spec with primary secrets and config maps
+		primaryCopy.Spec.Template.Spec = c.configTracker.ApplyPrimaryConfigs(canary.Spec.Template.Spec, configRefs)
+
+		// update pod annotations to ensure a rolling update
+		annotations, err := makeAnnotations(canary.Spec.Template.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+
+		primaryCopy.Spec.Template.Annotations = annotations
+		primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+
+		// update sts annotations
+		primaryCopy.ObjectMeta.Annotations = make(map[string]string)
+		filteredAnnotations := includeLabelsByPrefix(canary.ObjectMeta.Annotations, c.includeLabelPrefix)
+		for k, v := range filteredAnnotations {
+			primaryCopy.ObjectMeta.Annotations[k] = v
+		}
+		// update sts labels
+		filteredLabels := includeLabelsByPrefix(canary.ObjectMeta.Labels, c.includeLabelPrefix)
+		primaryCopy.ObjectMeta.Labels = makePrimaryLabels(filteredLabels, primaryLabelValue, label)
+
+		// apply update
+		_, err = c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating statefulset %s.%s template spec failed: %w",
+			primaryName, cd.Namespace, err)
+	}
+
+	// record the replaced primary template for post-promotion rollbacks
+	if err := saveRevision(c.kubeClient, cd, previousTemplate); err != nil {
+		return fmt.Errorf("saving statefulset %s.%s revision failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	return nil
+}
+
+// RollbackPrimary restores the primary pod template of the given revision,
+// an empty revision selects the one replaced by the last promotion
+func (c *StatefulSetController) RollbackPrimary(cd *flaggerv1.Canary, revision string) error {
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	revisions, err := getRevisions(c.kubeClient, cd)
+	if err != nil {
+		return err
+	}
+	index, err := findRevision(revisions, revision)
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s rollback failed: %w", primaryName, cd.Namespace, err)
+	}
+
+	var replaced corev1.PodTemplateSpec
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		primary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+
+		replaced = primary.Spec.Template
+		primaryCopy := primary.DeepCopy()
+		primaryCopy.Spec.Template = revisions[index].Template
+		_, err = c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Update(context.TODO(), primaryCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("rolling back statefulset %s.%s template spec failed: %w",
+			primaryName, cd.Namespace, err)
+	}
+
+	if err := setRevisions(c.kubeClient, cd, swapRevision(cd, revisions, index, replaced)); err != nil {
+		return err
+	}
+	return setStatusLastPromotedSpec(c.flaggerClient, cd, revisions[index].Hash)
+}
+
+// HasTargetChanged returns true if the canary StatefulSet pod spec has changed
+func (c *StatefulSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	canary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return hasSpecChanged(cd, canary.Spec.Template)
+}
+
+// ScaleToZero sets the canary StatefulSet replicas to zero
+func (c *StatefulSetController) ScaleToZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = int32p(0)
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s update query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// ScaleFromZero sets the canary StatefulSet replicas to the primary replicas
+func (c *StatefulSetController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	replicas := int32p(1)
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas > 0 {
+		replicas = sts.Spec.Replicas
+	} else {
+		primaryName := fmt.Sprintf("%s-primary", targetName)
+		primary, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("statefulset %s.%s get query error: %w", primaryName, cd.Namespace, err)
+		}
+		if primary.Spec.Replicas != nil && *primary.Spec.Replicas > 0 {
+			replicas = primary.Spec.Replicas
+		}
+	}
+
+	stsCopy := sts.DeepCopy()
+	stsCopy.Spec.Replicas = replicas
+
+	_, err = c.kubeClient.AppsV1().StatefulSets(sts.Namespace).Update(context.TODO(), stsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("scaling up statefulset %s.%s failed: %w", stsCopy.GetName(), stsCopy.Namespace, err)
+	}
+	return nil
+}
+
+// GetMetadata returns the pod label selector and svc ports
+func (c *StatefulSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	targetName := cd.Spec.TargetRef.Name
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	var ports map[string]int32
+	if cd.Spec.Service.PortDiscovery {
+		ports = getPorts(cd, canarySts.Spec.Template.Spec.Containers)
+	}
+	return label, labelValue, ports, nil
+}
+
+func (c *StatefulSetController) createPrimaryStatefulSet(cd *flaggerv1.Canary, includeLabelPrefix []string) error {
+	targetName := cd.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+
+	canarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("statefulset %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	if canarySts.Spec.UpdateStrategy.Type != "" &&
+		canarySts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return fmt.Errorf("statefulset %s.%s must have RollingUpdate strategy but have %s",
+			targetName, cd.Namespace, canarySts.Spec.UpdateStrategy.Type)
+	}
+
+	// Create the labels map but filter unwanted labels
+	labels := includeLabelsByPrefix(canarySts.Labels, includeLabelPrefix)
+
+	label, labelValue, err := c.getSelectorLabel(canarySts)
+	primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
+	if err != nil {
+		return fmt.Errorf("getSelectorLabel failed: %w", err)
+	}
+
+	primarySts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// create primary secrets and config maps
+		configRefs, err := c.configTracker.GetTargetConfigs(cd)
+		if err != nil {
+			return fmt.Errorf("GetTargetConfigs failed: %w", err)
+		}
+		if err := c.configTracker.CreatePrimaryConfigs(cd, configRefs, c.includeLabelPrefix); err != nil {
+			return fmt.Errorf("CreatePrimaryConfigs failed: %w", err)
+		}
+		annotations, err := makeAnnotations(canarySts.Spec.Template.Annotations)
+		if err != nil {
+			return fmt.Errorf("makeAnnotations failed: %w", err)
+		}
+
+		replicas := int32(1)
+		if canarySts.Spec.Replicas != nil && *canarySts.Spec.Replicas > 0 {
+			replicas = *canarySts.Spec.Replicas
+		}
+
+		// create primary statefulset, the volume claim templates are copied
+		// so that the primary pods get their own persistent volumes
+		primarySts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        primaryName,
+				Namespace:   cd.Namespace,
+				Labels:      makePrimaryLabels(labels, primaryLabelValue, label),
+				Annotations: filterMetadata(canarySts.Annotations),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: appsv1.StatefulSetSpec{
+				ServiceName:          canarySts.Spec.ServiceName,
+				PodManagementPolicy:  canarySts.Spec.PodManagementPolicy,
+				VolumeClaimTemplates: canarySts.Spec.VolumeClaimTemplates,
+				MinReadySeconds:      canarySts.Spec.MinReadySeconds,
+				RevisionHistoryLimit: canarySts.Spec.RevisionHistoryLimit,
+				Replicas:             int32p(replicas),
+				UpdateStrategy:       getPrimaryStatefulSetStrategy(canarySts),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						label: primaryLabelValue,
+					},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:      makePrimaryLabels(canarySts.Spec.Template.Labels, primaryLabelValue, label),
+						Annotations: annotations,
+					},
+					// update spec with the primary secrets and config maps
+					Spec: c.configTracker.ApplyPrimaryConfigs(canarySts.Spec.Template.Spec, configRefs),
+				},
+			},
+		}
+
+		_, err = c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Create(context.TODO(), primarySts, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("creating statefulset %s.%s failed: %w", primarySts.Name, cd.Namespace, err)
+		}
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+			Infof("StatefulSet %s.%s created", primarySts.GetName(), cd.Namespace)
+	}
+
+	if err := reconcilePrimaryNetworkPolicies(c.kubeClient, c.logger, cd, canarySts.Spec.Template.Labels, label, labelValue); err != nil {
+		return fmt.Errorf("reconcilePrimaryNetworkPolicies failed: %w", err)
+	}
+	return nil
+}
+
+// getPrimaryStatefulSetStrategy returns the rolling update strategy of the target without its partition,
+// the partition is used to stage the target pods and must not hold back the promotion of the primary
+func getPrimaryStatefulSetStrategy(canarySts *appsv1.StatefulSet) appsv1.StatefulSetUpdateStrategy {
+	strategy := *canarySts.Spec.UpdateStrategy.DeepCopy()
+	if strategy.RollingUpdate != nil {
+		strategy.RollingUpdate.Partition = nil
+	}
+	return strategy
+}
+
+// getSelectorLabel returns the selector match label
+func (c *StatefulSetController) getSelectorLabel(statefulSet *appsv1.StatefulSet) (string, string, error) {
+	for _, l := range c.labels {
+		if _, ok := statefulSet.Spec.Selector.MatchLabels[l]; ok {
+			return l, statefulSet.Spec.Selector.MatchLabels[l], nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"statefulset %s.%s spec.selector.matchLabels must contain one of %v'",
+		statefulSet.Name, statefulSet.Namespace, c.labels,
+	)
+}
+
+func (c *StatefulSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	return c.configTracker.HasConfigChanged(cd)
+}
+
+// Finalize scale the reference instance from zero
+func (c *StatefulSetController) Finalize(cd *flaggerv1.Canary) error {
+	if err := c.ScaleFromZero(cd); err != nil {
+		return fmt.Errorf("ScaleFromZero failed: %w", err)
+	}
+	return nil
+}
+
+// ReconcileBaseline is a no-op for statefulsets, a baseline copy would need its own persistent volumes
+func (c *StatefulSetController) ReconcileBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (c *StatefulSetController) DeleteBaseline(_ *flaggerv1.Canary) error {
+	return nil
+}
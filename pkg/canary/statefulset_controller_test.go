@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestStatefulSetController_Initialize(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	stsPrimary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "podinfo-primary", stsPrimary.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, "podinfo-headless", stsPrimary.Spec.ServiceName)
+	assert.Equal(t, int32(3), *stsPrimary.Spec.Replicas)
+	assert.Len(t, stsPrimary.Spec.VolumeClaimTemplates, 1)
+	assert.Nil(t, stsPrimary.Spec.UpdateStrategy.RollingUpdate.Partition)
+	assert.Equal(t, "podinfo-config-env-primary",
+		stsPrimary.Spec.Template.Spec.Containers[0].EnvFrom[0].ConfigMapRef.Name)
+}
+
+func TestStatefulSetController_Initialize_OnDelete(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	sts := newStatefulSetControllerTestPodInfo("stefanprodan/podinfo:3.1.0")
+	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	_, err := mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), sts, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Initialize(mocks.canary)
+	require.Error(t, err)
+}
+
+func TestStatefulSetController_Promote(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	sts2 := newStatefulSetControllerTestPodInfo("stefanprodan/podinfo:3.1.1")
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), sts2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	config2 := newStatefulSetControllerTestConfigMap("blue")
+	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	stsPrimary, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "stefanprodan/podinfo:3.1.1", stsPrimary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", stsPrimary.Spec.Template.Labels["app"])
+	assert.Nil(t, stsPrimary.Spec.UpdateStrategy.RollingUpdate.Partition)
+
+	configPrimary, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "blue", configPrimary.Data["color"])
+}
+
+func TestStatefulSetController_Scale(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = mocks.controller.ScaleToZero(mocks.canary)
+	require.NoError(t, err)
+
+	sts, err := mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *sts.Spec.Replicas)
+
+	err = mocks.controller.ScaleFromZero(mocks.canary)
+	require.NoError(t, err)
+
+	sts, err = mocks.kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *sts.Spec.Replicas)
+}
+
+func TestStatefulSetController_HasTargetChanged(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	err := mocks.controller.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = mocks.controller.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized})
+	require.NoError(t, err)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, cd.Status.Images, 1)
+	assert.Equal(t, "3.1.0", cd.Status.Images[0].Tag)
+
+	changed, err := mocks.controller.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	sts2 := newStatefulSetControllerTestPodInfo("stefanprodan/podinfo:3.1.1")
+	_, err = mocks.kubeClient.AppsV1().StatefulSets("default").Update(context.TODO(), sts2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = mocks.controller.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestStatefulSetController_isStatefulSetReady(t *testing.T) {
+	mocks := newStatefulSetFixture()
+	cd := &flaggerv1.Canary{}
+
+	// observed generation is less than desired generation
+	sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: int32p(3)}}
+	sts.Status.ObservedGeneration--
+	retryable, err := mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// pods below the partition keep the current revision
+	sts = newStatefulSetControllerTestPodInfo("stefanprodan/podinfo:3.1.1")
+	sts.Status = appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 3}
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.NoError(t, err)
+	require.True(t, retryable)
+
+	// rollout in progress
+	sts.Spec.UpdateStrategy.RollingUpdate.Partition = nil
+	cd.Status.LastTransitionTime = metav1.Now()
+	cd.Spec.ProgressDeadlineSeconds = int32p(60)
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.True(t, retryable)
+
+	// deadline exceeded
+	cd.Spec.ProgressDeadlineSeconds = int32p(-1e6)
+	retryable, err = mocks.controller.isStatefulSetReady(cd, sts, 100)
+	require.Error(t, err)
+	require.False(t, retryable)
+}
@@ -47,11 +47,14 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 		cdCopy.Status.CanaryWeight = status.CanaryWeight
 		cdCopy.Status.FailedChecks = status.FailedChecks
 		cdCopy.Status.Iterations = status.Iterations
+		cdCopy.Status.Locality = status.Locality
+		cdCopy.Status.Regression = status.Regression
 		cdCopy.Status.LastAppliedSpec = hash
 		if status.Phase == flaggerv1.CanaryPhaseInitialized {
 			cdCopy.Status.LastPromotedSpec = hash
 		}
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		setLastProgressTime(cd, cdCopy)
 		setAll(cdCopy)
 
 		if ok, conditions := MakeStatusConditions(cd, status.Phase); ok {
@@ -106,6 +109,7 @@ func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, va
 		cdCopy := cd.DeepCopy()
 		cdCopy.Status.CanaryWeight = val
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		setLastProgressTime(cd, cdCopy)
 
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
@@ -143,6 +147,84 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+func setStatusLastPromotedSpec(flaggerClient clientset.Interface, cd *flaggerv1.Canary, hash string) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.LastPromotedSpec = hash
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+func setStatusLocality(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.Locality = val
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+func setStatusRegression(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val *flaggerv1.CanaryRegressionStatus) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.Regression = val.DeepCopy()
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
@@ -163,6 +245,8 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 			cdCopy.Status.Iterations = 0
 			if phase == flaggerv1.CanaryPhaseWaitingPromotion {
 				cdCopy.Status.Iterations = cd.GetAnalysis().Iterations - 1
+			} else {
+				cdCopy.Status.Locality = 0
 			}
 		}
 
@@ -171,6 +255,8 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 			cdCopy.Status.LastPromotedSpec = cd.Status.LastAppliedSpec
 		}
 
+		setLastProgressTime(cd, cdCopy)
+
 		if ok, conditions := MakeStatusConditions(cdCopy, phase); ok {
 			cdCopy.Status.Conditions = conditions
 		}
@@ -185,6 +271,13 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 	return nil
 }
 
+// setLastProgressTime records the time of the update if the canary weight or phase changes
+func setLastProgressTime(cd *flaggerv1.Canary, cdCopy *flaggerv1.Canary) {
+	if cd.Status.Phase != cdCopy.Status.Phase || cd.Status.CanaryWeight != cdCopy.Status.CanaryWeight {
+		cdCopy.Status.LastProgressTime = metav1.Now()
+	}
+}
+
 // getStatusCondition returns a condition based on type
 func getStatusCondition(status flaggerv1.CanaryStatus, conditionType flaggerv1.CanaryConditionType) *flaggerv1.CanaryCondition {
 	for i := range status.Conditions {
@@ -196,11 +289,10 @@ func getStatusCondition(status flaggerv1.CanaryStatus, conditionType flaggerv1.C
 	return nil
 }
 
-// MakeStatusCondition updates the canary status conditions based on canary phase
+// MakeStatusConditions updates the canary status conditions based on canary phase,
+// the Promoted and Healthy conditions are true only after the initialization or promotion finished
 func MakeStatusConditions(cd *flaggerv1.Canary,
 	phase flaggerv1.CanaryPhase) (bool, []flaggerv1.CanaryCondition) {
-	currentCondition := getStatusCondition(cd.Status, flaggerv1.PromotedType)
-
 	message := fmt.Sprintf("New %s detected, starting initialization.", cd.Spec.TargetRef.Kind)
 	status := corev1.ConditionUnknown
 	switch phase {
@@ -233,8 +325,30 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		message = fmt.Sprintf("Canary analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind)
 	}
 
-	newCondition := &flaggerv1.CanaryCondition{
-		Type:               flaggerv1.PromotedType,
+	promoted, promotedChanged := makeStatusCondition(cd.Status, flaggerv1.PromotedType, status, phase, message)
+	healthy, healthyChanged := makeStatusCondition(cd.Status, flaggerv1.HealthyType, status, phase, message)
+	if !promotedChanged && !healthyChanged {
+		return false, nil
+	}
+
+	// keep the conditions not driven by the canary phase e.g. the preflight checks
+	conditions := []flaggerv1.CanaryCondition{promoted, healthy}
+	for _, condition := range cd.Status.Conditions {
+		if condition.Type != flaggerv1.PromotedType && condition.Type != flaggerv1.HealthyType {
+			conditions = append(conditions, condition)
+		}
+	}
+	return true, conditions
+}
+
+// makeStatusCondition returns the condition for the given type and reports if it differs from the current one,
+// the last transition time is preserved when the status is unchanged
+func makeStatusCondition(canaryStatus flaggerv1.CanaryStatus, conditionType flaggerv1.CanaryConditionType,
+	status corev1.ConditionStatus, phase flaggerv1.CanaryPhase, message string) (flaggerv1.CanaryCondition, bool) {
+	currentCondition := getStatusCondition(canaryStatus, conditionType)
+
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               conditionType,
 		Status:             status,
 		LastUpdateTime:     metav1.Now(),
 		LastTransitionTime: metav1.Now(),
@@ -245,14 +359,14 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 	if currentCondition != nil &&
 		currentCondition.Status == newCondition.Status &&
 		currentCondition.Reason == newCondition.Reason {
-		return false, nil
+		return *currentCondition, false
 	}
 
 	if currentCondition != nil && currentCondition.Status == newCondition.Status {
 		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
 	}
 
-	return true, []flaggerv1.CanaryCondition{*newCondition}
+	return newCondition, true
 }
 
 // updateStatusWithUpgrade tries to update the status sub-resource
@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// TargetGroupController is managing a Deployment target together with the Deployments
+// of its target group, every member gets a primary and the group is promoted, scaled
+// and rolled back as a whole
+type TargetGroupController struct {
+	*DeploymentController
+}
+
+// TargetGroupMember returns a copy of the canary that targets a Deployment of the target group
+func TargetGroupMember(cd *flaggerv1.Canary, member flaggerv1.CanaryTargetGroupMember) *flaggerv1.Canary {
+	mcd := cd.DeepCopy()
+	mcd.Spec.TargetRef = flaggerv1.LocalObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       member.Name,
+	}
+	mcd.Spec.TargetGroup = nil
+	mcd.Spec.AutoscalerRef = nil
+	mcd.Spec.Service.Name = ""
+	if member.Port > 0 {
+		mcd.Spec.Service.Port = member.Port
+		mcd.Spec.Service.TargetPort = member.TargetPort
+	} else if member.TargetPort.String() != "0" {
+		mcd.Spec.Service.TargetPort = member.TargetPort
+	}
+	return mcd
+}
+
+// targets returns the canary followed by a copy for each member of the target group
+func (c *TargetGroupController) targets(cd *flaggerv1.Canary) []*flaggerv1.Canary {
+	targets := []*flaggerv1.Canary{cd}
+	for _, member := range cd.Spec.TargetGroup {
+		targets = append(targets, TargetGroupMember(cd, member))
+	}
+	return targets
+}
+
+// GetMetadata returns the pod label selector and svc ports of the target
+func (c *TargetGroupController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
+	if cd.Spec.TargetRef.Kind != "Deployment" {
+		return "", "", nil, fmt.Errorf("canary %s.%s targetGroup requires a Deployment target, got %s",
+			cd.Name, cd.Namespace, cd.Spec.TargetRef.Kind)
+	}
+	return c.DeploymentController.GetMetadata(cd)
+}
+
+// Initialize creates the primary deployments of the target and of the group members
+func (c *TargetGroupController) Initialize(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.Initialize(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsPrimaryReady returns an error if any of the primary deployments is not ready
+func (c *TargetGroupController) IsPrimaryReady(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.IsPrimaryReady(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsCanaryReady returns an error if any of the canary deployments is not ready
+func (c *TargetGroupController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
+	for _, target := range c.targets(cd) {
+		if retriable, err := c.DeploymentController.IsCanaryReady(target); err != nil {
+			return retriable, err
+		}
+	}
+	return true, nil
+}
+
+// Promote copies the pod spec, secrets and config maps of every group member to its primary
+func (c *TargetGroupController) Promote(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.Promote(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollbackPrimary restores the primary pod templates of the given revision, the revision
+// must be recorded for every group member before any of the primaries is changed
+func (c *TargetGroupController) RollbackPrimary(cd *flaggerv1.Canary, revision string) error {
+	targets := c.targets(cd)
+	for _, target := range targets {
+		if _, _, err := c.findPrimaryRevision(target, revision); err != nil {
+			return err
+		}
+	}
+
+	var hash string
+	for _, target := range targets {
+		restored, err := c.rollbackPrimaryTemplate(target, revision)
+		if err != nil {
+			return err
+		}
+		if hash == "" {
+			hash = restored
+		}
+	}
+	return setStatusLastPromotedSpec(c.flaggerClient, cd, hash)
+}
+
+// HasTargetChanged returns true if the pod spec of any group member has changed
+func (c *TargetGroupController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
+	deployments, err := c.getDeployments(cd)
+	if err != nil {
+		return false, err
+	}
+	return hasSpecChanged(cd, podTemplates(deployments))
+}
+
+// HaveDependenciesChanged returns true if the config maps or secrets of any group member have changed
+func (c *TargetGroupController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bool, error) {
+	configs, err := c.getConfigRefs(cd)
+	if err != nil {
+		return false, err
+	}
+
+	if len(configs) == 0 && cd.Status.TrackedConfigs == nil {
+		return false, nil
+	}
+	if cd.Status.TrackedConfigs == nil || len(configs) != len(*cd.Status.TrackedConfigs) {
+		return true, nil
+	}
+	for name, checksum := range configs {
+		if (*cd.Status.TrackedConfigs)[name] != checksum {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SyncStatus encodes the pod specs of the group members and updates the canary status
+func (c *TargetGroupController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	deployments, err := c.getDeployments(cd)
+	if err != nil {
+		return err
+	}
+
+	configs, err := c.getConfigRefs(cd)
+	if err != nil {
+		return err
+	}
+
+	dep := deployments[0]
+	return syncCanaryStatus(c.flaggerClient, cd, status, podTemplates(deployments), func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.TrackedConfigs = &configs
+		cdCopy.Status.Images = PodImages(dep.Spec.Template.Spec)
+		cdCopy.Status.Commit = GitCommit(dep.Spec.Template.Annotations, dep.Annotations)
+	})
+}
+
+// ScaleToZero scales the canary deployments of the group to zero
+func (c *TargetGroupController) ScaleToZero(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.ScaleToZero(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScaleFromZero scales up the canary deployments of the group
+func (c *TargetGroupController) ScaleFromZero(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.ScaleFromZero(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize reverts the deployments of the group to the primary replicas
+func (c *TargetGroupController) Finalize(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.Finalize(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReconcileBaseline creates the baseline deployments of the group
+func (c *TargetGroupController) ReconcileBaseline(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.ReconcileBaseline(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBaseline removes the baseline deployments of the group
+func (c *TargetGroupController) DeleteBaseline(cd *flaggerv1.Canary) error {
+	for _, target := range c.targets(cd) {
+		if err := c.DeploymentController.DeleteBaseline(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDeployments returns the canary deployments of the target and of the group members
+func (c *TargetGroupController) getDeployments(cd *flaggerv1.Canary) ([]*appsv1.Deployment, error) {
+	var deployments []*appsv1.Deployment
+	for _, target := range c.targets(cd) {
+		name := target.Spec.TargetRef.Name
+		dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", name, cd.Namespace, err)
+		}
+		deployments = append(deployments, dep)
+	}
+	return deployments, nil
+}
+
+// podTemplates returns the pod templates hashed to detect a change of the group
+func podTemplates(deployments []*appsv1.Deployment) []corev1.PodTemplateSpec {
+	templates := make([]corev1.PodTemplateSpec, 0, len(deployments))
+	for _, dep := range deployments {
+		templates = append(templates, dep.Spec.Template)
+	}
+	return templates
+}
+
+// getConfigRefs returns the checksums of the config maps and secrets used by the group members
+func (c *TargetGroupController) getConfigRefs(cd *flaggerv1.Canary) (map[string]string, error) {
+	configs := make(map[string]string)
+	for _, target := range c.targets(cd) {
+		refs, err := c.configTracker.GetConfigRefs(target)
+		if err != nil {
+			return nil, fmt.Errorf("GetConfigRefs failed: %w", err)
+		}
+		if refs == nil {
+			continue
+		}
+		for name, checksum := range *refs {
+			configs[name] = checksum
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTargetGroupFixture(t *testing.T) (deploymentControllerFixture, *TargetGroupController) {
+	mocks := newDeploymentFixture(deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"})
+	backend := newDeploymentControllerTest(deploymentConfigs{name: "backend", label: "app", labelValue: "backend"})
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), backend, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	mocks.canary.Spec.TargetGroup = []flaggerv1.CanaryTargetGroupMember{{Name: "backend", Port: 8080}}
+	return mocks, &TargetGroupController{DeploymentController: &mocks.controller}
+}
+
+func setTargetGroupPrimaryReady(t *testing.T, mocks deploymentControllerFixture, name string) {
+	p, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	p.Status = appsv1.DeploymentStatus{
+		Replicas:          1,
+		UpdatedReplicas:   1,
+		ReadyReplicas:     1,
+		AvailableReplicas: 1,
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), p, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func initializeTargetGroup(t *testing.T, mocks deploymentControllerFixture, ctrl *TargetGroupController) {
+	require.Error(t, ctrl.Initialize(mocks.canary))
+	setTargetGroupPrimaryReady(t, mocks, "podinfo-primary")
+
+	// the group is ready only when the primaries of all members are ready
+	require.Error(t, ctrl.Initialize(mocks.canary))
+	setTargetGroupPrimaryReady(t, mocks, "backend-primary")
+
+	require.NoError(t, ctrl.Initialize(mocks.canary))
+}
+
+func TestTargetGroupMember(t *testing.T) {
+	cd := newDeploymentControllerTestCanary(canaryConfigs{targetName: "podinfo"})
+	cd.Spec.Service.Name = "frontend"
+	cd.Spec.Service.TargetPort = intstr.FromString("http")
+	cd.Spec.TargetGroup = []flaggerv1.CanaryTargetGroupMember{{Name: "backend"}}
+
+	member := TargetGroupMember(cd, cd.Spec.TargetGroup[0])
+	assert.Equal(t, "backend", member.Spec.TargetRef.Name)
+	assert.Equal(t, "Deployment", member.Spec.TargetRef.Kind)
+	assert.Nil(t, member.Spec.AutoscalerRef)
+	assert.Empty(t, member.Spec.TargetGroup)
+	assert.Equal(t, int32(9898), member.Spec.Service.Port)
+	assert.Equal(t, "http", member.Spec.Service.TargetPort.String())
+
+	apexName, primaryName, canaryName := member.GetServiceNames()
+	assert.Equal(t, "backend", apexName)
+	assert.Equal(t, "backend-primary", primaryName)
+	assert.Equal(t, "backend-canary", canaryName)
+
+	// the member port resets the target port inherited from the canary
+	member = TargetGroupMember(cd, flaggerv1.CanaryTargetGroupMember{Name: "backend", Port: 8080})
+	assert.Equal(t, int32(8080), member.Spec.Service.Port)
+	assert.Equal(t, "0", member.Spec.Service.TargetPort.String())
+
+	// the canary is not modified
+	assert.Equal(t, "podinfo", cd.Spec.TargetRef.Name)
+	assert.Len(t, cd.Spec.TargetGroup, 1)
+}
+
+func TestTargetGroupController_Initialize(t *testing.T) {
+	mocks, ctrl := newTargetGroupFixture(t)
+	initializeTargetGroup(t, mocks, ctrl)
+
+	backendPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "backend-primary", backendPrimary.Spec.Selector.MatchLabels["app"])
+
+	require.NoError(t, ctrl.ScaleToZero(mocks.canary))
+	for _, name := range []string{"podinfo", "backend"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), *dep.Spec.Replicas)
+	}
+}
+
+func TestTargetGroupController_GetMetadata(t *testing.T) {
+	mocks, ctrl := newTargetGroupFixture(t)
+
+	label, labelValue, _, err := ctrl.GetMetadata(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, "name", label)
+	assert.Equal(t, "podinfo", labelValue)
+
+	mocks.canary.Spec.TargetRef.Kind = "DaemonSet"
+	_, _, _, err = ctrl.GetMetadata(mocks.canary)
+	require.Error(t, err)
+}
+
+func TestTargetGroupController_HasTargetChanged(t *testing.T) {
+	mocks, ctrl := newTargetGroupFixture(t)
+	initializeTargetGroup(t, mocks, ctrl)
+
+	err := ctrl.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseInitialized})
+	require.NoError(t, err)
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.TargetGroup = mocks.canary.Spec.TargetGroup
+
+	changed, err := ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// a change of a group member starts a new analysis of the group
+	backend, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	backend.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:6.0.1"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), backend, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed, err = ctrl.HasTargetChanged(cd)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	require.NoError(t, ctrl.Promote(cd))
+	backendPrimary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:6.0.1", backendPrimary.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestTargetGroupController_RollbackPrimary(t *testing.T) {
+	mocks, ctrl := newTargetGroupFixture(t)
+	initializeTargetGroup(t, mocks, ctrl)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	previousImage := primary.Spec.Template.Spec.Containers[0].Image
+
+	mocks.canary.Spec.RevisionHistoryLimit = 1
+	mocks.canary.Status.LastPromotedSpec = "v1"
+	for _, name := range []string{"podinfo", "backend"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		dep.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:6.0.1"
+		_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// promote the target alone, the backend has no revision to roll back to
+	require.NoError(t, ctrl.DeploymentController.Promote(mocks.canary))
+	mocks.canary.Status.LastPromotedSpec = "v2"
+
+	require.Error(t, ctrl.RollbackPrimary(mocks.canary, "v1"))
+	primary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:6.0.1", primary.Spec.Template.Spec.Containers[0].Image)
+
+	// record the backend revision replaced by the same promotion
+	mocks.canary.Status.LastPromotedSpec = "v1"
+	require.NoError(t, ctrl.DeploymentController.Promote(TargetGroupMember(mocks.canary, mocks.canary.Spec.TargetGroup[0])))
+	mocks.canary.Status.LastPromotedSpec = "v2"
+
+	require.NoError(t, ctrl.RollbackPrimary(mocks.canary, "v1"))
+	for _, name := range []string{"podinfo-primary", "backend-primary"} {
+		primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, previousImage, primary.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", cd.Status.LastPromotedSpec)
+}
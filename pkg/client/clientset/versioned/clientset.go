@@ -25,14 +25,18 @@ import (
 	apisixv2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/apisix/v2"
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2"
+	emissaryv3alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/emissary/v3alpha1"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gateway/v1"
 	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1alpha2"
 	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1beta1"
 	gloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
-	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
 	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
+	knativev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/knative/v1"
 	kumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	openshiftroutev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/openshift/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha2"
@@ -48,14 +52,18 @@ type Interface interface {
 	ApisixV2() apisixv2.ApisixV2Interface
 	AppmeshV1beta2() appmeshv1beta2.AppmeshV1beta2Interface
 	AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface
+	EmissaryV3alpha1() emissaryv3alpha1.EmissaryV3alpha1Interface
+	ExternalDNSV1alpha1() externaldnsv1alpha1.ExternalDNSV1alpha1Interface
 	FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface
 	GatewayV1() gatewayv1.GatewayV1Interface
 	GatewayapiV1alpha2() gatewayapiv1alpha2.GatewayapiV1alpha2Interface
 	GatewayapiV1beta1() gatewayapiv1beta1.GatewayapiV1beta1Interface
 	GlooV1() gloov1.GlooV1Interface
-	NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface
 	KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface
+	KnativeV1() knativev1.KnativeV1Interface
 	KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface
+	MonitoringV1() monitoringv1.MonitoringV1Interface
+	OpenShiftRouteV1() openshiftroutev1.OpenShiftRouteV1Interface
 	ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface
 	SplitV1alpha1() splitv1alpha1.SplitV1alpha1Interface
 	SplitV1alpha2() splitv1alpha2.SplitV1alpha2Interface
@@ -66,22 +74,26 @@ type Interface interface {
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
-	apisixV2           *apisixv2.ApisixV2Client
-	appmeshV1beta2     *appmeshv1beta2.AppmeshV1beta2Client
-	appmeshV1beta1     *appmeshv1beta1.AppmeshV1beta1Client
-	flaggerV1beta1     *flaggerv1beta1.FlaggerV1beta1Client
-	gatewayV1          *gatewayv1.GatewayV1Client
-	gatewayapiV1alpha2 *gatewayapiv1alpha2.GatewayapiV1alpha2Client
-	gatewayapiV1beta1  *gatewayapiv1beta1.GatewayapiV1beta1Client
-	glooV1             *gloov1.GlooV1Client
-	networkingV1alpha3 *networkingv1alpha3.NetworkingV1alpha3Client
-	kedaV1alpha1       *kedav1alpha1.KedaV1alpha1Client
-	kumaV1alpha1       *kumav1alpha1.KumaV1alpha1Client
-	projectcontourV1   *projectcontourv1.ProjectcontourV1Client
-	splitV1alpha1      *splitv1alpha1.SplitV1alpha1Client
-	splitV1alpha2      *splitv1alpha2.SplitV1alpha2Client
-	splitV1alpha3      *splitv1alpha3.SplitV1alpha3Client
-	traefikV1alpha1    *traefikv1alpha1.TraefikV1alpha1Client
+	apisixV2            *apisixv2.ApisixV2Client
+	appmeshV1beta2      *appmeshv1beta2.AppmeshV1beta2Client
+	appmeshV1beta1      *appmeshv1beta1.AppmeshV1beta1Client
+	emissaryV3alpha1    *emissaryv3alpha1.EmissaryV3alpha1Client
+	externalDNSV1alpha1 *externaldnsv1alpha1.ExternalDNSV1alpha1Client
+	flaggerV1beta1      *flaggerv1beta1.FlaggerV1beta1Client
+	gatewayV1           *gatewayv1.GatewayV1Client
+	gatewayapiV1alpha2  *gatewayapiv1alpha2.GatewayapiV1alpha2Client
+	gatewayapiV1beta1   *gatewayapiv1beta1.GatewayapiV1beta1Client
+	glooV1              *gloov1.GlooV1Client
+	kedaV1alpha1        *kedav1alpha1.KedaV1alpha1Client
+	knativeV1           *knativev1.KnativeV1Client
+	kumaV1alpha1        *kumav1alpha1.KumaV1alpha1Client
+	monitoringV1        *monitoringv1.MonitoringV1Client
+	openShiftRouteV1    *openshiftroutev1.OpenShiftRouteV1Client
+	projectcontourV1    *projectcontourv1.ProjectcontourV1Client
+	splitV1alpha1       *splitv1alpha1.SplitV1alpha1Client
+	splitV1alpha2       *splitv1alpha2.SplitV1alpha2Client
+	splitV1alpha3       *splitv1alpha3.SplitV1alpha3Client
+	traefikV1alpha1     *traefikv1alpha1.TraefikV1alpha1Client
 }
 
 // ApisixV2 retrieves the ApisixV2Client
@@ -99,6 +111,16 @@ func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return c.appmeshV1beta1
 }
 
+// EmissaryV3alpha1 retrieves the EmissaryV3alpha1Client
+func (c *Clientset) EmissaryV3alpha1() emissaryv3alpha1.EmissaryV3alpha1Interface {
+	return c.emissaryV3alpha1
+}
+
+// ExternalDNSV1alpha1 retrieves the ExternalDNSV1alpha1Client
+func (c *Clientset) ExternalDNSV1alpha1() externaldnsv1alpha1.ExternalDNSV1alpha1Interface {
+	return c.externalDNSV1alpha1
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return c.flaggerV1beta1
@@ -124,21 +146,31 @@ func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return c.glooV1
 }
 
-// NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
-func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
-	return c.networkingV1alpha3
-}
-
 // KedaV1alpha1 retrieves the KedaV1alpha1Client
 func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
 	return c.kedaV1alpha1
 }
 
+// KnativeV1 retrieves the KnativeV1Client
+func (c *Clientset) KnativeV1() knativev1.KnativeV1Interface {
+	return c.knativeV1
+}
+
 // KumaV1alpha1 retrieves the KumaV1alpha1Client
 func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
 	return c.kumaV1alpha1
 }
 
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return c.monitoringV1
+}
+
+// OpenShiftRouteV1 retrieves the OpenShiftRouteV1Client
+func (c *Clientset) OpenShiftRouteV1() openshiftroutev1.OpenShiftRouteV1Interface {
+	return c.openShiftRouteV1
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return c.projectcontourV1
@@ -220,6 +252,14 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.emissaryV3alpha1, err = emissaryv3alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.externalDNSV1alpha1, err = externaldnsv1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.flaggerV1beta1, err = flaggerv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -240,11 +280,11 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
-	cs.networkingV1alpha3, err = networkingv1alpha3.NewForConfigAndClient(&configShallowCopy, httpClient)
+	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
 	}
-	cs.kedaV1alpha1, err = kedav1alpha1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	cs.knativeV1, err = knativev1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
 	}
@@ -252,6 +292,14 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.monitoringV1, err = monitoringv1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.openShiftRouteV1, err = openshiftroutev1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 	cs.projectcontourV1, err = projectcontourv1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
@@ -296,14 +344,18 @@ func New(c rest.Interface) *Clientset {
 	cs.apisixV2 = apisixv2.New(c)
 	cs.appmeshV1beta2 = appmeshv1beta2.New(c)
 	cs.appmeshV1beta1 = appmeshv1beta1.New(c)
+	cs.emissaryV3alpha1 = emissaryv3alpha1.New(c)
+	cs.externalDNSV1alpha1 = externaldnsv1alpha1.New(c)
 	cs.flaggerV1beta1 = flaggerv1beta1.New(c)
 	cs.gatewayV1 = gatewayv1.New(c)
 	cs.gatewayapiV1alpha2 = gatewayapiv1alpha2.New(c)
 	cs.gatewayapiV1beta1 = gatewayapiv1beta1.New(c)
 	cs.glooV1 = gloov1.New(c)
-	cs.networkingV1alpha3 = networkingv1alpha3.New(c)
 	cs.kedaV1alpha1 = kedav1alpha1.New(c)
+	cs.knativeV1 = knativev1.New(c)
 	cs.kumaV1alpha1 = kumav1alpha1.New(c)
+	cs.monitoringV1 = monitoringv1.New(c)
+	cs.openShiftRouteV1 = openshiftroutev1.New(c)
 	cs.projectcontourV1 = projectcontourv1.New(c)
 	cs.splitV1alpha1 = splitv1alpha1.New(c)
 	cs.splitV1alpha2 = splitv1alpha2.New(c)
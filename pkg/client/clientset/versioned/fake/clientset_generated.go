@@ -26,6 +26,10 @@ import (
 	fakeappmeshv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta1/fake"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2"
 	fakeappmeshv1beta2 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/appmesh/v1beta2/fake"
+	emissaryv3alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/emissary/v3alpha1"
+	fakeemissaryv3alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/emissary/v3alpha1/fake"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1"
+	fakeexternaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/externaldns/v1alpha1/fake"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1"
 	fakeflaggerv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/flagger/v1beta1/fake"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gateway/v1"
@@ -36,12 +40,16 @@ import (
 	fakegatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gatewayapi/v1beta1/fake"
 	gloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1"
 	fakegloov1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/gloo/v1/fake"
-	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
-	fakenetworkingv1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3/fake"
 	kedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1"
 	fakekedav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/keda/v1alpha1/fake"
+	knativev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/knative/v1"
+	fakeknativev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/knative/v1/fake"
 	kumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1"
 	fakekumav1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/kuma/v1alpha1/fake"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1"
+	fakemonitoringv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/monitoring/v1/fake"
+	openshiftroutev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/openshift/v1"
+	fakeopenshiftroutev1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/openshift/v1/fake"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1"
 	fakeprojectcontourv1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/projectcontour/v1/fake"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/smi/v1alpha1"
@@ -124,6 +132,16 @@ func (c *Clientset) AppmeshV1beta1() appmeshv1beta1.AppmeshV1beta1Interface {
 	return &fakeappmeshv1beta1.FakeAppmeshV1beta1{Fake: &c.Fake}
 }
 
+// EmissaryV3alpha1 retrieves the EmissaryV3alpha1Client
+func (c *Clientset) EmissaryV3alpha1() emissaryv3alpha1.EmissaryV3alpha1Interface {
+	return &fakeemissaryv3alpha1.FakeEmissaryV3alpha1{Fake: &c.Fake}
+}
+
+// ExternalDNSV1alpha1 retrieves the ExternalDNSV1alpha1Client
+func (c *Clientset) ExternalDNSV1alpha1() externaldnsv1alpha1.ExternalDNSV1alpha1Interface {
+	return &fakeexternaldnsv1alpha1.FakeExternalDNSV1alpha1{Fake: &c.Fake}
+}
+
 // FlaggerV1beta1 retrieves the FlaggerV1beta1Client
 func (c *Clientset) FlaggerV1beta1() flaggerv1beta1.FlaggerV1beta1Interface {
 	return &fakeflaggerv1beta1.FakeFlaggerV1beta1{Fake: &c.Fake}
@@ -149,21 +167,31 @@ func (c *Clientset) GlooV1() gloov1.GlooV1Interface {
 	return &fakegloov1.FakeGlooV1{Fake: &c.Fake}
 }
 
-// NetworkingV1alpha3 retrieves the NetworkingV1alpha3Client
-func (c *Clientset) NetworkingV1alpha3() networkingv1alpha3.NetworkingV1alpha3Interface {
-	return &fakenetworkingv1alpha3.FakeNetworkingV1alpha3{Fake: &c.Fake}
-}
-
 // KedaV1alpha1 retrieves the KedaV1alpha1Client
 func (c *Clientset) KedaV1alpha1() kedav1alpha1.KedaV1alpha1Interface {
 	return &fakekedav1alpha1.FakeKedaV1alpha1{Fake: &c.Fake}
 }
 
+// KnativeV1 retrieves the KnativeV1Client
+func (c *Clientset) KnativeV1() knativev1.KnativeV1Interface {
+	return &fakeknativev1.FakeKnativeV1{Fake: &c.Fake}
+}
+
 // KumaV1alpha1 retrieves the KumaV1alpha1Client
 func (c *Clientset) KumaV1alpha1() kumav1alpha1.KumaV1alpha1Interface {
 	return &fakekumav1alpha1.FakeKumaV1alpha1{Fake: &c.Fake}
 }
 
+// MonitoringV1 retrieves the MonitoringV1Client
+func (c *Clientset) MonitoringV1() monitoringv1.MonitoringV1Interface {
+	return &fakemonitoringv1.FakeMonitoringV1{Fake: &c.Fake}
+}
+
+// OpenShiftRouteV1 retrieves the OpenShiftRouteV1Client
+func (c *Clientset) OpenShiftRouteV1() openshiftroutev1.OpenShiftRouteV1Interface {
+	return &fakeopenshiftroutev1.FakeOpenShiftRouteV1{Fake: &c.Fake}
+}
+
 // ProjectcontourV1 retrieves the ProjectcontourV1Client
 func (c *Clientset) ProjectcontourV1() projectcontourv1.ProjectcontourV1Interface {
 	return &fakeprojectcontourv1.FakeProjectcontourV1{Fake: &c.Fake}
@@ -22,14 +22,18 @@ import (
 	apisixv2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
 	appmeshv1beta1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta1"
 	appmeshv1beta2 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
+	emissaryv3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
+	externaldnsv1alpha1 "github.com/fluxcd/flagger/pkg/apis/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	gatewayapiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
 	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
 	gatewayv1 "github.com/fluxcd/flagger/pkg/apis/gloo/gateway/v1"
 	gloov1 "github.com/fluxcd/flagger/pkg/apis/gloo/gloo/v1"
-	networkingv1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
 	kumav1alpha1 "github.com/fluxcd/flagger/pkg/apis/kuma/v1alpha1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
+	openshiftroutev1 "github.com/fluxcd/flagger/pkg/apis/openshift/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
 	splitv1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
 	splitv1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
@@ -49,14 +53,18 @@ var localSchemeBuilder = runtime.SchemeBuilder{
 	apisixv2.AddToScheme,
 	appmeshv1beta2.AddToScheme,
 	appmeshv1beta1.AddToScheme,
+	emissaryv3alpha1.AddToScheme,
+	externaldnsv1alpha1.AddToScheme,
 	flaggerv1beta1.AddToScheme,
 	gatewayv1.AddToScheme,
 	gatewayapiv1alpha2.AddToScheme,
 	gatewayapiv1beta1.AddToScheme,
 	gloov1.AddToScheme,
-	networkingv1alpha3.AddToScheme,
 	kedav1alpha1.AddToScheme,
+	knativev1.AddToScheme,
 	kumav1alpha1.AddToScheme,
+	monitoringv1.AddToScheme,
+	openshiftroutev1.AddToScheme,
 	projectcontourv1.AddToScheme,
 	splitv1alpha1.AddToScheme,
 	splitv1alpha2.AddToScheme,
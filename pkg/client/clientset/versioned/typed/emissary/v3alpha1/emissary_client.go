@@ -16,39 +16,34 @@ limitations under the License.
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha3
+package v3alpha1
 
 import (
 	"net/http"
 
-	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	v3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
 	"github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
 	rest "k8s.io/client-go/rest"
 )
 
-type NetworkingV1alpha3Interface interface {
+type EmissaryV3alpha1Interface interface {
 	RESTClient() rest.Interface
-	DestinationRulesGetter
-	VirtualServicesGetter
+	MappingsGetter
 }
 
-// NetworkingV1alpha3Client is used to interact with features provided by the networking.istio.io group.
-type NetworkingV1alpha3Client struct {
+// EmissaryV3alpha1Client is used to interact with features provided by the getambassador.io group.
+type EmissaryV3alpha1Client struct {
 	restClient rest.Interface
 }
 
-func (c *NetworkingV1alpha3Client) DestinationRules(namespace string) DestinationRuleInterface {
-	return newDestinationRules(c, namespace)
+func (c *EmissaryV3alpha1Client) Mappings(namespace string) MappingInterface {
+	return newMappings(c, namespace)
 }
 
-func (c *NetworkingV1alpha3Client) VirtualServices(namespace string) VirtualServiceInterface {
-	return newVirtualServices(c, namespace)
-}
-
-// NewForConfig creates a new NetworkingV1alpha3Client for the given config.
+// NewForConfig creates a new EmissaryV3alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
-func NewForConfig(c *rest.Config) (*NetworkingV1alpha3Client, error) {
+func NewForConfig(c *rest.Config) (*EmissaryV3alpha1Client, error) {
 	config := *c
 	if err := setConfigDefaults(&config); err != nil {
 		return nil, err
@@ -60,9 +55,9 @@ func NewForConfig(c *rest.Config) (*NetworkingV1alpha3Client, error) {
 	return NewForConfigAndClient(&config, httpClient)
 }
 
-// NewForConfigAndClient creates a new NetworkingV1alpha3Client for the given config and http client.
+// NewForConfigAndClient creates a new EmissaryV3alpha1Client for the given config and http client.
 // Note the http client provided takes precedence over the configured transport values.
-func NewForConfigAndClient(c *rest.Config, h *http.Client) (*NetworkingV1alpha3Client, error) {
+func NewForConfigAndClient(c *rest.Config, h *http.Client) (*EmissaryV3alpha1Client, error) {
 	config := *c
 	if err := setConfigDefaults(&config); err != nil {
 		return nil, err
@@ -71,12 +66,12 @@ func NewForConfigAndClient(c *rest.Config, h *http.Client) (*NetworkingV1alpha3C
 	if err != nil {
 		return nil, err
 	}
-	return &NetworkingV1alpha3Client{client}, nil
+	return &EmissaryV3alpha1Client{client}, nil
 }
 
-// NewForConfigOrDie creates a new NetworkingV1alpha3Client for the given config and
+// NewForConfigOrDie creates a new EmissaryV3alpha1Client for the given config and
 // panics if there is an error in the config.
-func NewForConfigOrDie(c *rest.Config) *NetworkingV1alpha3Client {
+func NewForConfigOrDie(c *rest.Config) *EmissaryV3alpha1Client {
 	client, err := NewForConfig(c)
 	if err != nil {
 		panic(err)
@@ -84,13 +79,13 @@ func NewForConfigOrDie(c *rest.Config) *NetworkingV1alpha3Client {
 	return client
 }
 
-// New creates a new NetworkingV1alpha3Client for the given RESTClient.
-func New(c rest.Interface) *NetworkingV1alpha3Client {
-	return &NetworkingV1alpha3Client{c}
+// New creates a new EmissaryV3alpha1Client for the given RESTClient.
+func New(c rest.Interface) *EmissaryV3alpha1Client {
+	return &EmissaryV3alpha1Client{c}
 }
 
 func setConfigDefaults(config *rest.Config) error {
-	gv := v1alpha3.SchemeGroupVersion
+	gv := v3alpha1.SchemeGroupVersion
 	config.GroupVersion = &gv
 	config.APIPath = "/apis"
 	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
@@ -104,7 +99,7 @@ func setConfigDefaults(config *rest.Config) error {
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *NetworkingV1alpha3Client) RESTClient() rest.Interface {
+func (c *EmissaryV3alpha1Client) RESTClient() rest.Interface {
 	if c == nil {
 		return nil
 	}
@@ -19,26 +19,22 @@ limitations under the License.
 package fake
 
 import (
-	v1alpha3 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/istio/v1alpha3"
+	v3alpha1 "github.com/fluxcd/flagger/pkg/client/clientset/versioned/typed/emissary/v3alpha1"
 	rest "k8s.io/client-go/rest"
 	testing "k8s.io/client-go/testing"
 )
 
-type FakeNetworkingV1alpha3 struct {
+type FakeEmissaryV3alpha1 struct {
 	*testing.Fake
 }
 
-func (c *FakeNetworkingV1alpha3) DestinationRules(namespace string) v1alpha3.DestinationRuleInterface {
-	return &FakeDestinationRules{c, namespace}
-}
-
-func (c *FakeNetworkingV1alpha3) VirtualServices(namespace string) v1alpha3.VirtualServiceInterface {
-	return &FakeVirtualServices{c, namespace}
+func (c *FakeEmissaryV3alpha1) Mappings(namespace string) v3alpha1.MappingInterface {
+	return &FakeMappings{c, namespace}
 }
 
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
-func (c *FakeNetworkingV1alpha3) RESTClient() rest.Interface {
+func (c *FakeEmissaryV3alpha1) RESTClient() rest.Interface {
 	var ret *rest.RESTClient
 	return ret
 }
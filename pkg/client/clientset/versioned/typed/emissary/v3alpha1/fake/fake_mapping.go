@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMappings implements MappingInterface
+type FakeMappings struct {
+	Fake *FakeEmissaryV3alpha1
+	ns   string
+}
+
+var mappingsResource = schema.GroupVersionResource{Group: "getambassador.io", Version: "v3alpha1", Resource: "mappings"}
+
+var mappingsKind = schema.GroupVersionKind{Group: "getambassador.io", Version: "v3alpha1", Kind: "Mapping"}
+
+// Get takes name of the mapping, and returns the corresponding mapping object, and an error if there is any.
+func (c *FakeMappings) Get(ctx context.Context, name string, options v1.GetOptions) (result *v3alpha1.Mapping, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(mappingsResource, c.ns, name), &v3alpha1.Mapping{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v3alpha1.Mapping), err
+}
+
+// List takes label and field selectors, and returns the list of Mappings that match those selectors.
+func (c *FakeMappings) List(ctx context.Context, opts v1.ListOptions) (result *v3alpha1.MappingList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(mappingsResource, mappingsKind, c.ns, opts), &v3alpha1.MappingList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v3alpha1.MappingList{ListMeta: obj.(*v3alpha1.MappingList).ListMeta}
+	for _, item := range obj.(*v3alpha1.MappingList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested mappings.
+func (c *FakeMappings) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(mappingsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a mapping and creates it.  Returns the server's representation of the mapping, and an error, if there is any.
+func (c *FakeMappings) Create(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.CreateOptions) (result *v3alpha1.Mapping, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(mappingsResource, c.ns, mapping), &v3alpha1.Mapping{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v3alpha1.Mapping), err
+}
+
+// Update takes the representation of a mapping and updates it. Returns the server's representation of the mapping, and an error, if there is any.
+func (c *FakeMappings) Update(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.UpdateOptions) (result *v3alpha1.Mapping, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(mappingsResource, c.ns, mapping), &v3alpha1.Mapping{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v3alpha1.Mapping), err
+}
+
+// Delete takes name of the mapping and deletes it. Returns an error if one occurs.
+func (c *FakeMappings) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(mappingsResource, c.ns, name, opts), &v3alpha1.Mapping{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMappings) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(mappingsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v3alpha1.MappingList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched mapping.
+func (c *FakeMappings) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v3alpha1.Mapping, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(mappingsResource, c.ns, name, pt, data, subresources...), &v3alpha1.Mapping{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v3alpha1.Mapping), err
+}
@@ -16,8 +16,6 @@ limitations under the License.
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha3
+package v3alpha1
 
-type DestinationRuleExpansion interface{}
-
-type VirtualServiceExpansion interface{}
+type MappingExpansion interface{}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v3alpha1
+
+import (
+	"context"
+	"time"
+
+	v3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
+	scheme "github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// MappingsGetter has a method to return a MappingInterface.
+// A group's client should implement this interface.
+type MappingsGetter interface {
+	Mappings(namespace string) MappingInterface
+}
+
+// MappingInterface has methods to work with Mapping resources.
+type MappingInterface interface {
+	Create(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.CreateOptions) (*v3alpha1.Mapping, error)
+	Update(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.UpdateOptions) (*v3alpha1.Mapping, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v3alpha1.Mapping, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v3alpha1.MappingList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v3alpha1.Mapping, err error)
+	MappingExpansion
+}
+
+// mappings implements MappingInterface
+type mappings struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMappings returns a Mappings
+func newMappings(c *EmissaryV3alpha1Client, namespace string) *mappings {
+	return &mappings{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the mapping, and returns the corresponding mapping object, and an error if there is any.
+func (c *mappings) Get(ctx context.Context, name string, options v1.GetOptions) (result *v3alpha1.Mapping, err error) {
+	result = &v3alpha1.Mapping{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("mappings").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Mappings that match those selectors.
+func (c *mappings) List(ctx context.Context, opts v1.ListOptions) (result *v3alpha1.MappingList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v3alpha1.MappingList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("mappings").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested mappings.
+func (c *mappings) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("mappings").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a mapping and creates it.  Returns the server's representation of the mapping, and an error, if there is any.
+func (c *mappings) Create(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.CreateOptions) (result *v3alpha1.Mapping, err error) {
+	result = &v3alpha1.Mapping{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("mappings").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(mapping).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a mapping and updates it. Returns the server's representation of the mapping, and an error, if there is any.
+func (c *mappings) Update(ctx context.Context, mapping *v3alpha1.Mapping, opts v1.UpdateOptions) (result *v3alpha1.Mapping, err error) {
+	result = &v3alpha1.Mapping{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("mappings").
+		Name(mapping.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(mapping).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the mapping and deletes it. Returns an error if one occurs.
+func (c *mappings) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("mappings").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *mappings) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("mappings").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched mapping.
+func (c *mappings) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v3alpha1.Mapping, err error) {
+	result = &v3alpha1.Mapping{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("mappings").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
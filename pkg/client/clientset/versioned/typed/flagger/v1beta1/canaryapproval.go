@@ -16,13 +16,13 @@ limitations under the License.
 
 // Code generated by client-gen. DO NOT EDIT.
 
-package v1alpha3
+package v1beta1
 
 import (
 	"context"
 	"time"
 
-	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	scheme "github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -30,45 +30,45 @@ import (
 	rest "k8s.io/client-go/rest"
 )
 
-// VirtualServicesGetter has a method to return a VirtualServiceInterface.
+// CanaryApprovalsGetter has a method to return a CanaryApprovalInterface.
 // A group's client should implement this interface.
-type VirtualServicesGetter interface {
-	VirtualServices(namespace string) VirtualServiceInterface
+type CanaryApprovalsGetter interface {
+	CanaryApprovals(namespace string) CanaryApprovalInterface
 }
 
-// VirtualServiceInterface has methods to work with VirtualService resources.
-type VirtualServiceInterface interface {
-	Create(ctx context.Context, virtualService *v1alpha3.VirtualService, opts v1.CreateOptions) (*v1alpha3.VirtualService, error)
-	Update(ctx context.Context, virtualService *v1alpha3.VirtualService, opts v1.UpdateOptions) (*v1alpha3.VirtualService, error)
+// CanaryApprovalInterface has methods to work with CanaryApproval resources.
+type CanaryApprovalInterface interface {
+	Create(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.CreateOptions) (*v1beta1.CanaryApproval, error)
+	Update(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.UpdateOptions) (*v1beta1.CanaryApproval, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
-	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha3.VirtualService, error)
-	List(ctx context.Context, opts v1.ListOptions) (*v1alpha3.VirtualServiceList, error)
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.CanaryApproval, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.CanaryApprovalList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
-	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha3.VirtualService, err error)
-	VirtualServiceExpansion
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryApproval, err error)
+	CanaryApprovalExpansion
 }
 
-// virtualServices implements VirtualServiceInterface
-type virtualServices struct {
+// canaryApprovals implements CanaryApprovalInterface
+type canaryApprovals struct {
 	client rest.Interface
 	ns     string
 }
 
-// newVirtualServices returns a VirtualServices
-func newVirtualServices(c *NetworkingV1alpha3Client, namespace string) *virtualServices {
-	return &virtualServices{
+// newCanaryApprovals returns a CanaryApprovals
+func newCanaryApprovals(c *FlaggerV1beta1Client, namespace string) *canaryApprovals {
+	return &canaryApprovals{
 		client: c.RESTClient(),
 		ns:     namespace,
 	}
 }
 
-// Get takes name of the virtualService, and returns the corresponding virtualService object, and an error if there is any.
-func (c *virtualServices) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha3.VirtualService, err error) {
-	result = &v1alpha3.VirtualService{}
+// Get takes name of the canaryApproval, and returns the corresponding canaryApproval object, and an error if there is any.
+func (c *canaryApprovals) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CanaryApproval, err error) {
+	result = &v1beta1.CanaryApproval{}
 	err = c.client.Get().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
 		Do(ctx).
@@ -76,16 +76,16 @@ func (c *virtualServices) Get(ctx context.Context, name string, options v1.GetOp
 	return
 }
 
-// List takes label and field selectors, and returns the list of VirtualServices that match those selectors.
-func (c *virtualServices) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha3.VirtualServiceList, err error) {
+// List takes label and field selectors, and returns the list of CanaryApprovals that match those selectors.
+func (c *canaryApprovals) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CanaryApprovalList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
 	}
-	result = &v1alpha3.VirtualServiceList{}
+	result = &v1beta1.CanaryApprovalList{}
 	err = c.client.Get().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
 		Do(ctx).
@@ -93,8 +93,8 @@ func (c *virtualServices) List(ctx context.Context, opts v1.ListOptions) (result
 	return
 }
 
-// Watch returns a watch.Interface that watches the requested virtualServices.
-func (c *virtualServices) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+// Watch returns a watch.Interface that watches the requested canaryApprovals.
+func (c *canaryApprovals) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -102,44 +102,44 @@ func (c *virtualServices) Watch(ctx context.Context, opts v1.ListOptions) (watch
 	opts.Watch = true
 	return c.client.Get().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
 		Watch(ctx)
 }
 
-// Create takes the representation of a virtualService and creates it.  Returns the server's representation of the virtualService, and an error, if there is any.
-func (c *virtualServices) Create(ctx context.Context, virtualService *v1alpha3.VirtualService, opts v1.CreateOptions) (result *v1alpha3.VirtualService, err error) {
-	result = &v1alpha3.VirtualService{}
+// Create takes the representation of a canaryApproval and creates it.  Returns the server's representation of the canaryApproval, and an error, if there is any.
+func (c *canaryApprovals) Create(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.CreateOptions) (result *v1beta1.CanaryApproval, err error) {
+	result = &v1beta1.CanaryApproval{}
 	err = c.client.Post().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		VersionedParams(&opts, scheme.ParameterCodec).
-		Body(virtualService).
+		Body(canaryApproval).
 		Do(ctx).
 		Into(result)
 	return
 }
 
-// Update takes the representation of a virtualService and updates it. Returns the server's representation of the virtualService, and an error, if there is any.
-func (c *virtualServices) Update(ctx context.Context, virtualService *v1alpha3.VirtualService, opts v1.UpdateOptions) (result *v1alpha3.VirtualService, err error) {
-	result = &v1alpha3.VirtualService{}
+// Update takes the representation of a canaryApproval and updates it. Returns the server's representation of the canaryApproval, and an error, if there is any.
+func (c *canaryApprovals) Update(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.UpdateOptions) (result *v1beta1.CanaryApproval, err error) {
+	result = &v1beta1.CanaryApproval{}
 	err = c.client.Put().
 		Namespace(c.ns).
-		Resource("virtualservices").
-		Name(virtualService.Name).
+		Resource("canaryapprovals").
+		Name(canaryApproval.Name).
 		VersionedParams(&opts, scheme.ParameterCodec).
-		Body(virtualService).
+		Body(canaryApproval).
 		Do(ctx).
 		Into(result)
 	return
 }
 
-// Delete takes name of the virtualService and deletes it. Returns an error if one occurs.
-func (c *virtualServices) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+// Delete takes name of the canaryApproval and deletes it. Returns an error if one occurs.
+func (c *canaryApprovals) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		Name(name).
 		Body(&opts).
 		Do(ctx).
@@ -147,14 +147,14 @@ func (c *virtualServices) Delete(ctx context.Context, name string, opts v1.Delet
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *virtualServices) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+func (c *canaryApprovals) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
 	if listOpts.TimeoutSeconds != nil {
 		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
 		Body(&opts).
@@ -162,12 +162,12 @@ func (c *virtualServices) DeleteCollection(ctx context.Context, opts v1.DeleteOp
 		Error()
 }
 
-// Patch applies the patch and returns the patched virtualService.
-func (c *virtualServices) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha3.VirtualService, err error) {
-	result = &v1alpha3.VirtualService{}
+// Patch applies the patch and returns the patched canaryApproval.
+func (c *canaryApprovals) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryApproval, err error) {
+	result = &v1beta1.CanaryApproval{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
-		Resource("virtualservices").
+		Resource("canaryapprovals").
 		Name(name).
 		SubResource(subresources...).
 		VersionedParams(&opts, scheme.ParameterCodec).
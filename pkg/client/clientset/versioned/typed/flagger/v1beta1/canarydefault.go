@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	scheme "github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// CanaryDefaultsGetter has a method to return a CanaryDefaultInterface.
+// A group's client should implement this interface.
+type CanaryDefaultsGetter interface {
+	CanaryDefaults(namespace string) CanaryDefaultInterface
+}
+
+// CanaryDefaultInterface has methods to work with CanaryDefault resources.
+type CanaryDefaultInterface interface {
+	Create(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.CreateOptions) (*v1beta1.CanaryDefault, error)
+	Update(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.UpdateOptions) (*v1beta1.CanaryDefault, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.CanaryDefault, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.CanaryDefaultList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryDefault, err error)
+	CanaryDefaultExpansion
+}
+
+// canaryDefaults implements CanaryDefaultInterface
+type canaryDefaults struct {
+	client rest.Interface
+	ns     string
+}
+
+// newCanaryDefaults returns a CanaryDefaults
+func newCanaryDefaults(c *FlaggerV1beta1Client, namespace string) *canaryDefaults {
+	return &canaryDefaults{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the canaryDefault, and returns the corresponding canaryDefault object, and an error if there is any.
+func (c *canaryDefaults) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CanaryDefault, err error) {
+	result = &v1beta1.CanaryDefault{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of CanaryDefaults that match those selectors.
+func (c *canaryDefaults) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CanaryDefaultList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.CanaryDefaultList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested canaryDefaults.
+func (c *canaryDefaults) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a canaryDefault and creates it.  Returns the server's representation of the canaryDefault, and an error, if there is any.
+func (c *canaryDefaults) Create(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.CreateOptions) (result *v1beta1.CanaryDefault, err error) {
+	result = &v1beta1.CanaryDefault{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(canaryDefault).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a canaryDefault and updates it. Returns the server's representation of the canaryDefault, and an error, if there is any.
+func (c *canaryDefaults) Update(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.UpdateOptions) (result *v1beta1.CanaryDefault, err error) {
+	result = &v1beta1.CanaryDefault{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		Name(canaryDefault.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(canaryDefault).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the canaryDefault and deletes it. Returns an error if one occurs.
+func (c *canaryDefaults) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *canaryDefaults) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched canaryDefault.
+func (c *canaryDefaults) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryDefault, err error) {
+	result = &v1beta1.CanaryDefault{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("canarydefaults").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeCanaryApprovals implements CanaryApprovalInterface
+type FakeCanaryApprovals struct {
+	Fake *FakeFlaggerV1beta1
+	ns   string
+}
+
+var canaryapprovalsResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaryapprovals"}
+
+var canaryapprovalsKind = schema.GroupVersionKind{Group: "flagger.app", Version: "v1beta1", Kind: "CanaryApproval"}
+
+// Get takes name of the canaryApproval, and returns the corresponding canaryApproval object, and an error if there is any.
+func (c *FakeCanaryApprovals) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CanaryApproval, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(canaryapprovalsResource, c.ns, name), &v1beta1.CanaryApproval{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryApproval), err
+}
+
+// List takes label and field selectors, and returns the list of CanaryApprovals that match those selectors.
+func (c *FakeCanaryApprovals) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CanaryApprovalList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(canaryapprovalsResource, canaryapprovalsKind, c.ns, opts), &v1beta1.CanaryApprovalList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.CanaryApprovalList{ListMeta: obj.(*v1beta1.CanaryApprovalList).ListMeta}
+	for _, item := range obj.(*v1beta1.CanaryApprovalList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested canaryApprovals.
+func (c *FakeCanaryApprovals) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(canaryapprovalsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a canaryApproval and creates it.  Returns the server's representation of the canaryApproval, and an error, if there is any.
+func (c *FakeCanaryApprovals) Create(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.CreateOptions) (result *v1beta1.CanaryApproval, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(canaryapprovalsResource, c.ns, canaryApproval), &v1beta1.CanaryApproval{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryApproval), err
+}
+
+// Update takes the representation of a canaryApproval and updates it. Returns the server's representation of the canaryApproval, and an error, if there is any.
+func (c *FakeCanaryApprovals) Update(ctx context.Context, canaryApproval *v1beta1.CanaryApproval, opts v1.UpdateOptions) (result *v1beta1.CanaryApproval, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(canaryapprovalsResource, c.ns, canaryApproval), &v1beta1.CanaryApproval{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryApproval), err
+}
+
+// Delete takes name of the canaryApproval and deletes it. Returns an error if one occurs.
+func (c *FakeCanaryApprovals) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(canaryapprovalsResource, c.ns, name, opts), &v1beta1.CanaryApproval{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeCanaryApprovals) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(canaryapprovalsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.CanaryApprovalList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched canaryApproval.
+func (c *FakeCanaryApprovals) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryApproval, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(canaryapprovalsResource, c.ns, name, pt, data, subresources...), &v1beta1.CanaryApproval{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryApproval), err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeCanaryDefaults implements CanaryDefaultInterface
+type FakeCanaryDefaults struct {
+	Fake *FakeFlaggerV1beta1
+	ns   string
+}
+
+var canarydefaultsResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canarydefaults"}
+
+var canarydefaultsKind = schema.GroupVersionKind{Group: "flagger.app", Version: "v1beta1", Kind: "CanaryDefault"}
+
+// Get takes name of the canaryDefault, and returns the corresponding canaryDefault object, and an error if there is any.
+func (c *FakeCanaryDefaults) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.CanaryDefault, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(canarydefaultsResource, c.ns, name), &v1beta1.CanaryDefault{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryDefault), err
+}
+
+// List takes label and field selectors, and returns the list of CanaryDefaults that match those selectors.
+func (c *FakeCanaryDefaults) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.CanaryDefaultList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(canarydefaultsResource, canarydefaultsKind, c.ns, opts), &v1beta1.CanaryDefaultList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.CanaryDefaultList{ListMeta: obj.(*v1beta1.CanaryDefaultList).ListMeta}
+	for _, item := range obj.(*v1beta1.CanaryDefaultList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested canaryDefaults.
+func (c *FakeCanaryDefaults) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(canarydefaultsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a canaryDefault and creates it.  Returns the server's representation of the canaryDefault, and an error, if there is any.
+func (c *FakeCanaryDefaults) Create(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.CreateOptions) (result *v1beta1.CanaryDefault, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(canarydefaultsResource, c.ns, canaryDefault), &v1beta1.CanaryDefault{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryDefault), err
+}
+
+// Update takes the representation of a canaryDefault and updates it. Returns the server's representation of the canaryDefault, and an error, if there is any.
+func (c *FakeCanaryDefaults) Update(ctx context.Context, canaryDefault *v1beta1.CanaryDefault, opts v1.UpdateOptions) (result *v1beta1.CanaryDefault, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(canarydefaultsResource, c.ns, canaryDefault), &v1beta1.CanaryDefault{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryDefault), err
+}
+
+// Delete takes name of the canaryDefault and deletes it. Returns an error if one occurs.
+func (c *FakeCanaryDefaults) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(canarydefaultsResource, c.ns, name, opts), &v1beta1.CanaryDefault{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeCanaryDefaults) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(canarydefaultsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.CanaryDefaultList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched canaryDefault.
+func (c *FakeCanaryDefaults) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.CanaryDefault, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(canarydefaultsResource, c.ns, name, pt, data, subresources...), &v1beta1.CanaryDefault{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.CanaryDefault), err
+}
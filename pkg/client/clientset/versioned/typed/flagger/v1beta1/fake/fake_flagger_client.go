@@ -36,6 +36,14 @@ func (c *FakeFlaggerV1beta1) Canaries(namespace string) v1beta1.CanaryInterface
 	return &FakeCanaries{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) CanaryApprovals(namespace string) v1beta1.CanaryApprovalInterface {
+	return &FakeCanaryApprovals{c, namespace}
+}
+
+func (c *FakeFlaggerV1beta1) CanaryDefaults(namespace string) v1beta1.CanaryDefaultInterface {
+	return &FakeCanaryDefaults{c, namespace}
+}
+
 func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTemplateInterface {
 	return &FakeMetricTemplates{c, namespace}
 }
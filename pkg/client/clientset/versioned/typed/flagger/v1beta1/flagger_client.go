@@ -30,6 +30,8 @@ type FlaggerV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AlertProvidersGetter
 	CanariesGetter
+	CanaryApprovalsGetter
+	CanaryDefaultsGetter
 	MetricTemplatesGetter
 }
 
@@ -46,6 +48,14 @@ func (c *FlaggerV1beta1Client) Canaries(namespace string) CanaryInterface {
 	return newCanaries(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) CanaryApprovals(namespace string) CanaryApprovalInterface {
+	return newCanaryApprovals(c, namespace)
+}
+
+func (c *FlaggerV1beta1Client) CanaryDefaults(namespace string) CanaryDefaultInterface {
+	return newCanaryDefaults(c, namespace)
+}
+
 func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateInterface {
 	return newMetricTemplates(c, namespace)
 }
@@ -22,4 +22,8 @@ type AlertProviderExpansion interface{}
 
 type CanaryExpansion interface{}
 
+type CanaryApprovalExpansion interface{}
+
+type CanaryDefaultExpansion interface{}
+
 type MetricTemplateExpansion interface{}
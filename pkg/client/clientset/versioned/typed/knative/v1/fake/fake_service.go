@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeServices implements ServiceInterface
+type FakeServices struct {
+	Fake *FakeKnativeV1
+	ns   string
+}
+
+var servicesResource = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+
+var servicesKind = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+
+// Get takes name of the service, and returns the corresponding service object, and an error if there is any.
+func (c *FakeServices) Get(ctx context.Context, name string, options v1.GetOptions) (result *knativev1.Service, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(servicesResource, c.ns, name), &knativev1.Service{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*knativev1.Service), err
+}
+
+// List takes label and field selectors, and returns the list of Services that match those selectors.
+func (c *FakeServices) List(ctx context.Context, opts v1.ListOptions) (result *knativev1.ServiceList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(servicesResource, servicesKind, c.ns, opts), &knativev1.ServiceList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &knativev1.ServiceList{ListMeta: obj.(*knativev1.ServiceList).ListMeta}
+	for _, item := range obj.(*knativev1.ServiceList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested services.
+func (c *FakeServices) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(servicesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a service and creates it.  Returns the server's representation of the service, and an error, if there is any.
+func (c *FakeServices) Create(ctx context.Context, service *knativev1.Service, opts v1.CreateOptions) (result *knativev1.Service, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(servicesResource, c.ns, service), &knativev1.Service{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*knativev1.Service), err
+}
+
+// Update takes the representation of a service and updates it. Returns the server's representation of the service, and an error, if there is any.
+func (c *FakeServices) Update(ctx context.Context, service *knativev1.Service, opts v1.UpdateOptions) (result *knativev1.Service, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(servicesResource, c.ns, service), &knativev1.Service{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*knativev1.Service), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeServices) UpdateStatus(ctx context.Context, service *knativev1.Service, opts v1.UpdateOptions) (*knativev1.Service, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(servicesResource, "status", c.ns, service), &knativev1.Service{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*knativev1.Service), err
+}
+
+// Delete takes name of the service and deletes it. Returns an error if one occurs.
+func (c *FakeServices) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(servicesResource, c.ns, name, opts), &knativev1.Service{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeServices) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(servicesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &knativev1.ServiceList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched service.
+func (c *FakeServices) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *knativev1.Service, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(servicesResource, c.ns, name, pt, data, subresources...), &knativev1.Service{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*knativev1.Service), err
+}
@@ -16,75 +16,75 @@ limitations under the License.
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha3
+package v3alpha1
 
 import (
 	"context"
 	time "time"
 
-	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	emissaryv3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
 	versioned "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
-	v1alpha3 "github.com/fluxcd/flagger/pkg/client/listers/istio/v1alpha3"
+	v3alpha1 "github.com/fluxcd/flagger/pkg/client/listers/emissary/v3alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
 	cache "k8s.io/client-go/tools/cache"
 )
 
-// DestinationRuleInformer provides access to a shared informer and lister for
-// DestinationRules.
-type DestinationRuleInformer interface {
+// MappingInformer provides access to a shared informer and lister for
+// Mappings.
+type MappingInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha3.DestinationRuleLister
+	Lister() v3alpha1.MappingLister
 }
 
-type destinationRuleInformer struct {
+type mappingInformer struct {
 	factory          internalinterfaces.SharedInformerFactory
 	tweakListOptions internalinterfaces.TweakListOptionsFunc
 	namespace        string
 }
 
-// NewDestinationRuleInformer constructs a new informer for DestinationRule type.
+// NewMappingInformer constructs a new informer for Mapping type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewDestinationRuleInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
-	return NewFilteredDestinationRuleInformer(client, namespace, resyncPeriod, indexers, nil)
+func NewMappingInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredMappingInformer(client, namespace, resyncPeriod, indexers, nil)
 }
 
-// NewFilteredDestinationRuleInformer constructs a new informer for DestinationRule type.
+// NewFilteredMappingInformer constructs a new informer for Mapping type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewFilteredDestinationRuleInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+func NewFilteredMappingInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
 	return cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.NetworkingV1alpha3().DestinationRules(namespace).List(context.TODO(), options)
+				return client.EmissaryV3alpha1().Mappings(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.NetworkingV1alpha3().DestinationRules(namespace).Watch(context.TODO(), options)
+				return client.EmissaryV3alpha1().Mappings(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&istiov1alpha3.DestinationRule{},
+		&emissaryv3alpha1.Mapping{},
 		resyncPeriod,
 		indexers,
 	)
 }
 
-func (f *destinationRuleInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredDestinationRuleInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+func (f *mappingInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredMappingInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
 }
 
-func (f *destinationRuleInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&istiov1alpha3.DestinationRule{}, f.defaultInformer)
+func (f *mappingInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&emissaryv3alpha1.Mapping{}, f.defaultInformer)
 }
 
-func (f *destinationRuleInformer) Lister() v1alpha3.DestinationRuleLister {
-	return v1alpha3.NewDestinationRuleLister(f.Informer().GetIndexer())
+func (f *mappingInformer) Lister() v3alpha1.MappingLister {
+	return v3alpha1.NewMappingLister(f.Informer().GetIndexer())
 }
@@ -26,14 +26,18 @@ import (
 	versioned "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	apisix "github.com/fluxcd/flagger/pkg/client/informers/externalversions/apisix"
 	appmesh "github.com/fluxcd/flagger/pkg/client/informers/externalversions/appmesh"
+	emissary "github.com/fluxcd/flagger/pkg/client/informers/externalversions/emissary"
+	externaldns "github.com/fluxcd/flagger/pkg/client/informers/externalversions/externaldns"
 	flagger "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger"
 	gateway "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gateway"
 	gatewayapi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gatewayapi"
 	gloo "github.com/fluxcd/flagger/pkg/client/informers/externalversions/gloo"
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
-	istio "github.com/fluxcd/flagger/pkg/client/informers/externalversions/istio"
 	keda "github.com/fluxcd/flagger/pkg/client/informers/externalversions/keda"
+	knative "github.com/fluxcd/flagger/pkg/client/informers/externalversions/knative"
 	kuma "github.com/fluxcd/flagger/pkg/client/informers/externalversions/kuma"
+	monitoring "github.com/fluxcd/flagger/pkg/client/informers/externalversions/monitoring"
+	openshift "github.com/fluxcd/flagger/pkg/client/informers/externalversions/openshift"
 	projectcontour "github.com/fluxcd/flagger/pkg/client/informers/externalversions/projectcontour"
 	smi "github.com/fluxcd/flagger/pkg/client/informers/externalversions/smi"
 	traefik "github.com/fluxcd/flagger/pkg/client/informers/externalversions/traefik"
@@ -256,13 +260,17 @@ type SharedInformerFactory interface {
 
 	Apisix() apisix.Interface
 	Appmesh() appmesh.Interface
+	Emissary() emissary.Interface
+	ExternalDNS() externaldns.Interface
 	Flagger() flagger.Interface
 	Gateway() gateway.Interface
 	Gatewayapi() gatewayapi.Interface
 	Gloo() gloo.Interface
-	Networking() istio.Interface
 	Keda() keda.Interface
+	Knative() knative.Interface
 	Kuma() kuma.Interface
+	Monitoring() monitoring.Interface
+	OpenShiftRoute() openshift.Interface
 	Projectcontour() projectcontour.Interface
 	Split() smi.Interface
 	Traefik() traefik.Interface
@@ -276,6 +284,14 @@ func (f *sharedInformerFactory) Appmesh() appmesh.Interface {
 	return appmesh.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Emissary() emissary.Interface {
+	return emissary.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) ExternalDNS() externaldns.Interface {
+	return externaldns.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Flagger() flagger.Interface {
 	return flagger.New(f, f.namespace, f.tweakListOptions)
 }
@@ -292,18 +308,26 @@ func (f *sharedInformerFactory) Gloo() gloo.Interface {
 	return gloo.New(f, f.namespace, f.tweakListOptions)
 }
 
-func (f *sharedInformerFactory) Networking() istio.Interface {
-	return istio.New(f, f.namespace, f.tweakListOptions)
-}
-
 func (f *sharedInformerFactory) Keda() keda.Interface {
 	return keda.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Knative() knative.Interface {
+	return knative.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Kuma() kuma.Interface {
 	return kuma.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) OpenShiftRoute() openshift.Interface {
+	return openshift.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Projectcontour() projectcontour.Interface {
 	return projectcontour.New(f, f.namespace, f.tweakListOptions)
 }
@@ -16,75 +16,75 @@ limitations under the License.
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha3
+package v1beta1
 
 import (
 	"context"
 	time "time"
 
-	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
+	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	versioned "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
-	v1alpha3 "github.com/fluxcd/flagger/pkg/client/listers/istio/v1alpha3"
+	v1beta1 "github.com/fluxcd/flagger/pkg/client/listers/flagger/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	watch "k8s.io/apimachinery/pkg/watch"
 	cache "k8s.io/client-go/tools/cache"
 )
 
-// VirtualServiceInformer provides access to a shared informer and lister for
-// VirtualServices.
-type VirtualServiceInformer interface {
+// CanaryApprovalInformer provides access to a shared informer and lister for
+// CanaryApprovals.
+type CanaryApprovalInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha3.VirtualServiceLister
+	Lister() v1beta1.CanaryApprovalLister
 }
 
-type virtualServiceInformer struct {
+type canaryApprovalInformer struct {
 	factory          internalinterfaces.SharedInformerFactory
 	tweakListOptions internalinterfaces.TweakListOptionsFunc
 	namespace        string
 }
 
-// NewVirtualServiceInformer constructs a new informer for VirtualService type.
+// NewCanaryApprovalInformer constructs a new informer for CanaryApproval type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewVirtualServiceInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
-	return NewFilteredVirtualServiceInformer(client, namespace, resyncPeriod, indexers, nil)
+func NewCanaryApprovalInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredCanaryApprovalInformer(client, namespace, resyncPeriod, indexers, nil)
 }
 
-// NewFilteredVirtualServiceInformer constructs a new informer for VirtualService type.
+// NewFilteredCanaryApprovalInformer constructs a new informer for CanaryApproval type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewFilteredVirtualServiceInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+func NewFilteredCanaryApprovalInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
 	return cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.NetworkingV1alpha3().VirtualServices(namespace).List(context.TODO(), options)
+				return client.FlaggerV1beta1().CanaryApprovals(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.NetworkingV1alpha3().VirtualServices(namespace).Watch(context.TODO(), options)
+				return client.FlaggerV1beta1().CanaryApprovals(namespace).Watch(context.TODO(), options)
 			},
 		},
-		&istiov1alpha3.VirtualService{},
+		&flaggerv1beta1.CanaryApproval{},
 		resyncPeriod,
 		indexers,
 	)
 }
 
-func (f *virtualServiceInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredVirtualServiceInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+func (f *canaryApprovalInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredCanaryApprovalInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
 }
 
-func (f *virtualServiceInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&istiov1alpha3.VirtualService{}, f.defaultInformer)
+func (f *canaryApprovalInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&flaggerv1beta1.CanaryApproval{}, f.defaultInformer)
 }
 
-func (f *virtualServiceInformer) Lister() v1alpha3.VirtualServiceLister {
-	return v1alpha3.NewVirtualServiceLister(f.Informer().GetIndexer())
+func (f *canaryApprovalInformer) Lister() v1beta1.CanaryApprovalLister {
+	return v1beta1.NewCanaryApprovalLister(f.Informer().GetIndexer())
 }
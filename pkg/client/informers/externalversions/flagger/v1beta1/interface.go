@@ -28,6 +28,10 @@ type Interface interface {
 	AlertProviders() AlertProviderInformer
 	// Canaries returns a CanaryInformer.
 	Canaries() CanaryInformer
+	// CanaryApprovals returns a CanaryApprovalInformer.
+	CanaryApprovals() CanaryApprovalInformer
+	// CanaryDefaults returns a CanaryDefaultInformer.
+	CanaryDefaults() CanaryDefaultInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
 }
@@ -53,6 +57,16 @@ func (v *version) Canaries() CanaryInformer {
 	return &canaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CanaryApprovals returns a CanaryApprovalInformer.
+func (v *version) CanaryApprovals() CanaryApprovalInformer {
+	return &canaryApprovalInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// CanaryDefaults returns a CanaryDefaultInformer.
+func (v *version) CanaryDefaults() CanaryDefaultInformer {
+	return &canaryDefaultInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // MetricTemplates returns a MetricTemplateInformer.
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -24,14 +24,18 @@ import (
 	v2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
 	v1beta1 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta1"
 	v1beta2 "github.com/fluxcd/flagger/pkg/apis/appmesh/v1beta2"
+	v3alpha1 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
+	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/externaldns/v1alpha1"
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	v1alpha2 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1alpha2"
 	gatewayapiv1beta1 "github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
 	v1 "github.com/fluxcd/flagger/pkg/apis/gloo/gateway/v1"
 	gloov1 "github.com/fluxcd/flagger/pkg/apis/gloo/gloo/v1"
-	v1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
-	v1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	kedav1alpha1 "github.com/fluxcd/flagger/pkg/apis/keda/v1alpha1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
 	kumav1alpha1 "github.com/fluxcd/flagger/pkg/apis/kuma/v1alpha1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
+	openshiftv1 "github.com/fluxcd/flagger/pkg/apis/openshift/v1"
 	projectcontourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
 	smiv1alpha1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
 	smiv1alpha2 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha2"
@@ -87,11 +91,19 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta2.SchemeGroupVersion.WithResource("virtualservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Appmesh().V1beta2().VirtualServices().Informer()}, nil
 
+		// Group=externaldns.k8s.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("dnsendpoints"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.ExternalDNS().V1alpha1().DNSEndpoints().Informer()}, nil
+
 		// Group=flagger.app, Version=v1beta1
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("alertproviders"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().AlertProviders().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().Canaries().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canaryapprovals"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryApprovals().Informer()}, nil
+	case flaggerv1beta1.SchemeGroupVersion.WithResource("canarydefaults"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().CanaryDefaults().Informer()}, nil
 	case flaggerv1beta1.SchemeGroupVersion.WithResource("metrictemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Flagger().V1beta1().MetricTemplates().Informer()}, nil
 
@@ -107,28 +119,38 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case gatewayapiv1beta1.SchemeGroupVersion.WithResource("httproutes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gatewayapi().V1beta1().HTTPRoutes().Informer()}, nil
 
+		// Group=getambassador.io, Version=v3alpha1
+	case v3alpha1.SchemeGroupVersion.WithResource("mappings"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Emissary().V3alpha1().Mappings().Informer()}, nil
+
 		// Group=gloo.solo.io, Version=v1
 	case gloov1.SchemeGroupVersion.WithResource("upstreams"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Gloo().V1().Upstreams().Informer()}, nil
 
 		// Group=keda.sh, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
+	case kedav1alpha1.SchemeGroupVersion.WithResource("scaledobjects"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
 
 		// Group=kuma.io, Version=v1alpha1
 	case kumav1alpha1.SchemeGroupVersion.WithResource("trafficroutes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kuma().V1alpha1().TrafficRoutes().Informer()}, nil
 
-		// Group=networking.istio.io, Version=v1alpha3
-	case v1alpha3.SchemeGroupVersion.WithResource("destinationrules"):
-		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().DestinationRules().Informer()}, nil
-	case v1alpha3.SchemeGroupVersion.WithResource("virtualservices"):
-		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha3().VirtualServices().Informer()}, nil
+		// Group=monitoring.coreos.com, Version=v1
+	case monitoringv1.SchemeGroupVersion.WithResource("prometheusrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1().PrometheusRules().Informer()}, nil
 
 		// Group=projectcontour.io, Version=v1
 	case projectcontourv1.SchemeGroupVersion.WithResource("httpproxies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Projectcontour().V1().HTTPProxies().Informer()}, nil
 
+		// Group=route.openshift.io, Version=v1
+	case openshiftv1.SchemeGroupVersion.WithResource("routes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.OpenShiftRoute().V1().Routes().Informer()}, nil
+
+		// Group=serving.knative.dev, Version=v1
+	case knativev1.SchemeGroupVersion.WithResource("services"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Knative().V1().Services().Informer()}, nil
+
 		// Group=split.smi-spec.io, Version=v1alpha1
 	case smiv1alpha1.SchemeGroupVersion.WithResource("trafficsplits"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha1().TrafficSplits().Informer()}, nil
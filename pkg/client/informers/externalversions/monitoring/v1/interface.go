@@ -16,7 +16,7 @@ limitations under the License.
 
 // Code generated by informer-gen. DO NOT EDIT.
 
-package v1alpha3
+package v1
 
 import (
 	internalinterfaces "github.com/fluxcd/flagger/pkg/client/informers/externalversions/internalinterfaces"
@@ -24,10 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
-	// DestinationRules returns a DestinationRuleInformer.
-	DestinationRules() DestinationRuleInformer
-	// VirtualServices returns a VirtualServiceInformer.
-	VirtualServices() VirtualServiceInformer
+	// PrometheusRules returns a PrometheusRuleInformer.
+	PrometheusRules() PrometheusRuleInformer
 }
 
 type version struct {
@@ -41,12 +39,7 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
-// DestinationRules returns a DestinationRuleInformer.
-func (v *version) DestinationRules() DestinationRuleInformer {
-	return &destinationRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
-}
-
-// VirtualServices returns a VirtualServiceInformer.
-func (v *version) VirtualServices() VirtualServiceInformer {
-	return &virtualServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+// PrometheusRules returns a PrometheusRuleInformer.
+func (v *version) PrometheusRules() PrometheusRuleInformer {
+	return &prometheusRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
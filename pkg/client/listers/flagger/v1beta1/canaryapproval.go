@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CanaryApprovalLister helps list CanaryApprovals.
+// All objects returned here must be treated as read-only.
+type CanaryApprovalLister interface {
+	// List lists all CanaryApprovals in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.CanaryApproval, err error)
+	// CanaryApprovals returns an object that can list and get CanaryApprovals.
+	CanaryApprovals(namespace string) CanaryApprovalNamespaceLister
+	CanaryApprovalListerExpansion
+}
+
+// canaryApprovalLister implements the CanaryApprovalLister interface.
+type canaryApprovalLister struct {
+	indexer cache.Indexer
+}
+
+// NewCanaryApprovalLister returns a new CanaryApprovalLister.
+func NewCanaryApprovalLister(indexer cache.Indexer) CanaryApprovalLister {
+	return &canaryApprovalLister{indexer: indexer}
+}
+
+// List lists all CanaryApprovals in the indexer.
+func (s *canaryApprovalLister) List(selector labels.Selector) (ret []*v1beta1.CanaryApproval, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.CanaryApproval))
+	})
+	return ret, err
+}
+
+// CanaryApprovals returns an object that can list and get CanaryApprovals.
+func (s *canaryApprovalLister) CanaryApprovals(namespace string) CanaryApprovalNamespaceLister {
+	return canaryApprovalNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// CanaryApprovalNamespaceLister helps list and get CanaryApprovals.
+// All objects returned here must be treated as read-only.
+type CanaryApprovalNamespaceLister interface {
+	// List lists all CanaryApprovals in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.CanaryApproval, err error)
+	// Get retrieves the CanaryApproval from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.CanaryApproval, error)
+	CanaryApprovalNamespaceListerExpansion
+}
+
+// canaryApprovalNamespaceLister implements the CanaryApprovalNamespaceLister
+// interface.
+type canaryApprovalNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all CanaryApprovals in the indexer for a given namespace.
+func (s canaryApprovalNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.CanaryApproval, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.CanaryApproval))
+	})
+	return ret, err
+}
+
+// Get retrieves the CanaryApproval from the indexer for a given namespace and name.
+func (s canaryApprovalNamespaceLister) Get(name string) (*v1beta1.CanaryApproval, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("canaryapproval"), name)
+	}
+	return obj.(*v1beta1.CanaryApproval), nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CanaryDefaultLister helps list CanaryDefaults.
+// All objects returned here must be treated as read-only.
+type CanaryDefaultLister interface {
+	// List lists all CanaryDefaults in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.CanaryDefault, err error)
+	// CanaryDefaults returns an object that can list and get CanaryDefaults.
+	CanaryDefaults(namespace string) CanaryDefaultNamespaceLister
+	CanaryDefaultListerExpansion
+}
+
+// canaryDefaultLister implements the CanaryDefaultLister interface.
+type canaryDefaultLister struct {
+	indexer cache.Indexer
+}
+
+// NewCanaryDefaultLister returns a new CanaryDefaultLister.
+func NewCanaryDefaultLister(indexer cache.Indexer) CanaryDefaultLister {
+	return &canaryDefaultLister{indexer: indexer}
+}
+
+// List lists all CanaryDefaults in the indexer.
+func (s *canaryDefaultLister) List(selector labels.Selector) (ret []*v1beta1.CanaryDefault, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.CanaryDefault))
+	})
+	return ret, err
+}
+
+// CanaryDefaults returns an object that can list and get CanaryDefaults.
+func (s *canaryDefaultLister) CanaryDefaults(namespace string) CanaryDefaultNamespaceLister {
+	return canaryDefaultNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// CanaryDefaultNamespaceLister helps list and get CanaryDefaults.
+// All objects returned here must be treated as read-only.
+type CanaryDefaultNamespaceLister interface {
+	// List lists all CanaryDefaults in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.CanaryDefault, err error)
+	// Get retrieves the CanaryDefault from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.CanaryDefault, error)
+	CanaryDefaultNamespaceListerExpansion
+}
+
+// canaryDefaultNamespaceLister implements the CanaryDefaultNamespaceLister
+// interface.
+type canaryDefaultNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all CanaryDefaults in the indexer for a given namespace.
+func (s canaryDefaultNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.CanaryDefault, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.CanaryDefault))
+	})
+	return ret, err
+}
+
+// Get retrieves the CanaryDefault from the indexer for a given namespace and name.
+func (s canaryDefaultNamespaceLister) Get(name string) (*v1beta1.CanaryDefault, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("canarydefault"), name)
+	}
+	return obj.(*v1beta1.CanaryDefault), nil
+}
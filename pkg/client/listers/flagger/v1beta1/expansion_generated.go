@@ -34,6 +34,22 @@ type CanaryListerExpansion interface{}
 // CanaryNamespaceLister.
 type CanaryNamespaceListerExpansion interface{}
 
+// CanaryApprovalListerExpansion allows custom methods to be added to
+// CanaryApprovalLister.
+type CanaryApprovalListerExpansion interface{}
+
+// CanaryApprovalNamespaceListerExpansion allows custom methods to be added to
+// CanaryApprovalNamespaceLister.
+type CanaryApprovalNamespaceListerExpansion interface{}
+
+// CanaryDefaultListerExpansion allows custom methods to be added to
+// CanaryDefaultLister.
+type CanaryDefaultListerExpansion interface{}
+
+// CanaryDefaultNamespaceListerExpansion allows custom methods to be added to
+// CanaryDefaultNamespaceLister.
+type CanaryDefaultNamespaceListerExpansion interface{}
+
 // MetricTemplateListerExpansion allows custom methods to be added to
 // MetricTemplateLister.
 type MetricTemplateListerExpansion interface{}
@@ -16,20 +16,12 @@ limitations under the License.
 
 // Code generated by lister-gen. DO NOT EDIT.
 
-package v1alpha3
+package v1
 
-// DestinationRuleListerExpansion allows custom methods to be added to
-// DestinationRuleLister.
-type DestinationRuleListerExpansion interface{}
+// PrometheusRuleListerExpansion allows custom methods to be added to
+// PrometheusRuleLister.
+type PrometheusRuleListerExpansion interface{}
 
-// DestinationRuleNamespaceListerExpansion allows custom methods to be added to
-// DestinationRuleNamespaceLister.
-type DestinationRuleNamespaceListerExpansion interface{}
-
-// VirtualServiceListerExpansion allows custom methods to be added to
-// VirtualServiceLister.
-type VirtualServiceListerExpansion interface{}
-
-// VirtualServiceNamespaceListerExpansion allows custom methods to be added to
-// VirtualServiceNamespaceLister.
-type VirtualServiceNamespaceListerExpansion interface{}
+// PrometheusRuleNamespaceListerExpansion allows custom methods to be added to
+// PrometheusRuleNamespaceLister.
+type PrometheusRuleNamespaceListerExpansion interface{}
@@ -18,6 +18,7 @@ package controller
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
@@ -35,10 +37,12 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	"github.com/fluxcd/flagger/pkg/canary"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	flaggerscheme "github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
 	flaggerinformers "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/incident"
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
 	"github.com/fluxcd/flagger/pkg/notifier"
@@ -49,31 +53,49 @@ const controllerAgentName = "flagger"
 
 // Controller is managing the canary objects and schedules canary deployments
 type Controller struct {
-	kubeClient           kubernetes.Interface
-	flaggerClient        clientset.Interface
-	flaggerInformers     Informers
-	flaggerSynced        cache.InformerSynced
-	flaggerWindow        time.Duration
-	workqueue            workqueue.RateLimitingInterface
-	eventRecorder        record.EventRecorder
-	logger               *zap.SugaredLogger
-	canaries             *sync.Map
-	jobs                 map[string]CanaryJob
-	recorder             metrics.Recorder
-	notifier             notifier.Interface
-	canaryFactory        *canary.Factory
-	routerFactory        *router.Factory
-	observerFactory      *observers.Factory
-	meshProvider         string
-	eventWebhook         string
-	clusterName          string
-	noCrossNamespaceRefs bool
+	kubeClient            kubernetes.Interface
+	flaggerClient         clientset.Interface
+	flaggerInformers      Informers
+	flaggerSynced         cache.InformerSynced
+	flaggerWindow         time.Duration
+	flaggerWindowCh       chan time.Duration
+	workqueue             workqueue.RateLimitingInterface
+	eventRecorder         record.EventRecorder
+	logger                *zap.SugaredLogger
+	canaries              *sync.Map
+	jobs                  map[string]CanaryJob
+	recorder              metrics.Recorder
+	notifier              notifier.Interface
+	canaryFactory         *canary.Factory
+	routerFactory         *router.Factory
+	observerFactory       *observers.Factory
+	meshProvider          string
+	eventWebhook          string
+	clusterName           string
+	noCrossNamespaceRefs  bool
+	namespacedRBAC        bool
+	maxConcurrentCanaries int
+	incidents             *incident.Watcher
+	ringClusters          *sync.Map
+	settingsMu            sync.RWMutex
+	reports               sync.Map
+	metricValues          sync.Map
+	checkStreaks          sync.Map
+	routeWarnings         sync.Map
+	frozen                sync.Map
+	quotaMu               sync.Mutex
+	quotaReservations     sync.Map
+	namespaces            sync.Map
 }
 
+// Informers holds the Flagger informers, the ApprovalInformer and DefaultInformer
+// are nil when the CanaryApproval and CanaryDefault CRDs are not registered
 type Informers struct {
-	CanaryInformer flaggerinformers.CanaryInformer
-	MetricInformer flaggerinformers.MetricTemplateInformer
-	AlertInformer  flaggerinformers.AlertProviderInformer
+	CanaryInformer   flaggerinformers.CanaryInformer
+	MetricInformer   flaggerinformers.MetricTemplateInformer
+	AlertInformer    flaggerinformers.AlertProviderInformer
+	ApprovalInformer flaggerinformers.CanaryApprovalInformer
+	DefaultInformer  flaggerinformers.CanaryDefaultInformer
 }
 
 func NewController(
@@ -90,7 +112,11 @@ func NewController(
 	version string,
 	eventWebhook string,
 	clusterName string,
+	extendedMetricLabels bool,
 	noCrossNamespaceRefs bool,
+	namespacedRBAC bool,
+	maxConcurrentCanaries int,
+	incidents *incident.Watcher,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
 	flaggerscheme.AddToScheme(scheme.Scheme)
@@ -101,29 +127,33 @@ func NewController(
 	})
 	eventRecorder := eventBroadcaster.NewRecorder(
 		scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
-	recorder := metrics.NewRecorder(controllerAgentName, true)
+	recorder := metrics.NewRecorder(controllerAgentName, true, extendedMetricLabels).WithCluster(clusterName)
 	recorder.SetInfo(version, meshProvider)
 
 	ctrl := &Controller{
-		kubeClient:           kubeClient,
-		flaggerClient:        flaggerClient,
-		flaggerInformers:     flaggerInformers,
-		flaggerSynced:        flaggerInformers.CanaryInformer.Informer().HasSynced,
-		workqueue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
-		eventRecorder:        eventRecorder,
-		logger:               logger,
-		canaries:             new(sync.Map),
-		jobs:                 map[string]CanaryJob{},
-		flaggerWindow:        flaggerWindow,
-		observerFactory:      observerFactory,
-		recorder:             recorder,
-		notifier:             notifier,
-		canaryFactory:        canaryFactory,
-		routerFactory:        routerFactory,
-		meshProvider:         meshProvider,
-		eventWebhook:         eventWebhook,
-		clusterName:          clusterName,
-		noCrossNamespaceRefs: noCrossNamespaceRefs,
+		kubeClient:            kubeClient,
+		flaggerClient:         flaggerClient,
+		flaggerInformers:      flaggerInformers,
+		flaggerSynced:         flaggerInformers.CanaryInformer.Informer().HasSynced,
+		workqueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
+		eventRecorder:         eventRecorder,
+		logger:                logger,
+		canaries:              new(sync.Map),
+		jobs:                  map[string]CanaryJob{},
+		flaggerWindow:         flaggerWindow,
+		flaggerWindowCh:       make(chan time.Duration, 1),
+		observerFactory:       observerFactory,
+		recorder:              recorder,
+		notifier:              notifier,
+		canaryFactory:         canaryFactory,
+		routerFactory:         routerFactory,
+		meshProvider:          meshProvider,
+		eventWebhook:          eventWebhook,
+		clusterName:           clusterName,
+		noCrossNamespaceRefs:  noCrossNamespaceRefs,
+		namespacedRBAC:        namespacedRBAC,
+		maxConcurrentCanaries: maxConcurrentCanaries,
+		incidents:             incidents,
 	}
 
 	flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -150,14 +180,15 @@ func NewController(
 
 				ctrl.enqueue(new)
 			} else if !newCanary.DeletionTimestamp.IsZero() && hasFinalizer(&newCanary) ||
-				!hasFinalizer(&newCanary) && newCanary.Spec.RevertOnDeletion {
+				!hasFinalizer(&newCanary) && ctrl.requiresFinalizer(&newCanary) {
 				// If this was marked for deletion and has finalizers enqueue for finalizing or
 				// if this canary doesn't have finalizers and RevertOnDeletion is true updated speck enqueue
 				ctrl.enqueue(new)
 			}
 
 			// If canary no longer desires reverting, finalizers should be removed
-			if oldCanary.Spec.RevertOnDeletion && !newCanary.Spec.RevertOnDeletion {
+			// unless the remote mesh objects must be cleaned up
+			if oldCanary.Spec.RevertOnDeletion && !ctrl.requiresFinalizer(&newCanary) {
 				ctrl.logger.Infof("%s.%s opting out, deleting finalizers", newCanary.Name, newCanary.Namespace)
 				err := ctrl.removeFinalizer(&newCanary)
 				if err != nil {
@@ -171,6 +202,8 @@ func NewController(
 			if ok {
 				ctrl.logger.Infof("Deleting %s.%s from cache", r.Name, r.Namespace)
 				ctrl.canaries.Delete(fmt.Sprintf("%s.%s", r.Name, r.Namespace))
+				ctrl.pruneCanaryState(r.Name, r.Namespace)
+				ctrl.recorder.DeleteCanary(&r)
 			}
 		},
 	})
@@ -194,11 +227,17 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 
 	c.logger.Info("Started operator workers")
 
-	tickChan := time.NewTicker(c.flaggerWindow).C
+	c.settingsMu.RLock()
+	ticker := time.NewTicker(c.flaggerWindow)
+	c.settingsMu.RUnlock()
+	defer ticker.Stop()
 	for {
 		select {
-		case <-tickChan:
+		case <-ticker.C:
 			c.scheduleCanaries()
+		case interval := <-c.flaggerWindowCh:
+			c.logger.Infof("Control loop interval set to %s", interval)
+			ticker.Reset(interval)
 		case <-stopCh:
 			c.logger.Info("Shutting down operator workers")
 			return nil
@@ -254,18 +293,27 @@ func (c *Controller) syncHandler(key string) error {
 	}
 
 	if err := c.verifyCanary(cd); err != nil {
+		c.setSpecValidCondition(cd, err)
 		return fmt.Errorf("invalid canary spec: %s", err)
 	}
+	c.setSpecValidCondition(cd, nil)
 
-	// Finalize if canary has been marked for deletion and revert is desired
-	if cd.Spec.RevertOnDeletion && cd.ObjectMeta.DeletionTimestamp != nil {
+	// Finalize if canary has been marked for deletion and revert or remote cleanup is desired
+	if cd.ObjectMeta.DeletionTimestamp != nil && (cd.Spec.RevertOnDeletion || hasFinalizer(cd)) {
 		// If finalizers have been previously removed proceed
 		if !hasFinalizer(cd) {
 			c.logger.Infof("Canary %s.%s has been finalized", cd.Name, cd.Namespace)
 			return nil
 		}
 
-		if cd.Status.Phase != flaggerv1.CanaryPhaseTerminated {
+		if !cd.Spec.RevertOnDeletion {
+			// the mesh router finalizer also deletes the remote objects when reverting
+			if err := c.finalizeRemoteMesh(cd); err != nil {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+					Errorf("Unable to finalize canary: %v", err)
+				return fmt.Errorf("unable to finalize to canary %s.%s error: %w", cd.Name, cd.Namespace, err)
+			}
+		} else if cd.Status.Phase != flaggerv1.CanaryPhaseTerminated {
 			if err := c.finalize(cd); err != nil {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
 					Errorf("Unable to finalize canary: %v", err)
@@ -280,6 +328,8 @@ func (c *Controller) syncHandler(key string) error {
 			return fmt.Errorf("unable to remove finalizer for canary %s.%s: %w", cd.Name, cd.Namespace, err)
 		}
 
+		c.pruneCanaryState(cd.Name, cd.Namespace)
+
 		// record event
 		c.recordEventInfof(cd, "Terminated canary %s.%s", cd.Name, cd.Namespace)
 
@@ -295,10 +345,11 @@ func (c *Controller) syncHandler(key string) error {
 		}
 	}
 
-	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cd)
+	// store the canary with the namespace defaults so that the scheduler uses the inherited interval
+	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), c.applyCanaryDefaults(cd))
 
-	// If opt in for revertOnDeletion add finalizer if not present
-	if cd.Spec.RevertOnDeletion && !hasFinalizer(cd) {
+	// If opt in for revertOnDeletion or the mesh spans remote clusters add finalizer if not present
+	if !hasFinalizer(cd) && c.requiresFinalizer(cd) {
 		if err := c.addFinalizer(cd); err != nil {
 			return fmt.Errorf("unable to add finalizer to canary %s.%s: %w", cd.Name, cd.Namespace, err)
 		}
@@ -319,12 +370,168 @@ func (c *Controller) enqueue(obj interface{}) {
 	c.workqueue.AddRateLimited(key)
 }
 
+// pruneCanaryState removes the in-memory rollout state kept for a canary that has been deleted
+func (c *Controller) pruneCanaryState(name string, namespace string) {
+	key := fmt.Sprintf("%s.%s", name, namespace)
+	c.reports.Delete(key)
+	c.metricValues.Delete(key)
+	c.checkStreaks.Delete(key)
+	c.routeWarnings.Delete(key)
+	c.frozen.Delete(key)
+	c.quotaReservations.Delete(key)
+}
+
 func (c *Controller) verifyCanary(canary *flaggerv1.Canary) error {
 	if c.noCrossNamespaceRefs {
 		if err := verifyNoCrossNamespaceRefs(canary); err != nil {
 			return err
 		}
 	}
+
+	provider := c.getMeshProvider()
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+	if err := verifyProviderFeatures(canary, provider); err != nil {
+		return err
+	}
+	if err := verifyPrimaryStrategy(canary); err != nil {
+		return err
+	}
+	return verifyAnalysis(canary)
+}
+
+// setSpecValidCondition reports the spec validation error on the canary status,
+// the condition is added only after a validation failed and is updated when the result changes
+func (c *Controller) setSpecValidCondition(cd *flaggerv1.Canary, verifyErr error) {
+	condition := flaggerv1.CanaryCondition{
+		Type:    flaggerv1.SpecValidType,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Succeeded",
+		Message: "Canary spec is valid.",
+	}
+	if verifyErr != nil {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "Failed"
+		condition.Message = verifyErr.Error()
+	}
+
+	var current *flaggerv1.CanaryCondition
+	for i := range cd.Status.Conditions {
+		if cd.Status.Conditions[i].Type == flaggerv1.SpecValidType {
+			current = &cd.Status.Conditions[i]
+		}
+	}
+	if current == nil && verifyErr == nil {
+		return
+	}
+	if current != nil && current.Status == condition.Status && current.Message == condition.Message {
+		return
+	}
+
+	if verifyErr != nil {
+		c.recordEventWarningf(cd, "Invalid canary spec: %v", verifyErr)
+	}
+	// the canary from the informer cache must not be modified
+	if err := c.setStatusConditions(cd.DeepCopy(), []flaggerv1.CanaryCondition{condition}); err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+	}
+}
+
+// verifyPrimaryStrategy rejects the primary strategy overrides that would produce an invalid deployment
+func verifyPrimaryStrategy(canary *flaggerv1.Canary) error {
+	strategy := canary.Spec.PrimaryStrategy
+	if strategy == nil || strategy.Type == flaggerv1.PrimaryStrategyRecreate {
+		return nil
+	}
+
+	zero := 0
+	for _, field := range []struct {
+		name  string
+		value *intstr.IntOrString
+	}{
+		{name: "maxSurge", value: strategy.MaxSurge},
+		{name: "maxUnavailable", value: strategy.MaxUnavailable},
+	} {
+		name, value := field.name, field.value
+		if value == nil {
+			continue
+		}
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+		if err != nil {
+			return fmt.Errorf("primaryStrategy.%s: %w", name, err)
+		}
+		if scaled < 0 {
+			return fmt.Errorf("primaryStrategy.%s can't be negative", name)
+		}
+		if scaled == 0 {
+			zero++
+		}
+	}
+	if zero == 2 {
+		return fmt.Errorf("primaryStrategy.maxSurge and primaryStrategy.maxUnavailable can't both be 0")
+	}
+	return nil
+}
+
+// verifyProviderFeatures rejects the routing settings that the provider would silently ignore
+func verifyProviderFeatures(canary *flaggerv1.Canary, provider string) error {
+	if canary.GetAnalysis() == nil || provider == flaggerv1.IstioProvider {
+		return nil
+	}
+	// the kubernetes providers run a blue/green analysis
+	if provider == flaggerv1.KubernetesProvider || provider == flaggerv1.SelectorSwitchProvider {
+		if canary.GetAnalysis().Soak != nil || canary.GetAnalysis().Bandit != nil {
+			return fmt.Errorf("soak and bandit are not supported by the %s provider", provider)
+		}
+	}
+	// the blue/green balance of the HAProxy ingress controller only splits the traffic by weight
+	if provider == flaggerv1.HAProxyProvider && len(canary.GetAnalysis().Match) > 0 {
+		return fmt.Errorf("A/B testing match conditions are not supported by the %s provider", provider)
+	}
+	for _, match := range canary.GetAnalysis().Match {
+		if len(match.Claims) > 0 {
+			return fmt.Errorf("match claims are supported only by the %s provider", flaggerv1.IstioProvider)
+		}
+	}
+	return nil
+}
+
+// verifyAnalysis rejects analysis settings that can't be used together
+func verifyAnalysis(canary *flaggerv1.Canary) error {
+	analysis := canary.GetAnalysis()
+	if analysis == nil {
+		return nil
+	}
+	for _, metric := range analysis.Metrics {
+		if metric.SLO != nil && len(metric.StepThresholds) > 0 {
+			return fmt.Errorf("metric %s: stepThresholds can't be used with slo", metric.Name)
+		}
+	}
+	// iterations select the A/B testing or blue/green strategies that don't shift the traffic gradually
+	if analysis.Iterations > 0 && (analysis.Soak != nil || analysis.Bandit != nil) {
+		return fmt.Errorf("soak and bandit can't be used with iterations")
+	}
+	if analysis.Soak != nil && analysis.Bandit != nil {
+		return fmt.Errorf("soak can't be used with bandit")
+	}
+	if _, err := canary.GetAnalysisSoakDuration(); err != nil {
+		return err
+	}
+	if _, err := canary.GetPrimaryReadinessDelay(); err != nil {
+		return err
+	}
+	for _, locality := range analysis.Localities {
+		// an empty match would route all the traffic as if it came from this locality
+		if len(locality.Match) == 0 {
+			return fmt.Errorf("locality %s: match can't be empty", locality.Name)
+		}
+		for _, match := range locality.Match {
+			if reflect.DeepEqual(match, istiov1alpha3.HTTPMatchRequest{}) {
+				return fmt.Errorf("locality %s: match conditions can't be empty", locality.Name)
+			}
+		}
+	}
 	return nil
 }
 
@@ -1,14 +1,23 @@
 package controller
 
 import (
+	"context"
+	"sync"
 	"testing"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1alpha3 "github.com/fluxcd/flagger/pkg/apis/istio/v1alpha3"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestController_verifyCanary(t *testing.T) {
+	zero := intstr.FromInt(0)
+	zeroPercent := intstr.FromString("0%")
+	one := intstr.FromInt(1)
 	tests := []struct {
 		name    string
 		canary  flaggerv1.Canary
@@ -90,6 +99,38 @@ func TestController_verifyCanary(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Primary strategy with zero maxSurge and maxUnavailable should return an error",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					PrimaryStrategy: &flaggerv1.PrimaryStrategy{
+						MaxSurge:       &zeroPercent,
+						MaxUnavailable: &zero,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Primary strategy with zero maxSurge is allowed",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					PrimaryStrategy: &flaggerv1.PrimaryStrategy{
+						MaxSurge:       &zero,
+						MaxUnavailable: &one,
+					},
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	ctrl := &Controller{
@@ -101,7 +142,173 @@ func TestController_verifyCanary(t *testing.T) {
 			err := ctrl.verifyCanary(&test.canary)
 			if test.wantErr {
 				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
 			}
 		})
 	}
 }
+
+func TestController_verifyAnalysis(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Metrics: []flaggerv1.CanaryMetric{
+					{
+						Name: "request-success-rate",
+						SLO:  &flaggerv1.CanarySLO{Objective: 99.9},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, verifyAnalysis(canary))
+
+	canary.Spec.Analysis.Metrics[0].StepThresholds = []flaggerv1.CanaryStepThreshold{{Weight: 20}}
+	require.Error(t, verifyAnalysis(canary))
+}
+
+func TestController_verifyAnalysisLocalities(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Localities: []flaggerv1.CanaryLocality{
+					{
+						Name: "us-east-1a",
+						Match: []istiov1alpha3.HTTPMatchRequest{
+							{SourceLabels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, verifyAnalysis(canary))
+
+	canary.Spec.Analysis.Localities[0].Match = []istiov1alpha3.HTTPMatchRequest{{}}
+	require.Error(t, verifyAnalysis(canary))
+
+	canary.Spec.Analysis.Localities[0].Match = nil
+	require.Error(t, verifyAnalysis(canary))
+}
+
+func TestController_verifyProviderFeatures(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Match: []istiov1alpha3.HTTPMatchRequest{
+					{
+						Claims: map[string]istiov1alpha1.StringMatch{
+							"groups": {Exact: "beta-testers"},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, verifyProviderFeatures(canary, flaggerv1.IstioProvider))
+	require.Error(t, verifyProviderFeatures(canary, flaggerv1.LinkerdProvider))
+
+	canary.Spec.Analysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{Headers: map[string]istiov1alpha1.StringMatch{"x-canary": {Exact: "insider"}}},
+	}
+	require.NoError(t, verifyProviderFeatures(canary, flaggerv1.NGINXProvider))
+	require.Error(t, verifyProviderFeatures(canary, flaggerv1.HAProxyProvider))
+}
+
+func TestImagesSuffix(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		Status: flaggerv1.CanaryStatus{
+			Images: []flaggerv1.CanaryImage{
+				{Container: "podinfo", Image: "ghcr.io/stefanprodan/podinfo:6.0.1", Tag: "6.0.1"},
+				{Container: "proxy", Image: "envoyproxy/envoy@sha256:4f5c2b0e4f1b4c3d2e1f", Digest: "sha256:4f5c2b0e4f1b4c3d2e1f"},
+			},
+		},
+	}
+	require.Equal(t, " (podinfo:6.0.1, envoy@sha256:4f5c2b0e4f1b)", imagesSuffix(cd))
+	require.Empty(t, imagesSuffix(&flaggerv1.Canary{}))
+}
+
+func TestController_pruneCanaryState(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	key := "podinfo.default"
+	mocks.ctrl.reports.Store(key, &flaggerv1.CanaryAnalysisReport{})
+	mocks.ctrl.metricValues.Store(key, map[string]float64{})
+	mocks.ctrl.checkStreaks.Store(key, 1)
+	mocks.ctrl.routeWarnings.Store(key, "warning")
+	mocks.ctrl.frozen.Store(key, true)
+
+	mocks.ctrl.pruneCanaryState("podinfo", "default")
+
+	for _, store := range []*sync.Map{&mocks.ctrl.reports,
+		&mocks.ctrl.metricValues, &mocks.ctrl.checkStreaks, &mocks.ctrl.routeWarnings, &mocks.ctrl.frozen} {
+		_, ok := store.Load(key)
+		require.False(t, ok)
+	}
+}
+
+func TestController_setSpecValidCondition(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	zero := intstr.FromInt(0)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Spec.PrimaryStrategy = &flaggerv1.PrimaryStrategy{MaxSurge: &zero, MaxUnavailable: &zero}
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(cd))
+
+	require.Error(t, mocks.ctrl.syncHandler("default/podinfo"))
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, corev1.ConditionFalse, specValidStatus(c))
+
+	// the condition is updated once the spec is fixed
+	c.Spec.PrimaryStrategy = nil
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(c))
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, corev1.ConditionTrue, specValidStatus(c))
+}
+
+func specValidStatus(cd *flaggerv1.Canary) corev1.ConditionStatus {
+	for _, condition := range cd.Status.Conditions {
+		if condition.Type == flaggerv1.SpecValidType {
+			return condition.Status
+		}
+	}
+	return ""
+}
+
+func TestController_verifyAnalysisSoak(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				Soak: &flaggerv1.CanarySoak{Weight: 10, Duration: "1h"},
+			},
+		},
+	}
+	require.NoError(t, verifyAnalysis(canary))
+
+	canary.Spec.Analysis.Soak.Duration = "1 hour"
+	require.Error(t, verifyAnalysis(canary))
+
+	// soak is ignored by the A/B testing and blue/green strategies
+	canary.Spec.Analysis.Soak.Duration = "1h"
+	canary.Spec.Analysis.Iterations = 10
+	require.Error(t, verifyAnalysis(canary))
+	require.Error(t, verifyProviderFeatures(canary, flaggerv1.KubernetesProvider))
+}
+
+func TestController_verifyAnalysisReadinessGate(t *testing.T) {
+	canary := &flaggerv1.Canary{
+		Spec: flaggerv1.CanarySpec{
+			Analysis: &flaggerv1.CanaryAnalysis{
+				PrimaryReadinessGate: &flaggerv1.CanaryReadinessGate{Delay: "10s"},
+			},
+		},
+	}
+	require.NoError(t, verifyAnalysis(canary))
+
+	canary.Spec.Analysis.PrimaryReadinessGate.Delay = "10 seconds"
+	require.Error(t, verifyAnalysis(canary))
+}
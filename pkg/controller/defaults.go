@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// applyCanaryDefaults returns a copy of the canary with the analysis defaults
+// of its namespace merged in, the canary is returned as is if no defaults exist
+func (c *Controller) applyCanaryDefaults(canary *flaggerv1.Canary) *flaggerv1.Canary {
+	if canary.GetAnalysis() == nil || c.flaggerInformers.DefaultInformer == nil {
+		return canary
+	}
+
+	defaults, err := c.flaggerInformers.DefaultInformer.Lister().CanaryDefaults(canary.Namespace).List(labels.Everything())
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Errorf("Canary defaults %s list query error: %v", canary.Namespace, err)
+		return canary
+	}
+	if len(defaults) == 0 {
+		return canary
+	}
+
+	sort.Slice(defaults, func(i, j int) bool {
+		return defaults[i].Name < defaults[j].Name
+	})
+
+	cd := canary.DeepCopy()
+	policy := make(map[string]bool)
+	for _, d := range defaults {
+		mergeCanaryDefaults(cd.GetAnalysis(), d.Spec, policy)
+	}
+	return cd
+}
+
+// mergeCanaryDefaults sets the analysis interval and threshold if they are empty
+// and appends the metrics, alerts and webhooks not already defined by name.
+// When the defaults are enforced, a default metric replaces the canary metric with the same name
+// unless a previous defaults object already set it, policy tracks the metric names set so far.
+func mergeCanaryDefaults(analysis *flaggerv1.CanaryAnalysis, defaults flaggerv1.CanaryDefaultSpec, policy map[string]bool) {
+	if analysis.Interval == "" {
+		analysis.Interval = defaults.Interval
+	}
+	if analysis.Threshold == 0 {
+		analysis.Threshold = defaults.Threshold
+	}
+
+	for _, metric := range defaults.Metrics {
+		if policy[metric.Name] {
+			continue
+		}
+		policy[metric.Name] = true
+
+		found := false
+		for i, m := range analysis.Metrics {
+			if m.Name == metric.Name {
+				if defaults.Enforce {
+					analysis.Metrics[i] = *metric.DeepCopy()
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			analysis.Metrics = append(analysis.Metrics, *metric.DeepCopy())
+		}
+	}
+
+	for _, alert := range defaults.Alerts {
+		found := false
+		for _, a := range analysis.Alerts {
+			if a.Name == alert.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			analysis.Alerts = append(analysis.Alerts, *alert.DeepCopy())
+		}
+	}
+
+	for _, webhook := range defaults.Webhooks {
+		found := false
+		for _, w := range analysis.Webhooks {
+			if w.Name == webhook.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			analysis.Webhooks = append(analysis.Webhooks, *webhook.DeepCopy())
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestMergeCanaryDefaults(t *testing.T) {
+	analysis := &flaggerv1.CanaryAnalysis{
+		Threshold: 5,
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 95},
+		},
+	}
+
+	policy := make(map[string]bool)
+	mergeCanaryDefaults(analysis, flaggerv1.CanaryDefaultSpec{
+		Interval:  "30s",
+		Threshold: 10,
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 99},
+			{Name: "request-duration", Threshold: 500},
+		},
+		Alerts: []flaggerv1.CanaryAlert{
+			{Name: "on-call", Severity: flaggerv1.SeverityError},
+		},
+		Webhooks: []flaggerv1.CanaryWebhook{
+			{Name: "load-test", URL: "http://flagger-loadtester/"},
+		},
+	}, policy)
+
+	// a later defaults object can't override the metrics set by a previous one
+	mergeCanaryDefaults(analysis, flaggerv1.CanaryDefaultSpec{
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 90},
+		},
+	}, policy)
+
+	assert.Equal(t, "30s", analysis.Interval)
+	assert.Equal(t, 5, analysis.Threshold)
+	require.Len(t, analysis.Metrics, 2)
+	// the canary metric overrides the default one
+	assert.Equal(t, float64(95), analysis.Metrics[0].Threshold)
+	assert.Equal(t, "request-duration", analysis.Metrics[1].Name)
+	require.Len(t, analysis.Alerts, 1)
+	assert.Equal(t, "on-call", analysis.Alerts[0].Name)
+	require.Len(t, analysis.Webhooks, 1)
+	assert.Equal(t, "load-test", analysis.Webhooks[0].Name)
+}
+
+func TestMergeCanaryDefaultsEnforce(t *testing.T) {
+	analysis := &flaggerv1.CanaryAnalysis{
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 95},
+		},
+	}
+
+	policy := make(map[string]bool)
+	mergeCanaryDefaults(analysis, flaggerv1.CanaryDefaultSpec{
+		Enforce: true,
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 99},
+		},
+	}, policy)
+
+	// a later defaults object can't override the metrics set by a previous one
+	mergeCanaryDefaults(analysis, flaggerv1.CanaryDefaultSpec{
+		Enforce: true,
+		Metrics: []flaggerv1.CanaryMetric{
+			{Name: "request-success-rate", Threshold: 90},
+		},
+	}, policy)
+
+	require.Len(t, analysis.Metrics, 1)
+	assert.Equal(t, float64(99), analysis.Metrics[0].Threshold)
+}
+
+func TestController_ApplyCanaryDefaults(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// no defaults in the namespace
+	assert.Equal(t, cd, mocks.ctrl.applyCanaryDefaults(cd))
+
+	defaults := &flaggerv1.CanaryDefault{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseline", Namespace: "default"},
+		Spec: flaggerv1.CanaryDefaultSpec{
+			Interval: "2m",
+			Webhooks: []flaggerv1.CanaryWebhook{
+				{Name: "smoke-test", Type: flaggerv1.PreRolloutHook, URL: "http://flagger-loadtester/"},
+			},
+		},
+	}
+	require.NoError(t, mocks.ctrl.flaggerInformers.DefaultInformer.Informer().GetIndexer().Add(defaults))
+
+	merged := mocks.ctrl.applyCanaryDefaults(cd)
+	assert.Equal(t, "2m", merged.GetAnalysis().Interval)
+	assert.Equal(t, 10, merged.GetAnalysis().Threshold)
+	require.Len(t, merged.GetAnalysis().Webhooks, len(cd.GetAnalysis().Webhooks)+1)
+
+	// the canary object is left untouched
+	assert.Empty(t, cd.GetAnalysis().Interval)
+
+	// the defaults are skipped when the CanaryDefault CRD is not registered
+	mocks.ctrl.flaggerInformers.DefaultInformer = nil
+	assert.Equal(t, cd, mocks.ctrl.applyCanaryDefaults(cd))
+}
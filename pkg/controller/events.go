@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
 	"github.com/fluxcd/flagger/pkg/notifier"
 )
 
@@ -52,19 +53,26 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 	for _, canaryWebhook := range r.GetAnalysis().Webhooks {
 		if canaryWebhook.Type == flaggerv1.EventHook {
 			webhookOverride = true
-			err := CallEventWebhook(r, canaryWebhook, fmt.Sprintf(template, args...), eventType)
+			credentials, err := c.webhookCredentials(r, &canaryWebhook)
+			if err == nil {
+				payload := newEventWebhookPayload(r, canaryWebhook, fmt.Sprintf(template, args...), eventType)
+				payload.Metrics = c.getMetricValues(r)
+				err = callWebhook(canaryWebhook.URL, payload, "5s", credentials)
+			}
 			if err != nil {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
 			}
 		}
 	}
 
-	if c.eventWebhook != "" && !webhookOverride {
+	if eventWebhook := c.getEventWebhook(); eventWebhook != "" && !webhookOverride {
 		hook := flaggerv1.CanaryWebhook{
 			Name: "events",
-			URL:  c.eventWebhook,
+			URL:  eventWebhook,
 		}
-		err := CallEventWebhook(r, hook, fmt.Sprintf(template, args...), eventType)
+		payload := newEventWebhookPayload(r, hook, fmt.Sprintf(template, args...), eventType)
+		payload.Metrics = c.getMetricValues(r)
+		err := callWebhook(hook.URL, payload, "5s", nil)
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", r.Name, r.Namespace)).Errorf("error sending event to webhook: %s", err)
 		}
@@ -72,6 +80,18 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 }
 
 func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity) {
+	c.alertWithFields(canary, message, metadata, severity, nil)
+}
+
+// phaseField returns the alert field holding the phase the canary transitions to,
+// notifiers use it to tell the promotions and rollbacks apart from the other alerts
+func phaseField(phase flaggerv1.CanaryPhase) []notifier.Field {
+	return []notifier.Field{{Name: notifier.PhaseField, Value: string(phase)}}
+}
+
+// alertWithFields sends an alert with additional fields e.g. the gate the canary is waiting on
+func (c *Controller) alertWithFields(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity,
+	extraFields []notifier.Field) {
 	var fields []notifier.Field
 
 	if c.clusterName != "" {
@@ -87,9 +107,42 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		fields = append(fields, alertMetadata(canary)...)
 	}
 
+	if values := c.getMetricValues(canary); len(values) > 0 {
+		fields = append(fields,
+			notifier.Field{
+				Name:  "Metrics",
+				Value: formatMetricValues(values),
+			},
+		)
+	}
+
+	if len(canary.Status.Images) > 0 {
+		var images []string
+		for _, image := range canary.Status.Images {
+			images = append(images, image.Image)
+		}
+		fields = append(fields,
+			notifier.Field{
+				Name:  "Images",
+				Value: strings.Join(images, ", "),
+			},
+		)
+	}
+
+	if canary.Status.Commit != "" {
+		fields = append(fields,
+			notifier.Field{
+				Name:  notifier.CommitField,
+				Value: canary.Status.Commit,
+			},
+		)
+	}
+
+	fields = append(fields, extraFields...)
+
 	// send alert with the global notifier
 	if len(canary.GetAnalysis().Alerts) == 0 {
-		err := c.notifier.Post(canary.Name, canary.Namespace, message, fields, string(severity))
+		err := c.getNotifier().Post(canary.Name, canary.Namespace, message, notifier.WithoutRolloutFields(fields), string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Errorf("alert can't be sent: %v", err)
@@ -137,6 +190,9 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		// https://datatracker.ietf.org/doc/html/rfc6750
 		token := ""
 
+		// set the proxy, the proxy from the secret takes precedence
+		proxy := provider.Spec.Proxy
+
 		// extract address from secret
 		if provider.Spec.SecretRef != nil {
 			secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
@@ -147,7 +203,7 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			}
 			if address, ok := secret.Data["address"]; ok {
 				url = string(address)
-			} else {
+			} else if url == "" {
 				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 					Errorf("alert provider %s.%s secret does not contain an address", alert.ProviderRef.Name, providerNamespace)
 				continue
@@ -156,6 +212,10 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			if tokenFromSecret, ok := secret.Data["token"]; ok {
 				token = string(tokenFromSecret)
 			}
+
+			if proxyFromSecret, ok := secret.Data["proxy"]; ok {
+				proxy = string(proxyFromSecret)
+			}
 		}
 
 		// set defaults
@@ -167,10 +227,6 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 		if provider.Spec.Channel != "" {
 			channel = provider.Spec.Channel
 		}
-		proxy := ""
-		if provider.Spec.Proxy != "" {
-			proxy = provider.Spec.Proxy
-		}
 
 		// create notifier based on provider type
 		f := notifier.NewFactory(url, token, proxy, username, channel)
@@ -181,8 +237,14 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			continue
 		}
 
+		// send the commit and phase fields only to the providers reporting the rollout state
+		providerFields := fields
+		if !notifier.TracksRollouts(provider.Spec.Type) {
+			providerFields = notifier.WithoutRolloutFields(fields)
+		}
+
 		// send alert
-		err = n.Post(canary.Name, canary.Namespace, message, fields, string(severity))
+		err = n.Post(canary.Name, canary.Namespace, message, providerFields, string(severity))
 		if err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Errorf("alert provider $s.%s send error: %v", alert.ProviderRef.Name, providerNamespace, err)
@@ -191,6 +253,87 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 	}
 }
 
+// targetCommit returns the Git commit SHA the canary target was built from,
+// the SHA is read from the pod template annotations or from the target annotations,
+// it is called once per revision and the SHA is kept in the canary status
+func (c *Controller) targetCommit(cd *flaggerv1.Canary) string {
+	var annotations []map[string]string
+	name := cd.Spec.TargetRef.Name
+	switch cd.Spec.TargetRef.Kind {
+	case "Deployment":
+		dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		annotations = append(annotations, dep.Spec.Template.Annotations, dep.Annotations)
+	case "DaemonSet":
+		ds, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		annotations = append(annotations, ds.Spec.Template.Annotations, ds.Annotations)
+	case "StatefulSet":
+		sts, err := c.kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		annotations = append(annotations, sts.Spec.Template.Annotations, sts.Annotations)
+	case "Service":
+		if cd.Spec.TargetRef.IsKnativeService() {
+			ksvc, err := c.flaggerClient.KnativeV1().Services(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return ""
+			}
+			annotations = append(annotations, ksvc.Spec.Template.Annotations, ksvc.Annotations)
+			break
+		}
+		svc, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		annotations = append(annotations, svc.Annotations)
+	}
+
+	return canary.GitCommit(annotations...)
+}
+
+// targetImages returns the images of the canary target containers
+func (c *Controller) targetImages(cd *flaggerv1.Canary) []flaggerv1.CanaryImage {
+	template, err := c.targetPodTemplate(cd)
+	if err != nil || template == nil {
+		return nil
+	}
+	return canary.PodImages(template.Spec)
+}
+
+// imagesSuffix returns the image versions of the canary to be appended to event messages
+// e.g. " (podinfo:6.0.1, envoy@sha256:4f5c2b0e4f1b)"
+func imagesSuffix(cd *flaggerv1.Canary) string {
+	if len(cd.Status.Images) == 0 {
+		return ""
+	}
+
+	var versions []string
+	for _, image := range cd.Status.Images {
+		name := strings.TrimSuffix(image.Image, "@"+image.Digest)
+		name = strings.TrimSuffix(name, ":"+image.Tag)
+		name = name[strings.LastIndex(name, "/")+1:]
+		switch {
+		case image.Tag != "":
+			versions = append(versions, fmt.Sprintf("%s:%s", name, image.Tag))
+		case image.Digest != "":
+			digest := image.Digest
+			if len(digest) > 19 {
+				digest = digest[:19]
+			}
+			versions = append(versions, fmt.Sprintf("%s@%s", name, digest))
+		default:
+			versions = append(versions, name)
+		}
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(versions, ", "))
+}
+
 func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 	var fields []notifier.Field
 
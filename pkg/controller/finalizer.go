@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/router"
 )
 
 const finalizer = "finalizer.flagger.app"
@@ -43,7 +44,7 @@ func (c *Controller) finalize(old interface{}) error {
 	}
 
 	// Retrieve a controller
-	canaryController := c.canaryFactory.Controller(canary.Spec.TargetRef.Kind)
+	canaryController := c.canaryFactory.TargetGroupController(canary)
 
 	// Set the status to terminating if not already in that state
 	if canary.Status.Phase != flaggerv1.CanaryPhaseTerminating {
@@ -102,10 +103,13 @@ func (c *Controller) finalize(old interface{}) error {
 	if err := router.Finalize(canary); err != nil {
 		return fmt.Errorf("failed revert router: %w", err)
 	}
+	if err := c.finalizeTargetGroupServices(canary); err != nil {
+		return fmt.Errorf("failed revert target group router: %w", err)
+	}
 	c.logger.Infof("%s.%s router reverted", canary.Name, canary.Namespace)
 
 	// Revert the mesh objects
-	if err := c.revertMesh(canary); err != nil {
+	if err := c.revertMesh(canary, labelSelector, labelValue); err != nil {
 		return fmt.Errorf("failed to revert mesh: %w", err)
 	}
 
@@ -115,13 +119,13 @@ func (c *Controller) finalize(old interface{}) error {
 
 // revertMesh reverts defined mesh provider based upon the implementation's respective Finalize method.
 // If the Finalize method encounters and error that is returned, else revert is considered successful.
-func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
-	provider := c.meshProvider
+func (c *Controller) revertMesh(r *flaggerv1.Canary, labelSelector string, labelValue string) error {
+	provider := c.getMeshProvider()
 	if r.Spec.Provider != "" {
 		provider = r.Spec.Provider
 	}
 
-	meshRouter := c.routerFactory.MeshRouter(provider, "")
+	meshRouter := c.routerFactory.MeshRouter(provider, labelSelector, labelValue)
 	if err := meshRouter.Finalize(r); err != nil {
 		return fmt.Errorf("meshRouter.Finlize failed: %w", err)
 	}
@@ -130,6 +134,42 @@ func (c *Controller) revertMesh(r *flaggerv1.Canary) error {
 	return nil
 }
 
+// remoteMeshRouter returns the Istio router of the canary and true if the routing
+// objects are replicated to the other primary clusters of the mesh
+func (c *Controller) remoteMeshRouter(canary *flaggerv1.Canary) (*router.IstioRouter, bool) {
+	provider := c.getMeshProvider()
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+
+	// the routing objects of the remote clusters are deleted by name, the pod labels are not needed
+	ir, ok := c.routerFactory.MeshRouter(provider, "", "").(*router.IstioRouter)
+	return ir, ok && ir.HasRemoteClusters()
+}
+
+// requiresFinalizer returns true if the canary must be finalized before deletion,
+// either to revert the targets or to delete the routing objects of the remote clusters
+func (c *Controller) requiresFinalizer(canary *flaggerv1.Canary) bool {
+	if canary.Spec.RevertOnDeletion {
+		return true
+	}
+	_, ok := c.remoteMeshRouter(canary)
+	return ok
+}
+
+// finalizeRemoteMesh deletes the routing objects generated in the remote clusters of the mesh
+func (c *Controller) finalizeRemoteMesh(canary *flaggerv1.Canary) error {
+	ir, ok := c.remoteMeshRouter(canary)
+	if !ok {
+		return nil
+	}
+	if err := ir.FinalizeRemote(canary); err != nil {
+		return fmt.Errorf("failed to finalize remote mesh: %w", err)
+	}
+	c.logger.Infof("%s.%s remote mesh objects deleted", canary.Name, canary.Namespace)
+	return nil
+}
+
 // hasFinalizer evaluates the finalizers of a given canary for for existence of a provide finalizer string.
 // It returns a boolean, true if the finalizer is found false otherwise.
 func hasFinalizer(canary *flaggerv1.Canary) bool {
@@ -17,10 +17,15 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	fakeIstio "istio.io/client-go/pkg/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sTesting "k8s.io/client-go/testing"
 
@@ -104,3 +109,48 @@ func TestFinalizer_removeFinalizer(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizer_remoteMesh(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	remoteClient := fakeIstio.NewSimpleClientset()
+	mocks.ctrl.routerFactory.AddRemoteIstioClient(remoteClient)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, err := remoteClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the finalizer is added without revertOnDeletion
+	require.True(t, mocks.ctrl.requiresFinalizer(mocks.canary))
+	err = mocks.ctrl.syncHandler("default/podinfo")
+	require.NoError(t, err)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, hasFinalizer(c))
+
+	// the remote objects are deleted on canary deletion
+	now := metav1.Now()
+	c.DeletionTimestamp = &now
+	err = mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(c)
+	require.NoError(t, err)
+
+	err = mocks.ctrl.syncHandler("default/podinfo")
+	require.NoError(t, err)
+
+	_, err = remoteClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, hasFinalizer(c))
+}
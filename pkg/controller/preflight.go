@@ -0,0 +1,488 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// preflightCheck verifies a prerequisite of the canary analysis
+type preflightCheck struct {
+	condition flaggerv1.CanaryConditionType
+	run       func(canary *flaggerv1.Canary, provider string) (string, error)
+	// advisory checks report a failure without halting the rollout
+	advisory bool
+}
+
+// runPreflightChecks verifies the prerequisites of the canary analysis and records the results
+// as status conditions, it returns false if any of the checks failed
+func (c *Controller) runPreflightChecks(canary *flaggerv1.Canary) bool {
+	provider := c.getMeshProvider()
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+
+	checks := []preflightCheck{
+		{condition: flaggerv1.MeshAvailableType, run: c.checkMeshAvailable},
+		{condition: flaggerv1.MetricsAvailableType, run: c.checkMetricsAvailable},
+		{condition: flaggerv1.SidecarInjectedType, run: c.checkSidecarInjected, advisory: true},
+		{condition: flaggerv1.WebhooksReachableType, run: c.checkWebhooksReachable},
+	}
+
+	passed := true
+	conditions := make([]flaggerv1.CanaryCondition, 0, len(checks))
+	for _, check := range checks {
+		condition := flaggerv1.CanaryCondition{
+			Type:   check.condition,
+			Status: corev1.ConditionTrue,
+			Reason: "Succeeded",
+		}
+		message, err := check.run(canary, provider)
+		switch {
+		case err != nil && check.advisory:
+			message = err.Error()
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = "Warning"
+			c.recordEventWarningf(canary, "Preflight check %s warning for %s.%s: %v",
+				check.condition, canary.Name, canary.Namespace, err)
+		case err != nil:
+			message = err.Error()
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = "Failed"
+			passed = false
+			c.recordEventWarningf(canary, "Preflight check %s failed for %s.%s: %v",
+				check.condition, canary.Name, canary.Namespace, err)
+		}
+		condition.Message = message
+		conditions = append(conditions, condition)
+	}
+
+	if err := c.setStatusConditions(canary, conditions); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
+	return passed
+}
+
+// setStatusConditions adds or updates the given conditions,
+// the last transition time is preserved when the status is unchanged
+// and the status update is skipped when none of the conditions changed
+func (c *Controller) setStatusConditions(cd *flaggerv1.Canary, conditions []flaggerv1.CanaryCondition) error {
+	if !conditionsChanged(cd.Status.Conditions, conditions) {
+		return nil
+	}
+
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		canary, err := c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+		}
+
+		cdCopy := canary.DeepCopy()
+		now := metav1.Now()
+		for _, condition := range conditions {
+			condition.LastUpdateTime = now
+			condition.LastTransitionTime = now
+			found := false
+			for i, current := range cdCopy.Status.Conditions {
+				if current.Type != condition.Type {
+					continue
+				}
+				if current.Status == condition.Status {
+					condition.LastTransitionTime = current.LastTransitionTime
+				}
+				cdCopy.Status.Conditions[i] = condition
+				found = true
+			}
+			if !found {
+				cdCopy.Status.Conditions = append(cdCopy.Status.Conditions, condition)
+			}
+		}
+
+		updated, err := c.flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+
+		// keep the in-memory object in sync for the next status updates
+		cd.Status.Conditions = updated.Status.Conditions
+		cd.ResourceVersion = updated.ResourceVersion
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("canary %s.%s conditions update failed: %w", name, ns, err)
+	}
+	return nil
+}
+
+// conditionsChanged returns true if any of the conditions is missing from the current ones
+// or differs in status, reason or message
+func conditionsChanged(current []flaggerv1.CanaryCondition, conditions []flaggerv1.CanaryCondition) bool {
+	for _, condition := range conditions {
+		found := false
+		for _, cur := range current {
+			if cur.Type != condition.Type {
+				continue
+			}
+			found = true
+			if cur.Status != condition.Status || cur.Reason != condition.Reason || cur.Message != condition.Message {
+				return true
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
+
+// meshGroupVersion returns the API group version of the routing objects managed for the provider,
+// an empty value is returned for the providers using Kubernetes core APIs
+func meshGroupVersion(provider string) string {
+	switch {
+	case strings.HasPrefix(provider, flaggerv1.AppMeshProvider+":v1beta2"):
+		return "appmesh.k8s.aws/v1beta2"
+	case provider == flaggerv1.AppMeshProvider:
+		return "appmesh.k8s.aws/v1beta1"
+	case provider == flaggerv1.LinkerdProvider:
+		return "split.smi-spec.io/v1alpha1"
+	case strings.HasPrefix(provider, flaggerv1.SMIProvider+":v1alpha1"):
+		return "split.smi-spec.io/v1alpha1"
+	case strings.HasPrefix(provider, flaggerv1.SMIProvider+":v1alpha2"), provider == flaggerv1.OsmProvider:
+		return "split.smi-spec.io/v1alpha2"
+	case strings.HasPrefix(provider, flaggerv1.SMIProvider+":v1alpha3"):
+		return "split.smi-spec.io/v1alpha3"
+	case provider == flaggerv1.ContourProvider:
+		return "projectcontour.io/v1"
+	case strings.HasPrefix(provider, flaggerv1.GlooProvider):
+		return "gateway.solo.io/v1"
+	case provider == flaggerv1.TraefikProvider:
+		return "traefik.containo.us/v1alpha1"
+	case provider == flaggerv1.ApisixProvider:
+		return "apisix.apache.org/v2"
+	case provider == flaggerv1.OpenShiftProvider:
+		return "route.openshift.io/v1"
+	case provider == flaggerv1.EmissaryProvider:
+		return "getambassador.io/v3alpha1"
+	case provider == flaggerv1.KnativeProvider:
+		return "serving.knative.dev/v1"
+	case provider == flaggerv1.ExternalDNSProvider:
+		return "externaldns.k8s.io/v1alpha1"
+	case provider == flaggerv1.KumaProvider:
+		return "kuma.io/v1alpha1"
+	case strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider+":v1alpha2"):
+		return "gateway.networking.k8s.io/v1alpha2"
+	case strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider+":v1beta1"), provider == flaggerv1.GKEProvider:
+		return "gateway.networking.k8s.io/v1beta1"
+	case provider == flaggerv1.NGINXProvider, provider == flaggerv1.HAProxyProvider, provider == flaggerv1.SkipperProvider,
+		provider == flaggerv1.KubernetesProvider, provider == flaggerv1.SelectorSwitchProvider:
+		return ""
+	default:
+		return "networking.istio.io/v1beta1"
+	}
+}
+
+// checkMeshAvailable verifies that the CRDs of the mesh or ingress provider are installed
+func (c *Controller) checkMeshAvailable(_ *flaggerv1.Canary, provider string) (string, error) {
+	groupVersion := meshGroupVersion(provider)
+	if groupVersion == "" {
+		return fmt.Sprintf("Provider %s uses Kubernetes APIs", provider), nil
+	}
+
+	if _, err := c.routerFactory.MeshClient().Discovery().ServerResourcesForGroupVersion(groupVersion); err != nil {
+		return "", fmt.Errorf("API %s of provider %s not available: %w", groupVersion, provider, err)
+	}
+	return fmt.Sprintf("API %s is available", groupVersion), nil
+}
+
+// checkMetricsAvailable verifies that the metrics providers answer a test query
+func (c *Controller) checkMetricsAvailable(canary *flaggerv1.Canary, _ string) (string, error) {
+	if canary.SkipAnalysis() || len(canary.GetAnalysis().Metrics) == 0 {
+		return "No metrics to check", nil
+	}
+
+	if err := c.checkMetricProviderAvailability(canary); err != nil {
+		return "", err
+	}
+	return "Metrics providers are available", nil
+}
+
+// checkSidecarInjected verifies that the sidecar injection is enabled
+// on the namespace or the pod template for the meshes relying on sidecars,
+// the injection can also be enabled by webhooks that don't match these labels
+// so the check is advisory and the pods are verified by checkSidecarsRunning
+func (c *Controller) checkSidecarInjected(canary *flaggerv1.Canary, provider string) (string, error) {
+	if canary.Annotations[flaggerv1.SkipSidecarCheckAnnotation] == "true" {
+		return fmt.Sprintf("Check disabled by the %s annotation", flaggerv1.SkipSidecarCheckAnnotation), nil
+	}
+
+	var key, value string
+	switch provider {
+	case flaggerv1.LinkerdProvider:
+		key, value = "linkerd.io/inject", "enabled"
+	case flaggerv1.IstioProvider:
+		key, value = "sidecar.istio.io/inject", "true"
+	default:
+		return fmt.Sprintf("Provider %s does not require sidecars", provider), nil
+	}
+
+	template, err := c.targetPodTemplate(canary)
+	if err != nil {
+		return "", err
+	}
+	if template == nil {
+		return fmt.Sprintf("Target %s does not define a pod template", canary.Spec.TargetRef.Kind), nil
+	}
+	if template.Labels[key] == value || template.Annotations[key] == value {
+		return fmt.Sprintf("Injection enabled by the pod template %s", key), nil
+	}
+	if provider == flaggerv1.IstioProvider && template.Labels["istio.io/rev"] != "" {
+		return "Injection enabled by the pod template istio.io/rev label", nil
+	}
+
+	// namespaces are cluster scoped and can't be read with namespaced RBAC
+	if c.namespacedRBAC {
+		return fmt.Sprintf("Injection labels of namespace %s not verified with namespaced RBAC", canary.Namespace), nil
+	}
+
+	ns, err := c.getNamespace(canary.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("unable to verify the injection labels: %w", err)
+	}
+	if provider == flaggerv1.LinkerdProvider {
+		if ns.Annotations[key] == value {
+			return fmt.Sprintf("Injection enabled by the namespace %s annotation", key), nil
+		}
+	} else if ns.Labels["istio-injection"] == "enabled" || ns.Labels["istio.io/rev"] != "" {
+		return "Injection enabled by the namespace labels", nil
+	} else if ns.Labels[istioDataplaneModeLabel] == "ambient" {
+		return "Ambient mode enabled by the namespace labels", nil
+	}
+
+	return "", fmt.Errorf("sidecar injection is not enabled on namespace %s or %s %s",
+		canary.Namespace, canary.Spec.TargetRef.Kind, canary.Spec.TargetRef.Name)
+}
+
+const (
+	// istioDataplaneModeLabel set to ambient on a namespace enrolls its pods in Istio ambient mode
+	istioDataplaneModeLabel = "istio.io/dataplane-mode"
+	// istioAmbientRedirectionAnnotation is set by the Istio CNI on the pods captured by ambient mode
+	istioAmbientRedirectionAnnotation = "ambient.istio.io/redirection"
+)
+
+// sidecarContainerName returns the name of the proxy container injected by the mesh provider,
+// an empty value is returned for the providers that don't rely on sidecars
+func sidecarContainerName(provider string) string {
+	switch provider {
+	case flaggerv1.LinkerdProvider:
+		return "linkerd-proxy"
+	case flaggerv1.IstioProvider:
+		return "istio-proxy"
+	}
+	return ""
+}
+
+// isMeshMember returns true if the pod runs the mesh proxy or is captured by Istio ambient mode
+func isMeshMember(pod corev1.Pod, provider string, sidecar string) bool {
+	if provider == flaggerv1.IstioProvider && pod.Annotations[istioAmbientRedirectionAnnotation] == "enabled" {
+		return true
+	}
+	return hasContainer(pod.Spec, sidecar)
+}
+
+// checkSidecarsRunning verifies that the pods matching the label selector run the mesh proxy,
+// without sidecars the canary doesn't receive the mesh traffic and the metrics are meaningless
+func (c *Controller) checkSidecarsRunning(canary *flaggerv1.Canary, provider string, labelSelector string, labelValue string) error {
+	sidecar := sidecarContainerName(provider)
+	if sidecar == "" || labelSelector == "" || canary.Annotations[flaggerv1.SkipSidecarCheckAnnotation] == "true" {
+		return nil
+	}
+
+	pods, err := c.kubeClient.CoreV1().Pods(canary.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", labelSelector, labelValue),
+	})
+	if err != nil {
+		return fmt.Errorf("pods %s=%s list query error: %w", labelSelector, labelValue, err)
+	}
+
+	var missing []string
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && !isMeshMember(pod, provider, sidecar) {
+			missing = append(missing, pod.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("sidecar %s is missing from pods %s", sidecar, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// hasContainer returns true if the pod spec has a container or a native sidecar with the given name
+func hasContainer(spec corev1.PodSpec, name string) bool {
+	for _, container := range spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	for _, container := range spec.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isSidecarRunning halts the analysis when the canary pods are not part of the mesh
+// and records the result on the SidecarRunning condition
+func (c *Controller) isSidecarRunning(canary *flaggerv1.Canary, provider string, labelSelector string, labelValue string) bool {
+	condition := flaggerv1.CanaryCondition{
+		Type:    flaggerv1.SidecarRunningType,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Succeeded",
+		Message: "Sidecar running in the canary pods",
+	}
+	err := c.checkSidecarsRunning(canary, provider, labelSelector, labelValue)
+	if err != nil {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = flaggerv1.SidecarMissingReason
+		condition.Message = err.Error()
+		c.recordEventWarningf(canary, "Halt advancement %s.%s: %v", canary.Name, canary.Namespace, err)
+	}
+
+	// the condition is updated only when the sidecar state changes,
+	// the condition is added only after a first failure
+	changed := err != nil
+	for _, current := range canary.Status.Conditions {
+		if current.Type == flaggerv1.SidecarRunningType {
+			missing := current.Reason == flaggerv1.SidecarMissingReason
+			changed = (err != nil && (!missing || current.Message != condition.Message)) || (err == nil && missing)
+		}
+	}
+	if changed {
+		if err := c.setStatusConditions(canary, []flaggerv1.CanaryCondition{condition}); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+	}
+	return err == nil
+}
+
+// targetPodTemplate returns the pod template of the canary target, nil is returned for Kubernetes Service targets
+func (c *Controller) targetPodTemplate(canary *flaggerv1.Canary) (*corev1.PodTemplateSpec, error) {
+	name := canary.Spec.TargetRef.Name
+	switch canary.Spec.TargetRef.Kind {
+	case "Deployment":
+		dep, err := c.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		return &dep.Spec.Template, nil
+	case "DaemonSet":
+		ds, err := c.kubeClient.AppsV1().DaemonSets(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		return &ds.Spec.Template, nil
+	case "StatefulSet":
+		sts, err := c.kubeClient.AppsV1().StatefulSets(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		return &sts.Spec.Template, nil
+	case "Service":
+		if !canary.Spec.TargetRef.IsKnativeService() {
+			return nil, nil
+		}
+		ksvc, err := c.flaggerClient.KnativeV1().Services(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("knative service %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		return &corev1.PodTemplateSpec{
+			ObjectMeta: ksvc.Spec.Template.ObjectMeta,
+			Spec:       ksvc.Spec.Template.Spec.PodSpec,
+		}, nil
+	}
+	return nil, nil
+}
+
+// webhookDialTimeout is the max duration of the webhooks reachability check
+const webhookDialTimeout = 5 * time.Second
+
+// checkWebhooksReachable verifies that a TCP connection can be opened to each webhook address,
+// the address from the webhook secret takes precedence over the URL as for the webhook calls
+func (c *Controller) checkWebhooksReachable(canary *flaggerv1.Canary, _ string) (string, error) {
+	webhooks := canary.GetAnalysis().Webhooks
+	if len(webhooks) == 0 {
+		return "No webhooks to check", nil
+	}
+
+	// dial the webhooks in parallel so that the check takes at most the dial timeout
+	results := make([]string, len(webhooks))
+	var wg sync.WaitGroup
+	for i, webhook := range webhooks {
+		if _, err := c.webhookCredentials(canary, &webhook); err != nil {
+			results[i] = fmt.Sprintf("%s (%v)", webhook.Name, err)
+			continue
+		}
+		u, err := url.Parse(webhook.URL)
+		if err != nil {
+			results[i] = fmt.Sprintf("%s (invalid URL)", webhook.Name)
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, name string, address string) {
+			defer wg.Done()
+			conn, err := net.DialTimeout("tcp", address, webhookDialTimeout)
+			if err != nil {
+				results[i] = name
+				return
+			}
+			conn.Close()
+		}(i, webhook.Name, net.JoinHostPort(u.Hostname(), port))
+	}
+	wg.Wait()
+
+	var unreachable []string
+	for _, result := range results {
+		if result != "" {
+			unreachable = append(unreachable, result)
+		}
+	}
+	if len(unreachable) > 0 {
+		return "", fmt.Errorf("webhooks not reachable: %s", strings.Join(unreachable, ", "))
+	}
+	return fmt.Sprintf("%d webhooks are reachable", len(webhooks)), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	monitoringv1 "github.com/fluxcd/flagger/pkg/apis/monitoring/v1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+)
+
+func recordingRulesName(canary *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-recording-rules", canary.Name)
+}
+
+// recordedSeriesName returns the name of the series recorded for a builtin metric
+// e.g. flagger:canary_request_success_rate
+func recordedSeriesName(metric string) string {
+	return "flagger:canary_" + strings.ReplaceAll(metric, "-", "_")
+}
+
+// recordingRules returns a recording rule for each builtin metric query of the canary analysis
+// and maps the rendered queries to the selectors of the recorded series
+func recordingRules(canary *flaggerv1.Canary, metricsProvider string) ([]monitoringv1.Rule, map[string]string, error) {
+	var rules []monitoringv1.Rule
+	records := make(map[string]string)
+	for _, metric := range analysisMetrics(canary) {
+		if !isBuiltinMetric(metric.Name) {
+			continue
+		}
+		if metric.Interval == "" {
+			metric.Interval = canary.GetMetricInterval()
+		}
+
+		queries, err := observers.RenderQueries(metricsProvider, toMetricModel(canary, metric.Interval, metric.TemplateVariables))
+		if err != nil {
+			return nil, nil, fmt.Errorf("rendering %s query failed: %w", metric.Name, err)
+		}
+		query := queries[metric.Name]
+		if _, ok := records[query]; ok {
+			continue
+		}
+
+		series := recordedSeriesName(metric.Name)
+		rules = append(rules, monitoringv1.Rule{
+			Record: series,
+			Expr:   intstr.FromString(query),
+			Labels: map[string]string{
+				"namespace": canary.Namespace,
+				"canary":    canary.Name,
+				"interval":  metric.Interval,
+			},
+		})
+		records[query] = fmt.Sprintf(`%s{namespace="%s",canary="%s",interval="%s"}`,
+			series, canary.Namespace, canary.Name, metric.Interval)
+	}
+	return rules, records, nil
+}
+
+// reconcileRecordingRules creates or updates the PrometheusRule that precomputes
+// the builtin metric queries of the canary analysis
+func (c *Controller) reconcileRecordingRules(canary *flaggerv1.Canary) error {
+	spec := canary.GetAnalysis().RecordingRules
+	if spec == nil {
+		return nil
+	}
+
+	rules, _, err := recordingRules(canary, c.getMetricsProvider(canary))
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	name := recordingRulesName(canary)
+	newSpec := monitoringv1.PrometheusRuleSpec{
+		Groups: []monitoringv1.RuleGroup{
+			{
+				Name:     fmt.Sprintf("flagger-%s.%s", canary.Name, canary.Namespace),
+				Interval: spec.Interval,
+				Rules:    rules,
+			},
+		},
+	}
+
+	rule, err := c.flaggerClient.MonitoringV1().PrometheusRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		rule = &monitoringv1.PrometheusRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: canary.Namespace,
+				Labels:    spec.Labels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: newSpec,
+		}
+		_, err = c.flaggerClient.MonitoringV1().PrometheusRules(canary.Namespace).Create(context.TODO(), rule, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("PrometheusRule %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("PrometheusRule %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("PrometheusRule %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	specDiff := cmp.Diff(newSpec, rule.Spec, cmpopts.EquateEmpty())
+	labelsDiff := cmp.Diff(spec.Labels, rule.Labels, cmpopts.EquateEmpty())
+	if specDiff == "" && labelsDiff == "" {
+		return nil
+	}
+
+	clone := rule.DeepCopy()
+	clone.Spec = newSpec
+	clone.Labels = spec.Labels
+	_, err = c.flaggerClient.MonitoringV1().PrometheusRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("PrometheusRule %s.%s update error: %w", name, canary.Namespace, err)
+	}
+	c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("PrometheusRule %s.%s updated", name, canary.Namespace)
+	return nil
+}
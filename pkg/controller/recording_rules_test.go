@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_reconcileRecordingRules(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	cd := mocks.canary.DeepCopy()
+
+	// disabled
+	require.NoError(t, mocks.ctrl.reconcileRecordingRules(cd))
+	_, err := mocks.flaggerClient.MonitoringV1().PrometheusRules("default").Get(context.TODO(), "podinfo-recording-rules", metav1.GetOptions{})
+	require.Error(t, err)
+
+	// create
+	cd.Spec.Analysis.RecordingRules = &flaggerv1.CanaryRecordingRules{
+		Interval: "30s",
+		Labels:   map[string]string{"release": "prometheus"},
+	}
+	require.NoError(t, mocks.ctrl.reconcileRecordingRules(cd))
+
+	rule, err := mocks.flaggerClient.MonitoringV1().PrometheusRules("default").Get(context.TODO(), "podinfo-recording-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "prometheus", rule.Labels["release"])
+	assert.Equal(t, "podinfo", rule.OwnerReferences[0].Name)
+	require.Len(t, rule.Spec.Groups, 1)
+	assert.Equal(t, "30s", rule.Spec.Groups[0].Interval)
+
+	rules := rule.Spec.Groups[0].Rules
+	require.Len(t, rules, 2)
+	assert.Equal(t, "flagger:canary_request_success_rate", rules[0].Record)
+	assert.Equal(t, "flagger:canary_request_duration", rules[1].Record)
+	assert.Equal(t, map[string]string{"namespace": "default", "canary": "podinfo", "interval": "1m"}, rules[0].Labels)
+	assert.Contains(t, rules[0].Expr.String(), "istio_requests_total")
+
+	// update
+	cd.Spec.Analysis.Metrics[0].Interval = "2m"
+	cd.Spec.Analysis.RecordingRules.Labels = map[string]string{"release": "kube-prometheus-stack"}
+	require.NoError(t, mocks.ctrl.reconcileRecordingRules(cd))
+
+	rule, err = mocks.flaggerClient.MonitoringV1().PrometheusRules("default").Get(context.TODO(), "podinfo-recording-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "kube-prometheus-stack", rule.Labels["release"])
+	assert.Equal(t, "2m", rule.Spec.Groups[0].Rules[0].Labels["interval"])
+	assert.Contains(t, rule.Spec.Groups[0].Rules[0].Expr.String(), "[2m]")
+}
+
+func TestController_recordingRules(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: "request-success-rate", Interval: "1m"},
+		{Name: "request-success-rate", Interval: "1m", Threshold: 90},
+		{Name: "request-duration"},
+		{Name: "custom", Query: "up"},
+	}
+
+	rules, records, err := recordingRules(cd, flaggerv1.IstioProvider)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Len(t, records, 2)
+
+	// the metric interval defaults to the analysis interval
+	assert.Equal(t, "1m", rules[1].Labels["interval"])
+	assert.Equal(t, `flagger:canary_request_duration{namespace="default",canary="podinfo",interval="1m"}`,
+		records[rules[1].Expr.String()])
+}
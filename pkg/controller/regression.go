@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// baselineKey is the data key of the analysis baseline ConfigMap
+const baselineKey = "baseline.json"
+
+func baselineName(canary *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-analysis-baseline", canary.Name)
+}
+
+func hasRegression(canary *flaggerv1.Canary) bool {
+	return hasReport(canary) && canary.GetAnalysis().Report.Regression != nil
+}
+
+// isAnalysisStep returns true for the steps where the metrics are checked
+func isAnalysisStep(step flaggerv1.CanaryAnalysisStep) bool {
+	return step.Phase == flaggerv1.CanaryPhaseProgressing || step.Phase == flaggerv1.CanaryPhaseWaitingPromotion
+}
+
+// steadyStateWeight returns the highest canary weight of the analysis steps with metric samples
+func steadyStateWeight(steps []flaggerv1.CanaryAnalysisStep) (int, bool) {
+	weight, found := 0, false
+	for _, step := range steps {
+		if isAnalysisStep(step) && len(step.Metrics) > 0 && (!found || step.CanaryWeight > weight) {
+			weight, found = step.CanaryWeight, true
+		}
+	}
+	return weight, found
+}
+
+// summarizeMetrics returns the mean of each metric measured during the analysis steps at the canary weight
+func summarizeMetrics(steps []flaggerv1.CanaryAnalysisStep, weight int) map[string]float64 {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, step := range steps {
+		if !isAnalysisStep(step) || step.CanaryWeight != weight {
+			continue
+		}
+		for _, sample := range step.Metrics {
+			sums[sample.Name] += sample.Value
+			counts[sample.Name]++
+		}
+	}
+
+	summary := make(map[string]float64, len(sums))
+	for name, sum := range sums {
+		summary[name] = sum / float64(counts[name])
+	}
+	return summary
+}
+
+// higherIsBetter returns true for the metrics that regress when their value decreases,
+// the success rate and the metrics with only a min threshold or with an SLO
+func higherIsBetter(canary *flaggerv1.Canary, name string) bool {
+	if name == flaggerv1.BuiltinMetricRequestSuccessRate {
+		return true
+	}
+	for _, metric := range analysisMetrics(canary) {
+		if metric.Name != name {
+			continue
+		}
+		if metric.SLO != nil {
+			return true
+		}
+		if tr := metric.ThresholdRange; tr != nil && tr.Min != nil && tr.Max == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isRegression returns true when the value is worse than the baseline by more than the tolerance
+func isRegression(value, baseline float64, tolerance int, higherIsBetter bool) bool {
+	if baseline == 0 {
+		return false
+	}
+	change := (value - baseline) / baseline * 100
+	if higherIsBetter {
+		return change < -float64(tolerance)
+	}
+	return change > float64(tolerance)
+}
+
+// storeBaseline saves the summarized metrics of a successful rollout in a ConfigMap owned by the canary
+func (c *Controller) storeBaseline(canary *flaggerv1.Canary, report *flaggerv1.CanaryAnalysisReport) error {
+	baseline := flaggerv1.CanaryAnalysisBaseline{
+		Revision: report.Revision,
+		Images:   canary.Status.Images,
+		EndTime:  report.EndTime,
+		Metrics:  report.Summary,
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling baseline failed: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      baselineName(canary),
+			Namespace: canary.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			},
+		},
+		Data: map[string]string{baselineKey: string(data)},
+	}
+	_, err = c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		_, err = c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("configmap %s.%s upsert failed: %w", cm.Name, cm.Namespace, err)
+	}
+	return nil
+}
+
+// getBaseline returns the summarized metrics of the previous successful rollout, nil if there is none
+func (c *Controller) getBaseline(canary *flaggerv1.Canary) (*flaggerv1.CanaryAnalysisBaseline, error) {
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Get(context.TODO(), baselineName(canary), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configmap %s.%s get query error: %w", baselineName(canary), canary.Namespace, err)
+	}
+
+	baseline := &flaggerv1.CanaryAnalysisBaseline{}
+	if err := json.Unmarshal([]byte(cm.Data[baselineKey]), baseline); err != nil {
+		return nil, fmt.Errorf("configmap %s.%s decoding failed: %w", baselineName(canary), canary.Namespace, err)
+	}
+	return baseline, nil
+}
+
+// updateRegressionStatus adds the metric values measured by the analysis to the means kept in the canary status,
+// the means are discarded when the canary weight changes
+func updateRegressionStatus(status *flaggerv1.CanaryRegressionStatus, weight int, values map[string]float64) *flaggerv1.CanaryRegressionStatus {
+	result := &flaggerv1.CanaryRegressionStatus{CanaryWeight: weight}
+	if status != nil && status.CanaryWeight == weight {
+		result = status.DeepCopy()
+	}
+
+	for name, value := range values {
+		found := false
+		for i := range result.Metrics {
+			metric := &result.Metrics[i]
+			if metric.Name == name {
+				metric.Mean = (metric.Mean*float64(metric.Samples) + value) / float64(metric.Samples+1)
+				metric.Samples++
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Metrics = append(result.Metrics, flaggerv1.CanaryRegressionMetric{Name: name, Mean: value, Samples: 1})
+		}
+	}
+	sort.Slice(result.Metrics, func(i, j int) bool {
+		return result.Metrics[i].Name < result.Metrics[j].Name
+	})
+	return result
+}
+
+// runRegressionChecks compares the metrics measured at the max weight (or during the iterations)
+// with the previous successful release, the means and the reported regressions are kept in the
+// canary status so that the checks survive a restart, the advancement is halted only if the canary opted in
+func (c *Controller) runRegressionChecks(canary *flaggerv1.Canary, canaryController canary.Controller, maxWeight int) bool {
+	if !hasRegression(canary) {
+		return true
+	}
+	if canary.GetAnalysis().Iterations == 0 && canary.Status.CanaryWeight < maxWeight {
+		return true
+	}
+
+	baseline, err := c.getBaseline(canary)
+	if err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return true
+	}
+	if baseline == nil {
+		return true
+	}
+
+	regression := canary.GetAnalysis().Report.Regression
+	tolerance := regression.GetTolerance()
+	status := updateRegressionStatus(canary.Status.Regression, canary.Status.CanaryWeight, c.getMetricValues(canary))
+
+	passed := true
+	for i := range status.Metrics {
+		metric := &status.Metrics[i]
+		base, ok := baseline.Metrics[metric.Name]
+		if !ok || !isRegression(metric.Mean, base, tolerance, higherIsBetter(canary, metric.Name)) {
+			continue
+		}
+
+		if regression.Halt {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f regressed from %.2f of revision %s (tolerance %d%%)",
+				canary.Name, canary.Namespace, metric.Name, metric.Mean, base, baseline.Revision, tolerance)
+			passed = false
+		}
+		if !metric.Regressed {
+			metric.Regressed = true
+			if !regression.Halt {
+				c.recordEventWarningf(canary, "Regression of %s.%s %s %.2f from %.2f of revision %s (tolerance %d%%)",
+					canary.Name, canary.Namespace, metric.Name, metric.Mean, base, baseline.Revision, tolerance)
+			}
+			c.alert(canary, fmt.Sprintf("Metric %s regressed to %.2f from %.2f of the previous release", metric.Name, metric.Mean, base),
+				true, flaggerv1.SeverityWarn)
+		}
+	}
+
+	if err := canaryController.SetStatusRegression(canary, status); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
+	canary.Status.Regression = status
+	return passed
+}
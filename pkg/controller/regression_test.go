@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DeploymentRegressionBaseline(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Report = &flaggerv1.CanaryReport{
+		Regression: &flaggerv1.CanaryRegression{},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	for i := 0; i < 10; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
+
+	baseline, err := mocks.ctrl.getBaseline(mocks.canary)
+	require.NoError(t, err)
+	require.NotNil(t, baseline)
+	assert.NotEmpty(t, baseline.Revision)
+	assert.Contains(t, baseline.Metrics, flaggerv1.BuiltinMetricRequestSuccessRate)
+	assert.Contains(t, baseline.Metrics, flaggerv1.BuiltinMetricRequestDuration)
+}
+
+func TestScheduler_RegressionChecks(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Report = &flaggerv1.CanaryReport{
+		Regression: &flaggerv1.CanaryRegression{Halt: true},
+	}
+	mocks := newDeploymentFixture(cd)
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	cd.Status.CanaryWeight = 50
+	mocks.ctrl.recordMetric(cd, flaggerv1.BuiltinMetricRequestSuccessRate, 99.5)
+	mocks.ctrl.recordMetric(cd, flaggerv1.BuiltinMetricRequestDuration, 200)
+
+	storeBaseline := func(metrics map[string]float64) {
+		data, err := json.Marshal(flaggerv1.CanaryAnalysisBaseline{Revision: "v1", Metrics: metrics})
+		require.NoError(t, err)
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-analysis-baseline", Namespace: "default"},
+			Data:       map[string]string{baselineKey: string(data)},
+		}
+		_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Create(context.TODO(), cm, metav1.CreateOptions{})
+		if err != nil {
+			_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), cm, metav1.UpdateOptions{})
+		}
+		require.NoError(t, err)
+	}
+
+	// no baseline
+	assert.True(t, mocks.ctrl.runRegressionChecks(cd, mocks.deployer, 50))
+
+	// the request duration mean is within the tolerance
+	storeBaseline(map[string]float64{
+		flaggerv1.BuiltinMetricRequestSuccessRate: 99.9,
+		flaggerv1.BuiltinMetricRequestDuration:    190,
+	})
+	assert.True(t, mocks.ctrl.runRegressionChecks(cd, mocks.deployer, 50))
+
+	// the request duration mean increased by 100%
+	storeBaseline(map[string]float64{
+		flaggerv1.BuiltinMetricRequestSuccessRate: 99.9,
+		flaggerv1.BuiltinMetricRequestDuration:    100,
+	})
+	assert.False(t, mocks.ctrl.runRegressionChecks(cd, mocks.deployer, 50))
+
+	// the means and the reported regressions are kept in the canary status
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.Regression)
+	assert.Equal(t, 50, c.Status.Regression.CanaryWeight)
+	assert.Equal(t, []flaggerv1.CanaryRegressionMetric{
+		{Name: flaggerv1.BuiltinMetricRequestDuration, Mean: 200, Samples: 2, Regressed: true},
+		{Name: flaggerv1.BuiltinMetricRequestSuccessRate, Mean: 99.5, Samples: 2},
+	}, c.Status.Regression.Metrics)
+
+	// the gate still halts the advancement after a restart
+	mocks.ctrl.resetMetricValues(c)
+	c.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	c.Status.CanaryWeight = 50
+	assert.False(t, mocks.ctrl.runRegressionChecks(c, mocks.deployer, 50))
+
+	// the metrics are compared only at the max weight
+	assert.True(t, mocks.ctrl.runRegressionChecks(c, mocks.deployer, 60))
+}
+
+func TestUpdateRegressionStatus(t *testing.T) {
+	status := updateRegressionStatus(nil, 50, map[string]float64{"latency": 100})
+	status = updateRegressionStatus(status, 50, map[string]float64{"latency": 200})
+	assert.Equal(t, []flaggerv1.CanaryRegressionMetric{{Name: "latency", Mean: 150, Samples: 2}}, status.Metrics)
+
+	// the means are discarded when the weight changes
+	status = updateRegressionStatus(status, 60, map[string]float64{"latency": 300})
+	assert.Equal(t, 60, status.CanaryWeight)
+	assert.Equal(t, []flaggerv1.CanaryRegressionMetric{{Name: "latency", Mean: 300, Samples: 1}}, status.Metrics)
+}
+
+func TestIsRegression(t *testing.T) {
+	assert.True(t, isRegression(120, 100, 10, false))
+	assert.False(t, isRegression(105, 100, 10, false))
+	assert.False(t, isRegression(80, 100, 10, false))
+	assert.True(t, isRegression(80, 100, 10, true))
+	assert.False(t, isRegression(95, 100, 10, true))
+	assert.False(t, isRegression(10, 0, 10, false))
+}
+
+func TestSummarizeMetrics(t *testing.T) {
+	steps := []flaggerv1.CanaryAnalysisStep{
+		{
+			Phase:        flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight: 10,
+			Metrics:      []flaggerv1.CanaryMetricSample{{Name: "latency", Value: 500}},
+		},
+		{
+			Phase:        flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight: 20,
+			Metrics:      []flaggerv1.CanaryMetricSample{{Name: "latency", Value: 100}},
+		},
+		{
+			Phase:        flaggerv1.CanaryPhaseWaitingPromotion,
+			CanaryWeight: 20,
+			Metrics:      []flaggerv1.CanaryMetricSample{{Name: "latency", Value: 200}},
+		},
+		{
+			Phase:        flaggerv1.CanaryPhasePromoting,
+			CanaryWeight: 100,
+		},
+	}
+
+	weight, ok := steadyStateWeight(steps)
+	require.True(t, ok)
+	assert.Equal(t, 20, weight)
+	assert.Equal(t, map[string]float64{"latency": 150}, summarizeMetrics(steps, weight))
+}
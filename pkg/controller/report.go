@@ -0,0 +1,230 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// reportKey is the data key of the analysis report ConfigMap
+const reportKey = "report.json"
+
+func reportName(canary *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-analysis-report", canary.Name)
+}
+
+func hasReport(canary *flaggerv1.Canary) bool {
+	return canary.GetAnalysis() != nil && canary.GetAnalysis().Report != nil
+}
+
+// startReport discards the report of the previous rollout and starts a new one
+func (c *Controller) startReport(canary *flaggerv1.Canary) {
+	if !hasReport(canary) {
+		return
+	}
+	c.reports.Store(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace), &flaggerv1.CanaryAnalysisReport{
+		Name:      canary.Name,
+		Namespace: canary.Namespace,
+		StartTime: metav1.Now(),
+	})
+}
+
+// currentReportStep returns the step matching the canary status, a new step is added
+// when the phase, weight or iterations changed since the last recorded step
+func (c *Controller) currentReportStep(canary *flaggerv1.Canary) *flaggerv1.CanaryAnalysisStep {
+	if !hasReport(canary) || !isRunningAnalysis(canary) {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	value, ok := c.reports.Load(key)
+	if !ok {
+		// the rollout started before Flagger was restarted
+		value = &flaggerv1.CanaryAnalysisReport{
+			Name:       canary.Name,
+			Namespace:  canary.Namespace,
+			StartTime:  metav1.Now(),
+			Incomplete: true,
+		}
+		c.reports.Store(key, value)
+	}
+	report := value.(*flaggerv1.CanaryAnalysisReport)
+
+	if n := len(report.Steps); n > 0 {
+		last := &report.Steps[n-1]
+		if last.Phase == canary.Status.Phase && last.CanaryWeight == canary.Status.CanaryWeight &&
+			last.Iterations == canary.Status.Iterations {
+			return last
+		}
+	}
+
+	report.Steps = append(report.Steps, flaggerv1.CanaryAnalysisStep{
+		Phase:        canary.Status.Phase,
+		CanaryWeight: canary.Status.CanaryWeight,
+		Iterations:   canary.Status.Iterations,
+		StartTime:    metav1.Now(),
+	})
+	return &report.Steps[len(report.Steps)-1]
+}
+
+// reportMetric adds the metric value to the current step of the report
+func (c *Controller) reportMetric(canary *flaggerv1.Canary, name string, value float64) {
+	step := c.currentReportStep(canary)
+	if step == nil {
+		return
+	}
+	step.Metrics = append(step.Metrics, flaggerv1.CanaryMetricSample{
+		Name:  name,
+		Value: value,
+		Time:  metav1.Now(),
+	})
+}
+
+// reportWebhook adds the webhook outcome to the current step of the report,
+// consecutive calls with the same outcome are counted as attempts
+func (c *Controller) reportWebhook(canary *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook, err error) {
+	step := c.currentReportStep(canary)
+	if step == nil {
+		return
+	}
+
+	outcome := flaggerv1.CanaryWebhookOutcome{
+		Name:     webhook.Name,
+		Type:     webhook.Type,
+		Passed:   err == nil,
+		Attempts: 1,
+		Time:     metav1.Now(),
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+
+	if n := len(step.Webhooks); n > 0 {
+		last := &step.Webhooks[n-1]
+		if last.Name == outcome.Name && last.Passed == outcome.Passed && last.Error == outcome.Error {
+			last.Attempts++
+			last.Time = outcome.Time
+			return
+		}
+	}
+	step.Webhooks = append(step.Webhooks, outcome)
+}
+
+// finishReport completes the report with the rollout outcome and publishes it
+func (c *Controller) finishReport(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) {
+	if !hasReport(canary) {
+		return
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	report := &flaggerv1.CanaryAnalysisReport{
+		Name:       canary.Name,
+		Namespace:  canary.Namespace,
+		StartTime:  metav1.Now(),
+		Incomplete: true,
+	}
+	if value, ok := c.reports.LoadAndDelete(key); ok {
+		report = value.(*flaggerv1.CanaryAnalysisReport)
+	}
+
+	report.Revision = canary.Status.LastAppliedSpec
+	report.Phase = phase
+	report.EndTime = metav1.Now()
+	report.Duration = report.EndTime.Sub(report.StartTime.Time).Round(time.Second).String()
+	for i := range report.Steps {
+		end := report.EndTime
+		if i+1 < len(report.Steps) {
+			end = report.Steps[i+1].StartTime
+		}
+		report.Steps[i].Duration = end.Sub(report.Steps[i].StartTime.Time).Round(time.Second).String()
+	}
+	if report.Steps == nil {
+		report.Steps = []flaggerv1.CanaryAnalysisStep{}
+	}
+	if weight, ok := steadyStateWeight(report.Steps); ok {
+		report.Summary = summarizeMetrics(report.Steps, weight)
+	}
+
+	if err := c.publishReport(canary, report); err != nil {
+		c.recordEventWarningf(canary, "Analysis report publishing failed: %v", err)
+	}
+
+	// keep the metrics of the successful rollouts to detect the regressions of the next ones
+	if hasRegression(canary) && phase == flaggerv1.CanaryPhaseSucceeded && len(report.Summary) > 0 {
+		if err := c.storeBaseline(canary, report); err != nil {
+			c.recordEventWarningf(canary, "Analysis baseline update failed: %v", err)
+		}
+	}
+}
+
+// publishReport stores the report in a ConfigMap owned by the canary and posts it to the report URL
+func (c *Controller) publishReport(canary *flaggerv1.Canary, report *flaggerv1.CanaryAnalysisReport) error {
+	spec := canary.GetAnalysis().Report
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling report failed: %w", err)
+	}
+
+	if spec.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      reportName(canary),
+				Namespace: canary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Data: map[string]string{reportKey: string(data)},
+		}
+		_, err := c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = c.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("configmap %s.%s upsert failed: %w", cm.Name, cm.Namespace, err)
+		}
+	}
+
+	if spec.URL != "" || spec.SecretRef != nil {
+		hook := flaggerv1.CanaryWebhook{URL: spec.URL, SecretRef: spec.SecretRef}
+		credentials, err := c.webhookCredentials(canary, &hook)
+		if err != nil {
+			return err
+		}
+		if err := callWebhook(hook.URL, report, spec.Timeout, credentials); err != nil {
+			return fmt.Errorf("posting report to %s failed: %w", hook.URL, err)
+		}
+	}
+
+	c.recordEventInfof(canary, "Analysis report published for %s.%s revision %s",
+		canary.Name, canary.Namespace, report.Revision)
+	return nil
+}
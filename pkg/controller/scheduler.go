@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
@@ -86,6 +87,15 @@ func (c *Controller) nextStepWeight(canary *flaggerv1.Canary, canaryWeight int)
 	return maxStep
 }
 
+// nextTrafficWeight returns the canary weight set by the next step,
+// once the max weight is reached the next step is the promotion and the total weight is returned
+func (c *Controller) nextTrafficWeight(canary *flaggerv1.Canary, canaryWeight int, maxWeight int) int {
+	if canaryWeight >= maxWeight {
+		return c.totalWeight(canary)
+	}
+	return c.min(canaryWeight+c.nextStepWeight(canary, canaryWeight), c.totalWeight(canary))
+}
+
 // scheduleCanaries synchronises the canary map with the jobs map,
 // for new canaries new jobs are created and started
 // for the removed canaries the jobs are stopped and deleted
@@ -163,6 +173,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			Errorf("Canary %s.%s not found", name, namespace)
 		return
 	}
+	cd = c.applyCanaryDefaults(cd)
 
 	if cd.Spec.Suspend {
 		msg := "skipping canary run as object is suspended"
@@ -172,14 +183,21 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// list the other canaries of the group once per run
+	members, err := c.getGroupMembers(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
 	// override the global provider if one is specified in the canary spec
-	provider := c.meshProvider
+	provider := c.getMeshProvider()
 	if cd.Spec.Provider != "" {
 		provider = cd.Spec.Provider
 	}
 
 	// init controller based on target kind
-	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
+	canaryController := c.canaryFactory.TargetGroupController(cd)
 
 	labelSelector, labelValue, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
@@ -208,8 +226,15 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
+	// precompute the builtin metric queries with Prometheus recording rules
+	if !cd.SkipAnalysis() {
+		if err := c.reconcileRecordingRules(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+	}
+
 	// init mesh router
-	meshRouter := c.routerFactory.MeshRouter(provider, labelSelector)
+	meshRouter := c.routerFactory.MeshRouter(provider, labelSelector, labelValue)
 
 	// register the AppMesh VirtualNodes before creating the primary deployment
 	// otherwise the pods will not be injected with the Envoy proxy
@@ -242,8 +267,17 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
-	// change the apex service pod selector to primary
-	if err := kubeRouter.Reconcile(cd); err != nil {
+	// change the apex service pod selector to primary,
+	// the selector switch router manages the apex service selector during the analysis
+	if provider != flaggerv1.SelectorSwitchProvider {
+		if err := kubeRouter.Reconcile(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+	}
+
+	// route the target group members to their primaries
+	if err := c.reconcileTargetGroupServices(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
 		return
 	}
@@ -257,6 +291,12 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
+	// scale down the target once the primary serves the traffic
+	if err := c.scaleDownTarget(cd, canaryController, labelSelector, labelValue); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+
 	// set canary phase to initialized and sync the status
 	if err = c.setPhaseInitialized(cd, canaryController); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
@@ -270,7 +310,16 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// report stalled rollouts
+	c.checkStuck(cd)
+
 	if !shouldAdvance {
+		if _, _, _, err := c.getValidatedRoutes(cd, meshRouter); err != nil {
+			c.recordRouteWarningf(cd, "%v", err)
+		}
+		if !c.checkRollbackRevision(cd, canaryController) {
+			c.checkRollbackWindow(cd, canaryController)
+		}
 		c.recorder.SetStatus(cd, cd.Status.Phase)
 		return
 	}
@@ -283,10 +332,14 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			c.recordEventWarningf(cd, "%v", err)
 			return
 		}
+		if err := c.isPrimaryTrafficReady(cd, labelSelector, labelValue); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
 	}
 
 	// get the routing settings
-	primaryWeight, canaryWeight, mirrored, err := meshRouter.GetRoutes(cd)
+	primaryWeight, canaryWeight, mirrored, err := c.getValidatedRoutes(cd, meshRouter)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
 		return
@@ -294,6 +347,11 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
 
+	// don't start a new rollout while an incident is open
+	if isRolloutStarting(cd, shouldAdvance) && c.isFrozen(cd) {
+		return
+	}
+
 	// check if canary analysis should start (canary revision has changes) or continue
 	if ok := c.checkCanaryStatus(cd, canaryController, scalerReconciler, shouldAdvance); !ok {
 		return
@@ -344,7 +402,41 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		cd.Status.Phase == flaggerv1.CanaryPhaseWaitingPromotion {
 		if ok := c.runRollbackHooks(cd, cd.Status.Phase); ok {
 			c.recordEventWarningf(cd, "Rolling back %s.%s manual webhook invoked", cd.Name, cd.Namespace)
-			c.alert(cd, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn)
+			c.alertWithFields(cd, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn,
+				phaseField(flaggerv1.CanaryPhaseFailed))
+			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
+			return
+		}
+		if member := c.getFailedGroupMember(cd, members); member != nil {
+			c.recordEventWarningf(cd, "Rolling back %s.%s %s failed",
+				cd.Name, cd.Namespace, groupDescription(cd, member))
+			c.alertWithFields(cd, fmt.Sprintf("Rolling back %s failed", groupDescription(cd, member)),
+				false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
+			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
+			return
+		}
+		if c.hasExceededMaxDuration(cd) {
+			c.recordEventWarningf(cd, "Rolling back %s.%s max duration %s exceeded",
+				cd.Name, cd.Namespace, cd.GetAnalysisMaxDuration())
+			c.alertWithFields(cd, fmt.Sprintf("Rolling back max duration %s exceeded", cd.GetAnalysisMaxDuration()),
+				false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
+			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
+			return
+		}
+	}
+
+	// restore the primary of a promoting canary if another canary of the group failed
+	if cd.Status.Phase == flaggerv1.CanaryPhasePromoting || cd.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		if member := c.getFailedGroupMember(cd, members); member != nil {
+			c.recordEventWarningf(cd, "Rolling back %s.%s promotion %s failed",
+				cd.Name, cd.Namespace, groupDescription(cd, member))
+			// the revision replaced by the promotion is looked up by hash so that a retry can't restore the new one
+			if err := canaryController.RollbackPrimary(cd, cd.Status.LastPromotedSpec); err != nil {
+				c.recordEventWarningf(cd, "Primary %s.%s can't be restored to the previous revision: %v",
+					cd.Spec.TargetRef.Name, cd.Namespace, err)
+			}
+			c.alertWithFields(cd, fmt.Sprintf("Rolling back promotion %s failed", groupDescription(cd, member)),
+				false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
 			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
 			return
 		}
@@ -375,6 +467,14 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 
+		// reset the failed checks counter for the post-promotion checks
+		if cd.GetAnalysis().RollbackWindow != nil {
+			if err := canaryController.SetStatusFailedChecks(cd, 0); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return
+			}
+		}
+
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
@@ -382,9 +482,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
 		c.runPostRolloutHooks(cd, flaggerv1.CanaryPhaseSucceeded)
-		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
-		c.alert(cd, "Canary analysis completed successfully, promotion finished.",
-			false, flaggerv1.SeverityInfo)
+		c.finishReport(cd, flaggerv1.CanaryPhaseSucceeded)
+		c.recordEventInfof(cd, "Promotion completed! Scaling down %s.%s%s", cd.Spec.TargetRef.Name, cd.Namespace, imagesSuffix(cd))
+		c.alertWithFields(cd, "Canary analysis completed successfully, promotion finished.",
+			false, flaggerv1.SeverityInfo, phaseField(flaggerv1.CanaryPhaseSucceeded))
 		return
 	}
 
@@ -394,13 +495,26 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if !retriable {
 			c.recordEventWarningf(cd, "Rolling back %s.%s progress deadline exceeded %v",
 				cd.Name, cd.Namespace, err)
-			c.alert(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
-				false, flaggerv1.SeverityError)
+			c.alertWithFields(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
+				false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
 		}
 		c.rollback(cd, canaryController, meshRouter, scalerReconciler)
 		return
 	}
 
+	// halt the analysis if the canary pods are not part of the mesh
+	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing && !c.isSidecarRunning(cd, provider, labelSelector, labelValue) {
+		return
+	}
+
+	// run a copy of the primary sized as the canary, the Istio router sends it the same share as the canary
+	if cd.GetAnalysis().Baseline && provider == flaggerv1.IstioProvider && cd.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+		if err := canaryController.ReconcileBaseline(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+	}
+
 	// record analysis duration
 	defer func() {
 		c.recorder.SetDuration(cd, time.Since(begin))
@@ -410,7 +524,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// skip check if no traffic is routed or mirrored to canary
 	if canaryWeight == 0 && cd.Status.Iterations == 0 &&
 		!(cd.GetAnalysis().Mirror && mirrored) {
-		c.recordEventInfof(cd, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
+		c.recordEventInfof(cd, "Starting canary analysis for %s.%s%s", cd.Spec.TargetRef.Name, cd.Namespace, imagesSuffix(cd))
 
 		// run pre-rollout web hooks
 		if ok := c.runPreRolloutHooks(cd); !ok {
@@ -420,27 +534,66 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 	} else {
+		// hold the current step until the metrics have enough samples
+		if ok, err := c.runSampleSizeChecks(cd); err != nil {
+			c.recordEventErrorf(cd, "%v", err)
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return
+		} else if !ok {
+			return
+		}
+
 		if ok := c.runAnalysis(cd); !ok {
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 			}
 			return
 		}
+
+		// compare the steady-state metrics with the previous successful release
+		if ok := c.runRegressionChecks(cd, canaryController, maxWeight); !ok {
+			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return
+		}
+		c.decayFailedChecks(cd, canaryController)
 	}
 
-	// use blue/green strategy for kubernetes provider
-	if provider == flaggerv1.KubernetesProvider {
+	// hold the traffic shifting and the promotion while an incident is open,
+	// the analysis keeps running so that a failing canary is rolled back
+	if c.isFrozen(cd) {
+		return
+	}
+
+	// hold the current step until the other canaries of the group catch up
+	if !c.isGroupInStep(cd, members) {
+		return
+	}
+
+	// use blue/green strategy for kubernetes and selector switch providers
+	if provider == flaggerv1.KubernetesProvider || provider == flaggerv1.SelectorSwitchProvider {
 		if len(cd.GetAnalysis().Match) > 0 {
-			c.recordEventWarningf(cd, "A/B testing is not supported when using the kubernetes provider")
+			c.recordEventWarningf(cd, "A/B testing is not supported when using the %s provider", provider)
 			cd.GetAnalysis().Match = nil
 		}
 		if cd.GetAnalysis().Iterations < 1 {
-			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the kubernetes provider")
+			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the %s provider", provider)
 			c.recordEventWarningf(cd, "Setting canaryAnalysis.iterations: 10")
 			cd.GetAnalysis().Iterations = 10
 		}
 	}
 
+	// traffic mirroring is implemented only by the Istio router
+	if cd.GetAnalysis().Mirror {
+		if _, ok := meshRouter.(*router.IstioRouter); !ok {
+			c.recordEventWarningf(cd, "Traffic mirroring is not supported when using the %s provider", provider)
+			cd.GetAnalysis().Mirror = false
+		}
+	}
+
 	// strategy: A/B testing
 	if len(cd.GetAnalysis().Match) > 0 && cd.GetAnalysis().Iterations > 0 {
 		c.runAB(cd, canaryController, meshRouter)
@@ -453,11 +606,23 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// strategy: multi-armed bandit traffic allocation (experimental)
+	if cd.GetAnalysis().Bandit != nil {
+		c.runBandit(cd, canaryController, meshRouter, canaryWeight, primaryWeight, maxWeight)
+		return
+	}
+
+	// strategy: time-based promotion
+	if cd.GetAnalysis().Soak != nil {
+		c.runSoak(cd, canaryController, meshRouter, canaryWeight, primaryWeight)
+		return
+	}
+
 	// strategy: Canary progressive traffic increase
 	if c.nextStepWeight(cd, canaryWeight) > 0 {
 		// run hook only if traffic is not mirrored
 		if !mirrored {
-			if promote := c.runConfirmTrafficIncreaseHooks(cd); !promote {
+			if promote := c.runConfirmTrafficIncreaseHooks(cd, c.nextTrafficWeight(cd, canaryWeight, maxWeight)); !promote {
 				return
 			}
 		}
@@ -466,6 +631,76 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 }
 
+// getValidatedRoutes returns the routing settings after checking that the weights sum up to the total weight
+// and match the canary status, drifted weights are reset and a warning event is emitted
+func (c *Controller) getValidatedRoutes(canary *flaggerv1.Canary, meshRouter router.Interface) (int, int, bool, error) {
+	primaryWeight, canaryWeight, mirrored, err := meshRouter.GetRoutes(canary)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	// the Kubernetes provider has no routes
+	if _, ok := meshRouter.(*router.NopRouter); ok {
+		return primaryWeight, canaryWeight, mirrored, nil
+	}
+
+	totalWeight := c.totalWeight(canary)
+	expectedPrimary, expectedCanary, ok := c.expectedRoutes(canary)
+	if !ok {
+		if primaryWeight+canaryWeight == totalWeight {
+			c.routeWarnings.Delete(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace))
+			return primaryWeight, canaryWeight, mirrored, nil
+		}
+		// keep the canary weight and adjust the primary one
+		expectedCanary = c.min(totalWeight, canaryWeight)
+		if expectedCanary < 0 {
+			expectedCanary = 0
+		}
+		expectedPrimary = totalWeight - expectedCanary
+	}
+
+	if primaryWeight == expectedPrimary && canaryWeight == expectedCanary {
+		c.routeWarnings.Delete(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace))
+		return primaryWeight, canaryWeight, mirrored, nil
+	}
+
+	if err := meshRouter.SetRoutes(canary, expectedPrimary, expectedCanary, mirrored); err != nil {
+		return 0, 0, false, err
+	}
+	c.recordRouteWarningf(canary, "Route weights drift detected for %s.%s, reset primary weight %d -> %d and canary weight %d -> %d",
+		canary.Name, canary.Namespace, primaryWeight, expectedPrimary, canaryWeight, expectedCanary)
+
+	return expectedPrimary, expectedCanary, mirrored, nil
+}
+
+// recordRouteWarningf emits a warning event about the canary routes only when it differs from the last one,
+// so that a persistent drift or routing error doesn't produce an event on every tick
+func (c *Controller) recordRouteWarningf(canary *flaggerv1.Canary, template string, args ...interface{}) {
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	msg := fmt.Sprintf(template, args...)
+	if prev, ok := c.routeWarnings.Load(key); ok && prev.(string) == msg {
+		return
+	}
+	c.routeWarnings.Store(key, msg)
+	c.recordEventWarningf(canary, "%s", msg)
+}
+
+// expectedRoutes returns the primary and canary weights inferred from the canary status,
+// ok is false when the phase or the deployment strategy doesn't determine the weights
+func (c *Controller) expectedRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, ok bool) {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseInitialized, flaggerv1.CanaryPhaseSucceeded, flaggerv1.CanaryPhaseFailed:
+		return c.totalWeight(canary), 0, true
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion:
+		// A/B testing and Blue/Green don't record the weight in status
+		if canary.GetAnalysis().Iterations > 0 || len(canary.GetAnalysis().Match) > 0 {
+			return 0, 0, false
+		}
+		return c.totalWeight(canary) - canary.Status.CanaryWeight, canary.Status.CanaryWeight, true
+	}
+	return 0, 0, false
+}
+
 func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryController canary.Controller,
 	meshRouter router.Interface, provider string, canaryWeight int, primaryWeight int) {
 	// finalize promotion since no traffic shifting is possible for Kubernetes CNI
@@ -570,12 +805,41 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 		}
 
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
-		c.recordEventInfof(canary, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
+		c.recordEventInfof(canary, "Advance %s.%s canary weight %v%s", canary.Name, canary.Namespace, canaryWeight, imagesSuffix(canary))
 		return
 	}
 
 	// promote canary - max weight reached
 	if canaryWeight >= maxWeight {
+		// expand the canary to the next locality and restart the traffic shifting,
+		// only the Istio router implements locality routing
+		_, isIstio := meshRouter.(*router.IstioRouter)
+		if localities := canary.GetAnalysis().Localities; isIstio && canary.Status.Locality < len(localities)-1 {
+			locality := canary.Status.Locality + 1
+			if err := canaryController.SetStatusLocality(canary, locality); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+			canary.Status.Locality = locality
+
+			canaryWeight = c.nextStepWeight(canary, 0)
+			primaryWeight = c.totalWeight(canary) - canaryWeight
+			if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+
+			if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return
+			}
+
+			c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+			c.recordEventInfof(canary, "Expanding %s.%s canary to locality %s",
+				canary.Name, canary.Namespace, localities[locality].Name)
+			return
+		}
+
 		// check promotion gate
 		if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
 			return
@@ -613,8 +877,8 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
-		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
-			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
+		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v%s",
+			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations, imagesSuffix(canary))
 		return
 	}
 
@@ -647,20 +911,20 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 	// increment iterations
 	if canary.GetAnalysis().Iterations > canary.Status.Iterations {
 		// If in "mirror" mode, mirror requests during the entire B/G canary test
-		if provider != "kubernetes" &&
-			canary.GetAnalysis().Mirror && !mirrored {
+		// so that the analysis runs against the live traffic shape before the cutover
+		if canary.GetAnalysis().Mirror && !mirrored {
 			if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, true); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
+				return
 			}
-			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-				Infof("Start traffic mirroring")
+			c.recordEventInfof(canary, "Start traffic mirroring to %s.%s", canary.Spec.TargetRef.Name, canary.Namespace)
 		}
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 			return
 		}
-		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
-			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
+		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v%s",
+			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations, imagesSuffix(canary))
 		return
 	}
 
@@ -710,11 +974,59 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 
 }
 
+// runSoak holds the canary at the soak weight and promotes it once the soak duration
+// has elapsed, a failed check during the soak triggers a rollback
+func (c *Controller) runSoak(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, canaryWeight int, primaryWeight int) {
+	soak := canary.GetAnalysis().Soak
+	if canaryWeight != soak.Weight {
+		c.setCanaryWeight(canary, canaryController, meshRouter, soak.Weight,
+			fmt.Sprintf("Advance %s.%s canary weight %v, soaking for %s", canary.Name, canary.Namespace, soak.Weight, soak.Duration))
+		return
+	}
+
+	// refuse to promote when the soak duration can't be determined
+	duration, err := canary.GetAnalysisSoakDuration()
+	if err != nil {
+		c.recordEventWarningf(canary, "Halt advancement %s.%s: %v", canary.Name, canary.Namespace, err)
+		return
+	}
+
+	// the soak starts when the canary weight is set
+	elapsed := time.Since(canary.Status.LastProgressTime.Time)
+	if elapsed < duration {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Soaking canary weight %v, %s remaining", canaryWeight, (duration - elapsed).Round(time.Second))
+		return
+	}
+
+	// promote canary - soak completed
+	c.runCanary(canary, canaryController, meshRouter, false, canaryWeight, primaryWeight, canaryWeight)
+}
+
+// setCanaryWeight routes the given weight to the canary and records it in the status
+func (c *Controller) setCanaryWeight(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, canaryWeight int, message string) {
+	primaryWeight := c.totalWeight(canary) - canaryWeight
+	if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+	c.recordEventInfof(canary, "%s%s", message, imagesSuffix(canary))
+}
+
 func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 	// run external checks
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callCanaryWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
@@ -733,9 +1045,59 @@ func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
 		return ok
 	}
 
+	ok = c.runTargetGroupMetricChecks(canary)
+	if !ok {
+		return ok
+	}
+
 	return true
 }
 
+// checkStreak counts the consecutive successful checks since the failed checks counter last changed
+type checkStreak struct {
+	successes    int
+	failedChecks int
+}
+
+// decayFailedChecks decrements the failed checks counter after the configured number
+// of consecutive successful checks, any failed check in between restarts the streak
+func (c *Controller) decayFailedChecks(canary *flaggerv1.Canary, canaryController canary.Controller) {
+	decay := canary.GetAnalysis().FailedChecksDecay
+	if decay < 1 {
+		return
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	var streak checkStreak
+	if value, ok := c.checkStreaks.Load(key); ok {
+		streak = value.(checkStreak)
+	}
+	if canary.Status.FailedChecks != streak.failedChecks {
+		streak = checkStreak{failedChecks: canary.Status.FailedChecks}
+	}
+	if streak.failedChecks > 0 {
+		streak.successes++
+	}
+
+	if streak.successes >= decay {
+		failedChecks := canary.Status.FailedChecks - 1
+		if err := canaryController.SetStatusFailedChecks(canary, failedChecks); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
+		canary.Status.FailedChecks = failedChecks
+		c.recordEventInfof(canary, "Decreasing %s.%s failed checks to %v after %v successful checks",
+			canary.Name, canary.Namespace, failedChecks, decay)
+		streak = checkStreak{failedChecks: failedChecks}
+	}
+	c.checkStreaks.Store(key, streak)
+}
+
+// resetCheckStreak discards the successful checks counted during the previous analysis
+func (c *Controller) resetCheckStreak(canary *flaggerv1.Canary) {
+	c.checkStreaks.Delete(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace))
+}
+
 func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, scalerReconciler canary.ScalerReconciler, err error, retriable bool) bool {
 	if !canary.SkipAnalysis() {
 		return false
@@ -744,7 +1106,8 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 	// regardless if analysis is being skipped, rollback if canary failed to progress
 	if !retriable {
 		c.recordEventWarningf(canary, "Rolling back %s.%s progress deadline exceeded %v", canary.Name, canary.Namespace, err)
-		c.alert(canary, fmt.Sprintf("Progress deadline exceeded %v", err), false, flaggerv1.SeverityError)
+		c.alertWithFields(canary, fmt.Sprintf("Progress deadline exceeded %v", err), false, flaggerv1.SeverityError,
+			phaseField(flaggerv1.CanaryPhaseFailed))
 		c.rollback(canary, canaryController, meshRouter, scalerReconciler)
 
 		return true
@@ -792,10 +1155,11 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 
 	// notify
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseSucceeded)
-	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s",
-		canary.Spec.TargetRef.Name, canary.Namespace)
-	c.alert(canary, "Canary analysis was skipped, promotion finished.",
-		false, flaggerv1.SeverityInfo)
+	c.finishReport(canary, flaggerv1.CanaryPhaseSucceeded)
+	c.recordEventInfof(canary, "Promotion completed! Canary analysis was skipped for %s.%s%s",
+		canary.Spec.TargetRef.Name, canary.Namespace, imagesSuffix(canary))
+	c.alertWithFields(canary, "Canary analysis was skipped, promotion finished.",
+		false, flaggerv1.SeverityInfo, phaseField(flaggerv1.CanaryPhaseSucceeded))
 
 	return true
 }
@@ -815,6 +1179,11 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false, err
 		}
+
+		// restart the analysis of the current spec if requested
+		if _, ok := canary.Annotations[flaggerv1.RerunAnalysisAnnotation]; ok {
+			return true, nil
+		}
 	}
 
 	newTarget, err := canaryController.HasTargetChanged(canary)
@@ -843,12 +1212,15 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 		return true
 	}
 
-	var err error
-	canary, err = c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
+	latest, err := c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
 	if err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 		return false
 	}
+	// keep the analysis merged with the namespace defaults at the start of the run
+	latest.Spec.Analysis = canary.Spec.Analysis.DeepCopy()
+	latest.Spec.CanaryAnalysis = canary.Spec.CanaryAnalysis.DeepCopy()
+	canary = latest
 
 	if shouldAdvance {
 		// check confirm-rollout gate
@@ -856,11 +1228,43 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			return false
 		}
 
+		// wait for the previous promotion ring to promote the new revision
+		if !c.isPreviousRingPromoted(canary, canaryController) {
+			return false
+		}
+
+		// wait for the canaries this canary depends on to finish their rollout
+		if !c.areDependenciesReady(canary) {
+			return false
+		}
+
+		// verify the prerequisites of the analysis before scaling up the canary
+		if !c.runPreflightChecks(canary) {
+			c.recordEventWarningf(canary, "Halt %s.%s rollout, preflight checks failed", canary.Name, canary.Namespace)
+			return false
+		}
+
+		// queue the canary if the namespace runs the max number of concurrent canaries
+		if !c.hasNamespaceQuota(canary) {
+			return false
+		}
+		c.resetMetricValues(canary)
+		c.resetCheckStreak(canary)
+
+		_, rerun := canary.Annotations[flaggerv1.RerunAnalysisAnnotation]
 		canaryPhaseProgressing := canary.DeepCopy()
 		canaryPhaseProgressing.Status.Phase = flaggerv1.CanaryPhaseProgressing
-		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
-		c.alert(canaryPhaseProgressing, "New revision detected, progressing canary analysis.",
-			true, flaggerv1.SeverityInfo)
+		canaryPhaseProgressing.Status.Images = c.targetImages(canary)
+		canaryPhaseProgressing.Status.Commit = c.targetCommit(canary)
+		if rerun {
+			c.recordEventInfof(canaryPhaseProgressing, "Analysis restarted! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
+			c.alert(canaryPhaseProgressing, "Analysis restarted, progressing canary analysis.",
+				true, flaggerv1.SeverityInfo)
+		} else {
+			c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace, imagesSuffix(canaryPhaseProgressing))
+			c.alert(canaryPhaseProgressing, "New revision detected, progressing canary analysis.",
+				true, flaggerv1.SeverityInfo)
+		}
 
 		if scalerReconciler != nil {
 			err = scalerReconciler.ResumeTargetScaler(canary)
@@ -877,12 +1281,76 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false
 		}
+		c.startReport(canary)
+		if rerun {
+			if err := c.removeAnnotation(canary, flaggerv1.RerunAnalysisAnnotation); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+			}
+		}
 		c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseProgressing)
 		return false
 	}
 	return false
 }
 
+// checkStuck emits a warning event and alert each time a rollout without weight or phase changes
+// exceeds a multiple of the stuck duration
+func (c *Controller) checkStuck(canary *flaggerv1.Canary) {
+	stuckDuration := canary.GetAnalysisStuckDuration()
+	if stuckDuration == 0 || canary.Status.LastProgressTime.IsZero() {
+		return
+	}
+
+	var reason string
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseWaiting:
+		reason = "waiting on confirm-rollout gate"
+	case flaggerv1.CanaryPhaseWaitingPromotion:
+		reason = "waiting on confirm-promotion gate"
+	case flaggerv1.CanaryPhaseProgressing:
+		reason = "analysis halted"
+		if canary.Status.FailedChecks > 0 {
+			reason = fmt.Sprintf("analysis halted with %v failed checks", canary.Status.FailedChecks)
+		}
+	case flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		reason = "waiting on primary rollout"
+	default:
+		return
+	}
+
+	// report once per stuck duration, the check runs once per analysis interval
+	elapsed := time.Since(canary.Status.LastProgressTime.Time)
+	if canary.IsSoaking() {
+		if duration, err := canary.GetAnalysisSoakDuration(); err == nil {
+			elapsed -= duration
+		}
+	}
+	if elapsed < stuckDuration || elapsed/stuckDuration == (elapsed-canary.GetAnalysisInterval())/stuckDuration {
+		return
+	}
+
+	c.recordEventWarningf(canary, "Canary %s.%s stuck at %v%% for %s: %s",
+		canary.Name, canary.Namespace, canary.Status.CanaryWeight, elapsed.Round(time.Minute), reason)
+	c.alert(canary, fmt.Sprintf("Canary stuck at %v%% for %s: %s", canary.Status.CanaryWeight, elapsed.Round(time.Minute), reason),
+		false, flaggerv1.SeverityWarn)
+}
+
+// hasExceededMaxDuration returns true if the rollout started more than max duration ago,
+// the start is the transition of the promoted condition to unknown
+func (c *Controller) hasExceededMaxDuration(canary *flaggerv1.Canary) bool {
+	maxDuration := canary.GetAnalysisMaxDuration()
+	if maxDuration == 0 {
+		return false
+	}
+
+	for _, condition := range canary.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Status == corev1.ConditionUnknown {
+			return time.Since(condition.LastTransitionTime.Time) > maxDuration
+		}
+	}
+	return false
+}
+
 func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
 	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhaseWaitingPromotion {
@@ -901,8 +1369,8 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 	if canary.Status.FailedChecks >= canary.GetAnalysisThreshold() {
 		c.recordEventWarningf(canary, "Rolling back %s.%s failed checks threshold reached %v",
 			canary.Name, canary.Namespace, canary.Status.FailedChecks)
-		c.alert(canary, fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks),
-			false, flaggerv1.SeverityError)
+		c.alertWithFields(canary, fmt.Sprintf("Failed checks threshold reached %v", canary.Status.FailedChecks),
+			false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
 	}
 
 	// route all traffic back to primary
@@ -915,8 +1383,8 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 
 	canaryPhaseFailed := canary.DeepCopy()
 	canaryPhaseFailed.Status.Phase = flaggerv1.CanaryPhaseFailed
-	c.recordEventWarningf(canaryPhaseFailed, "Canary failed! Scaling down %s.%s",
-		canaryPhaseFailed.Name, canaryPhaseFailed.Namespace)
+	c.recordEventWarningf(canaryPhaseFailed, "Canary failed! Scaling down %s.%s%s",
+		canaryPhaseFailed.Name, canaryPhaseFailed.Namespace, imagesSuffix(canaryPhaseFailed))
 
 	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 
@@ -932,6 +1400,12 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 		return
 	}
 
+	// remove the baseline
+	if err := canaryController.DeleteBaseline(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
 	// mark canary as failed
 	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: 0}); err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
@@ -940,6 +1414,109 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 
 	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
 	c.runPostRolloutHooks(canary, flaggerv1.CanaryPhaseFailed)
+	c.finishReport(canary, flaggerv1.CanaryPhaseFailed)
+}
+
+// checkRollbackWindow rolls back the primary to the revision replaced by the last promotion
+// if the post-promotion checks fail or a rollback webhook is invoked during the rollback window
+func (c *Controller) checkRollbackWindow(canary *flaggerv1.Canary, canaryController canary.Controller) {
+	if canary.GetAnalysis().RollbackWindow == nil || canary.Status.Phase != flaggerv1.CanaryPhaseSucceeded {
+		return
+	}
+
+	// skip the checks if the primary was rolled back manually
+	if canary.Status.LastPromotedSpec != canary.Status.LastAppliedSpec {
+		return
+	}
+
+	var promotedAt time.Time
+	for _, condition := range canary.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Reason == string(flaggerv1.CanaryPhaseSucceeded) {
+			promotedAt = condition.LastTransitionTime.Time
+		}
+	}
+	if promotedAt.IsZero() || time.Since(promotedAt) > canary.GetRollbackWindow() {
+		return
+	}
+
+	rollback := c.runRollbackHooks(canary, canary.Status.Phase)
+	if rollback {
+		c.recordEventWarningf(canary, "Rolling back %s.%s primary manual webhook invoked", canary.Name, canary.Namespace)
+	} else if !c.runRollbackWindowChecks(canary) {
+		failedChecks := canary.Status.FailedChecks + 1
+		if err := canaryController.SetStatusFailedChecks(canary, failedChecks); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return
+		}
+		if failedChecks < canary.GetAnalysisThreshold() {
+			return
+		}
+		c.recordEventWarningf(canary, "Rolling back %s.%s primary post-promotion checks threshold reached %v",
+			canary.Name, canary.Namespace, failedChecks)
+	} else {
+		return
+	}
+
+	if err := canaryController.RollbackPrimary(canary, ""); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFailed); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+
+	c.recorder.SetStatus(canary, flaggerv1.CanaryPhaseFailed)
+	c.alertWithFields(canary, "Post-promotion checks failed, primary rolled back to the previous revision.",
+		false, flaggerv1.SeverityError, phaseField(flaggerv1.CanaryPhaseFailed))
+}
+
+// checkRollbackRevision rolls back the primary to the revision requested with the rollback annotation
+// and returns true if a rollback was attempted
+func (c *Controller) checkRollbackRevision(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+	revision, ok := canary.Annotations[flaggerv1.RollbackRevisionAnnotation]
+	if !ok {
+		return false
+	}
+
+	// the annotation is removed even if the rollback fails to avoid retrying an invalid revision
+	rollbackErr := canaryController.RollbackPrimary(canary, revision)
+	if err := c.removeAnnotation(canary, flaggerv1.RollbackRevisionAnnotation); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+	}
+	if rollbackErr != nil {
+		c.recordEventWarningf(canary, "%v", rollbackErr)
+		return true
+	}
+
+	if revision == "" {
+		revision = "previous"
+	}
+	c.recordEventInfof(canary, "Primary %s.%s rolled back to revision %s",
+		canary.Spec.TargetRef.Name, canary.Namespace, revision)
+	c.alert(canary, fmt.Sprintf("Primary rolled back to revision %s.", revision),
+		false, flaggerv1.SeverityWarn)
+	return true
+}
+
+// removeAnnotation deletes a request annotation from the canary once it has been handled
+func (c *Controller) removeAnnotation(canary *flaggerv1.Canary, key string) error {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", canary.Name, canary.Namespace, err)
+		}
+
+		cdCopy := cd.DeepCopy()
+		delete(cdCopy.Annotations, key)
+		_, err = c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("removing canary %s.%s annotation %s failed: %w", canary.Name, canary.Namespace, key, err)
+	}
+	return nil
 }
 
 func (c *Controller) setPhaseInitialized(cd *flaggerv1.Canary, canaryController canary.Controller) error {
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// runBandit moves the traffic towards the version with the best rewards,
+// the canary weight is increased while the canary scores at least as well as the primary
+// and decreased down to the bandit min weight otherwise
+func (c *Controller) runBandit(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, canaryWeight int, primaryWeight int, maxWeight int) {
+	step := canary.GetAnalysis().StepWeight
+	if step < 1 {
+		c.recordEventWarningf(canary, "Setting canaryAnalysis.stepWeight: 10 for the bandit traffic allocation")
+		step = 10
+	}
+	minWeight := canary.GetAnalysis().Bandit.MinWeight
+	if minWeight < 1 {
+		minWeight = step
+	}
+
+	// start exploring the canary
+	if canaryWeight == 0 {
+		c.setCanaryWeight(canary, canaryController, meshRouter, c.min(minWeight, maxWeight),
+			fmt.Sprintf("Advance %s.%s canary weight %v", canary.Name, canary.Namespace, c.min(minWeight, maxWeight)))
+		return
+	}
+
+	score, err := c.banditScore(canary)
+	if err != nil {
+		c.recordEventErrorf(canary, "%v", err)
+		if err := canaryController.SetStatusFailedChecks(canary, canary.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		return
+	}
+
+	if score >= 0 {
+		// promote the canary once it wins at max weight
+		if canaryWeight >= maxWeight {
+			c.runCanary(canary, canaryController, meshRouter, false, canaryWeight, primaryWeight, maxWeight)
+			return
+		}
+		weight := c.min(canaryWeight+step, maxWeight)
+		if promote := c.runConfirmTrafficIncreaseHooks(canary, weight); !promote {
+			return
+		}
+		c.setCanaryWeight(canary, canaryController, meshRouter, weight,
+			fmt.Sprintf("Advance %s.%s canary weight %v (reward score %.2f)", canary.Name, canary.Namespace, weight, score))
+		return
+	}
+
+	if canaryWeight <= minWeight {
+		c.recordEventWarningf(canary, "Halt %s.%s advancement canary reward score %.2f is below the primary",
+			canary.Name, canary.Namespace, score)
+		if err := canaryController.SetStatusFailedChecks(canary, canary.Status.FailedChecks+1); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		return
+	}
+
+	weight := canaryWeight - step
+	if weight < minWeight {
+		weight = minWeight
+	}
+	c.setCanaryWeight(canary, canaryController, meshRouter, weight,
+		fmt.Sprintf("Reduce %s.%s canary weight %v (reward score %.2f)", canary.Name, canary.Namespace, weight, score))
+}
+
+// banditScore returns the weighted average of the canary rewards relative to the primary,
+// a positive score means the canary performs better than the primary
+func (c *Controller) banditScore(canary *flaggerv1.Canary) (float64, error) {
+	metrics := make(map[string]flaggerv1.CanaryMetric)
+	for _, metric := range analysisMetrics(canary) {
+		metrics[metric.Name] = metric
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	var score, total float64
+	for _, reward := range canary.GetAnalysis().Bandit.Rewards {
+		metric, ok := metrics[reward.Metric]
+		if !ok || metric.TemplateRef == nil {
+			return 0, fmt.Errorf("bandit reward %s must reference an analysis metric with a template", reward.Metric)
+		}
+
+		interval := metric.Interval
+		if interval == "" {
+			interval = canary.GetMetricInterval()
+		}
+		model := toMetricModel(canary, interval, metric.TemplateVariables)
+		canaryValue, err := c.runMetricTemplateModelQuery(canary, *metric.TemplateRef, interval, model)
+		if err != nil {
+			return 0, fmt.Errorf("bandit reward %s canary query failed: %w", reward.Metric, err)
+		}
+		model.Target = primaryName
+		primaryValue, err := c.runMetricTemplateModelQuery(canary, *metric.TemplateRef, interval, model)
+		if err != nil {
+			return 0, fmt.Errorf("bandit reward %s primary query failed: %w", reward.Metric, err)
+		}
+
+		weight := reward.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		score += weight * relativeReward(canaryValue, primaryValue, reward.Goal)
+		total += weight
+
+		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Debugf("Bandit reward %s canary %.2f primary %.2f", reward.Metric, canaryValue, primaryValue)
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("bandit has no rewards")
+	}
+	return score / total, nil
+}
+
+// relativeReward returns the canary advantage over the primary in the range [-2, 2]
+func relativeReward(canaryValue float64, primaryValue float64, goal flaggerv1.BanditGoal) float64 {
+	scale := math.Max(math.Abs(canaryValue), math.Abs(primaryValue))
+	if scale == 0 {
+		return 0
+	}
+
+	diff := (canaryValue - primaryValue) / scale
+	if goal == flaggerv1.BanditGoalMinimize {
+		return -diff
+	}
+	return diff
+}
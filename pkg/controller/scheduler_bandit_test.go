@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRelativeReward(t *testing.T) {
+	assert.Equal(t, 0.0, relativeReward(0, 0, flaggerv1.BanditGoalMaximize))
+	assert.Equal(t, 0.5, relativeReward(100, 50, flaggerv1.BanditGoalMaximize))
+	assert.Equal(t, -0.5, relativeReward(100, 50, flaggerv1.BanditGoalMinimize))
+	assert.Equal(t, -1.0, relativeReward(0, 50, ""))
+}
+
+func TestScheduler_DeploymentBandit(t *testing.T) {
+	// the primary latency is 100ms, the canary latency is set by the test
+	var canaryLatency atomic.Value
+	canaryLatency.Store("50")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value := canaryLatency.Load().(string)
+		if r.URL.Query().Get("query") == "vector(1)" {
+			// for IsOnline invoked by the preflight checks
+			value = "1"
+		} else if strings.Contains(r.URL.Query().Get("query"), "podinfo-primary") {
+			value = "100"
+		}
+		w.Write([]byte(fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"%s"]}]}}`, value)))
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:           "latency",
+			Interval:       "1m",
+			ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1000)},
+			TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+		},
+	}
+	cd.Spec.Analysis.Bandit = &flaggerv1.CanaryBandit{
+		Rewards: []flaggerv1.CanaryBanditReward{
+			{Metric: "latency", Goal: flaggerv1.BanditGoalMinimize},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(&flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latency"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    `histogram_quantile(0.99, latency{workload="{{ target }}"})`,
+		},
+	})
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	weight := func() int {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		return c.Status.CanaryWeight
+	}
+
+	// the faster canary gets more traffic
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 10, weight())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 20, weight())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 30, weight())
+
+	// the slower canary gets less traffic down to the min weight
+	canaryLatency.Store("150")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 20, weight())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 10, weight())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 10, weight())
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	// the canary is promoted once it wins at max weight
+	canaryLatency.Store("50")
+	for i := 0; i < 4; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	assert.Equal(t, 50, weight())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// isPrimaryServingTraffic returns an error until the apex service endpoints
+// route the traffic only to ready primary pods
+func (c *Controller) isPrimaryServingTraffic(cd *flaggerv1.Canary, labelSelector string, labelValue string) error {
+	apexName, _, _ := cd.GetServiceNames()
+	endpoints, err := c.kubeClient.CoreV1().Endpoints(cd.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("endpoints %s.%s get query error: %w", apexName, cd.Namespace, err)
+	}
+
+	pods, err := c.getPrimaryPods(cd, labelSelector, labelValue)
+	if err != nil {
+		return err
+	}
+	primary := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		primary[pod.Name] = true
+	}
+
+	primaryPods, otherPods := 0, 0
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" && primary[address.TargetRef.Name] {
+				primaryPods++
+			} else {
+				otherPods++
+			}
+		}
+	}
+
+	if primaryPods == 0 {
+		return fmt.Errorf("waiting for service %s.%s to route traffic to the primary pods", apexName, cd.Namespace)
+	}
+	if otherPods > 0 {
+		return fmt.Errorf("waiting for service %s.%s to stop routing traffic to %d non-primary endpoints",
+			apexName, cd.Namespace, otherPods)
+	}
+	return nil
+}
+
+// scaleDownTarget scales the target workload to zero during initialization,
+// after the traffic has been moved to the primary workload
+func (c *Controller) scaleDownTarget(cd *flaggerv1.Canary, canaryController canary.Controller, labelSelector string, labelValue string) error {
+	if cd.Status.Phase != "" && cd.Status.Phase != flaggerv1.CanaryPhaseInitializing {
+		return nil
+	}
+
+	if !cd.SkipAnalysis() && cd.Spec.TargetRef.Kind != "Service" {
+		if err := c.isPrimaryServingTraffic(cd, labelSelector, labelValue); err != nil {
+			return err
+		}
+		if err := c.isTargetGroupServingTraffic(cd); err != nil {
+			return err
+		}
+	}
+
+	c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).
+		Infof("Scaling down %s %s.%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+	if err := canaryController.ScaleToZero(cd); err != nil {
+		return fmt.Errorf("scaling down %s %s.%s failed: %w", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+	return nil
+}
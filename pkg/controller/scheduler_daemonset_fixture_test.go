@@ -21,10 +21,13 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	fakeIstio "istio.io/client-go/pkg/clientset/versioned/fake"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
@@ -48,6 +51,7 @@ type daemonSetFixture struct {
 	canary        *flaggerv1.Canary
 	kubeClient    kubernetes.Interface
 	meshClient    clientset.Interface
+	istioClient   istioclientset.Interface
 	flaggerClient clientset.Interface
 	deployer      canary.Controller
 	ctrl          *Controller
@@ -71,6 +75,8 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 	kubeClient := fake.NewSimpleClientset(
 		newDaemonSetTestDaemonSet(),
 		newDaemonSetTestService(),
+		newDaemonSetTestEndpoints(),
+		newDaemonSetTestPrimaryPod(),
 		newDaemonSetTestConfigMap(),
 		newDaemonSetTestConfigMapEnv(),
 		newDaemonSetTestConfigMapVol(),
@@ -78,6 +84,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		newDaemonSetTestSecretEnv(),
 		newDaemonSetTestSecretVol(),
 		newDaemonSetTestAlertProviderSecret(),
+		newTestNamespace(),
 	)
 
 	logger, _ := logger.NewLogger("debug")
@@ -86,13 +93,21 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
 
 	fi := Informers{
-		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
-		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
-		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryInformer:   flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+		MetricInformer:   flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
+		AlertInformer:    flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		ApprovalInformer: flaggerInformerFactory.Flagger().V1beta1().CanaryApprovals(),
+		DefaultInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryDefaults(),
+	}
+
+	// register the mesh API for the preflight checks
+	flaggerClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "networking.istio.io/v1beta1"},
 	}
 
 	// init router
-	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", "", logger, flaggerClient, true)
+	istioClient := fakeIstio.NewSimpleClientset()
+	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", "", logger, flaggerClient, istioClient, true, nil)
 
 	// init observer
 	observerFactory, _ := observers.NewFactory(testMetricsServerURL)
@@ -103,7 +118,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, []string{"app", "name"}, []string{""}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, nil, nil, flaggerClient, configTracker, []string{"app", "name"}, []string{""}, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
@@ -117,7 +132,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
-		recorder:         metrics.NewRecorder(controllerAgentName, false),
+		recorder:         metrics.NewRecorder(controllerAgentName, false, false),
 		routerFactory:    rf,
 		notifier:         &notifier.NopNotifier{},
 	}
@@ -126,14 +141,15 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDaemonSetTestMetricTemplate())
 	ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(newDaemonSetTestAlertProvider())
 
-	meshRouter := rf.MeshRouter("istio", "")
+	meshRouter := rf.MeshRouter("istio", "", "")
 
 	return daemonSetFixture{
 		canary:        c,
-		deployer:      canaryFactory.Controller("DaemonSet"),
+		deployer:      canaryFactory.Controller(flaggerv1.LocalObjectReference{Kind: "DaemonSet"}),
 		logger:        logger,
 		flaggerClient: flaggerClient,
 		meshClient:    flaggerClient,
+		istioClient:   istioClient,
 		kubeClient:    kubeClient,
 		ctrl:          ctrl,
 		router:        meshRouter,
@@ -629,6 +645,36 @@ func newDaemonSetTestService() *corev1.Service {
 	return d
 }
 
+func newDaemonSetTestPrimaryPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo-primary-7xq2m",
+			Labels:    map[string]string{"app": "podinfo-primary"},
+		},
+	}
+}
+
+func newDaemonSetTestEndpoints() *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{
+						IP:        "10.0.0.10",
+						TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-7xq2m"},
+					},
+				},
+				Ports: []corev1.EndpointPort{{Name: "http", Port: 9898}},
+			},
+		},
+	}
+}
+
 func newDaemonSetTestMetricTemplate() *flaggerv1.MetricTemplate {
 	provider := flaggerv1.MetricTemplateProvider{
 		Type:    "prometheus",
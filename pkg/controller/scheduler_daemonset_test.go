@@ -200,6 +200,11 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	err = mocks.router.SetRoutes(mocks.canary, primaryWeight, canaryWeight, false)
 	require.NoError(t, err)
 
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusWeight(cd, canaryWeight)
+	require.NoError(t, err)
+
 	// advance
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
@@ -25,11 +25,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	fakeIstio "istio.io/client-go/pkg/clientset/versioned/fake"
 	appsv1 "k8s.io/api/apps/v1"
 	hpav2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
@@ -53,6 +56,7 @@ type fixture struct {
 	canary        *flaggerv1.Canary
 	kubeClient    kubernetes.Interface
 	meshClient    clientset.Interface
+	istioClient   istioclientset.Interface
 	flaggerClient clientset.Interface
 	deployer      canary.Controller
 	ctrl          *Controller
@@ -99,6 +103,8 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 	kubeClient := fake.NewSimpleClientset(
 		newDeploymentTestDeployment(),
 		newDeploymentTestService(),
+		newDeploymentTestEndpoints(),
+		newDeploymentTestPrimaryPod(),
 		newDeploymentTestHPA(),
 		newDeploymentTestConfigMap(),
 		newDeploymentTestConfigMapEnv(),
@@ -107,6 +113,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		newDeploymentTestSecretEnv(),
 		newDeploymentTestSecretVol(),
 		newDeploymentTestAlertProviderSecret(),
+		newTestNamespace(),
 	)
 
 	logger, _ := logger.NewLogger("debug")
@@ -115,13 +122,21 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 	flaggerInformerFactory := informers.NewSharedInformerFactory(flaggerClient, 0)
 
 	fi := Informers{
-		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
-		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
-		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		CanaryInformer:   flaggerInformerFactory.Flagger().V1beta1().Canaries(),
+		MetricInformer:   flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
+		AlertInformer:    flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		ApprovalInformer: flaggerInformerFactory.Flagger().V1beta1().CanaryApprovals(),
+		DefaultInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryDefaults(),
+	}
+
+	// register the mesh API for the preflight checks
+	flaggerClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "networking.istio.io/v1beta1"},
 	}
 
 	// init router
-	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", "", logger, flaggerClient, true)
+	istioClient := fakeIstio.NewSimpleClientset()
+	rf := router.NewFactory(nil, kubeClient, flaggerClient, "annotationsPrefix", "", logger, flaggerClient, istioClient, true, nil)
 
 	// init observer
 	observerFactory, _ := observers.NewFactory(testMetricsServerURL)
@@ -132,7 +147,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, configTracker, []string{"app", "name"}, []string{""}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, nil, nil, flaggerClient, configTracker, []string{"app", "name"}, []string{""}, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
@@ -146,7 +161,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
-		recorder:         metrics.NewRecorder(controllerAgentName, false),
+		recorder:         metrics.NewRecorder(controllerAgentName, false, false),
 		routerFactory:    rf,
 		notifier:         &notifier.NopNotifier{},
 	}
@@ -156,14 +171,15 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDeploymentTestMetricTemplateCustomVars())
 	ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(newDeploymentTestAlertProvider())
 
-	meshRouter := rf.MeshRouter("istio", "")
+	meshRouter := rf.MeshRouter("istio", "", "")
 
 	return fixture{
 		canary:        c,
-		deployer:      canaryFactory.Controller("Deployment"),
+		deployer:      canaryFactory.Controller(flaggerv1.LocalObjectReference{Kind: "Deployment"}),
 		logger:        logger,
 		flaggerClient: flaggerClient,
 		meshClient:    flaggerClient,
+		istioClient:   istioClient,
 		kubeClient:    kubeClient,
 		ctrl:          ctrl,
 		router:        meshRouter,
@@ -695,6 +711,36 @@ func newDeploymentTestServiceV2() *corev1.Service {
 	return d
 }
 
+func newDeploymentTestPrimaryPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo-primary-5d8c9b7f6d-x2k4q",
+			Labels:    map[string]string{"app": "podinfo-primary"},
+		},
+	}
+}
+
+func newDeploymentTestEndpoints() *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{
+					{
+						IP:        "10.0.0.10",
+						TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-5d8c9b7f6d-x2k4q"},
+					},
+				},
+				Ports: []corev1.EndpointPort{{Name: "http", Port: 9898}},
+			},
+		},
+	}
+}
+
 func newDeploymentTestHPA() *hpav2.HorizontalPodAutoscaler {
 	h := &hpav2.HorizontalPodAutoscaler{
 		TypeMeta: metav1.TypeMeta{APIVersion: hpav2.SchemeGroupVersion.String()},
@@ -801,3 +847,13 @@ func newDeploymentTestAlertProvider() *flaggerv1.AlertProvider {
 		},
 	}
 }
+
+func newTestNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{"istio-injection": "enabled"},
+		},
+	}
+}
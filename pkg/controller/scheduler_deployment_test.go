@@ -24,13 +24,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
 	"github.com/fluxcd/flagger/pkg/notifier"
 )
 
@@ -42,6 +47,39 @@ func TestScheduler_DeploymentInit(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestScheduler_DeploymentInitZeroDowntime(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// the apex service still routes the traffic to the target pods
+	endpoints := newDeploymentTestEndpoints()
+	endpoints.Subsets[0].Addresses[0].TargetRef.Name = "podinfo-6b5c8f9d7c-q7w2e"
+	_, err := mocks.kubeClient.CoreV1().Endpoints("default").Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// initializing ...
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the target is not scaled down until the primary serves the traffic
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Nil(t, dep.Spec.Replicas)
+	require.Error(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// the endpoints are updated to the primary pods
+	_, err = mocks.kubeClient.CoreV1().Endpoints("default").Update(context.TODO(), newDeploymentTestEndpoints(), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// initialization done
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32p(0), dep.Spec.Replicas)
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+}
+
 func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 
@@ -67,6 +105,82 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	assert.Equal(t, int32(1), *c.Spec.Replicas)
 }
 
+func TestScheduler_DeploymentRouteDrift(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// manual edit
+	err := mocks.router.SetRoutes(mocks.canary, 60, 60, false)
+	require.NoError(t, err)
+
+	// repair drift
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_RouteWarningsDeduplicated(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	recorder := record.NewFakeRecorder(10)
+	mocks.ctrl.eventRecorder = recorder
+
+	mocks.ctrl.recordRouteWarningf(mocks.canary, "drift %d", 60)
+	mocks.ctrl.recordRouteWarningf(mocks.canary, "drift %d", 60)
+	assert.Len(t, recorder.Events, 1)
+
+	mocks.ctrl.recordRouteWarningf(mocks.canary, "drift %d", 70)
+	assert.Len(t, recorder.Events, 2)
+}
+
+func TestScheduler_DeploymentLocalities(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.Localities = []flaggerv1.CanaryLocality{
+		{Name: "us-east-1a"},
+		{Name: "us-east-1b"},
+	}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// max weight reached in the first locality
+	mocks.ctrl.runCanary(cd, mocks.deployer, mocks.router, false, 50, 50, 50)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.Locality)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(c)
+	require.NoError(t, err)
+	assert.Equal(t, 90, primaryWeight)
+	assert.Equal(t, 10, canaryWeight)
+
+	// max weight reached in the last locality
+	mocks.ctrl.runCanary(c, mocks.deployer, mocks.router, false, 50, 50, 50)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.Locality)
+}
+
 func TestScheduler_DeploymentRollback(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
@@ -111,6 +225,314 @@ func TestScheduler_DeploymentRollback(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
 }
 
+func TestScheduler_DeploymentMaxDuration(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.MaxDuration = "1h"
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// start the rollout
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	// move the rollout start past the max duration
+	cd = c.DeepCopy()
+	for i, condition := range cd.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType {
+			cd.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		}
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentStuck(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	recorder := record.NewFakeRecorder(10)
+	mocks.ctrl.eventRecorder = recorder
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.StuckDuration = "6h"
+	cd.Status.Phase = flaggerv1.CanaryPhaseWaitingPromotion
+	cd.Status.CanaryWeight = 20
+
+	// progress within the stuck duration
+	cd.Status.LastProgressTime = metav1.NewTime(time.Now().Add(-5 * time.Hour))
+	mocks.ctrl.checkStuck(cd)
+	require.Len(t, recorder.Events, 0)
+
+	// the stuck duration is exceeded
+	cd.Status.LastProgressTime = metav1.NewTime(time.Now().Add(-6*time.Hour - 10*time.Second))
+	mocks.ctrl.checkStuck(cd)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "stuck at 20% for 6h0m0s: waiting on confirm-promotion gate")
+
+	// the stuck canary was already reported
+	cd.Status.LastProgressTime = metav1.NewTime(time.Now().Add(-6*time.Hour - 2*time.Minute))
+	mocks.ctrl.checkStuck(cd)
+	require.Len(t, recorder.Events, 0)
+}
+
+func TestScheduler_DeploymentApproval(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.RequireApproval = true
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// promotion is halted without an approval
+	ok := mocks.ctrl.runConfirmPromotionHooks(cd, mocks.deployer)
+	assert.False(t, ok)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaitingPromotion, c.Status.Phase)
+
+	// an approval of another revision is ignored
+	approval := &flaggerv1.CanaryApproval{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-approval", Namespace: "default"},
+		Spec: flaggerv1.CanaryApprovalSpec{
+			CanaryRef:  corev1.LocalObjectReference{Name: "podinfo"},
+			Revision:   "unknown",
+			ApprovedBy: "release-manager",
+		},
+	}
+	approval, err = mocks.flaggerClient.FlaggerV1beta1().CanaryApprovals("default").Create(context.TODO(), approval, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.ApprovalInformer.Informer().GetIndexer().Add(approval))
+	assert.False(t, mocks.ctrl.runConfirmPromotionHooks(c, mocks.deployer))
+
+	// promotion proceeds with an approval of the current revision
+	approval.Spec.Revision = c.Status.LastAppliedSpec
+	approval, err = mocks.flaggerClient.FlaggerV1beta1().CanaryApprovals("default").Update(context.TODO(), approval, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.ApprovalInformer.Informer().GetIndexer().Update(approval))
+	assert.True(t, mocks.ctrl.runConfirmPromotionHooks(c, mocks.deployer))
+
+	// promotion is halted when the CanaryApproval CRD is not registered
+	mocks.ctrl.flaggerInformers.ApprovalInformer = nil
+	assert.False(t, mocks.ctrl.runConfirmPromotionHooks(c, mocks.deployer))
+}
+
+func TestScheduler_DeploymentConfirmTrafficIncreaseWeights(t *testing.T) {
+	var calls []int
+	approved := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload flaggerv1.CanaryWebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		calls = append(calls, payload.NextWeight)
+		if !approved {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{
+			Name:    "step-gate",
+			Type:    flaggerv1.ConfirmTrafficIncreaseHook,
+			URL:     ts.URL,
+			Weights: []int{20},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// the step to 10% is not gated
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+	assert.Empty(t, calls)
+
+	// the step to 20% waits for approval
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+	assert.Equal(t, []int{20}, calls)
+
+	approved = true
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 20, canaryWeight)
+
+	// the step to 30% is not gated
+	approved = false
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 30, canaryWeight)
+	assert.Equal(t, []int{20, 20}, calls)
+}
+
+func TestScheduler_DeploymentRerunAnalysis(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// fail the analysis
+	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+
+	// request a new analysis of the same spec
+	cd := c.DeepCopy()
+	cd.Annotations = map[string]string{flaggerv1.RerunAnalysisAnnotation: "true"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.NotContains(t, c.Annotations, flaggerv1.RerunAnalysisAnnotation)
+}
+
+func TestScheduler_DeploymentRollbackWindow(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// set a post-promotion metric check to fail
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.Analysis.Threshold = 2
+	cd.Spec.Analysis.RollbackWindow = &flaggerv1.CanaryRollbackWindow{
+		Duration: "30m",
+		Metrics: []flaggerv1.CanaryMetric{
+			{
+				Name:     "fail",
+				Interval: "1m",
+				ThresholdRange: &flaggerv1.CanaryThresholdRange{
+					Max: toFloatPtr(50),
+				},
+				Query: "fail",
+			},
+		},
+	}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// promote a new revision
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.Promote(cd)
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded)
+	require.NoError(t, err)
+
+	// the first failed check is below the threshold
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.checkRollbackWindow(c, mocks.deployer)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	// the second failed check rolls back the primary
+	mocks.ctrl.checkRollbackWindow(c, mocks.deployer)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, dep2.Spec.Template.Spec.Containers[0].Image, primary.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentRollbackRevision(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd := c.DeepCopy()
+	cd.Spec.RevisionHistoryLimit = 2
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	revision := cd.Status.LastPromotedSpec
+
+	// promote a new revision
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.Promote(cd)
+	require.NoError(t, err)
+
+	// request the rollback to the initial revision
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd = c.DeepCopy()
+	cd.Annotations = map[string]string{flaggerv1.RollbackRevisionAnnotation: revision}
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	ok := mocks.ctrl.checkRollbackRevision(cd, mocks.deployer)
+	assert.True(t, ok)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, dep2.Spec.Template.Spec.Containers[0].Image, primary.Spec.Template.Spec.Containers[0].Image)
+
+	// the annotation is removed after the rollback
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.RollbackRevisionAnnotation)
+	assert.Equal(t, revision, c.Status.LastPromotedSpec)
+	assert.False(t, mocks.ctrl.checkRollbackRevision(c, mocks.deployer))
+}
+
 func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
@@ -336,6 +758,11 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	err = mocks.router.SetRoutes(mocks.canary, primaryWeight, canaryWeight, false)
 	require.NoError(t, err)
 
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusWeight(cd, canaryWeight)
+	require.NoError(t, err)
+
 	// advance
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
@@ -431,8 +858,11 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	assert.False(t, mirrored)
 }
 
-func TestScheduler_DeploymentABTesting(t *testing.T) {
-	mocks := newDeploymentFixture(newDeploymentTestCanaryAB())
+func TestScheduler_DeploymentBlueGreenMirroring(t *testing.T) {
+	cd := newDeploymentTestCanaryMirror()
+	cd.Spec.Analysis.Iterations = 2
+	mocks := newDeploymentFixture(cd)
+
 	// initializing
 	mocks.ctrl.advanceCanary("podinfo", "default")
 
@@ -451,8 +881,53 @@ func TestScheduler_DeploymentABTesting(t *testing.T) {
 	mocks.ctrl.advanceCanary("podinfo", "default")
 	mocks.makeCanaryReady(t)
 
-	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	// mirror traffic to canary during the iterations
+	for i := 1; i <= 2; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+
+		primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+		require.NoError(t, err)
+		assert.Equal(t, 100, primaryWeight)
+		assert.Equal(t, 0, canaryWeight)
+		assert.True(t, mirrored)
+
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, i, c.Status.Iterations)
+	}
+
+	// stop mirroring and route all traffic to canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, primaryWeight)
+	assert.Equal(t, 100, canaryWeight)
+	assert.False(t, mirrored)
+}
+
+func TestScheduler_DeploymentABTesting(t *testing.T) {
+	mocks := newDeploymentFixture(newDeploymentTestCanaryAB())
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
 
 	// check if traffic is routed to canary
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
@@ -641,3 +1116,681 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	// initialization done - now send alert
 	mocks.ctrl.advanceCanary("podinfo", "default")
 }
+
+func TestScheduler_DeploymentAlertsCommit(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	assert.Empty(t, mocks.ctrl.targetCommit(mocks.canary))
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Annotations = map[string]string{
+		flaggerv1.GitCommitAnnotation: "2b7c0b4",
+	}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2b7c0b4", mocks.ctrl.targetCommit(mocks.canary))
+
+	// the commit is kept in the status
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2b7c0b4", c.Status.Commit)
+}
+
+func TestScheduler_DeploymentPreflight(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// enable sidecar injection on the pod template and start a rollout
+	dep2 := newDeploymentTestDeploymentV2()
+	dep2.Spec.Template.Annotations = map[string]string{"sidecar.istio.io/inject": "true"}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	conditions := make(map[flaggerv1.CanaryConditionType]corev1.ConditionStatus)
+	for _, condition := range c.Status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	assert.Equal(t, corev1.ConditionTrue, conditions[flaggerv1.MeshAvailableType])
+	assert.Equal(t, corev1.ConditionTrue, conditions[flaggerv1.MetricsAvailableType])
+	assert.Equal(t, corev1.ConditionTrue, conditions[flaggerv1.SidecarInjectedType])
+	assert.Equal(t, corev1.ConditionTrue, conditions[flaggerv1.WebhooksReachableType])
+
+	// preflight conditions are preserved on phase changes
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, c.Status.Conditions, 6)
+}
+
+func TestScheduler_DeploymentPreflightHalt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{Name: "load-test", Type: flaggerv1.RolloutHook, URL: ts.URL},
+	}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the rollout doesn't start while a preflight check fails
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == flaggerv1.WebhooksReachableType {
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, "Failed", condition.Reason)
+		}
+	}
+}
+
+func TestScheduler_DeploymentPreflightSidecarWarning(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = flaggerv1.IstioProvider
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// disable sidecar injection on the namespace
+	_, err := mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the injection can be enabled by a webhook, the failed check doesn't halt the rollout
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == flaggerv1.SidecarInjectedType {
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, "Warning", condition.Reason)
+		}
+	}
+
+	// the istio revision label of the pod template enables the injection
+	dep2.Spec.Template.Labels["istio.io/rev"] = "canary"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = mocks.ctrl.checkSidecarInjected(c, flaggerv1.IstioProvider)
+	require.NoError(t, err)
+}
+
+func TestController_setStatusConditionsUnchanged(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	condition := flaggerv1.CanaryCondition{
+		Type:    flaggerv1.MeshAvailableType,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Succeeded",
+		Message: "API is available",
+	}
+	require.NoError(t, mocks.ctrl.setStatusConditions(c, []flaggerv1.CanaryCondition{condition}))
+
+	// the status is not updated when the conditions are unchanged
+	flaggerClient := mocks.flaggerClient.(*fakeFlagger.Clientset)
+	flaggerClient.ClearActions()
+	require.NoError(t, mocks.ctrl.setStatusConditions(c, []flaggerv1.CanaryCondition{condition}))
+	assert.Empty(t, flaggerClient.Actions())
+}
+
+func TestScheduler_DeploymentSoak(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Soak = &flaggerv1.CanarySoak{Weight: 10, Duration: "1h"}
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// the canary is held at the soak weight
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+
+	// the canary is promoted after the soak duration
+	c.Status.LastProgressTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+}
+
+func TestScheduler_DeploymentSoakFailedCheck(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Soak = &flaggerv1.CanarySoak{Weight: 10, Duration: "1h"}
+	cd.Spec.Analysis.Metrics[2].ThresholdRange.Max = toFloatPtr(50)
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// set the soak weight
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// a single failed check rolls back the canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+}
+
+func TestScheduler_DeploymentFailedChecksDecay(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.FailedChecksDecay = 2
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	failedChecks := func() int {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		return c.Status.FailedChecks
+	}
+
+	// record two failed checks
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	err = mocks.deployer.SetStatusFailedChecks(c, 2)
+	require.NoError(t, err)
+
+	// each two successful checks decrement the counter
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 2, failedChecks())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 1, failedChecks())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 1, failedChecks())
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, 0, failedChecks())
+}
+
+func TestScheduler_DeploymentThresholdPercentage(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	// five checks at 10, 20, 30, 40 and 50% weight, roll back after more than one failed check
+	cd.Spec.Analysis.ThresholdPercentage = 20
+	cd.Spec.Analysis.Metrics[2].ThresholdRange.Max = toFloatPtr(50)
+	mocks := newDeploymentFixture(cd)
+	require.Equal(t, 2, cd.GetAnalysisThreshold())
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// two failed checks
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+}
+
+func TestScheduler_DeploymentSidecarMissing(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = flaggerv1.IstioProvider
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// start a rollout with a canary pod that wasn't injected
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo-6b5c8f9d7c-q7w2e",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "podinfo"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "podinfo"}},
+		},
+	}
+	_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// the analysis is halted
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.CanaryWeight)
+
+	var condition *flaggerv1.CanaryCondition
+	for i := range c.Status.Conditions {
+		if c.Status.Conditions[i].Type == flaggerv1.SidecarRunningType {
+			condition = &c.Status.Conditions[i]
+		}
+	}
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, flaggerv1.SidecarMissingReason, condition.Reason)
+	assert.Contains(t, condition.Message, pod.Name)
+
+	// the analysis resumes once the sidecar is running
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "istio-proxy"})
+	_, err = mocks.kubeClient.CoreV1().Pods("default").Update(context.TODO(), pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == flaggerv1.SidecarRunningType {
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		}
+	}
+}
+
+func TestController_checkSidecarsRunning(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo-6b5c8f9d7c-q7w2e",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "podinfo"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "podinfo"}},
+		},
+	}
+	ctrl := &Controller{kubeClient: fake.NewSimpleClientset(pod)}
+
+	require.Error(t, ctrl.checkSidecarsRunning(cd, flaggerv1.IstioProvider, "app", "podinfo"))
+	require.Error(t, ctrl.checkSidecarsRunning(cd, flaggerv1.LinkerdProvider, "app", "podinfo"))
+
+	// the providers without sidecars are not checked
+	require.NoError(t, ctrl.checkSidecarsRunning(cd, flaggerv1.GlooProvider, "app", "podinfo"))
+
+	// the check is disabled by the canary annotation
+	cd.Annotations = map[string]string{flaggerv1.SkipSidecarCheckAnnotation: "true"}
+	require.NoError(t, ctrl.checkSidecarsRunning(cd, flaggerv1.IstioProvider, "app", "podinfo"))
+
+	// the pods captured by Istio ambient mode are part of the mesh
+	cd.Annotations = nil
+	pod.Annotations = map[string]string{istioAmbientRedirectionAnnotation: "enabled"}
+	_, err := ctrl.kubeClient.CoreV1().Pods("default").Update(context.TODO(), pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.checkSidecarsRunning(cd, flaggerv1.IstioProvider, "app", "podinfo"))
+}
+
+func TestScheduler_DeploymentSelectorSwitch(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = flaggerv1.SelectorSwitchProvider
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
+		Interval:   "1m",
+		Iterations: 1,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	apexSelector := func() map[string]string {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		return svc.Spec.Selector
+	}
+	serviceSelector := func(name string) map[string]string {
+		svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return svc.Spec.Selector
+	}
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, serviceSelector("podinfo-primary"), apexSelector())
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes and advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, serviceSelector("podinfo-primary"), apexSelector())
+
+	// route all traffic to canary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	assert.Equal(t, serviceSelector("podinfo-canary"), apexSelector())
+
+	// promoting and finalising keep the canary selector until the primary is updated
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFinalising))
+
+	// switch back to primary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
+	assert.Equal(t, serviceSelector("podinfo-primary"), apexSelector())
+}
+
+func TestScheduler_DeploymentGroup(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Group = "podinfo"
+	mocks := newDeploymentFixture(cd)
+
+	// add a group member that is rolling out
+	member := newDeploymentTestCanary()
+	member.Name = "worker"
+	member.Spec.TargetRef.Name = "worker"
+	member.Spec.Group = "podinfo"
+	member.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), member, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(member))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance in step with the member
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+
+	// wait for the member to catch up
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+
+	// roll back when the member fails
+	w, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "worker", metav1.GetOptions{})
+	require.NoError(t, err)
+	w.Status.Phase = flaggerv1.CanaryPhaseFailed
+	w.Status.LastTransitionTime = metav1.Now()
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), w, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(w))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+}
+
+func TestScheduler_DeploymentGroupContinue(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Group = "podinfo"
+	cd.Spec.GroupFailurePolicy = flaggerv1.GroupFailurePolicyContinue
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// add a group member that failed during the rollout
+	member := newDeploymentTestCanary()
+	member.Name = "worker"
+	member.Spec.TargetRef.Name = "worker"
+	member.Spec.Group = "podinfo"
+	member.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed, LastTransitionTime: metav1.Now()}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), member, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(member))
+
+	// keep analysing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+}
+
+func TestScheduler_DeploymentGroupPromotionRollback(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Group = "podinfo"
+	mocks := newDeploymentFixture(cd)
+
+	// add a group member without a new revision
+	member := newDeploymentTestCanary()
+	member.Name = "worker"
+	member.Spec.TargetRef.Name = "worker"
+	member.Spec.Group = "podinfo"
+	member.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseSucceeded}
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), member, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(member))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// skip to the max weight and promote
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.deployer.SetStatusWeight(c, 50))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.1", primary.Spec.Template.Spec.Containers[0].Image)
+
+	// the member fails while the canary is promoting
+	w, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "worker", metav1.GetOptions{})
+	require.NoError(t, err)
+	w.Status.Phase = flaggerv1.CanaryPhaseFailed
+	w.Status.LastTransitionTime = metav1.Now()
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(w))
+
+	// the primary is restored to the previous revision
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	primary, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+}
+
+func TestScheduler_DeploymentDependsOn(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.DependsOn = []string{"backend"}
+	mocks := newDeploymentFixture(cd)
+
+	// add a dependency that is rolling out
+	backend := newDeploymentTestCanary()
+	backend.Name = "backend"
+	backend.Spec.TargetRef.Name = "backend"
+	backend.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(backend))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// wait for the dependency to finish its rollout
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	backend.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseSucceeded}
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(backend))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestScheduler_DeploymentNamespaceQuota(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.maxConcurrentCanaries = 1
+
+	// add a canary that is running analysis in the same namespace
+	other := newDeploymentTestCanary()
+	other.Name = "worker"
+	other.Spec.TargetRef.Name = "worker"
+	other.Status = flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}
+	_, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), other, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(other))
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// queue the new revision while the namespace quota is used
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// the namespace annotation overrides the global quota
+	ns := newTestNamespace()
+	ns.Annotations = map[string]string{flaggerv1.MaxConcurrentCanariesAnnotation: "2"}
+	_, err = mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	// expire the cached namespace
+	mocks.ctrl.namespaces.Delete("default")
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestScheduler_DeploymentReport(t *testing.T) {
+	var posted flaggerv1.CanaryAnalysisReport
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Report = &flaggerv1.CanaryReport{ConfigMap: true, URL: ts.URL}
+	mocks := newDeploymentFixture(cd)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// run the analysis until the promotion is completed
+	for i := 0; i < 10; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+	}
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
+
+	cm, err := mocks.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-analysis-report", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	var report flaggerv1.CanaryAnalysisReport
+	require.NoError(t, json.Unmarshal([]byte(cm.Data["report.json"]), &report))
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, report.Phase)
+	assert.False(t, report.Incomplete)
+	require.NotEmpty(t, report.Steps)
+
+	var weights []int
+	for _, step := range report.Steps {
+		if step.Phase == flaggerv1.CanaryPhaseProgressing && len(step.Metrics) > 0 {
+			weights = append(weights, step.CanaryWeight)
+		}
+	}
+	assert.Equal(t, []int{10, 20, 30, 40, 50}, weights)
+	assert.Equal(t, report.Revision, posted.Revision)
+	assert.Equal(t, len(report.Steps), len(posted.Steps))
+
+	// the in-memory report is discarded once published
+	_, ok := mocks.ctrl.reports.Load("podinfo.default")
+	assert.False(t, ok)
+}
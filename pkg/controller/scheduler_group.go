@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// getGroupMembers returns the other canaries in the same namespace and group, and the canaries
+// linked to this one with dependsOn in either direction, from the informer cache.
+// The returned objects are shared with the cache and must not be modified.
+func (c *Controller) getGroupMembers(canary *flaggerv1.Canary) ([]*flaggerv1.Canary, error) {
+	list, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(canary.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("canaries %s list query error: %w", canary.Namespace, err)
+	}
+
+	var members []*flaggerv1.Canary
+	for _, item := range list {
+		if item.Name == canary.Name {
+			continue
+		}
+		if isInGroup(canary, item) || dependsOn(canary, item.Name) || dependsOn(item, canary.Name) {
+			members = append(members, item)
+		}
+	}
+	return members, nil
+}
+
+// isInGroup returns true if both canaries belong to the same group
+func isInGroup(canary *flaggerv1.Canary, other *flaggerv1.Canary) bool {
+	return canary.Spec.Group != "" && canary.Spec.Group == other.Spec.Group
+}
+
+// dependsOn returns true if the canary lists the named canary in its dependencies
+func dependsOn(canary *flaggerv1.Canary, name string) bool {
+	for _, dependency := range canary.Spec.DependsOn {
+		if dependency == name {
+			return true
+		}
+	}
+	return false
+}
+
+// groupDescription returns how the member is related to the canary, used in events and alerts
+func groupDescription(canary *flaggerv1.Canary, member *flaggerv1.Canary) string {
+	if isInGroup(canary, member) {
+		return fmt.Sprintf("canary %s of group %s", member.Name, canary.Spec.Group)
+	}
+	return fmt.Sprintf("canary %s of the dependsOn chain", member.Name)
+}
+
+// areDependenciesReady returns true when none of the canaries this canary depends on
+// is rolling out or has failed, otherwise the canary waits before starting its rollout
+func (c *Controller) areDependenciesReady(canary *flaggerv1.Canary) bool {
+	for _, name := range canary.Spec.DependsOn {
+		dependency, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(canary.Namespace).Get(name)
+		if err != nil {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement, dependency %s get query error: %v",
+				canary.Name, canary.Namespace, name, err)
+			return false
+		}
+
+		switch dependency.Status.Phase {
+		case flaggerv1.CanaryPhaseWaiting, flaggerv1.CanaryPhaseFailed:
+		default:
+			if !isRunningAnalysis(dependency) {
+				continue
+			}
+		}
+		c.recordEventInfof(canary, "Halt %s.%s advancement waiting for canary %s to finish its rollout, current phase %s",
+			canary.Name, canary.Namespace, name, dependency.Status.Phase)
+		return false
+	}
+	return true
+}
+
+// rolloutProgress returns the progress of the rollout as a fraction of the max weight or iterations,
+// ok is false when the canary is not rolling out
+func (c *Controller) rolloutProgress(canary *flaggerv1.Canary) (progress float64, ok bool) {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseWaiting:
+		return 0, true
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion:
+		if iterations := canary.GetAnalysis().Iterations; iterations > 0 {
+			return float64(canary.Status.Iterations) / float64(iterations), true
+		}
+		if maxWeight := c.maxWeight(canary); maxWeight > 0 {
+			return float64(canary.Status.CanaryWeight) / float64(maxWeight), true
+		}
+		return 0, true
+	case flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return 1, true
+	}
+	return 0, false
+}
+
+// isGroupInStep returns false when another canary of the group is rolling out and is behind this canary,
+// the canary holds the current step until the other members catch up
+func (c *Controller) isGroupInStep(canary *flaggerv1.Canary, members []*flaggerv1.Canary) bool {
+	progress, _ := c.rolloutProgress(canary)
+	for _, member := range members {
+		if !isInGroup(canary, member) {
+			continue
+		}
+		if memberProgress, ok := c.rolloutProgress(member); ok && memberProgress < progress {
+			c.recordEventInfof(canary, "Waiting for canary %s.%s of group %s to catch up",
+				member.Name, member.Namespace, canary.Spec.Group)
+			return false
+		}
+	}
+	return true
+}
+
+// getFailedGroupMember returns a canary from the group or dependsOn chain that failed during this canary's rollout,
+// the failures are ignored when the group failure policy is Continue
+func (c *Controller) getFailedGroupMember(canary *flaggerv1.Canary, members []*flaggerv1.Canary) *flaggerv1.Canary {
+	if canary.GetGroupFailurePolicy() == flaggerv1.GroupFailurePolicyContinue {
+		return nil
+	}
+
+	var started metav1.Time
+	for _, condition := range canary.Status.Conditions {
+		if condition.Type == flaggerv1.PromotedType && condition.Status == corev1.ConditionUnknown {
+			started = condition.LastTransitionTime
+		}
+	}
+	if started.IsZero() {
+		return nil
+	}
+
+	for _, member := range members {
+		if member.Status.Phase == flaggerv1.CanaryPhaseFailed && !member.Status.LastTransitionTime.Before(&started) {
+			return member
+		}
+	}
+	return nil
+}
@@ -18,20 +18,30 @@ package controller
 
 import (
 	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/notifier"
 )
 
-func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bool {
+// runConfirmTrafficIncreaseHooks calls the gates before the canary weight is increased to nextWeight,
+// the gates that list specific weights are called only for the steps that reach one of those weights
+func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary, nextWeight int) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmTrafficIncreaseHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			if len(webhook.Weights) > 0 && !containsWeight(webhook.Weights, nextWeight) {
+				continue
+			}
+			err := c.callTrafficIncreaseWebhook(canary, webhook, nextWeight)
 			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for traffic increase approval %s",
-					canary.Name, canary.Namespace, webhook.Name)
+				c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for traffic increase to %v approval %s",
+					canary.Name, canary.Namespace, nextWeight, webhook.Name)
 				if !webhook.MuteAlert {
-					c.alert(canary, "Canary traffic increase is waiting for approval.", false, flaggerv1.SeverityWarn)
+					c.alertWithFields(canary, "Canary traffic increase is waiting for approval.", false, flaggerv1.SeverityWarn,
+						gateFields(webhook))
 				}
 				return false
 			}
@@ -44,7 +54,7 @@ func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bo
 func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, canary.Status.Phase, webhook)
+			err := c.callCanaryWebhook(canary, canary.Status.Phase, webhook)
 			if err != nil {
 				if canary.Status.Phase != flaggerv1.CanaryPhaseWaiting {
 					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaiting); err != nil {
@@ -53,7 +63,8 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 					c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for approval %s",
 						canary.Name, canary.Namespace, webhook.Name)
 					if !webhook.MuteAlert {
-						c.alert(canary, "Canary is waiting for approval.", false, flaggerv1.SeverityWarn)
+						c.alertWithFields(canary, "Canary is waiting for approval.", false, flaggerv1.SeverityWarn,
+							gateFields(webhook))
 					}
 				}
 				return false
@@ -67,35 +78,94 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 func (c *Controller) runConfirmPromotionHooks(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.ConfirmPromotionHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callCanaryWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
-				if canary.Status.Phase != flaggerv1.CanaryPhaseWaitingPromotion {
-					if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaitingPromotion); err != nil {
-						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
-					}
-					c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for promotion approval %s",
-						canary.Name, canary.Namespace, webhook.Name)
-					if !webhook.MuteAlert {
-						c.alert(canary, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn)
-					}
-				} else {
-					if err := canaryController.SetStatusIterations(canary, canary.GetAnalysis().Iterations-1); err != nil {
-						c.recordEventWarningf(canary, "%v", err)
-					}
-				}
+				c.haltPromotion(canary, canaryController, webhook.Name, webhook.MuteAlert, gateFields(webhook))
 				return false
 			} else {
 				c.recordEventInfof(canary, "Confirm-promotion check %s passed", webhook.Name)
 			}
 		}
 	}
+
+	if canary.GetAnalysis().RequireApproval {
+		approval, err := c.getCanaryApproval(canary)
+		if err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return false
+		}
+		if approval == nil {
+			c.haltPromotion(canary, canaryController,
+				fmt.Sprintf("CanaryApproval for revision %s", canary.Status.LastAppliedSpec), false, nil)
+			return false
+		}
+		c.recordEventInfof(canary, "Promotion of revision %s approved with CanaryApproval %s (approvedBy: %q)",
+			approval.Spec.Revision, approval.Name, approval.Spec.ApprovedBy)
+	}
 	return true
 }
 
+// haltPromotion sets the canary in the waiting promotion phase or
+// keeps it on the last iteration if it is already waiting
+func (c *Controller) haltPromotion(canary *flaggerv1.Canary, canaryController canary.Controller, name string, muteAlert bool,
+	fields []notifier.Field) {
+	if canary.Status.Phase != flaggerv1.CanaryPhaseWaitingPromotion {
+		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseWaitingPromotion); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+		}
+		c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for promotion approval %s",
+			canary.Name, canary.Namespace, name)
+		if !muteAlert {
+			c.alertWithFields(canary, "Canary promotion is waiting for approval.", false, flaggerv1.SeverityWarn, fields)
+		}
+	} else {
+		if err := canaryController.SetStatusIterations(canary, canary.GetAnalysis().Iterations-1); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+	}
+}
+
+// gateFields returns the gate alert field if the webhook is backed by the load tester gate API,
+// allowing interactive notifiers to release or abort the canary
+func gateFields(webhook flaggerv1.CanaryWebhook) []notifier.Field {
+	if !strings.HasSuffix(webhook.URL, "/gate/check") {
+		return nil
+	}
+	return []notifier.Field{{Name: notifier.GateField, Value: string(webhook.Type)}}
+}
+
+// containsWeight returns true if the weight is in the list
+func containsWeight(weights []int, weight int) bool {
+	for _, w := range weights {
+		if w == weight {
+			return true
+		}
+	}
+	return false
+}
+
+// getCanaryApproval returns the approval matching the canary and its current revision
+func (c *Controller) getCanaryApproval(canary *flaggerv1.Canary) (*flaggerv1.CanaryApproval, error) {
+	if c.flaggerInformers.ApprovalInformer == nil {
+		return nil, fmt.Errorf("halt %s.%s promotion, the CanaryApproval CRD is not registered", canary.Name, canary.Namespace)
+	}
+	approvals, err := c.flaggerInformers.ApprovalInformer.Lister().CanaryApprovals(canary.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("canary approvals %s list query error: %w", canary.Namespace, err)
+	}
+
+	for _, approval := range approvals {
+		if approval.Spec.CanaryRef.Name == canary.Name && approval.Spec.Revision == canary.Status.LastAppliedSpec {
+			return approval.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
+
 func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PreRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			err := c.callCanaryWebhook(canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement pre-rollout check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
@@ -111,7 +181,7 @@ func (c *Controller) runPreRolloutHooks(canary *flaggerv1.Canary) bool {
 func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.PostRolloutHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callCanaryWebhook(canary, phase, webhook)
 			if err != nil {
 				c.recordEventWarningf(canary, "Post-rollout hook %s failed %v", webhook.Name, err)
 				return false
@@ -126,7 +196,7 @@ func (c *Controller) runPostRolloutHooks(canary *flaggerv1.Canary, phase flagger
 func (c *Controller) runRollbackHooks(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) bool {
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == flaggerv1.RollbackHook {
-			err := CallWebhook(canary.Name, canary.Namespace, phase, webhook)
+			err := c.callCanaryWebhook(canary, phase, webhook)
 			if err != nil {
 				c.recordEventInfof(canary, "Rollback hook %s not signaling a rollback", webhook.Name)
 			} else {
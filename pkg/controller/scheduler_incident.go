@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/incident"
+)
+
+// isFrozen returns true when an open incident holds the traffic shifting of the canary,
+// the events are emitted only when the canary is frozen or resumed
+func (c *Controller) isFrozen(canary *flaggerv1.Canary) bool {
+	var open []incident.Incident
+	if c.incidents != nil && canary.Annotations[flaggerv1.IgnoreIncidentsAnnotation] != "true" {
+		open = c.incidents.Open()
+	}
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	_, wasFrozen := c.frozen.Load(key)
+	switch {
+	case len(open) > 0 && !wasFrozen:
+		c.frozen.Store(key, true)
+		c.recordEventWarningf(canary, "Halt %s.%s advancement incident %s is open",
+			canary.Name, canary.Namespace, open[0])
+	case len(open) == 0 && wasFrozen:
+		c.frozen.Delete(key)
+		c.recordEventInfof(canary, "Resume %s.%s advancement incidents are resolved", canary.Name, canary.Namespace)
+	}
+	return len(open) > 0
+}
+
+// isRolloutStarting returns true if the canary isn't running an analysis,
+// a new rollout starts from these phases when a change is detected
+func isRolloutStarting(canary *flaggerv1.Canary, shouldAdvance bool) bool {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return false
+	}
+	return shouldAdvance
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/incident"
+)
+
+type fakeIncidents struct {
+	incidents []incident.Incident
+}
+
+func (f *fakeIncidents) OpenIncidents() ([]incident.Incident, error) {
+	return f.incidents, nil
+}
+
+func TestScheduler_IncidentFreeze(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	provider := &fakeIncidents{}
+	mocks.ctrl.incidents = incident.NewWatcher(provider, time.Minute, nil, mocks.logger)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+
+	// open incident
+	provider.incidents = []incident.Incident{{ID: "1", Name: "Database outage", Severity: "SEV1"}}
+	mocks.ctrl.incidents.Poll()
+
+	// hold
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, canaryWeight)
+
+	// ignore the incident
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, mocks.ctrl.isFrozen(cd))
+	cd.Annotations = map[string]string{"flagger.app/ignore-incidents": "true"}
+	assert.False(t, mocks.ctrl.isFrozen(cd))
+
+	// resolved incident
+	provider.incidents = nil
+	mocks.ctrl.incidents.Poll()
+
+	// resume
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	_, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 20, canaryWeight)
+}
+
+func TestScheduler_IncidentFreezeRollback(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	provider := &fakeIncidents{}
+	mocks.ctrl.incidents = incident.NewWatcher(provider, time.Minute, nil, mocks.logger)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// open incident
+	provider.incidents = []incident.Incident{{ID: "1", Name: "Database outage", Severity: "SEV1"}}
+	mocks.ctrl.incidents.Poll()
+
+	// a new rollout doesn't start while the incident is open
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// start the rollout and freeze it
+	provider.incidents = nil
+	mocks.ctrl.incidents.Poll()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+	provider.incidents = []incident.Incident{{ID: "1", Name: "Database outage", Severity: "SEV1"}}
+	mocks.ctrl.incidents.Poll()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, canaryWeight)
+
+	// a failing canary is rolled back during the incident
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Status.FailedChecks = 10
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), cd, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+}
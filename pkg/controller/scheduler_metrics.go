@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,8 +38,13 @@ const (
 // to be called during canary initialization
 func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) error {
 	for _, metric := range canary.GetAnalysis().Metrics {
-		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
-			observerFactory := c.observerFactory
+		if metric.TemplateRef == nil && metric.Query == "" && !isBuiltinMetric(metric.Name) {
+			return fmt.Errorf("metric %s is not a builtin metric (%s, %s) and has no templateRef",
+				metric.Name, flaggerv1.BuiltinMetricRequestSuccessRate, flaggerv1.BuiltinMetricRequestDuration)
+		}
+
+		if isBuiltinMetric(metric.Name) {
+			observerFactory := c.getObserverFactory()
 			if canary.Spec.MetricsServer != "" {
 				var err error
 				observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
@@ -90,22 +96,72 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 	return nil
 }
 
-func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+// recordMetric keeps the last value of the metric for the webhook and alert payloads
+// and adds the value to the analysis report
+func (c *Controller) recordMetric(canary *flaggerv1.Canary, name string, value float64) {
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	values := map[string]float64{name: value}
+	if current, ok := c.metricValues.Load(key); ok {
+		for k, v := range current.(map[string]float64) {
+			if k != name {
+				values[k] = v
+			}
+		}
+	}
+	c.metricValues.Store(key, values)
+	c.reportMetric(canary, name, value)
+}
+
+// getMetricValues returns the last value measured for each metric of the current analysis
+func (c *Controller) getMetricValues(canary *flaggerv1.Canary) map[string]float64 {
+	if values, ok := c.metricValues.Load(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)); ok {
+		return values.(map[string]float64)
+	}
+	return nil
+}
+
+// resetMetricValues discards the values measured during the previous analysis
+func (c *Controller) resetMetricValues(canary *flaggerv1.Canary) {
+	c.metricValues.Delete(fmt.Sprintf("%s.%s", canary.Name, canary.Namespace))
+}
+
+// formatMetricValues returns the metric values sorted by name e.g. "request-duration: 120.00, request-success-rate: 99.50"
+func formatMetricValues(values map[string]float64) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %.2f", name, values[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func isBuiltinMetric(name string) bool {
+	return name == flaggerv1.BuiltinMetricRequestSuccessRate || name == flaggerv1.BuiltinMetricRequestDuration
+}
+
+// getMetricsProvider returns the provider used to query the builtin metrics of the canary
+func (c *Controller) getMetricsProvider(canary *flaggerv1.Canary) string {
 	// override the global provider if one is specified in the canary spec
 	var metricsProvider string
+	meshProvider := c.getMeshProvider()
 	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
 	// For example, `crossover` metrics provider should be used for `smi:crossover` mesh provider
-	if strings.Contains(c.meshProvider, "crossover") {
+	if strings.Contains(meshProvider, "crossover") {
 		metricsProvider = "crossover"
 	} else {
-		metricsProvider = c.meshProvider
+		metricsProvider = meshProvider
 	}
 
 	if canary.Spec.Provider != "" {
 		metricsProvider = canary.Spec.Provider
 
 		// set the metrics provider to Linkerd Prometheus when Linkerd is the default mesh provider
-		if strings.Contains(c.meshProvider, "linkerd") {
+		if strings.Contains(meshProvider, "linkerd") {
 			metricsProvider = "linkerd"
 		}
 	}
@@ -114,8 +170,14 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 		metricsProvider = metricsProvider + MetricsProviderServiceSuffix
 	}
 
+	return metricsProvider
+}
+
+func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+	metricsProvider := c.getMetricsProvider(canary)
+
 	// create observer based on the mesh provider
-	observerFactory := c.observerFactory
+	observerFactory := c.getObserverFactory()
 
 	// override the global metrics server if one is specified in the canary spec
 	if canary.Spec.MetricsServer != "" {
@@ -126,15 +188,22 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 			return false
 		}
 	}
+
+	// read the series recorded by the generated Prometheus rules
+	if canary.GetAnalysis().RecordingRules != nil {
+		if _, records, err := recordingRules(canary, metricsProvider); err == nil {
+			observerFactory = observerFactory.WithRecords(records)
+		}
+	}
 	observer := observerFactory.Observer(metricsProvider)
 
 	// run metrics checks
-	for _, metric := range canary.GetAnalysis().Metrics {
+	for _, metric := range analysisMetrics(canary) {
 		if metric.Interval == "" {
 			metric.Interval = canary.GetMetricInterval()
 		}
 
-		if metric.Name == "request-success-rate" {
+		if metric.Name == flaggerv1.BuiltinMetricRequestSuccessRate {
 			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric.Interval, metric.TemplateVariables))
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
@@ -146,8 +215,13 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
-			c.recorder.SetAnalysis(canary, metric.Name, val)
-			if metric.ThresholdRange != nil {
+			c.recorder.SetAnalysis(canary, metric.Name, metric.Interval, val)
+			c.recordMetric(canary, metric.Name, val)
+			if metric.SLO != nil {
+				if !c.checkSLO(canary, metric, val) {
+					return false
+				}
+			} else if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
@@ -166,7 +240,7 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 			}
 		}
 
-		if metric.Name == "request-duration" {
+		if metric.Name == flaggerv1.BuiltinMetricRequestDuration {
 			val, err := observer.GetRequestDuration(toMetricModel(canary, metric.Interval, metric.TemplateVariables))
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
@@ -177,7 +251,8 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
-			c.recorder.SetAnalysis(canary, metric.Name, val.Seconds())
+			c.recorder.SetAnalysis(canary, metric.Name, metric.Interval, val.Seconds())
+			c.recordMetric(canary, metric.Name, float64(val)/float64(time.Millisecond))
 			if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
@@ -210,8 +285,13 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 				}
 				return false
 			}
-			c.recorder.SetAnalysis(canary, metric.Name, val)
-			if metric.ThresholdRange != nil {
+			c.recorder.SetAnalysis(canary, metric.Name, metric.Interval, val)
+			c.recordMetric(canary, metric.Name, val)
+			if metric.SLO != nil {
+				if !c.checkSLO(canary, metric, val) {
+					return false
+				}
+			} else if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
@@ -235,48 +315,9 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 }
 
 func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
-	for _, metric := range canary.GetAnalysis().Metrics {
+	for _, metric := range analysisMetrics(canary) {
 		if metric.TemplateRef != nil {
-			namespace := canary.Namespace
-			if metric.TemplateRef.Namespace != canary.Namespace && metric.TemplateRef.Namespace != "" {
-				namespace = metric.TemplateRef.Namespace
-			}
-
-			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
-				return false
-			}
-
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					c.recordEventErrorf(canary, "Metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-					return false
-				}
-				credentials = secret.Data
-			}
-
-			factory := providers.Factory{}
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
-					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
-				return false
-			}
-
-			query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, metric.Interval, metric.TemplateVariables))
-			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, namespace)).
-				Debugf("Metric template %s.%s query: %s", metric.TemplateRef.Name, namespace, query)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
-					metric.TemplateRef.Name, namespace, err)
-				return false
-			}
-
-			val, err := provider.RunQuery(query)
+			val, err := c.runMetricTemplateQuery(canary, *metric.TemplateRef, metric.Interval, metric.TemplateVariables)
 			if err != nil {
 				if errors.Is(err, providers.ErrNoValuesFound) {
 					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s: %v",
@@ -287,9 +328,14 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 				return false
 			}
 
-			c.recorder.SetAnalysis(canary, metric.Name, val)
+			c.recorder.SetAnalysis(canary, metric.Name, metric.Interval, val)
+			c.recordMetric(canary, metric.Name, val)
 
-			if metric.ThresholdRange != nil {
+			if metric.SLO != nil {
+				if !c.checkSLO(canary, metric, val) {
+					return false
+				}
+			} else if metric.ThresholdRange != nil {
 				tr := *metric.ThresholdRange
 				if tr.Min != nil && val < *tr.Min {
 					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
@@ -312,6 +358,163 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 	return true
 }
 
+// runRollbackWindowChecks runs the post-promotion metric checks, the builtin metrics
+// are skipped as they measure the canary workload
+func (c *Controller) runRollbackWindowChecks(canary *flaggerv1.Canary) bool {
+	cd := canary.DeepCopy()
+	cd.GetAnalysis().Metrics = nil
+	for _, metric := range canary.GetAnalysis().RollbackWindow.Metrics {
+		if !isBuiltinMetric(metric.Name) {
+			cd.GetAnalysis().Metrics = append(cd.GetAnalysis().Metrics, metric)
+		}
+	}
+
+	return c.runBuiltinMetricChecks(cd) && c.runMetricChecks(cd)
+}
+
+// runMetricTemplateQuery renders the query of the referenced metric template and runs it against the template provider
+func (c *Controller) runMetricTemplateQuery(canary *flaggerv1.Canary, ref flaggerv1.CrossNamespaceObjectReference,
+	interval string, variables map[string]string) (float64, error) {
+	return c.runMetricTemplateModelQuery(canary, ref, interval, toMetricModel(canary, interval, variables))
+}
+
+// runMetricTemplateModelQuery renders the query of the referenced metric template with the given model
+func (c *Controller) runMetricTemplateModelQuery(canary *flaggerv1.Canary, ref flaggerv1.CrossNamespaceObjectReference,
+	interval string, model flaggerv1.MetricTemplateModel) (float64, error) {
+	namespace := canary.Namespace
+	if ref.Namespace != canary.Namespace && ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(ref.Name)
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s error: %w", ref.Name, namespace, err)
+	}
+
+	var credentials map[string][]byte
+	if template.Spec.Provider.SecretRef != nil {
+		secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("metric template %s.%s secret %s error: %w",
+				ref.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+		}
+		credentials = secret.Data
+	}
+
+	factory := providers.Factory{}
+	provider, err := factory.Provider(interval, template.Spec.Provider, credentials)
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s provider %s error: %w",
+			ref.Name, namespace, template.Spec.Provider.Type, err)
+	}
+
+	query, err := observers.RenderQuery(template.Spec.Query, model)
+	c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Debugf("Metric template %s.%s query: %s", ref.Name, namespace, query)
+	if err != nil {
+		return 0, fmt.Errorf("metric template %s.%s query render error: %w", ref.Name, namespace, err)
+	}
+
+	return provider.RunQuery(query)
+}
+
+// runSampleSizeChecks returns false if any of the metrics has less samples than required in its interval
+func (c *Controller) runSampleSizeChecks(canary *flaggerv1.Canary) (bool, error) {
+	for _, metric := range analysisMetrics(canary) {
+		if metric.SampleSize == nil {
+			continue
+		}
+
+		interval := metric.Interval
+		if interval == "" {
+			interval = canary.GetMetricInterval()
+		}
+
+		val, err := c.runMetricTemplateQuery(canary, metric.SampleSize.TemplateRef, interval, metric.TemplateVariables)
+		if err != nil && !errors.Is(err, providers.ErrNoValuesFound) {
+			return false, fmt.Errorf("sample size query failed for %s: %w", metric.Name, err)
+		}
+
+		if val < metric.SampleSize.Min {
+			c.recordEventInfof(canary, "Waiting for %s.%s metric %s samples %.0f < %v",
+				canary.Name, canary.Namespace, metric.Name, val, metric.SampleSize.Min)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// checkSLO validates a success rate in percent against the metric SLO,
+// the analysis is halted when the canary burns the error budget faster than the max burn rate
+func (c *Controller) checkSLO(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric, val float64) bool {
+	burnRate, err := sloBurnRate(*metric.SLO, val)
+	if err != nil {
+		c.recordEventErrorf(canary, "Metric %s SLO error: %v", metric.Name, err)
+		return false
+	}
+	if burnRate > metric.SLO.GetMaxBurnRate() {
+		c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f%% error budget burn rate %.2f > %v (SLO %v%%)",
+			canary.Name, canary.Namespace, metric.Name, val, burnRate, metric.SLO.GetMaxBurnRate(), metric.SLO.Objective)
+		return false
+	}
+	return true
+}
+
+// sloBurnRate returns the rate at which the error budget is consumed
+// for the given success rate, a burn rate of 1 exhausts the budget exactly at the end of the SLO window
+func sloBurnRate(slo flaggerv1.CanarySLO, successRate float64) (float64, error) {
+	if slo.Objective <= 0 || slo.Objective >= 100 {
+		return 0, fmt.Errorf("objective %v must be between 0 and 100", slo.Objective)
+	}
+	errorRate := 100 - successRate
+	if errorRate < 0 {
+		errorRate = 0
+	}
+	return errorRate / (100 - slo.Objective), nil
+}
+
+// analysisMetrics returns the metrics to be checked for the current canary weight
+func analysisMetrics(canary *flaggerv1.Canary) []flaggerv1.CanaryMetric {
+	return expandMetricWindows(applyStepThresholds(canary.GetAnalysis().Metrics, canary.Status.CanaryWeight))
+}
+
+// applyStepThresholds sets the threshold range of each metric to the step threshold
+// with the highest weight lower or equal to the canary weight
+func applyStepThresholds(metrics []flaggerv1.CanaryMetric, weight int) []flaggerv1.CanaryMetric {
+	res := make([]flaggerv1.CanaryMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		match := -1
+		for _, st := range metric.StepThresholds {
+			if st.Weight <= weight && st.Weight > match {
+				match = st.Weight
+				tr := st.ThresholdRange
+				metric.ThresholdRange = &tr
+			}
+		}
+		res = append(res, metric)
+	}
+	return res
+}
+
+// expandMetricWindows returns a copy of the metric for each of its windows,
+// the analysis passes only if the metric passes in every window
+func expandMetricWindows(metrics []flaggerv1.CanaryMetric) []flaggerv1.CanaryMetric {
+	var res []flaggerv1.CanaryMetric
+	for _, metric := range metrics {
+		if len(metric.Windows) == 0 {
+			res = append(res, metric)
+			continue
+		}
+		for _, window := range metric.Windows {
+			m := metric
+			m.Interval = window
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
 func toMetricModel(r *flaggerv1.Canary, interval string, variables map[string]string) flaggerv1.MetricTemplateModel {
 	service := r.Spec.TargetRef.Name
 	if r.Spec.Service.Name != "" {
@@ -332,6 +535,7 @@ func toMetricModel(r *flaggerv1.Canary, interval string, variables map[string]st
 		Service:   service,
 		Ingress:   ingress,
 		Route:     route,
+		Baseline:  fmt.Sprintf("%s-baseline", r.Spec.TargetRef.Name),
 		Interval:  interval,
 		Variables: variables,
 	}
@@ -49,6 +49,15 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
 	})
 
+	t.Run("unknown", func(t *testing.T) {
+		analysis := &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{Name: "istio_requests_total"}}}
+		canary := &flaggerv1.Canary{Spec: flaggerv1.CanarySpec{Analysis: analysis}}
+		obs, err := observers.NewFactory(testMetricsServerURL)
+		require.NoError(t, err)
+		ctrl := Controller{observerFactory: obs, logger: zap.S(), eventRecorder: &record.FakeRecorder{}}
+		require.Error(t, ctrl.checkMetricProviderAvailability(canary))
+	})
+
 	t.Run("templateRef", func(t *testing.T) {
 		ctrl := newDeploymentFixture(nil).ctrl
 
@@ -107,4 +116,119 @@ func TestController_runMetricChecks(t *testing.T) {
 		}
 		assert.Equal(t, true, ctrl.runMetricChecks(canary))
 	})
+
+	t.Run("slo", func(t *testing.T) {
+		ctrl := newDeploymentFixture(nil).ctrl
+		analysis := &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+			Name: "success-rate",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{
+				Name:      "envoy",
+				Namespace: "default",
+			},
+			SLO: &flaggerv1.CanarySLO{
+				Objective:   99.9,
+				MaxBurnRate: 2,
+			},
+		}}}
+		canary := &flaggerv1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
+		}
+		assert.Equal(t, true, ctrl.runMetricChecks(canary))
+
+		canary.Spec.Analysis.Metrics[0].SLO.Objective = 100
+		assert.Equal(t, false, ctrl.runMetricChecks(canary))
+	})
+}
+
+func TestController_sloBurnRate(t *testing.T) {
+	slo := flaggerv1.CanarySLO{Objective: 99}
+
+	burnRate, err := sloBurnRate(slo, 100)
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), burnRate)
+
+	burnRate, err = sloBurnRate(slo, 99)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, burnRate, 0.0001)
+
+	burnRate, err = sloBurnRate(slo, 95)
+	require.NoError(t, err)
+	assert.InDelta(t, 5, burnRate, 0.0001)
+
+	_, err = sloBurnRate(flaggerv1.CanarySLO{Objective: 100}, 99)
+	require.Error(t, err)
+}
+
+func TestController_runSampleSizeChecks(t *testing.T) {
+	ctrl := newDeploymentFixture(nil).ctrl
+	analysis := &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+		Name:     "error-rate",
+		Interval: "1m",
+		SampleSize: &flaggerv1.CanaryMetricSampleSize{
+			Min: 10,
+			TemplateRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:      "envoy",
+				Namespace: "default",
+			},
+		},
+	}}}
+	canary := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+		Spec:       flaggerv1.CanarySpec{Analysis: analysis},
+	}
+
+	ok, err := ctrl.runSampleSizeChecks(canary)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// hold until enough samples
+	canary.Spec.Analysis.Metrics[0].SampleSize.Min = 1000
+	ok, err = ctrl.runSampleSizeChecks(canary)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// template not found
+	canary.Spec.Analysis.Metrics[0].SampleSize.TemplateRef.Name = "non-exist"
+	_, err = ctrl.runSampleSizeChecks(canary)
+	require.Error(t, err)
+}
+
+func TestController_expandMetricWindows(t *testing.T) {
+	metrics := []flaggerv1.CanaryMetric{
+		{Name: "request-success-rate", Interval: "1m"},
+		{Name: "error-rate", Interval: "1m", Windows: []string{"1m", "5m"}},
+	}
+
+	res := expandMetricWindows(metrics)
+	require.Len(t, res, 3)
+	assert.Equal(t, "request-success-rate", res[0].Name)
+	assert.Equal(t, "1m", res[0].Interval)
+	assert.Equal(t, "error-rate", res[1].Name)
+	assert.Equal(t, "1m", res[1].Interval)
+	assert.Equal(t, "error-rate", res[2].Name)
+	assert.Equal(t, "5m", res[2].Interval)
+}
+
+func TestController_applyStepThresholds(t *testing.T) {
+	metrics := []flaggerv1.CanaryMetric{{
+		Name:           "request-success-rate",
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(90)},
+		StepThresholds: []flaggerv1.CanaryStepThreshold{
+			{Weight: 50, ThresholdRange: flaggerv1.CanaryThresholdRange{Min: toFloatPtr(99)}},
+			{Weight: 20, ThresholdRange: flaggerv1.CanaryThresholdRange{Min: toFloatPtr(95)}},
+		},
+	}}
+
+	res := applyStepThresholds(metrics, 10)
+	assert.Equal(t, float64(90), *res[0].ThresholdRange.Min)
+
+	res = applyStepThresholds(metrics, 20)
+	assert.Equal(t, float64(95), *res[0].ThresholdRange.Min)
+
+	res = applyStepThresholds(metrics, 60)
+	assert.Equal(t, float64(99), *res[0].ThresholdRange.Min)
+
+	// the spec is not mutated
+	assert.Equal(t, float64(90), *metrics[0].ThresholdRange.Min)
 }
@@ -0,0 +1,174 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// getNamespaceQuota returns the max number of canaries that can run analysis at the same time
+// in the canary namespace, the namespace annotation takes precedence over the global setting
+// and is ignored with namespaced RBAC since namespaces are cluster scoped
+func (c *Controller) getNamespaceQuota(canary *flaggerv1.Canary) int {
+	limit := c.getMaxConcurrentCanaries()
+	if c.namespacedRBAC {
+		return limit
+	}
+
+	ns, err := c.getNamespace(canary.Namespace)
+	if err != nil {
+		return limit
+	}
+
+	if value, ok := ns.Annotations[flaggerv1.MaxConcurrentCanariesAnnotation]; ok {
+		nsLimit, err := strconv.Atoi(value)
+		if err != nil || nsLimit < 0 {
+			c.recordEventWarningf(canary, "Invalid %s annotation %q on namespace %s",
+				flaggerv1.MaxConcurrentCanariesAnnotation, value, canary.Namespace)
+			return limit
+		}
+		return nsLimit
+	}
+	return limit
+}
+
+// namespaceCacheTTL is the max age of the namespaces read by getNamespace
+const namespaceCacheTTL = time.Minute
+
+// cachedNamespace is the result of a namespace lookup
+type cachedNamespace struct {
+	namespace *corev1.Namespace
+	err       error
+	expires   time.Time
+}
+
+// getNamespace returns the namespace from a short-lived cache, the failed lookups are cached
+// as well and the error is logged only when it differs from the previous lookup
+func (c *Controller) getNamespace(name string) (*corev1.Namespace, error) {
+	var prev cachedNamespace
+	if value, ok := c.namespaces.Load(name); ok {
+		prev = value.(cachedNamespace)
+		if time.Now().Before(prev.expires) {
+			return prev.namespace, prev.err
+		}
+	}
+
+	ns, err := c.kubeClient.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("namespace %s get query error: %w", name, err)
+		if prev.err == nil || prev.err.Error() != err.Error() {
+			c.logger.Warnf("%v, the namespace annotations and labels are ignored", err)
+		}
+	}
+	c.namespaces.Store(name, cachedNamespace{namespace: ns, err: err, expires: time.Now().Add(namespaceCacheTTL)})
+	return ns, err
+}
+
+// isRunningAnalysis returns true if the canary is between the start of the analysis and the end of the promotion
+func isRunningAnalysis(canary *flaggerv1.Canary) bool {
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return true
+	}
+	return false
+}
+
+// quotaReservation is a slot taken by a canary that passed the quota check
+// but whose Progressing phase is not yet visible in the informer cache,
+// the reservation expires after one analysis interval
+type quotaReservation struct {
+	namespace string
+	slot      string
+	expires   time.Time
+}
+
+// quotaSlot returns the slot taken by a running canary, the canaries of a group share one slot
+func quotaSlot(canary *flaggerv1.Canary) string {
+	if canary.Spec.Group != "" {
+		return "group/" + canary.Spec.Group
+	}
+	return "canary/" + canary.Name
+}
+
+// hasNamespaceQuota returns false when the namespace already runs the max number of canaries,
+// the canary stays queued until one of the running canaries finishes.
+// The canaries of a group take a single slot so that the group members can run in lockstep.
+// The check and the reservation of the slot are done under a lock so that two canaries
+// starting in the same interval can't both take the last slot.
+func (c *Controller) hasNamespaceQuota(canary *flaggerv1.Canary) bool {
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	key := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+	// drop the previous reservation, the canary didn't start if it's checking the quota again
+	c.quotaReservations.Delete(key)
+
+	limit := c.getNamespaceQuota(canary)
+	if limit == 0 {
+		return true
+	}
+
+	list, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(canary.Namespace).List(labels.Everything())
+	if err != nil {
+		c.recordEventWarningf(canary, "canaries %s list query error: %v", canary.Namespace, err)
+		return false
+	}
+
+	slots := make(map[string]bool)
+	for _, item := range list {
+		if item.Name == canary.Name || !isRunningAnalysis(item) {
+			continue
+		}
+		// the reservation is no longer needed once the cache shows the canary running
+		c.quotaReservations.Delete(fmt.Sprintf("%s.%s", item.Name, item.Namespace))
+		slots[quotaSlot(item)] = true
+	}
+	c.quotaReservations.Range(func(key, value interface{}) bool {
+		r := value.(quotaReservation)
+		if time.Now().After(r.expires) {
+			c.quotaReservations.Delete(key)
+			return true
+		}
+		if r.namespace == canary.Namespace {
+			slots[r.slot] = true
+		}
+		return true
+	})
+
+	if !slots[quotaSlot(canary)] && len(slots) >= limit {
+		c.recordEventInfof(canary, "Waiting for a free slot, %v of %v concurrent canaries are running in namespace %s",
+			len(slots), limit, canary.Namespace)
+		return false
+	}
+
+	c.quotaReservations.Store(key, quotaReservation{
+		namespace: canary.Namespace,
+		slot:      quotaSlot(canary),
+		expires:   time.Now().Add(canary.GetAnalysisInterval()),
+	})
+	return true
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_hasNamespaceQuota(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.maxConcurrentCanaries = 1
+
+	first := newDeploymentTestCanary()
+	second := newDeploymentTestCanary()
+	second.Name = "podinfo-second"
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(second))
+
+	// the cache doesn't show any running canary yet,
+	// the reservation taken by the first canary must queue the second one
+	assert.True(t, mocks.ctrl.hasNamespaceQuota(first))
+	assert.False(t, mocks.ctrl.hasNamespaceQuota(second))
+
+	// the reservation is dropped when the first canary is deleted
+	mocks.ctrl.quotaReservations.Delete("podinfo.default")
+	assert.True(t, mocks.ctrl.hasNamespaceQuota(second))
+
+	// the running canary is counted from the cache once the reservation is dropped
+	running := first.DeepCopy()
+	running.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(running))
+	mocks.ctrl.quotaReservations.Delete("podinfo-second.default")
+	assert.False(t, mocks.ctrl.hasNamespaceQuota(second))
+}
+
+func TestController_NamespacedRBAC(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = flaggerv1.IstioProvider
+	mocks := newDeploymentFixture(cd)
+	mocks.ctrl.maxConcurrentCanaries = 1
+
+	ns := newTestNamespace()
+	ns.Annotations = map[string]string{flaggerv1.MaxConcurrentCanariesAnnotation: "2"}
+	_, err := mocks.kubeClient.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, mocks.ctrl.getNamespaceQuota(cd))
+
+	// namespaces can't be read with namespaced RBAC
+	kubeClient := mocks.kubeClient.(*fake.Clientset)
+	kubeClient.PrependReactor("get", "namespaces", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(action.GetResource().GroupResource(), "default", nil)
+	})
+	mocks.ctrl.namespacedRBAC = true
+	kubeClient.ClearActions()
+
+	// the global quota is used and the sidecar check doesn't fail
+	assert.Equal(t, 1, mocks.ctrl.getNamespaceQuota(cd))
+	_, err = mocks.ctrl.checkSidecarInjected(cd, flaggerv1.IstioProvider)
+	require.NoError(t, err)
+	for _, action := range kubeClient.Actions() {
+		assert.NotEqual(t, "namespaces", action.GetResource().Resource)
+	}
+}
+
+func TestController_getNamespace(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	kubeClient := mocks.kubeClient.(*fake.Clientset)
+	kubeClient.PrependReactor("get", "namespaces", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(action.GetResource().GroupResource(), "default", nil)
+	})
+	kubeClient.ClearActions()
+
+	// the failed lookup is cached until it expires
+	_, err := mocks.ctrl.getNamespace("default")
+	require.Error(t, err)
+	_, err = mocks.ctrl.getNamespace("default")
+	require.Error(t, err)
+	assert.Len(t, kubeClient.Actions(), 1)
+
+	// the namespace is read again once the cache entry expires
+	mocks.ctrl.namespaces.Store("default", cachedNamespace{err: err, expires: time.Now()})
+	_, err = mocks.ctrl.getNamespace("default")
+	require.Error(t, err)
+	assert.Len(t, kubeClient.Actions(), 2)
+}
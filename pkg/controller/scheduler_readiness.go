@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// isPrimaryTrafficReady returns an error until every ready primary pod is registered
+// as a ready endpoint of the primary service for longer than the readiness gate delay,
+// the gate is checked only before the traffic is first shifted
+func (c *Controller) isPrimaryTrafficReady(cd *flaggerv1.Canary, labelSelector string, labelValue string) error {
+	if cd.GetAnalysis().PrimaryReadinessGate == nil || cd.Spec.TargetRef.Kind == "Service" || !isTrafficShiftPending(cd) {
+		return nil
+	}
+
+	delay, err := cd.GetPrimaryReadinessDelay()
+	if err != nil {
+		return err
+	}
+
+	_, primaryName, _ := cd.GetServiceNames()
+	endpoints, err := c.kubeClient.CoreV1().Endpoints(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("endpoints %s.%s get query error: %w", primaryName, cd.Namespace, err)
+	}
+	registered := make(map[string]bool)
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+				registered[address.TargetRef.Name] = true
+			}
+		}
+	}
+
+	pods, err := c.getPrimaryPods(cd, labelSelector, labelValue)
+	if err != nil {
+		return err
+	}
+
+	var readySince time.Time
+	for _, pod := range pods {
+		ready := podReadyCondition(&pod)
+		if ready == nil || ready.Status != corev1.ConditionTrue {
+			return fmt.Errorf("waiting for primary pod %s.%s to be ready", pod.Name, cd.Namespace)
+		}
+		if !registered[pod.Name] {
+			return fmt.Errorf("waiting for primary pod %s.%s to be registered as an endpoint of service %s",
+				pod.Name, cd.Namespace, primaryName)
+		}
+		if ready.LastTransitionTime.After(readySince) {
+			readySince = ready.LastTransitionTime.Time
+		}
+	}
+
+	if !readySince.IsZero() && time.Since(readySince) < delay {
+		return fmt.Errorf("waiting %v for the primary endpoints of service %s.%s to be programmed",
+			delay-time.Since(readySince).Round(time.Second), primaryName, cd.Namespace)
+	}
+	return nil
+}
+
+// isTrafficShiftPending returns true before the analysis shifts the first traffic to the canary
+// and while the promotion routes the traffic back to the primary, so that a primary pod
+// that isn't ready mid-analysis (e.g. during a scale-up) doesn't halt the rollout
+func isTrafficShiftPending(cd *flaggerv1.Canary) bool {
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing:
+		return cd.Status.CanaryWeight == 0 && cd.Status.Iterations == 0
+	case flaggerv1.CanaryPhasePromoting:
+		return true
+	default:
+		return false
+	}
+}
+
+// getPrimaryPods returns the primary pods matched by the workload label selector,
+// the pods marked for deletion are skipped
+func (c *Controller) getPrimaryPods(cd *flaggerv1.Canary, labelSelector string, labelValue string) ([]corev1.Pod, error) {
+	selector := fmt.Sprintf("%s=%s-primary", labelSelector, labelValue)
+	pods, err := c.kubeClient.CoreV1().Pods(cd.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("pods %s list query error: %w", selector, err)
+	}
+
+	result := make([]corev1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+func podReadyCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodReady {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
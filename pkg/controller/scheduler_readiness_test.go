@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newReadinessTestPod(name string, readySince time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "podinfo-primary"},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(readySince),
+			}},
+		},
+	}
+}
+
+func TestController_isPrimaryTrafficReady(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	kubeClient := fake.NewSimpleClientset(
+		newReadinessTestPod("podinfo-primary-1", time.Now().Add(-time.Hour)),
+		newReadinessTestPod("podinfo-primary-2", time.Now().Add(-5*time.Second)),
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-primary", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-1"}},
+				},
+				NotReadyAddresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-2"}},
+				},
+			}},
+		},
+	)
+	ctrl := &Controller{kubeClient: kubeClient}
+
+	// disabled
+	require.NoError(t, ctrl.isPrimaryTrafficReady(cd, "app", "podinfo"))
+
+	// pod not registered
+	cd.Spec.Analysis.PrimaryReadinessGate = &flaggerv1.CanaryReadinessGate{Delay: "30s"}
+	err := ctrl.isPrimaryTrafficReady(cd, "app", "podinfo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "podinfo-primary-2")
+
+	// waiting for the delay
+	endpoints, err := kubeClient.CoreV1().Endpoints("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	endpoints.Subsets[0].Addresses = append(endpoints.Subsets[0].Addresses, endpoints.Subsets[0].NotReadyAddresses...)
+	endpoints.Subsets[0].NotReadyAddresses = nil
+	_, err = kubeClient.CoreV1().Endpoints("default").Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = ctrl.isPrimaryTrafficReady(cd, "app", "podinfo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "to be programmed")
+
+	// ready
+	cd.Spec.Analysis.PrimaryReadinessGate.Delay = ""
+	require.NoError(t, ctrl.isPrimaryTrafficReady(cd, "app", "podinfo"))
+
+	// pod not ready
+	pod := newReadinessTestPod("podinfo-primary-3", time.Now())
+	pod.Status.Conditions[0].Status = corev1.ConditionFalse
+	_, err = kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	err = ctrl.isPrimaryTrafficReady(cd, "app", "podinfo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "podinfo-primary-3.default to be ready")
+
+	// skipped once the traffic is shifted
+	cd.Status.CanaryWeight = 10
+	require.NoError(t, ctrl.isPrimaryTrafficReady(cd, "app", "podinfo"))
+
+	// checked again when the promotion routes the traffic back to the primary
+	cd.Status.Phase = flaggerv1.CanaryPhasePromoting
+	require.Error(t, ctrl.isPrimaryTrafficReady(cd, "app", "podinfo"))
+}
+
+func TestController_isPrimaryServingTraffic(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	kubeClient := fake.NewSimpleClientset(
+		// the primary pods are matched by label, not by the target name prefix
+		newReadinessTestPod("worker-0", time.Now()),
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "worker-0"}},
+					{IP: "10.0.0.2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-canary"}},
+				},
+			}},
+		},
+	)
+	ctrl := &Controller{kubeClient: kubeClient}
+
+	err := ctrl.isPrimaryServingTraffic(cd, "app", "podinfo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 non-primary endpoints")
+
+	endpoints, err := kubeClient.CoreV1().Endpoints("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	endpoints.Subsets[0].Addresses = endpoints.Subsets[0].Addresses[:1]
+	_, err = kubeClient.CoreV1().Endpoints("default").Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, ctrl.isPrimaryServingTraffic(cd, "app", "podinfo"))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// LinkRingClusters gives each controller access to the canaries of the other controllers' clusters,
+// so that a canary can reference the previous promotion ring in another cluster by its cluster name
+func LinkRingClusters(controllers ...*Controller) {
+	clusters := new(sync.Map)
+	for _, ctrl := range controllers {
+		clusters.Store(ctrl.clusterName, ctrl.flaggerClient)
+		ctrl.ringClusters = clusters
+	}
+}
+
+// JoinRingClusters links a controller that was created after startup, e.g. for a remote cluster
+// that became reachable later on, to the clusters of an already linked controller
+func JoinRingClusters(ctrl *Controller, linked *Controller) {
+	linked.ringClusters.Store(ctrl.clusterName, ctrl.flaggerClient)
+	ctrl.ringClusters = linked.ringClusters
+}
+
+// getPreviousRing returns the canary of the previous promotion ring and the name of its cluster
+func (c *Controller) getPreviousRing(cd *flaggerv1.Canary) (*flaggerv1.Canary, string, error) {
+	ref := cd.Spec.PreviousRing
+	client := c.flaggerClient
+	cluster := c.clusterName
+	if ref.Cluster != "" && ref.Cluster != c.clusterName {
+		var rc interface{}
+		ok := false
+		if c.ringClusters != nil {
+			rc, ok = c.ringClusters.Load(ref.Cluster)
+		}
+		if !ok {
+			return nil, ref.Cluster, fmt.Errorf("previous ring cluster %s not found", ref.Cluster)
+		}
+		client = rc.(clientset.Interface)
+		cluster = ref.Cluster
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = cd.Namespace
+	}
+	if c.noCrossNamespaceRefs && cluster == c.clusterName && namespace != cd.Namespace {
+		return nil, cluster, fmt.Errorf("previous ring canary %s.%s is in another namespace and cross-namespace references are disabled",
+			ref.Name, namespace)
+	}
+
+	previous, err := client.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, cluster, fmt.Errorf("previous ring canary %s.%s get query error: %w", ref.Name, namespace, err)
+	}
+	return previous, cluster, nil
+}
+
+// isPreviousRingPromoted returns true when the canary has no previous ring or when the
+// canary of the previous ring has successfully promoted the images of the new revision,
+// otherwise the canary waits for the previous ring before starting the analysis
+func (c *Controller) isPreviousRingPromoted(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	if cd.Spec.PreviousRing == nil {
+		return true
+	}
+
+	previous, cluster, err := c.getPreviousRing(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+
+	if previous.Status.Phase == flaggerv1.CanaryPhaseSucceeded &&
+		sameImages(previous.Status.Images, c.targetImages(cd)) {
+		return true
+	}
+
+	if cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseWaiting); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+		}
+		location := fmt.Sprintf("%s.%s", previous.Name, previous.Namespace)
+		if cluster != "" {
+			location = fmt.Sprintf("%s in cluster %s", location, cluster)
+		}
+		c.recordEventInfof(cd, "Halt %s.%s advancement waiting for the previous ring canary %s to promote the revision",
+			cd.Name, cd.Namespace, location)
+	}
+	return false
+}
+
+// sameImages returns true when both lists contain the same image for each container
+func sameImages(a, b []flaggerv1.CanaryImage) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	images := make(map[string]string, len(a))
+	for _, image := range a {
+		images[image.Container] = image.Image
+	}
+	for _, image := range b {
+		if ref, ok := images[image.Container]; !ok || ref != image.Image {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+func TestScheduler_PreviousRing(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.PreviousRing = &flaggerv1.CanaryRingReference{
+		Cluster: "dev",
+		Name:    "podinfo",
+	}
+	mocks := newDeploymentFixture(cd)
+	dev := newDeploymentFixture(nil)
+	dev.ctrl.clusterName = "dev"
+	LinkRingClusters(mocks.ctrl, dev.ctrl)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the previous ring has not promoted the new revision
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseWaiting))
+
+	// the previous ring has promoted the new revision
+	previous, err := dev.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	previous.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	previous.Status.Images = canary.PodImages(dep2.Spec.Template.Spec)
+	_, err = dev.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), previous, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+}
+
+func TestScheduler_PreviousRingUnknownCluster(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.PreviousRing = &flaggerv1.CanaryRingReference{
+		Cluster: "staging",
+		Name:    "podinfo",
+	}
+	mocks := newDeploymentFixture(cd)
+
+	_, cluster, err := mocks.ctrl.getPreviousRing(cd)
+	require.Error(t, err)
+	assert.Equal(t, "staging", cluster)
+}
+
+func TestSameImages(t *testing.T) {
+	a := []flaggerv1.CanaryImage{
+		{Container: "podinfo", Image: "ghcr.io/stefanprodan/podinfo:6.0.1"},
+		{Container: "sidecar", Image: "envoy:1.22"},
+	}
+	b := []flaggerv1.CanaryImage{
+		{Container: "sidecar", Image: "envoy:1.22"},
+		{Container: "podinfo", Image: "ghcr.io/stefanprodan/podinfo:6.0.1"},
+	}
+	assert.True(t, sameImages(a, b))
+
+	b[1].Image = "ghcr.io/stefanprodan/podinfo:6.0.2"
+	assert.False(t, sameImages(a, b))
+	assert.False(t, sameImages(nil, nil))
+}
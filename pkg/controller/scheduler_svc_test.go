@@ -47,6 +47,30 @@ func TestScheduler_ServicePromotionWithWeightsOverflow(t *testing.T) {
 	testServicePromotion(t, newTestServiceCanaryWithWeightsOverflow(), []int{totalWeight, 99, 98, 90, 0})
 }
 
+func TestScheduler_ServiceCustomMetadata(t *testing.T) {
+	canary := newTestServiceCanary()
+	canary.Spec.Service.Primary = &flaggerv1.CustomMetadata{
+		Labels:      map[string]string{"role": "primary"},
+		Annotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "primary.example.com"},
+	}
+	canary.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Labels: map[string]string{"role": "canary"},
+	}
+	mocks := newDeploymentFixture(canary)
+
+	// init
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	primarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", primarySvc.Labels["role"])
+	assert.Equal(t, "primary.example.com", primarySvc.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+
+	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "canary", canarySvc.Labels["role"])
+}
+
 func testServicePromotion(t *testing.T, canary *flaggerv1.Canary, expectedPrimaryWeigths []int) {
 	mocks := newDeploymentFixture(canary)
 
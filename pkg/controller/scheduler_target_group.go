@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	"github.com/fluxcd/flagger/pkg/router"
+)
+
+// targetGroupMember bundles the canary copy of a target group member with its Kubernetes router
+type targetGroupMember struct {
+	canary        *flaggerv1.Canary
+	router        router.KubernetesRouter
+	labelSelector string
+	labelValue    string
+}
+
+// getTargetGroupMembers returns the target group members of the canary
+func (c *Controller) getTargetGroupMembers(cd *flaggerv1.Canary) ([]targetGroupMember, error) {
+	var members []targetGroupMember
+	for _, member := range cd.Spec.TargetGroup {
+		mcd := canary.TargetGroupMember(cd, member)
+		labelSelector, labelValue, ports, err := c.canaryFactory.Controller(mcd.Spec.TargetRef).GetMetadata(mcd)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, targetGroupMember{
+			canary:        mcd,
+			router:        c.routerFactory.KubernetesRouter(mcd.Spec.TargetRef.Kind, labelSelector, labelValue, ports),
+			labelSelector: labelSelector,
+			labelValue:    labelValue,
+		})
+	}
+	return members, nil
+}
+
+// reconcileTargetGroupServices creates the ClusterIP services of the target group members,
+// the member apex services always route to the primaries as the canaries are reached
+// through the member canary services
+func (c *Controller) reconcileTargetGroupServices(cd *flaggerv1.Canary) error {
+	members, err := c.getTargetGroupMembers(cd)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := member.router.Initialize(member.canary); err != nil {
+			return err
+		}
+		if err := member.router.Reconcile(member.canary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTargetGroupServingTraffic returns an error until the apex services of the
+// target group members route the traffic only to ready primary pods
+func (c *Controller) isTargetGroupServingTraffic(cd *flaggerv1.Canary) error {
+	members, err := c.getTargetGroupMembers(cd)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := c.isPrimaryServingTraffic(member.canary, member.labelSelector, member.labelValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalizeTargetGroupServices reverts the apex services of the target group members
+func (c *Controller) finalizeTargetGroupServices(cd *flaggerv1.Canary) error {
+	members, err := c.getTargetGroupMembers(cd)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := member.router.Finalize(member.canary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTargetGroupMetricChecks runs the analysis metrics against every target group member,
+// a failed member halts the advancement of the whole group
+func (c *Controller) runTargetGroupMetricChecks(cd *flaggerv1.Canary) bool {
+	for _, member := range cd.Spec.TargetGroup {
+		mcd := canary.TargetGroupMember(cd, member)
+		if !c.runBuiltinMetricChecks(mcd) || !c.runMetricChecks(mcd) {
+			c.recordEventWarningf(cd, "Halt %s.%s advancement target group member %s.%s failed the metric checks",
+				cd.Name, cd.Namespace, member.Name, cd.Namespace)
+			return false
+		}
+	}
+	return true
+}
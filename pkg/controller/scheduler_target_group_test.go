@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTargetGroupFixture(t *testing.T) fixture {
+	cd := newDeploymentTestCanary()
+	cd.Spec.TargetGroup = []flaggerv1.CanaryTargetGroupMember{{Name: "backend", Port: 8080}}
+	mocks := newDeploymentFixture(cd)
+
+	backend := newDeploymentTestDeployment()
+	backend.Name = "backend"
+	backend.Spec.Selector.MatchLabels = map[string]string{"app": "backend"}
+	backend.Spec.Template.Labels = map[string]string{"app": "backend"}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), backend, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	pod := newDeploymentTestPrimaryPod()
+	pod.Name = "backend-primary-7f9c6d5b4c-m3n8p"
+	pod.Labels = map[string]string{"app": "backend-primary"}
+	_, err = mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	endpoints := newDeploymentTestEndpoints()
+	endpoints.Name = "backend"
+	endpoints.Subsets[0].Addresses[0].TargetRef.Name = pod.Name
+	_, err = mocks.kubeClient.CoreV1().Endpoints("default").Create(context.TODO(), endpoints, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	return mocks
+}
+
+func initializeTargetGroupFixture(t *testing.T, mocks fixture) {
+	// create the target primary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// create the member primary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.Error(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+	mocks.makeReady(t, "backend-primary")
+
+	// initialization done
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+}
+
+func TestScheduler_TargetGroupInit(t *testing.T) {
+	mocks := newTargetGroupFixture(t)
+	initializeTargetGroupFixture(t, mocks)
+
+	// the member apex service routes to the member primary
+	svc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "backend-primary", svc.Spec.Selector["app"])
+	assert.Equal(t, int32(8080), svc.Spec.Ports[0].Port)
+
+	svc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "backend-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "backend", svc.Spec.Selector["app"])
+
+	for _, name := range []string{"podinfo", "backend"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32p(0), dep.Spec.Replicas)
+	}
+}
+
+func TestScheduler_TargetGroupRollback(t *testing.T) {
+	mocks := newTargetGroupFixture(t)
+	initializeTargetGroupFixture(t, mocks)
+
+	// a new revision of the member starts the analysis of the group
+	backend, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	backend.Spec.Template.Spec.Containers[0].Image = "quay.io/stefanprodan/podinfo:6.0.1"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), backend, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	for _, name := range []string{"podinfo", "backend"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.NotEqual(t, int32p(0), dep.Spec.Replicas)
+	}
+
+	// reach the failed checks threshold
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Status.FailedChecks = 10
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.makeCanaryReady(t)
+	mocks.makeReady(t, "backend")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFailed))
+
+	// the rollback scales down every member of the group
+	for _, name := range []string{"podinfo", "backend"} {
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32p(0), dep.Spec.Replicas)
+	}
+
+	// the member primary keeps running the previous revision
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, "quay.io/stefanprodan/podinfo:6.0.1", primary.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_TargetGroupFinalize(t *testing.T) {
+	mocks := newTargetGroupFixture(t)
+	initializeTargetGroupFixture(t, mocks)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	mocks.makeCanaryReady(t)
+	mocks.makeReady(t, "backend")
+	require.NoError(t, mocks.ctrl.finalize(c))
+
+	// the member is scaled back to the primary replicas
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32p(1), dep.Spec.Replicas)
+}
+
+func TestController_runTargetGroupMetricChecks(t *testing.T) {
+	// the backend reports an error rate above the threshold
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), "backend") {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"10"]}]}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"0"]}]}}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	cd := newDeploymentTestCanary()
+	cd.Spec.MetricsServer = ts.URL
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:      "error-rate",
+		Interval:  "1m",
+		Threshold: 1,
+		Query:     `sum(rate(http_requests_total{workload="{{ target }}",status="5xx"}[{{ interval }}]))`,
+	}}
+	assert.True(t, ctrl.runTargetGroupMetricChecks(cd))
+	assert.True(t, ctrl.runAnalysis(cd))
+
+	cd.Spec.TargetGroup = []flaggerv1.CanaryTargetGroupMember{{Name: "backend"}}
+	assert.False(t, ctrl.runTargetGroupMetricChecks(cd))
+	assert.False(t, ctrl.runAnalysis(cd))
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+// Settings holds the global controller options that can be reloaded at runtime
+type Settings struct {
+	MetricsServer         string
+	ControlLoopInterval   time.Duration
+	MeshProvider          string
+	EventWebhook          string
+	SlackURL              string
+	SlackToken            string
+	SlackProxyURL         string
+	SlackUser             string
+	SlackChannel          string
+	MSTeamsURL            string
+	MSTeamsProxyURL       string
+	MaxConcurrentCanaries int
+}
+
+// Notifier returns the global notifier, MS Teams takes precedence over Slack when both are configured
+func (s Settings) Notifier() (notifier.Interface, error) {
+	if s.MSTeamsURL != "" {
+		return notifier.NewFactory(s.MSTeamsURL, s.SlackToken, s.MSTeamsProxyURL, s.SlackUser, s.SlackChannel).
+			Notifier("msteams")
+	}
+	return notifier.NewFactory(s.SlackURL, s.SlackToken, s.SlackProxyURL, s.SlackUser, s.SlackChannel).
+		Notifier("slack")
+}
+
+// settingsKeys maps the ConfigMap keys to the settings fields, the keys match the command line flags
+var settingsKeys = map[string]func(s *Settings, value string) error{
+	"metrics-server": func(s *Settings, value string) error {
+		s.MetricsServer = value
+		return nil
+	},
+	"control-loop-interval": func(s *Settings, value string) error {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("interval must be greater than zero")
+		}
+		s.ControlLoopInterval = interval
+		return nil
+	},
+	"mesh-provider": func(s *Settings, value string) error {
+		s.MeshProvider = value
+		return nil
+	},
+	"event-webhook": func(s *Settings, value string) error {
+		s.EventWebhook = value
+		return nil
+	},
+	"slack-url": func(s *Settings, value string) error {
+		s.SlackURL = value
+		return nil
+	},
+	"slack-proxy-url": func(s *Settings, value string) error {
+		s.SlackProxyURL = value
+		return nil
+	},
+	"slack-user": func(s *Settings, value string) error {
+		s.SlackUser = value
+		return nil
+	},
+	"slack-channel": func(s *Settings, value string) error {
+		s.SlackChannel = value
+		return nil
+	},
+	"msteams-url": func(s *Settings, value string) error {
+		s.MSTeamsURL = value
+		return nil
+	},
+	"msteams-proxy-url": func(s *Settings, value string) error {
+		s.MSTeamsProxyURL = value
+		return nil
+	},
+	"max-concurrent-canaries": func(s *Settings, value string) error {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if limit < 0 {
+			return fmt.Errorf("limit must be zero or greater")
+		}
+		s.MaxConcurrentCanaries = limit
+		return nil
+	},
+}
+
+// SettingsFromConfigMap overrides the defaults with the values found in the ConfigMap data
+func SettingsFromConfigMap(data map[string]string, defaults Settings) (Settings, error) {
+	settings := defaults
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		set, ok := settingsKeys[key]
+		if !ok {
+			return defaults, fmt.Errorf("unknown setting %s", key)
+		}
+		if err := set(&settings, strings.TrimSpace(data[key])); err != nil {
+			return defaults, fmt.Errorf("invalid setting %s: %w", key, err)
+		}
+	}
+	return settings, nil
+}
+
+// ApplySettings replaces the global options of the controller,
+// the canaries pick up the new options on their next analysis run
+func (c *Controller) ApplySettings(settings Settings) error {
+	observerFactory, err := observers.NewFactory(settings.MetricsServer)
+	if err != nil {
+		return fmt.Errorf("error building prometheus client: %w", err)
+	}
+
+	notifierClient, err := settings.Notifier()
+	if err != nil {
+		return fmt.Errorf("error building notifier: %w", err)
+	}
+
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+
+	c.observerFactory = observerFactory
+	c.notifier = notifierClient
+	c.meshProvider = settings.MeshProvider
+	c.eventWebhook = settings.EventWebhook
+	c.maxConcurrentCanaries = settings.MaxConcurrentCanaries
+	if settings.ControlLoopInterval > 0 && settings.ControlLoopInterval != c.flaggerWindow {
+		c.flaggerWindow = settings.ControlLoopInterval
+		// replace any pending interval that the control loop didn't pick up yet
+		select {
+		case <-c.flaggerWindowCh:
+		default:
+		}
+		select {
+		case c.flaggerWindowCh <- settings.ControlLoopInterval:
+		default:
+		}
+	}
+	return nil
+}
+
+func (c *Controller) getObserverFactory() *observers.Factory {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.observerFactory
+}
+
+func (c *Controller) getNotifier() notifier.Interface {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.notifier
+}
+
+func (c *Controller) getMeshProvider() string {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.meshProvider
+}
+
+func (c *Controller) getEventWebhook() string {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.eventWebhook
+}
+
+func (c *Controller) getMaxConcurrentCanaries() int {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.maxConcurrentCanaries
+}
+
+// SettingsWatcher applies the settings ConfigMap to a set of controllers
+type SettingsWatcher struct {
+	mu          sync.Mutex
+	controllers []*Controller
+	settings    *Settings
+	logger      *zap.SugaredLogger
+}
+
+// Add applies the last accepted settings to a controller created after the watcher started,
+// e.g. for a remote cluster that became reachable later on, and includes it in the next reloads
+func (w *SettingsWatcher) Add(ctrl *Controller) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.controllers = append(w.controllers, ctrl)
+	if w.settings != nil {
+		if err := ctrl.ApplySettings(*w.settings); err != nil {
+			w.logger.Errorf("Settings rejected for cluster %s: %v", ctrl.clusterName, err)
+		}
+	}
+}
+
+// WatchSettings applies the settings found in the ConfigMap to the controllers every time the ConfigMap changes,
+// the defaults are restored when the ConfigMap is deleted
+func WatchSettings(kubeClient kubernetes.Interface, namespace, name string, defaults Settings,
+	logger *zap.SugaredLogger, stopCh <-chan struct{}, controllers ...*Controller) *SettingsWatcher {
+	watcher := &SettingsWatcher{controllers: controllers, logger: logger}
+	factory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 5*time.Minute,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fmt.Sprintf("metadata.name=%s", name)
+		}))
+
+	apply := func(data map[string]string) {
+		settings, err := SettingsFromConfigMap(data, defaults)
+		if err != nil {
+			logger.Errorf("Settings ConfigMap %s.%s rejected: %v", name, namespace, err)
+			return
+		}
+		watcher.mu.Lock()
+		defer watcher.mu.Unlock()
+		watcher.settings = &settings
+		for _, ctrl := range watcher.controllers {
+			if err := ctrl.ApplySettings(settings); err != nil {
+				logger.Errorf("Settings ConfigMap %s.%s rejected: %v", name, namespace, err)
+				return
+			}
+		}
+		logger.Infof("Settings reloaded from ConfigMap %s.%s", name, namespace)
+	}
+
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if cm, ok := obj.(*corev1.ConfigMap); ok {
+				apply(cm.Data)
+			}
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldCm, ok := old.(*corev1.ConfigMap)
+			if !ok {
+				return
+			}
+			newCm, ok := new.(*corev1.ConfigMap)
+			if ok && oldCm.ResourceVersion != newCm.ResourceVersion {
+				apply(newCm.Data)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			apply(nil)
+		},
+	})
+
+	go informer.Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger-settings", stopCh, informer.HasSynced); !ok {
+		logger.Errorf("Failed to wait for settings ConfigMap %s.%s cache to sync", name, namespace)
+	}
+	return watcher
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+func TestSettingsFromConfigMap(t *testing.T) {
+	defaults := Settings{
+		MetricsServer:       "http://prometheus:9090",
+		ControlLoopInterval: 10 * time.Second,
+		MeshProvider:        "istio",
+		SlackUser:           "flagger",
+	}
+
+	t.Run("override", func(t *testing.T) {
+		settings, err := SettingsFromConfigMap(map[string]string{
+			"metrics-server":          "http://thanos:9090",
+			"control-loop-interval":   "30s",
+			"mesh-provider":           "linkerd",
+			"slack-url":               "https://hooks.slack.com/services/test",
+			"max-concurrent-canaries": "3",
+		}, defaults)
+		require.NoError(t, err)
+		assert.Equal(t, "http://thanos:9090", settings.MetricsServer)
+		assert.Equal(t, 30*time.Second, settings.ControlLoopInterval)
+		assert.Equal(t, "linkerd", settings.MeshProvider)
+		assert.Equal(t, "https://hooks.slack.com/services/test", settings.SlackURL)
+		assert.Equal(t, "flagger", settings.SlackUser)
+		assert.Equal(t, 3, settings.MaxConcurrentCanaries)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		settings, err := SettingsFromConfigMap(map[string]string{"control-loop-interval": "1x"}, defaults)
+		require.Error(t, err)
+		assert.Equal(t, defaults, settings)
+
+		_, err = SettingsFromConfigMap(map[string]string{"mesh-providers": "linkerd"}, defaults)
+		require.Error(t, err)
+	})
+}
+
+func TestController_ApplySettings(t *testing.T) {
+	ctrl := Controller{
+		logger:          zap.S(),
+		notifier:        &notifier.NopNotifier{},
+		meshProvider:    "istio",
+		flaggerWindow:   10 * time.Second,
+		flaggerWindowCh: make(chan time.Duration, 1),
+	}
+
+	err := ctrl.ApplySettings(Settings{
+		MetricsServer:       testMetricsServerURL,
+		ControlLoopInterval: 20 * time.Second,
+		MeshProvider:        "linkerd",
+		EventWebhook:        "http://events:8080",
+		SlackURL:            "https://hooks.slack.com/services/test",
+		SlackUser:           "flagger",
+		SlackChannel:        "general",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "linkerd", ctrl.getMeshProvider())
+	assert.Equal(t, "http://events:8080", ctrl.getEventWebhook())
+	assert.IsType(t, &notifier.Slack{}, ctrl.getNotifier())
+	require.NotNil(t, ctrl.getObserverFactory())
+
+	// the control loop picks up the latest interval
+	err = ctrl.ApplySettings(Settings{MetricsServer: testMetricsServerURL, ControlLoopInterval: 30 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, <-ctrl.flaggerWindowCh)
+	assert.IsType(t, &notifier.NopNotifier{}, ctrl.getNotifier())
+}
+
+func TestWatchSettings(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "flagger-settings", Namespace: "flagger-system"},
+		Data:       map[string]string{"mesh-provider": "linkerd"},
+	})
+	ctrl := &Controller{
+		logger:          zap.S(),
+		meshProvider:    "istio",
+		flaggerWindow:   10 * time.Second,
+		flaggerWindowCh: make(chan time.Duration, 1),
+	}
+	defaults := Settings{MetricsServer: testMetricsServerURL, ControlLoopInterval: 10 * time.Second, MeshProvider: "istio"}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	watcher := WatchSettings(kubeClient, "flagger-system", "flagger-settings", defaults, zap.S(), stopCh, ctrl)
+	assert.Equal(t, "linkerd", ctrl.getMeshProvider())
+
+	// a controller added later on gets the current settings
+	remote := &Controller{
+		logger:          zap.S(),
+		meshProvider:    "istio",
+		flaggerWindow:   10 * time.Second,
+		flaggerWindowCh: make(chan time.Duration, 1),
+	}
+	watcher.Add(remote)
+	assert.Equal(t, "linkerd", remote.getMeshProvider())
+
+	// the defaults are restored when the ConfigMap is deleted
+	err := kubeClient.CoreV1().ConfigMaps("flagger-system").Delete(context.TODO(), "flagger-settings", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ctrl.getMeshProvider() == "istio" && remote.getMeshProvider() == "istio"
+	}, 5*time.Second, 100*time.Millisecond)
+}
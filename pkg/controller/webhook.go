@@ -19,6 +19,9 @@ package controller
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,12 +29,40 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
-func callWebhook(webhook string, payload interface{}, timeout string) error {
+// WebhookCredentials holds the bearer token and the mutual TLS config read from the webhook secret
+type WebhookCredentials struct {
+	Token     string
+	TLSConfig *tls.Config
+
+	client *http.Client
+}
+
+// webhookClients holds the HTTP clients of the webhooks using mutual TLS indexed by
+// the checksum of the TLS secret data, so that connections are reused across calls
+var webhookClients sync.Map
+
+// webhookClient returns the shared HTTP client for the TLS config,
+// the transport is cloned from the default one to keep the proxy from environment settings
+func webhookClient(key string, config *tls.Config) *http.Client {
+	if c, ok := webhookClients.Load(key); ok {
+		return c.(*http.Client)
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = config
+	c, _ := webhookClients.LoadOrStore(key, &http.Client{Transport: t})
+	return c.(*http.Client)
+}
+
+func callWebhook(webhook string, payload interface{}, timeout string, credentials *WebhookCredentials) error {
 	payloadBin, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -49,6 +80,10 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 
 	req.Header.Set("Content-Type", "application/json")
 
+	if credentials != nil && credentials.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", credentials.Token))
+	}
+
 	if timeout == "" {
 		timeout = "10s"
 	}
@@ -61,7 +96,12 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 	ctx, cancel := context.WithTimeout(req.Context(), t)
 	defer cancel()
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	client := http.DefaultClient
+	if credentials != nil && credentials.client != nil {
+		client = credentials.client
+	}
+
+	r, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -81,25 +121,37 @@ func callWebhook(webhook string, payload interface{}, timeout string) error {
 
 // CallWebhook does a HTTP POST to an external service and
 // returns an error if the response status code is non-2xx
-func CallWebhook(name string, namespace string, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+func CallWebhook(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook, credentials *WebhookCredentials) error {
+	return callWebhookWithPayload(w, newWebhookPayload(canary, phase, w), credentials)
+}
+
+func newWebhookPayload(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) flaggerv1.CanaryWebhookPayload {
 	payload := flaggerv1.CanaryWebhookPayload{
-		Name:      name,
-		Namespace: namespace,
-		Phase:     phase,
+		Name:         canary.Name,
+		Namespace:    canary.Namespace,
+		Phase:        phase,
+		CanaryWeight: canary.Status.CanaryWeight,
 	}
 
 	if w.Metadata != nil {
 		payload.Metadata = *w.Metadata
 	}
+	return payload
+}
 
+func callWebhookWithPayload(w flaggerv1.CanaryWebhook, payload flaggerv1.CanaryWebhookPayload, credentials *WebhookCredentials) error {
 	if len(w.Timeout) < 2 {
 		w.Timeout = "10s"
 	}
 
-	return callWebhook(w.URL, payload, w.Timeout)
+	return callWebhook(w.URL, payload, w.Timeout, credentials)
+}
+
+func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string, credentials *WebhookCredentials) error {
+	return callWebhook(w.URL, newEventWebhookPayload(r, w, message, eventtype), "5s", credentials)
 }
 
-func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string) error {
+func newEventWebhookPayload(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, eventtype string) flaggerv1.CanaryWebhookPayload {
 	t := time.Now()
 
 	payload := flaggerv1.CanaryWebhookPayload{
@@ -121,5 +173,89 @@ func CallEventWebhook(r *flaggerv1.Canary, w flaggerv1.CanaryWebhook, message, e
 			payload.Metadata[key] = value
 		}
 	}
-	return callWebhook(w.URL, payload, "5s")
+	return payload
+}
+
+// callCanaryWebhook calls the webhook using the credentials from the webhook secret, if any
+func (c *Controller) callCanaryWebhook(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase, w flaggerv1.CanaryWebhook) error {
+	credentials, err := c.webhookCredentials(canary, &w)
+	if err == nil {
+		payload := newWebhookPayload(canary, phase, w)
+		payload.Metrics = c.getMetricValues(canary)
+		err = callWebhookWithPayload(w, payload, credentials)
+	}
+	c.reportWebhook(canary, w, err)
+	return err
+}
+
+// callTrafficIncreaseWebhook calls a confirm-traffic-increase gate with the weight the canary will receive if the gate passes
+func (c *Controller) callTrafficIncreaseWebhook(canary *flaggerv1.Canary, w flaggerv1.CanaryWebhook, nextWeight int) error {
+	credentials, err := c.webhookCredentials(canary, &w)
+	if err == nil {
+		payload := newWebhookPayload(canary, flaggerv1.CanaryPhaseProgressing, w)
+		payload.NextWeight = nextWeight
+		payload.Metrics = c.getMetricValues(canary)
+		err = callWebhookWithPayload(w, payload, credentials)
+	}
+	c.reportWebhook(canary, w, err)
+	return err
+}
+
+// webhookCredentials reads the webhook secret, the address field takes precedence over the webhook URL,
+// the token field is sent as a bearer token and the tls.crt, tls.key and ca.crt fields are used for mutual TLS
+func (c *Controller) webhookCredentials(canary *flaggerv1.Canary, w *flaggerv1.CanaryWebhook) (*WebhookCredentials, error) {
+	if w.SecretRef == nil {
+		return nil, nil
+	}
+
+	secret, err := c.kubeClient.CoreV1().Secrets(canary.Namespace).Get(context.TODO(), w.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secret %s.%s get query failed: %w", w.SecretRef.Name, canary.Namespace, err)
+	}
+
+	if address, ok := secret.Data["address"]; ok {
+		w.URL = string(address)
+	}
+
+	credentials := &WebhookCredentials{
+		Token: string(secret.Data["token"]),
+	}
+
+	_, hasCert := secret.Data["tls.crt"]
+	_, hasCA := secret.Data["ca.crt"]
+	if !hasCert && !hasCA {
+		return credentials, nil
+	}
+
+	h := sha256.New()
+	for _, k := range []string{"tls.crt", "tls.key", "ca.crt"} {
+		h.Write(secret.Data[k])
+		h.Write([]byte{0})
+	}
+	key := fmt.Sprintf("%x", h.Sum(nil))
+	if c, ok := webhookClients.Load(key); ok {
+		credentials.client = c.(*http.Client)
+		credentials.TLSConfig = credentials.client.Transport.(*http.Transport).TLSClientConfig
+		return credentials, nil
+	}
+
+	credentials.TLSConfig = &tls.Config{}
+	if hasCert {
+		cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in secret %s.%s: %w", w.SecretRef.Name, canary.Namespace, err)
+		}
+		credentials.TLSConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if hasCA {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(secret.Data["ca.crt"]) {
+			return nil, fmt.Errorf("invalid CA certificate in secret %s.%s", w.SecretRef.Name, canary.Namespace)
+		}
+		credentials.TLSConfig.RootCAs = pool
+	}
+
+	credentials.client = webhookClient(key, credentials.TLSConfig)
+	return credentials, nil
 }
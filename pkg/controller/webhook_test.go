@@ -17,11 +17,21 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,6 +43,10 @@ import (
 
 func TestCallWebhook(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload flaggerv1.CanaryWebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "podinfo", payload.Name)
+		require.Equal(t, 20, payload.CanaryWeight)
 		w.WriteHeader(http.StatusAccepted)
 	}))
 	defer ts.Close()
@@ -43,7 +57,7 @@ func TestCallWebhook(t *testing.T) {
 		Metadata: &map[string]string{"key1": "val1"},
 	}
 
-	err := CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	err := CallWebhook(newWebhookTestCanary(), flaggerv1.CanaryPhaseProgressing, hook, nil)
 	require.NoError(t, err)
 }
 
@@ -57,10 +71,175 @@ func TestCallWebhook_StatusCode(t *testing.T) {
 		URL:  ts.URL,
 	}
 
-	err := CallWebhook("podinfo", v1.NamespaceDefault, flaggerv1.CanaryPhaseProgressing, hook)
+	err := CallWebhook(newWebhookTestCanary(), flaggerv1.CanaryPhaseProgressing, hook, nil)
 	assert.Error(t, err)
 }
 
+func TestCallWebhook_MutualTLS(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "flagger-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "flagger"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	_, err = mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "webhook-tls", Namespace: "default"},
+		Data: map[string][]byte{
+			"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDER}),
+			"tls.key": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: clientKeyDER}),
+			"ca.crt":  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}),
+		},
+	}, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	hook := flaggerv1.CanaryWebhook{
+		Name: "gate",
+		URL:  ts.URL,
+	}
+
+	// the server requires a client certificate
+	err = mocks.ctrl.callCanaryWebhook(newWebhookTestCanary(), flaggerv1.CanaryPhaseProgressing, hook)
+	require.Error(t, err)
+
+	hook.SecretRef = &corev1.LocalObjectReference{Name: "webhook-tls"}
+	err = mocks.ctrl.callCanaryWebhook(newWebhookTestCanary(), flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+
+	// the HTTP client is shared by the calls using the same secret
+	c1, err := mocks.ctrl.webhookCredentials(newWebhookTestCanary(), &hook)
+	require.NoError(t, err)
+	c2, err := mocks.ctrl.webhookCredentials(newWebhookTestCanary(), &hook)
+	require.NoError(t, err)
+	require.Same(t, c1.client, c2.client)
+	require.NotNil(t, c1.client.Transport.(*http.Transport).Proxy)
+}
+
+func TestCallWebhook_SecretRef(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/gate", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "webhook-auth", Namespace: "default"},
+		Data: map[string][]byte{
+			"address": []byte(ts.URL + "/gate"),
+			"token":   []byte("token"),
+		},
+	}, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	hook := flaggerv1.CanaryWebhook{
+		Name:      "gate",
+		URL:       "http://flagger-loadtester.test/",
+		SecretRef: &corev1.LocalObjectReference{Name: "webhook-auth"},
+	}
+
+	err = mocks.ctrl.callCanaryWebhook(newWebhookTestCanary(), flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+}
+
+func TestController_checkWebhooksReachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	_, err := mocks.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "webhook-address", Namespace: "default"},
+		Data:       map[string][]byte{"address": []byte(ts.URL + "/gate")},
+	}, v1.CreateOptions{})
+	require.NoError(t, err)
+
+	// the address from the secret takes precedence over the URL
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Webhooks = []flaggerv1.CanaryWebhook{
+		{Name: "direct", URL: ts.URL},
+		{Name: "secret", URL: "http://127.0.0.1:1/", SecretRef: &corev1.LocalObjectReference{Name: "webhook-address"}},
+	}
+	_, err = mocks.ctrl.checkWebhooksReachable(canary, "")
+	require.NoError(t, err)
+
+	canary.Spec.Analysis.Webhooks = append(canary.Spec.Analysis.Webhooks,
+		flaggerv1.CanaryWebhook{Name: "missing-secret", URL: ts.URL, SecretRef: &corev1.LocalObjectReference{Name: "missing"}},
+		flaggerv1.CanaryWebhook{Name: "closed", URL: "http://127.0.0.1:1/"},
+	)
+	_, err = mocks.ctrl.checkWebhooksReachable(canary, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing-secret")
+	assert.Contains(t, err.Error(), "closed")
+	assert.NotContains(t, err.Error(), "direct")
+}
+
+func TestCallWebhook_Metrics(t *testing.T) {
+	var payload flaggerv1.CanaryWebhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	canary := newWebhookTestCanary()
+	mocks.ctrl.recordMetric(canary, "request-success-rate", 99.5)
+	mocks.ctrl.recordMetric(canary, "request-duration", 120)
+
+	hook := flaggerv1.CanaryWebhook{Name: "gate", URL: ts.URL}
+	err := mocks.ctrl.callCanaryWebhook(canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"request-success-rate": 99.5, "request-duration": 120}, payload.Metrics)
+	assert.Equal(t, "request-duration: 120.00, request-success-rate: 99.50", formatMetricValues(payload.Metrics))
+
+	// the values of the previous analysis are not sent
+	mocks.ctrl.resetMetricValues(canary)
+	payload = flaggerv1.CanaryWebhookPayload{}
+	err = mocks.ctrl.callCanaryWebhook(canary, flaggerv1.CanaryPhaseProgressing, hook)
+	require.NoError(t, err)
+	assert.Empty(t, payload.Metrics)
+}
+
 func TestCallEventWebhook(t *testing.T) {
 	canaryName := "podinfo"
 	canaryNamespace := v1.NamespaceDefault
@@ -115,7 +294,7 @@ func TestCallEventWebhook(t *testing.T) {
 		},
 	}
 
-	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType)
+	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType, nil)
 	require.NoError(t, err)
 }
 
@@ -143,6 +322,18 @@ func TestCallEventWebhookStatusCode(t *testing.T) {
 		},
 	}
 
-	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType)
+	err := CallEventWebhook(canary, hook, canaryMessage, canaryEventType, nil)
 	assert.Error(t, err)
 }
+
+func newWebhookTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "podinfo",
+			Namespace: v1.NamespaceDefault,
+		},
+		Status: flaggerv1.CanaryStatus{
+			CanaryWeight: 20,
+		},
+	}
+}
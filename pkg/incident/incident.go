@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Incident is an open incident reported by the incident management tool
+type Incident struct {
+	ID       string
+	Name     string
+	Severity string
+	URL      string
+}
+
+// String returns the incident name and severity
+func (i Incident) String() string {
+	if i.Severity == "" {
+		return i.Name
+	}
+	return fmt.Sprintf("%s (%s)", i.Name, i.Severity)
+}
+
+// Interface lists the unresolved incidents of an incident management tool
+type Interface interface {
+	OpenIncidents() ([]Incident, error)
+}
+
+// NewProvider returns the client of the incident management tool
+func NewProvider(provider string, address string, token string) (Interface, error) {
+	switch provider {
+	case "statuspage":
+		return NewStatuspage(address)
+	case "incidentio":
+		return NewIncidentIO(address, token)
+	case "pagerduty":
+		return NewPagerDuty(address, token)
+	default:
+		return nil, fmt.Errorf("incident provider %s not supported", provider)
+	}
+}
+
+// Watcher polls the incident management tool and keeps the open incidents
+// matching the severities, the traffic shifting is held while an incident is open
+type Watcher struct {
+	provider   Interface
+	interval   time.Duration
+	severities map[string]bool
+	logger     *zap.SugaredLogger
+
+	mu        sync.RWMutex
+	incidents []Incident
+}
+
+// NewWatcher returns a watcher for the incidents of the given severities, all severities if empty
+func NewWatcher(provider Interface, interval time.Duration, severities []string, logger *zap.SugaredLogger) *Watcher {
+	w := &Watcher{
+		provider:   provider,
+		interval:   interval,
+		severities: make(map[string]bool),
+		logger:     logger,
+	}
+	for _, s := range severities {
+		if s = strings.TrimSpace(s); s != "" {
+			w.severities[strings.ToLower(s)] = true
+		}
+	}
+	return w
+}
+
+// Run polls the incident management tool until the stop channel is closed
+func (w *Watcher) Run(stopCh <-chan struct{}) {
+	w.Poll()
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			w.Poll()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Poll refreshes the open incidents, the last known incidents are kept
+// when the incident management tool is unreachable
+func (w *Watcher) Poll() {
+	incidents, err := w.provider.OpenIncidents()
+	if err != nil {
+		w.logger.Errorf("Incidents query failed: %v", err)
+		return
+	}
+
+	var open []Incident
+	for _, incident := range incidents {
+		if len(w.severities) == 0 || w.severities[strings.ToLower(incident.Severity)] {
+			open = append(open, incident)
+		}
+	}
+
+	w.mu.Lock()
+	previous := len(w.incidents)
+	w.incidents = open
+	w.mu.Unlock()
+
+	switch {
+	case previous == 0 && len(open) > 0:
+		w.logger.Infof("Traffic shifting held, incident %s is open", open[0])
+	case previous > 0 && len(open) == 0:
+		w.logger.Info("Traffic shifting resumed, all incidents are resolved")
+	}
+}
+
+// Open returns the open incidents
+func (w *Watcher) Open() []Incident {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]Incident(nil), w.incidents...)
+}
+
+// getJSON sends a GET request with the headers and decodes the JSON response
+func getJSON(client *http.Client, url string, headers map[string]string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error response: %s: %s", resp.Status, string(b))
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeProvider struct {
+	incidents []Incident
+	err       error
+}
+
+func (f *fakeProvider) OpenIncidents() ([]Incident, error) {
+	return f.incidents, f.err
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	provider := &fakeProvider{}
+	w := NewWatcher(provider, time.Minute, []string{"SEV1", " sev2"}, zap.S())
+
+	w.Poll()
+	assert.Empty(t, w.Open())
+
+	// filter by severity
+	provider.incidents = []Incident{
+		{ID: "1", Name: "Checkout errors", Severity: "SEV3"},
+		{ID: "2", Name: "Database outage", Severity: "SEV1"},
+		{ID: "3", Name: "Login latency", Severity: "SEV2"},
+	}
+	w.Poll()
+	open := w.Open()
+	require.Len(t, open, 2)
+	assert.Equal(t, "Database outage (SEV1)", open[0].String())
+	assert.Equal(t, "3", open[1].ID)
+
+	// keep the last known incidents on errors
+	provider.err = errors.New("unreachable")
+	w.Poll()
+	assert.Len(t, w.Open(), 2)
+
+	// resolved
+	provider.incidents, provider.err = nil, nil
+	w.Poll()
+	assert.Empty(t, w.Open())
+}
+
+func TestWatcher_AllSeverities(t *testing.T) {
+	provider := &fakeProvider{incidents: []Incident{{ID: "1", Name: "Outage"}}}
+	w := NewWatcher(provider, time.Minute, []string{""}, zap.S())
+	w.Poll()
+	assert.Len(t, w.Open(), 1)
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider("statuspage", "https://status.example.com", "")
+	require.NoError(t, err)
+	_, err = NewProvider("statuspage", "", "")
+	require.Error(t, err)
+	_, err = NewProvider("incidentio", "", "token")
+	require.NoError(t, err)
+	_, err = NewProvider("pagerduty", "", "")
+	require.Error(t, err)
+	_, err = NewProvider("opsgenie", "", "token")
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// https://api-docs.incident.io/tag/Incidents-V2
+const (
+	incidentIOAddress       = "https://api.incident.io"
+	incidentIOIncidentsPath = "/v2/incidents?status_category[one_of]=live&page_size=250"
+)
+
+// IncidentIO lists the live incidents of incident.io,
+// the severity name e.g. SEV1 is used as severity
+type IncidentIO struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+type incidentIOResponse struct {
+	Incidents []struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Reference string `json:"reference"`
+		Permalink string `json:"permalink"`
+		Severity  *struct {
+			Name string `json:"name"`
+		} `json:"severity"`
+	} `json:"incidents"`
+}
+
+// NewIncidentIO returns a client for the incident.io API, the address defaults to https://api.incident.io
+func NewIncidentIO(address string, token string) (*IncidentIO, error) {
+	if address == "" {
+		address = incidentIOAddress
+	}
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("incident.io address %s is not a valid URL", address)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("incident.io API key is required")
+	}
+	return &IncidentIO{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  http.DefaultClient,
+	}, nil
+}
+
+func (p *IncidentIO) OpenIncidents() ([]Incident, error) {
+	var result incidentIOResponse
+	headers := map[string]string{"Authorization": "Bearer " + p.token}
+	if err := getJSON(p.client, p.address+incidentIOIncidentsPath, headers, &result); err != nil {
+		return nil, fmt.Errorf("incident.io %w", err)
+	}
+
+	var incidents []Incident
+	for _, i := range result.Incidents {
+		incident := Incident{
+			ID:   i.ID,
+			Name: i.Name,
+			URL:  i.Permalink,
+		}
+		if i.Reference != "" {
+			incident.Name = fmt.Sprintf("%s %s", i.Reference, i.Name)
+		}
+		if i.Severity != nil {
+			incident.Severity = i.Severity.Name
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, nil
+}
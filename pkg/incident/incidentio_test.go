@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentIO_OpenIncidents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/incidents", r.URL.Path)
+		assert.Equal(t, "live", r.URL.Query().Get("status_category[one_of]"))
+		assert.Equal(t, "Bearer api-key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"incidents":[{"id":"01FDAG4SAP5TYPT98WGR2N7W91","name":"Our database is sad","reference":"INC-123","permalink":"https://app.incident.io/incidents/123","severity":{"id":"01FH5TZRWMNAFB0DZ23FD1TV96","name":"SEV1","rank":1}},{"id":"01FDAG4SAP5TYPT98WGR2N7W92","name":"Triage","reference":"INC-124"}]}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewIncidentIO(ts.URL, "api-key")
+	require.NoError(t, err)
+
+	incidents, err := client.OpenIncidents()
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, "INC-123 Our database is sad", incidents[0].Name)
+	assert.Equal(t, "SEV1", incidents[0].Severity)
+	assert.Equal(t, "https://app.incident.io/incidents/123", incidents[0].URL)
+	assert.Empty(t, incidents[1].Severity)
+}
+
+func TestIncidentIO_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	client, err := NewIncidentIO(ts.URL, "api-key")
+	require.NoError(t, err)
+
+	_, err = client.OpenIncidents()
+	require.Error(t, err)
+}
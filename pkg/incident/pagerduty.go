@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// https://developer.pagerduty.com/api-reference/9d0b4b12e36f9-list-incidents
+const (
+	pagerDutyAddress       = "https://api.pagerduty.com"
+	pagerDutyIncidentsPath = "/incidents?statuses[]=triggered&statuses[]=acknowledged&limit=100"
+)
+
+// PagerDuty lists the triggered and acknowledged incidents of PagerDuty,
+// the priority name e.g. P1 is used as severity or the urgency if the incident has no priority
+type PagerDuty struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+type pagerDutyResponse struct {
+	Incidents []struct {
+		ID       string `json:"id"`
+		Title    string `json:"title"`
+		Urgency  string `json:"urgency"`
+		HTMLURL  string `json:"html_url"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+	} `json:"incidents"`
+}
+
+// NewPagerDuty returns a client for the PagerDuty REST API, the address defaults to https://api.pagerduty.com
+func NewPagerDuty(address string, token string) (*PagerDuty, error) {
+	if address == "" {
+		address = pagerDutyAddress
+	}
+	if _, err := url.ParseRequestURI(address); err != nil {
+		return nil, fmt.Errorf("pagerduty address %s is not a valid URL", address)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("pagerduty API token is required")
+	}
+	return &PagerDuty{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  http.DefaultClient,
+	}, nil
+}
+
+func (p *PagerDuty) OpenIncidents() ([]Incident, error) {
+	var result pagerDutyResponse
+	headers := map[string]string{
+		"Authorization": "Token token=" + p.token,
+		"Accept":        "application/vnd.pagerduty+json;version=2",
+	}
+	if err := getJSON(p.client, p.address+pagerDutyIncidentsPath, headers, &result); err != nil {
+		return nil, fmt.Errorf("pagerduty %w", err)
+	}
+
+	var incidents []Incident
+	for _, i := range result.Incidents {
+		incident := Incident{
+			ID:       i.ID,
+			Name:     i.Title,
+			Severity: i.Urgency,
+			URL:      i.HTMLURL,
+		}
+		if i.Priority != nil && i.Priority.Name != "" {
+			incident.Severity = i.Priority.Name
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, nil
+}
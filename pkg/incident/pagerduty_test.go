@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDuty_OpenIncidents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/incidents", r.URL.Path)
+		assert.Equal(t, []string{"triggered", "acknowledged"}, r.URL.Query()["statuses[]"])
+		assert.Equal(t, "Token token=pd-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/vnd.pagerduty+json;version=2", r.Header.Get("Accept"))
+		w.Write([]byte(`{"incidents":[{"id":"PT4KHLK","title":"The server is on fire.","urgency":"high","html_url":"https://subdomain.pagerduty.com/incidents/PT4KHLK","priority":{"id":"P53ZZH5","name":"P1"}},{"id":"PT4KHLL","title":"Disk almost full","urgency":"low","priority":null}]}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewPagerDuty(ts.URL, "pd-token")
+	require.NoError(t, err)
+
+	incidents, err := client.OpenIncidents()
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, Incident{
+		ID:       "PT4KHLK",
+		Name:     "The server is on fire.",
+		Severity: "P1",
+		URL:      "https://subdomain.pagerduty.com/incidents/PT4KHLK",
+	}, incidents[0])
+	assert.Equal(t, "low", incidents[1].Severity)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// https://developer.statuspage.io/#operation/getPagesPageIdIncidentsUnresolved
+const statuspageUnresolvedPath = "/api/v2/incidents/unresolved.json"
+
+// Statuspage lists the unresolved incidents of an Atlassian Statuspage public page,
+// the incident impact (none, minor, major or critical) is used as severity
+type Statuspage struct {
+	address string
+	client  *http.Client
+}
+
+type statuspageResponse struct {
+	Incidents []struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Impact    string `json:"impact"`
+		Shortlink string `json:"shortlink"`
+	} `json:"incidents"`
+}
+
+// NewStatuspage returns a client for the status page at the address e.g. https://status.example.com
+func NewStatuspage(address string) (*Statuspage, error) {
+	if _, err := url.ParseRequestURI(address); address == "" || err != nil {
+		return nil, fmt.Errorf("statuspage address %s is not a valid URL", address)
+	}
+	return &Statuspage{
+		address: strings.TrimSuffix(address, "/"),
+		client:  http.DefaultClient,
+	}, nil
+}
+
+func (s *Statuspage) OpenIncidents() ([]Incident, error) {
+	var result statuspageResponse
+	if err := getJSON(s.client, s.address+statuspageUnresolvedPath, nil, &result); err != nil {
+		return nil, fmt.Errorf("statuspage %w", err)
+	}
+
+	var incidents []Incident
+	for _, i := range result.Incidents {
+		incidents = append(incidents, Incident{
+			ID:       i.ID,
+			Name:     i.Name,
+			Severity: i.Impact,
+			URL:      i.Shortlink,
+		})
+	}
+	return incidents, nil
+}
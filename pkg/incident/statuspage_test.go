@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incident
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatuspage_OpenIncidents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, statuspageUnresolvedPath, r.URL.Path)
+		w.Write([]byte(`{"page":{"id":"y2j98763l56x"},"incidents":[{"id":"p31zjtct2jer","name":"Data Layer Migration","status":"investigating","impact":"major","shortlink":"http://stspg.io/p31zjtct2jer"}]}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewStatuspage(ts.URL + "/")
+	require.NoError(t, err)
+
+	incidents, err := client.OpenIncidents()
+	require.NoError(t, err)
+	require.Len(t, incidents, 1)
+	assert.Equal(t, Incident{
+		ID:       "p31zjtct2jer",
+		Name:     "Data Layer Migration",
+		Severity: "major",
+		URL:      "http://stspg.io/p31zjtct2jer",
+	}, incidents[0])
+}
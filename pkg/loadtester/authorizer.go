@@ -24,14 +24,22 @@ import (
 
 type Authorizer struct {
 	namespaceRegexp *regexp.Regexp
+	peers           string
 }
 
-func NewAuthorizer(namespaceRegexp *regexp.Regexp) *Authorizer {
+func NewAuthorizer(namespaceRegexp *regexp.Regexp, peers string) *Authorizer {
 	return &Authorizer{
 		namespaceRegexp: namespaceRegexp,
+		peers:           peers,
 	}
 }
 
 func (a *Authorizer) Authorize(payload *flaggerv1.CanaryWebhookPayload) bool {
 	return a.namespaceRegexp == nil || a.namespaceRegexp.MatchString(payload.Namespace)
 }
+
+// AuthorizePeers returns true if the address is the load tester headless service,
+// distributed tasks can't be forwarded to arbitrary hosts
+func (a *Authorizer) AuthorizePeers(address string) bool {
+	return a.peers != "" && address == a.peers
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package loadtester
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
@@ -28,6 +29,10 @@ type BashTask struct {
 	TaskBase
 	command      string
 	logCmdOutput bool
+	// shell runs the command, defaults to bash
+	shell string
+	// splitOutput captures stdout and stderr separately and keeps the tail of each stream
+	splitOutput bool
 }
 
 func (task *BashTask) Hash() string {
@@ -35,11 +40,33 @@ func (task *BashTask) Hash() string {
 }
 
 func (task *BashTask) Run(ctx context.Context) (*TaskRunResult, error) {
-	cmd := exec.CommandContext(ctx, "bash", "-c", task.command)
-	out, err := cmd.CombinedOutput()
+	shell := task.shell
+	if shell == "" {
+		shell = "bash"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(shell, "-c", task.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stdout
+	if task.splitOutput {
+		cmd.Stderr = &stderr
+	}
+
+	err := runCommand(ctx, cmd)
+	out := stdout.Bytes()
+	if task.splitOutput {
+		out = formatCmdOutput(stdout.Bytes(), stderr.Bytes())
+	}
 
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out: %w", err)
+		}
 		task.logger.With("canary", task.canary).Errorf("command failed %s %v %s", task.command, err, out)
+		if task.splitOutput {
+			return &TaskRunResult{false, out}, fmt.Errorf("command %s failed: %w\n%s", task.command, err, out)
+		}
 		return &TaskRunResult{false, out}, fmt.Errorf("command %s failed: %s: %w", task.command, out, err)
 	} else {
 		if task.logCmdOutput {
@@ -50,6 +77,53 @@ func (task *BashTask) Run(ctx context.Context) (*TaskRunResult, error) {
 	return &TaskRunResult{true, out}, nil
 }
 
+// runCommand starts the command and waits for it to finish,
+// the command is killed along with its child processes when the context expires
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	return cmd.Wait()
+}
+
+// maxCmdOutput is the number of bytes kept from the end of each output stream,
+// the tail usually holds the failed assertions and it keeps the canary events readable
+const maxCmdOutput = 2048
+
+func formatCmdOutput(stdout, stderr []byte) []byte {
+	var out bytes.Buffer
+	if len(stdout) > 0 {
+		out.WriteString("stdout:\n")
+		out.Write(tail(stdout, maxCmdOutput))
+	}
+	if len(stderr) > 0 {
+		if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+			out.WriteString("\n")
+		}
+		out.WriteString("stderr:\n")
+		out.Write(tail(stderr, maxCmdOutput))
+	}
+	return out.Bytes()
+}
+
+func tail(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	return append([]byte("..."), b[len(b)-n:]...)
+}
+
 func (task *BashTask) String() string {
 	return task.command
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// TaskTypeChaos represents the chaos experiment type as string
+const TaskTypeChaos = "chaos"
+
+// ChaosTask creates a chaos experiment custom resource e.g. Chaos Mesh PodChaos or Litmus ChaosEngine
+// while the canary is at the given weight and deletes it at any other weight
+type ChaosTask struct {
+	TaskBase
+	experiment   *unstructured.Unstructured
+	resource     schema.GroupVersionResource
+	weight       int
+	canaryWeight int
+	client       dynamic.Interface
+}
+
+// NewChaosTask decodes the experiment manifest set in the webhook metadata,
+// the experiment namespace defaults to the canary namespace
+func NewChaosTask(metadata map[string]string, namespace string, canaryWeight int, canary string, logger *zap.SugaredLogger) (*ChaosTask, error) {
+	manifest, ok := metadata["manifest"]
+	if !ok {
+		return nil, errors.New("`manifest` is required with type chaos")
+	}
+
+	experiment := &unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), len(manifest))
+	if err := decoder.Decode(&experiment.Object); err != nil {
+		return nil, fmt.Errorf("unable to decode `manifest`: %w", err)
+	}
+	if experiment.GetKind() == "" || experiment.GetAPIVersion() == "" || experiment.GetName() == "" {
+		return nil, errors.New("`manifest` must contain the apiVersion, kind and metadata.name fields")
+	}
+	if experiment.GetNamespace() == "" {
+		experiment.SetNamespace(namespace)
+	}
+
+	weight := -1
+	if w, found := metadata["weight"]; found {
+		var err error
+		weight, err = strconv.Atoi(w)
+		if err != nil {
+			return nil, errors.New("unable to convert `weight` to int")
+		}
+	}
+
+	client, mapper, err := getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := experiment.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the %s resource: %w", gvk.String(), err)
+	}
+	resource := mapping.Resource
+
+	return &ChaosTask{
+		TaskBase: TaskBase{
+			canary: canary,
+			logger: logger,
+		},
+		experiment:   experiment,
+		resource:     resource,
+		weight:       weight,
+		canaryWeight: canaryWeight,
+		client:       client,
+	}, nil
+}
+
+func (task *ChaosTask) Hash() string {
+	return hash(task.canary + task.String())
+}
+
+// Run creates the experiment if the canary is at the target weight, otherwise it deletes it
+func (task *ChaosTask) Run(ctx context.Context) (*TaskRunResult, error) {
+	client := task.client.Resource(task.resource).Namespace(task.experiment.GetNamespace())
+
+	if task.weight >= 0 && task.weight != task.canaryWeight {
+		err := client.Delete(ctx, task.experiment.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return &TaskRunResult{false, nil}, fmt.Errorf("deleting chaos experiment %s failed: %w", task, err)
+		}
+		if err == nil {
+			task.logger.With("canary", task.canary).Infof("chaos experiment %s deleted", task)
+		}
+		return &TaskRunResult{true, nil}, nil
+	}
+
+	_, err := client.Create(ctx, task.experiment, metav1.CreateOptions{})
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return &TaskRunResult{true, nil}, nil
+		}
+		return &TaskRunResult{false, nil}, fmt.Errorf("creating chaos experiment %s failed: %w", task, err)
+	}
+
+	task.logger.With("canary", task.canary).Infof("chaos experiment %s created", task)
+	return &TaskRunResult{true, nil}, nil
+}
+
+func (task *ChaosTask) String() string {
+	return fmt.Sprintf("%s/%s.%s", strings.ToLower(task.experiment.GetKind()), task.experiment.GetName(), task.experiment.GetNamespace())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestChaosTask_Run(t *testing.T) {
+	podChaos := newChaosTestClient()
+	logger, _ := logger.NewLogger("info")
+
+	metadata := map[string]string{
+		"type":   TaskTypeChaos,
+		"weight": "20",
+		"manifest": `
+apiVersion: chaos-mesh.org/v1alpha1
+kind: PodChaos
+metadata:
+  name: podinfo-canary-kill
+spec:
+  action: pod-kill
+  mode: one
+  selector:
+    labelSelectors:
+      app: podinfo
+`,
+	}
+
+	// inject the fault at the target weight
+	task, err := NewChaosTask(metadata, "test", 20, "podinfo.test", logger)
+	require.NoError(t, err)
+	result, err := task.Run(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, result.ok)
+
+	experiment, err := kubeClient.Resource(podChaos).Namespace("test").Get(context.TODO(), "podinfo-canary-kill", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "PodChaos", experiment.GetKind())
+
+	// running again at the same weight is a no-op
+	result, err = task.Run(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, result.ok)
+
+	// remove the fault at the next weight
+	task, err = NewChaosTask(metadata, "test", 30, "podinfo.test", logger)
+	require.NoError(t, err)
+	result, err = task.Run(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, result.ok)
+
+	_, err = kubeClient.Resource(podChaos).Namespace("test").Get(context.TODO(), "podinfo-canary-kill", metav1.GetOptions{})
+	require.Error(t, err)
+}
+
+func TestChaosTask_Metadata(t *testing.T) {
+	newChaosTestClient()
+	logger, _ := logger.NewLogger("info")
+
+	_, err := NewChaosTask(map[string]string{}, "test", 0, "podinfo.test", logger)
+	require.Error(t, err)
+
+	_, err = NewChaosTask(map[string]string{"manifest": "kind: PodChaos"}, "test", 0, "podinfo.test", logger)
+	require.Error(t, err)
+}
+
+func newChaosTestClient() schema.GroupVersionResource {
+	podChaos := schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: "podchaos"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.AddSpecific(podChaos.GroupVersion().WithKind("PodChaos"), podChaos,
+		podChaos.GroupVersion().WithResource("podchaos"), meta.RESTScopeNamespace)
+
+	kubeClient = fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	kubeMapper = mapper
+	return podChaos
+}
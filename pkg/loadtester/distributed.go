@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// lookupHost resolves the load tester peers, it can be overridden in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
+// shardParams are the values available to the command template of a distributed task
+type shardParams struct {
+	QPS    int
+	Shard  int
+	Shards int
+}
+
+// isDistributed returns true if the task must be fanned out to the load tester peers,
+// tasks that were already forwarded by a peer carry the shard index and are run locally
+func isDistributed(metadata map[string]string) bool {
+	_, sharded := metadata["shard"]
+	return metadata["peers"] != "" && !sharded
+}
+
+// shardQPS splits the target QPS across the shards, the remainder
+// is spread over the first shards so that the total is preserved
+func shardQPS(qps, shard, shards int) int {
+	n := qps / shards
+	if shard < qps%shards {
+		n++
+	}
+	return n
+}
+
+// renderShardCommand replaces the {{ .QPS }}, {{ .Shard }} and {{ .Shards }}
+// placeholders in the command with the values of the current shard, commands
+// without the qps or shards metadata are left untouched since tools like ghz
+// use the same template syntax for their own call data
+func renderShardCommand(cmd string, metadata map[string]string) (string, error) {
+	_, hasQPS := metadata["qps"]
+	_, hasShards := metadata["shards"]
+	if !hasQPS && !hasShards {
+		return cmd, nil
+	}
+
+	params := shardParams{Shards: 1}
+	var err error
+	if v, ok := metadata["qps"]; ok {
+		if params.QPS, err = strconv.Atoi(v); err != nil {
+			return "", fmt.Errorf("invalid qps %s: %w", v, err)
+		}
+	}
+	if v, ok := metadata["shard"]; ok {
+		if params.Shard, err = strconv.Atoi(v); err != nil {
+			return "", fmt.Errorf("invalid shard %s: %w", v, err)
+		}
+	}
+	if v, ok := metadata["shards"]; ok {
+		if params.Shards, err = strconv.Atoi(v); err != nil || params.Shards < 1 {
+			return "", fmt.Errorf("invalid shards %s", v)
+		}
+	}
+	params.QPS = shardQPS(params.QPS, params.Shard, params.Shards)
+
+	tmpl, err := template.New("cmd").Option("missingkey=error").Parse(cmd)
+	if err != nil {
+		return "", fmt.Errorf("parsing cmd template failed: %w", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, params); err != nil {
+		return "", fmt.Errorf("rendering cmd template failed: %w", err)
+	}
+	return out.String(), nil
+}
+
+// distributeTask resolves the peers address to the load tester replicas
+// and forwards the task to each of them along with its shard index,
+// the task is sent to every replica and the forwarding errors are aggregated
+func distributeTask(ctx context.Context, payload *flaggerv1.CanaryWebhookPayload, logger *zap.SugaredLogger) error {
+	host, port, err := net.SplitHostPort(payload.Metadata["peers"])
+	if err != nil {
+		return fmt.Errorf("invalid peers address %s: %w", payload.Metadata["peers"], err)
+	}
+
+	addrs, err := lookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolving peers %s failed: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no peers found for %s", host)
+	}
+
+	// DNS returns the peers in a rotating order, the shards must be assigned
+	// to the same replicas on each call for the task deduplication to work
+	sort.Strings(addrs)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	errs := make([]error, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			errs[i] = forwardShard(ctx, client, payload, i, len(addrs), net.JoinHostPort(addr, port))
+		}(i, addr)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d shards failed: %s", len(failed), len(addrs), strings.Join(failed, "; "))
+	}
+
+	logger.With("canary", fmt.Sprintf("%s.%s", payload.Name, payload.Namespace)).
+		Infof("task distributed across %d load tester replicas", len(addrs))
+	return nil
+}
+
+// forwardShard sends the task to a load tester replica along with its shard index
+func forwardShard(ctx context.Context, client *http.Client, payload *flaggerv1.CanaryWebhookPayload, index, shards int, addr string) error {
+	shard := *payload
+	shard.Metadata = make(map[string]string, len(payload.Metadata)+2)
+	for k, v := range payload.Metadata {
+		shard.Metadata[k] = v
+	}
+	delete(shard.Metadata, "peers")
+	shard.Metadata["shard"] = strconv.Itoa(index)
+	shard.Metadata["shards"] = strconv.Itoa(shards)
+
+	body, err := json.Marshal(shard)
+	if err != nil {
+		return fmt.Errorf("encoding the task payload failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s/", addr), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("forwarding shard %d to %s failed: %w", index, addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("forwarding shard %d to %s failed with status %d", index, addr, resp.StatusCode)
+	}
+	return nil
+}
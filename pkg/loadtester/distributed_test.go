@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type recordingTaskRunner struct {
+	MockTaskRunner
+	mu    sync.Mutex
+	tasks []Task
+}
+
+func (r *recordingTaskRunner) Add(task Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks = append(r.tasks, task)
+}
+
+func TestShardQPS(t *testing.T) {
+	assert.Equal(t, 34, shardQPS(100, 0, 3))
+	assert.Equal(t, 33, shardQPS(100, 1, 3))
+	assert.Equal(t, 33, shardQPS(100, 2, 3))
+	assert.Equal(t, 100, shardQPS(100, 0, 1))
+}
+
+func TestRenderShardCommand(t *testing.T) {
+	cmd, err := renderShardCommand("hey -q {{ .QPS }} -c 1 http://podinfo", map[string]string{"qps": "10"})
+	require.NoError(t, err)
+	assert.Equal(t, "hey -q 10 -c 1 http://podinfo", cmd)
+
+	cmd, err = renderShardCommand("hey -q {{ .QPS }} #{{ .Shard }}/{{ .Shards }}", map[string]string{"qps": "10", "shard": "1", "shards": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "hey -q 5 #1/2", cmd)
+
+	// commands without shard metadata are not rendered
+	cmd, err = renderShardCommand(`ghz -d '{"id":"{{.RequestNumber}}"}'`, map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, `ghz -d '{"id":"{{.RequestNumber}}"}'`, cmd)
+
+	_, err = renderShardCommand("hey -q {{ .QPS }}", map[string]string{"qps": "ten"})
+	assert.Error(t, err)
+}
+
+func TestServer_HandleNewDistributedTask(t *testing.T) {
+	mocks := newServerFixture()
+	peer := &recordingTaskRunner{}
+	srv := httptest.NewServer(http.HandlerFunc(HandleNewTask(mocks.logger, peer, NewAuthorizer(nil, ""))))
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	lookupHost = func(ctx context.Context, name string) ([]string, error) {
+		assert.Equal(t, "loadtester-headless.test", name)
+		return []string{host, host, host}, nil
+	}
+	defer func() { lookupHost = net.DefaultResolver.LookupHost }()
+
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata: map[string]string{
+			"cmd":   "hey -z 1m -q {{ .QPS }} -c 1 http://podinfo-canary.test:9898/",
+			"qps":   "100",
+			"peers": net.JoinHostPort("loadtester-headless.test", port),
+		},
+	})
+	authorizer := NewAuthorizer(nil, net.JoinHostPort("loadtester-headless.test", port))
+	HandleNewTask(mocks.logger, &recordingTaskRunner{}, authorizer)(mocks.resp, req)
+	require.Equal(t, http.StatusAccepted, mocks.resp.Code)
+
+	require.Len(t, peer.tasks, 3)
+
+	var cmds []string
+	for _, task := range peer.tasks {
+		cmds = append(cmds, task.String())
+	}
+	sort.Strings(cmds)
+	assert.Equal(t, []string{
+		"hey -z 1m -q 33 -c 1 http://podinfo-canary.test:9898/",
+		"hey -z 1m -q 33 -c 1 http://podinfo-canary.test:9898/",
+		"hey -z 1m -q 34 -c 1 http://podinfo-canary.test:9898/",
+	}, cmds)
+}
+
+func TestServer_HandleNewDistributedTaskForbidden(t *testing.T) {
+	mocks := newServerFixture()
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata: map[string]string{
+			"cmd":   "hey -z 1m -q {{ .QPS }} -c 1 http://podinfo-canary.test:9898/",
+			"qps":   "100",
+			"peers": "attacker.example.com:80",
+		},
+	})
+	HandleNewTask(mocks.logger, &recordingTaskRunner{}, NewAuthorizer(nil, "loadtester-headless.test:8080"))(mocks.resp, req)
+	assert.Equal(t, http.StatusForbidden, mocks.resp.Code)
+}
+
+func TestDistributeTask(t *testing.T) {
+	mocks := newServerFixture()
+
+	// the first peer in sorted order fails, the second one must still receive its shard
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	_, port, err := net.SplitHostPort(failing.Listener.Addr().String())
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+	if err != nil {
+		t.Skipf("loopback address 127.0.0.2 not available: %v", err)
+	}
+	peer := &recordingTaskRunner{}
+	healthy := httptest.NewUnstartedServer(http.HandlerFunc(HandleNewTask(mocks.logger, peer, NewAuthorizer(nil, ""))))
+	healthy.Listener.Close()
+	healthy.Listener = listener
+	healthy.Start()
+	defer healthy.Close()
+
+	lookupHost = func(ctx context.Context, name string) ([]string, error) {
+		return []string{"127.0.0.2", "127.0.0.1"}, nil
+	}
+	defer func() { lookupHost = net.DefaultResolver.LookupHost }()
+
+	err = distributeTask(context.TODO(), &flaggerv1.CanaryWebhookPayload{
+		Name:      "podinfo",
+		Namespace: "test",
+		Metadata: map[string]string{
+			"cmd":   "hey -q {{ .QPS }} #{{ .Shard }}",
+			"qps":   "100",
+			"peers": net.JoinHostPort("loadtester-headless.test", port),
+		},
+	}, mocks.logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 shards failed")
+
+	// the shards are assigned in the sorted peers order
+	require.Len(t, peer.tasks, 1)
+	assert.Equal(t, "hey -q 50 #1", peer.tasks[0].String())
+}
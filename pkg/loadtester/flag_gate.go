@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"go.uber.org/zap"
+)
+
+// FeatureFlagGate evaluates LaunchDarkly feature flags
+type FeatureFlagGate struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// launchDarklyFlag holds the flag state per environment
+// https://apidocs.launchdarkly.com/tag/Feature-flags#operation/getFeatureFlag
+type launchDarklyFlag struct {
+	Environments map[string]struct {
+		On bool `json:"on"`
+	} `json:"environments"`
+}
+
+// NewFeatureFlagGate returns a gate for the given LaunchDarkly API address and access token
+func NewFeatureFlagGate(address string, token string) *FeatureFlagGate {
+	return &FeatureFlagGate{
+		address: address,
+		token:   token,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// isOn returns true if the flag is turned on in the given project and environment
+func (g *FeatureFlagGate) isOn(project, environment, flag string) (bool, error) {
+	if g.token == "" {
+		return false, errors.New("LaunchDarkly access token not set")
+	}
+
+	u := fmt.Sprintf("%s/api/v2/flags/%s/%s?env=%s", g.address,
+		url.PathEscape(project), url.PathEscape(flag), url.QueryEscape(environment))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	if err != nil {
+		return false, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Authorization", g.token)
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("flag request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return false, fmt.Errorf("reading the flag response failed: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("flag request failed with status %d: %s", res.StatusCode, string(body))
+	}
+
+	var ld launchDarklyFlag
+	if err := json.Unmarshal(body, &ld); err != nil {
+		return false, fmt.Errorf("decoding the flag response failed: %w", err)
+	}
+
+	env, ok := ld.Environments[environment]
+	if !ok {
+		return false, fmt.Errorf("flag %s not found in environment %s", flag, environment)
+	}
+	return env.On, nil
+}
+
+// HandleFlagGate approves the canary if the feature flag set in the webhook metadata is turned on
+func HandleFlagGate(logger *zap.SugaredLogger, flags *FeatureFlagGate, authorizer *Authorizer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("reading the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		canary := &flaggerv1.CanaryWebhookPayload{}
+		err = json.Unmarshal(body, canary)
+		if err != nil {
+			logger.Error("decoding the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !authorizer.Authorize(canary) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+
+		flag := canary.Metadata["flag"]
+		if flag == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("flag metadata is required"))
+			return
+		}
+
+		project := canary.Metadata["project"]
+		if project == "" {
+			project = "default"
+		}
+
+		environment := canary.Metadata["environment"]
+		if environment == "" {
+			environment = "production"
+		}
+
+		canaryName := fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)
+		approved, err := flags.isOn(project, environment, flag)
+		if err != nil {
+			logger.Errorf("%s flag gate check failed: %v", canaryName, err)
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		if approved {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Approved"))
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		}
+
+		logger.Infof("%s flag gate check %s/%s/%s: approved %v", canaryName, project, environment, flag, approved)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestServer_HandleFlagGate(t *testing.T) {
+	ld := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "api-token", r.Header.Get("Authorization"))
+		require.Equal(t, "production", r.URL.Query().Get("env"))
+		switch r.URL.Path {
+		case "/api/v2/flags/web/podinfo-release":
+			w.Write([]byte(`{"environments":{"production":{"on":true}}}`))
+		case "/api/v2/flags/web/podinfo-hold":
+			w.Write([]byte(`{"environments":{"production":{"on":false}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ld.Close()
+
+	flags := NewFeatureFlagGate(ld.URL, "api-token")
+
+	tests := []struct {
+		flag string
+		code int
+	}{
+		{"podinfo-release", http.StatusOK},
+		{"podinfo-hold", http.StatusForbidden},
+		{"podinfo-missing", http.StatusBadGateway},
+		{"", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		mocks := newServerFixture()
+		req := newJsonRequest("POST", "/gate/flag", &flaggerv1.CanaryWebhookPayload{
+			Name:      "podinfo",
+			Namespace: "test",
+			Metadata: map[string]string{
+				"project": "web",
+				"flag":    tt.flag,
+			},
+		})
+		HandleFlagGate(mocks.logger, flags, NewAuthorizer(nil, ""))(mocks.resp, req)
+		assert.Equal(t, tt.code, mocks.resp.Code, tt.flag)
+	}
+}
@@ -45,3 +45,9 @@ func (gs *GateStorage) isOpen(key string) (locked bool) {
 	}
 	return
 }
+
+// consume returns true if the gate is open and closes it
+func (gs *GateStorage) consume(key string) bool {
+	val, ok := gs.data.LoadAndDelete(key)
+	return ok && val.(bool)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+var (
+	kubeClient     dynamic.Interface
+	kubeMapper     meta.RESTMapper
+	kubeClientErr  error
+	kubeClientOnce sync.Once
+)
+
+// getKubeClient returns the in-cluster dynamic client used by the tasks
+// that manage Kubernetes objects and a discovery based mapper for custom resources
+func getKubeClient() (dynamic.Interface, meta.RESTMapper, error) {
+	kubeClientOnce.Do(func() {
+		if kubeClient != nil {
+			return
+		}
+		cfg, err := rest.InClusterConfig()
+		if err != nil {
+			kubeClientErr = fmt.Errorf("error building in-cluster config: %w", err)
+			return
+		}
+		dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+		if err != nil {
+			kubeClientErr = fmt.Errorf("error building discovery client: %w", err)
+			return
+		}
+		kubeMapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+		kubeClient, kubeClientErr = dynamic.NewForConfig(cfg)
+	})
+	return kubeClient, kubeMapper, kubeClientErr
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	tasksActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "tasks_active",
+		Help:      "Number of load test tasks currently running",
+	}, []string{"canary", "type"})
+
+	taskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "task_duration_seconds",
+		Help:      "Seconds spent running load test tasks",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"canary", "type", "status"})
+
+	taskErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "task_errors_total",
+		Help:      "Total number of load test tasks that failed or timed out",
+	}, []string{"canary", "type"})
+
+	requestsPerSecond = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "requests_per_second",
+		Help:      "Request rate reported by the last load test run",
+	}, []string{"canary"})
+
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "request_errors_total",
+		Help:      "Total number of failed requests reported by the load test runs",
+	}, []string{"canary"})
+)
+
+func init() {
+	prometheus.MustRegister(tasksActive, taskDuration, taskErrors, requestsPerSecond, requestErrors)
+}
+
+var (
+	// matches the rate summary printed by hey, wrk and ghz e.g. "Requests/sec: 9.9845"
+	rpsRegexp = regexp.MustCompile(`Requests/sec:\s+([0-9.]+)`)
+	// matches the hey status code distribution lines e.g. "[503] 12 responses"
+	statusRegexp = regexp.MustCompile(`^\s*\[(\d{3})\]\s+(\d+)\s+responses`)
+	// matches the hey error distribution lines e.g. "[3] Get http://podinfo: dial tcp ..."
+	errorRegexp = regexp.MustCompile(`^\s*\[(\d+)\]\s+\D`)
+)
+
+// taskType returns the metadata type of the given task
+func taskType(task Task) string {
+	switch task.(type) {
+	case *CmdTask:
+		return TaskTypeShell
+	case *NGrinderTask:
+		return TaskTypeNGrinder
+	default:
+		return "unknown"
+	}
+}
+
+// recordTaskMetrics records the task duration and outcome,
+// along with the request rate and errors found in the task output
+func recordTaskMetrics(task Task, result *TaskRunResult, duration time.Duration) {
+	typ := taskType(task)
+	status := "success"
+	if result == nil || !result.ok {
+		status = "failure"
+		taskErrors.WithLabelValues(task.Canary(), typ).Inc()
+	}
+	taskDuration.WithLabelValues(task.Canary(), typ, status).Observe(duration.Seconds())
+
+	if result == nil || len(result.out) == 0 {
+		return
+	}
+	rps, errs, ok := parseLoadTestOutput(result.out)
+	if !ok {
+		return
+	}
+	requestsPerSecond.WithLabelValues(task.Canary()).Set(rps)
+	requestErrors.WithLabelValues(task.Canary()).Add(float64(errs))
+}
+
+// parseLoadTestOutput extracts the request rate and the number of failed requests
+// from the summary printed by the load generator, errors are counted from the
+// 5xx status codes and the transport errors reported by hey
+func parseLoadTestOutput(out []byte) (rps float64, errs int, ok bool) {
+	m := rpsRegexp.FindSubmatch(out)
+	if m == nil {
+		return 0, 0, false
+	}
+	rps, err := strconv.ParseFloat(string(m[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if s := statusRegexp.FindStringSubmatch(line); s != nil {
+			if code, _ := strconv.Atoi(s[1]); code >= 500 {
+				n, _ := strconv.Atoi(s[2])
+				errs += n
+			}
+			continue
+		}
+		if s := errorRegexp.FindStringSubmatch(line); s != nil {
+			n, _ := strconv.Atoi(s[1])
+			errs += n
+		}
+	}
+	return rps, errs, true
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+const heyOutput = `
+Summary:
+  Total:	60.0248 secs
+  Slowest:	0.1018 secs
+  Fastest:	0.0010 secs
+  Average:	0.0049 secs
+  Requests/sec:	9.9792
+
+Status code distribution:
+  [200]	590 responses
+  [503]	7 responses
+
+Error distribution:
+  [2]	Get "http://podinfo-canary.test:9898/": dial tcp 10.0.0.1:9898: connect: connection refused
+`
+
+func TestParseLoadTestOutput(t *testing.T) {
+	rps, errs, ok := parseLoadTestOutput([]byte(heyOutput))
+	require.True(t, ok)
+	assert.Equal(t, 9.9792, rps)
+	assert.Equal(t, 9, errs)
+
+	_, _, ok = parseLoadTestOutput([]byte("command finished"))
+	assert.False(t, ok)
+}
+
+func TestRecordTaskMetrics(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	taskFactory, _ := GetTaskFactory(TaskTypeShell)
+	task, err := taskFactory(map[string]string{"cmd": "echo"}, "metrics.default", logger)
+	require.NoError(t, err)
+
+	recordTaskMetrics(task, &TaskRunResult{true, []byte(heyOutput)}, time.Second)
+	recordTaskMetrics(task, &TaskRunResult{false, nil}, time.Second)
+
+	assert.Equal(t, 9.9792, testutil.ToFloat64(requestsPerSecond.WithLabelValues("metrics.default")))
+	assert.Equal(t, float64(9), testutil.ToFloat64(requestErrors.WithLabelValues("metrics.default")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(taskErrors.WithLabelValues("metrics.default", TaskTypeShell)))
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// TaskTypeOPA represents the OPA policy check type as string
+const TaskTypeOPA = "opa"
+
+const defaultOPAPolicyPath = "flagger/deny"
+
+var canaryResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaries"}
+
+// OPATask evaluates the canary target pod template against an OPA policy
+type OPATask struct {
+	TaskBase
+	name       string
+	namespace  string
+	policyURL  string
+	client     dynamic.Interface
+	mapper     meta.RESTMapper
+	httpClient *http.Client
+}
+
+// OPAInput is the document sent to OPA as the policy input
+type OPAInput struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Target    metav1.TypeMeta        `json:"target"`
+	Template  map[string]interface{} `json:"template"`
+}
+
+// NewOPATask returns a task for the OPA server and the policy path set in the webhook metadata
+func NewOPATask(metadata map[string]string, name string, namespace string, logger *zap.SugaredLogger) (*OPATask, error) {
+	server, ok := metadata["server"]
+	if !ok {
+		return nil, errors.New("`server` is required with type opa")
+	}
+	if _, err := url.ParseRequestURI(server); err != nil {
+		return nil, fmt.Errorf("invalid `server` URL %s", server)
+	}
+
+	path := metadata["path"]
+	if path == "" {
+		path = defaultOPAPolicyPath
+	}
+
+	client, mapper, err := getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OPATask{
+		TaskBase: TaskBase{
+			canary: fmt.Sprintf("%s.%s", name, namespace),
+			logger: logger,
+		},
+		name:       name,
+		namespace:  namespace,
+		policyURL:  fmt.Sprintf("%s/v1/data/%s", strings.TrimSuffix(server, "/"), strings.Trim(path, "/")),
+		client:     client,
+		mapper:     mapper,
+		httpClient: http.DefaultClient,
+	}, nil
+}
+
+func (task *OPATask) Hash() string {
+	return hash(task.canary + task.policyURL)
+}
+
+// Run queries the policy with the target pod template as input,
+// the policy must return either a boolean or a list of violations
+func (task *OPATask) Run(ctx context.Context) (*TaskRunResult, error) {
+	input, err := task.input(ctx)
+	if err != nil {
+		return &TaskRunResult{false, nil}, err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("marshalling policy input failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, task.policyURL, bytes.NewBuffer(data))
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := task.httpClient.Do(req)
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("policy query failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("reading the policy response failed: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return &TaskRunResult{false, body}, fmt.Errorf("policy query failed with status %d: %s", res.StatusCode, string(body))
+	}
+
+	var decision struct {
+		Result interface{} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &decision); err != nil {
+		return &TaskRunResult{false, body}, fmt.Errorf("decoding the policy response failed: %w", err)
+	}
+
+	switch result := decision.Result.(type) {
+	case bool:
+		if !result {
+			return &TaskRunResult{false, body}, fmt.Errorf("policy %s denied %s", task.policyURL, task.canary)
+		}
+	case []interface{}:
+		if len(result) > 0 {
+			violations := make([]string, 0, len(result))
+			for _, v := range result {
+				violations = append(violations, fmt.Sprint(v))
+			}
+			return &TaskRunResult{false, body}, fmt.Errorf("policy violations: %s", strings.Join(violations, ", "))
+		}
+	case nil:
+		return &TaskRunResult{false, body}, fmt.Errorf("policy %s not found", task.policyURL)
+	default:
+		return &TaskRunResult{false, body}, fmt.Errorf("policy %s must return a boolean or a list of violations", task.policyURL)
+	}
+
+	task.logger.With("canary", task.canary).Infof("policy check %s passed", task.policyURL)
+	return &TaskRunResult{true, body}, nil
+}
+
+// input fetches the canary target and returns its pod template
+func (task *OPATask) input(ctx context.Context) (*OPAInput, error) {
+	cd, err := task.client.Resource(canaryResource).Namespace(task.namespace).Get(ctx, task.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("canary %s get query failed: %w", task.canary, err)
+	}
+
+	targetRef, _, err := unstructured.NestedStringMap(cd.Object, "spec", "targetRef")
+	if err != nil || targetRef["name"] == "" {
+		return nil, fmt.Errorf("canary %s has no target", task.canary)
+	}
+
+	gv, err := schema.ParseGroupVersion(targetRef["apiVersion"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid target apiVersion %s: %w", targetRef["apiVersion"], err)
+	}
+	mapping, err := task.mapper.RESTMapping(gv.WithKind(targetRef["kind"]).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the %s resource: %w", targetRef["kind"], err)
+	}
+
+	target, err := task.client.Resource(mapping.Resource).Namespace(task.namespace).Get(ctx, targetRef["name"], metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s.%s get query failed: %w", targetRef["kind"], targetRef["name"], task.namespace, err)
+	}
+
+	template, found, err := unstructured.NestedMap(target.Object, "spec", "template")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s.%s has no pod template", targetRef["kind"], targetRef["name"], task.namespace)
+	}
+
+	return &OPAInput{
+		Name:      task.name,
+		Namespace: task.namespace,
+		Target: metav1.TypeMeta{
+			APIVersion: targetRef["apiVersion"],
+			Kind:       targetRef["kind"],
+		},
+		Template: template,
+	}, nil
+}
+
+func (task *OPATask) String() string {
+	return task.policyURL
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestOPATask_Run(t *testing.T) {
+	newOPATestClient()
+	logger, _ := logger.NewLogger("info")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input OPAInput `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "podinfo", req.Input.Name)
+		require.Equal(t, "Deployment", req.Input.Target.Kind)
+		require.NotNil(t, req.Input.Template["spec"])
+
+		switch r.URL.Path {
+		case "/v1/data/flagger/deny":
+			w.Write([]byte(`{"result":["image registry docker.io is not allowed"]}`))
+		case "/v1/data/flagger/allow":
+			w.Write([]byte(`{"result":true}`))
+		case "/v1/data/flagger/clean":
+			w.Write([]byte(`{"result":[]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"", false},
+		{"flagger/allow", true},
+		{"flagger/clean", true},
+		{"flagger/missing", false},
+	}
+
+	for _, tt := range tests {
+		task, err := NewOPATask(map[string]string{"server": ts.URL, "path": tt.path}, "podinfo", "test", logger)
+		require.NoError(t, err)
+
+		result, err := task.Run(context.TODO())
+		assert.Equal(t, tt.ok, result.ok, tt.path)
+		if !tt.ok {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func newOPATestClient() {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(deployments.GroupVersion().WithKind("Deployment"), meta.RESTScopeNamespace)
+
+	canary := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "flagger.app/v1beta1",
+		"kind":       "Canary",
+		"metadata":   map[string]interface{}{"name": "podinfo", "namespace": "test"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "podinfo"},
+		},
+	}}
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "podinfo", "namespace": "test"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "podinfod", "image": "docker.io/stefanprodan/podinfo:6.0.0"},
+					},
+				},
+			},
+		},
+	}}
+
+	kubeClient = fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), canary, deployment)
+	kubeMapper = mapper
+}
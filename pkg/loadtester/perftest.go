@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// TaskTypeJMeter represents the JMeter test plan type as string
+	TaskTypeJMeter = "jmeter"
+	// TaskTypeGatling represents the Gatling simulation type as string
+	TaskTypeGatling = "gatling"
+)
+
+// PerfTestTask runs a JMeter test plan or a Gatling simulation mounted from a ConfigMap or PVC
+// and fails if the assertions defined in the test don't pass
+type PerfTestTask struct {
+	TaskBase
+	tool         string
+	bin          string
+	args         []string
+	maxErrorRate float64
+}
+
+// NewPerfTestTask builds the command line for the JMeter or Gatling task,
+// the binary path can be overridden with the bin metadata
+func NewPerfTestTask(typ string, metadata map[string]string, canary string, logger *zap.SugaredLogger) (*PerfTestTask, error) {
+	task := &PerfTestTask{
+		TaskBase: TaskBase{
+			canary: canary,
+			logger: logger,
+		},
+		tool: typ,
+		bin:  metadata["bin"],
+	}
+
+	switch typ {
+	case TaskTypeJMeter:
+		plan, ok := metadata["testPlan"]
+		if !ok {
+			return nil, errors.New("`testPlan` is required with type jmeter")
+		}
+		if task.bin == "" {
+			task.bin = "jmeter"
+		}
+		if v, ok := metadata["maxErrorRate"]; ok {
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, errors.New("unable to convert `maxErrorRate` to float")
+			}
+			task.maxErrorRate = rate
+		}
+		task.args = []string{"-n", "-t", plan}
+	case TaskTypeGatling:
+		simulation, ok := metadata["simulation"]
+		if !ok {
+			return nil, errors.New("`simulation` is required with type gatling")
+		}
+		if task.bin == "" {
+			task.bin = "gatling.sh"
+		}
+		task.args = []string{"-nr", "-s", simulation}
+		if folder, ok := metadata["simulationsFolder"]; ok {
+			task.args = append(task.args, "-sf", folder)
+		}
+	default:
+		return nil, fmt.Errorf("unknown performance test type %s", typ)
+	}
+
+	task.args = append(task.args, strings.Fields(metadata["args"])...)
+	return task, nil
+}
+
+func (task *PerfTestTask) Hash() string {
+	return hash(task.canary + task.String())
+}
+
+// Run executes the test in a temporary results folder, Gatling exits with a non-zero code
+// when the assertions fail while for JMeter the results file is parsed for failed samples
+func (task *PerfTestTask) Run(ctx context.Context) (*TaskRunResult, error) {
+	dir, err := os.MkdirTemp("", "flagger-"+task.tool)
+	if err != nil {
+		return &TaskRunResult{false, nil}, fmt.Errorf("creating the results folder failed: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := task.args
+	results := filepath.Join(dir, "results.jtl")
+	switch task.tool {
+	case TaskTypeJMeter:
+		args = append(args, "-l", results, "-j", filepath.Join(dir, "jmeter.log"))
+	case TaskTypeGatling:
+		args = append(args, "-rf", dir)
+	}
+
+	cmd := exec.CommandContext(ctx, task.bin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		task.logger.With("canary", task.canary).Errorf("%s test failed %s %v %s", task.tool, task, err, out)
+		return &TaskRunResult{false, out}, fmt.Errorf("%s test %s failed: %w\n%s", task.tool, task, err, tail(out, maxCmdOutput))
+	}
+
+	if task.tool == TaskTypeJMeter {
+		total, failed, messages, err := parseJMeterResults(results)
+		if err != nil {
+			return &TaskRunResult{false, out}, fmt.Errorf("jmeter test %s failed: %w", task, err)
+		}
+		if total == 0 {
+			return &TaskRunResult{false, out}, fmt.Errorf("jmeter test %s failed: no samples recorded", task)
+		}
+		rate := float64(failed) * 100 / float64(total)
+		if failed > 0 && rate > task.maxErrorRate {
+			return &TaskRunResult{false, out}, fmt.Errorf("jmeter test %s failed: %d of %d samples failed (%.2f%%): %s",
+				task, failed, total, rate, strings.Join(messages, "; "))
+		}
+	}
+
+	task.logger.With("canary", task.canary).Infof("%s test finished %s", task.tool, task)
+	return &TaskRunResult{true, out}, nil
+}
+
+func (task *PerfTestTask) String() string {
+	return strings.TrimSpace(task.bin + " " + strings.Join(task.args, " "))
+}
+
+// maxFailureMessages is the number of distinct assertion failures reported in the webhook response
+const maxFailureMessages = 5
+
+// parseJMeterResults reads the CSV results file and returns the number of samples,
+// the number of failed samples and their distinct failure messages
+func parseJMeterResults(path string) (total int, failed int, messages []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("reading the results file failed: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("reading the results header failed: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	successCol, ok := columns["success"]
+	if !ok {
+		return 0, 0, nil, errors.New("the results file has no success column, set jmeter.save.saveservice.output_format=csv")
+	}
+
+	seen := make(map[string]bool)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("reading the results failed: %w", err)
+		}
+		total++
+		if ok, _ := strconv.ParseBool(record[successCol]); ok {
+			continue
+		}
+		failed++
+
+		msg := "sample failed"
+		if i, ok := columns["label"]; ok {
+			msg = record[i]
+		}
+		if i, ok := columns["failureMessage"]; ok && record[i] != "" {
+			msg = fmt.Sprintf("%s: %s", msg, record[i])
+		} else if i, ok := columns["responseMessage"]; ok && record[i] != "" {
+			msg = fmt.Sprintf("%s: %s", msg, record[i])
+		}
+		if !seen[msg] && len(seen) < maxFailureMessages {
+			seen[msg] = true
+			messages = append(messages, msg)
+		}
+	}
+	return total, failed, messages, nil
+}
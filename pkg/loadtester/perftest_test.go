@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+const jmeterResults = `timeStamp,elapsed,label,responseCode,responseMessage,threadName,success,failureMessage
+1680000000000,12,home,200,OK,users 1-1,true,
+1680000000100,15,home,200,OK,users 1-1,true,
+1680000000200,900,api,200,OK,users 1-1,false,The operation lasted too long
+1680000000300,10,api,503,Service Unavailable,users 1-1,false,
+`
+
+// fakeJMeter writes a script that mimics jmeter by copying the results to the -l path
+func fakeJMeter(t *testing.T, results string) string {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "results.csv")
+	require.NoError(t, os.WriteFile(src, []byte(results), 0644))
+
+	bin := filepath.Join(dir, "jmeter")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-l" ]; then cp ` + src + ` "$2"; fi
+  shift
+done
+`
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	return bin
+}
+
+func TestPerfTestTask_JMeter(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	bin := fakeJMeter(t, jmeterResults)
+
+	task, err := NewPerfTestTask(TaskTypeJMeter, map[string]string{
+		"bin":      bin,
+		"testPlan": "/tests/podinfo.jmx",
+		"args":     "-Jhost=podinfo-canary.test",
+	}, "podinfo.default", logger)
+	require.NoError(t, err)
+	assert.Equal(t, bin+" -n -t /tests/podinfo.jmx -Jhost=podinfo-canary.test", task.String())
+
+	result, err := task.Run(context.TODO())
+	require.Error(t, err)
+	assert.False(t, result.ok)
+	assert.Contains(t, err.Error(), "2 of 4 samples failed (50.00%)")
+	assert.Contains(t, err.Error(), "api: The operation lasted too long; api: Service Unavailable")
+
+	// tolerate the failed samples within the max error rate
+	task, err = NewPerfTestTask(TaskTypeJMeter, map[string]string{
+		"bin":          bin,
+		"testPlan":     "/tests/podinfo.jmx",
+		"maxErrorRate": "50",
+	}, "podinfo.default", logger)
+	require.NoError(t, err)
+
+	result, err = task.Run(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, result.ok)
+
+	_, err = NewPerfTestTask(TaskTypeJMeter, map[string]string{}, "podinfo.default", logger)
+	assert.Error(t, err)
+}
+
+func TestPerfTestTask_Gatling(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	bin := filepath.Join(t.TempDir(), "gatling.sh")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\necho 'Global: percentage of successful events is greater than 99.0 : false'\nexit 2\n"), 0755))
+
+	task, err := NewPerfTestTask(TaskTypeGatling, map[string]string{
+		"bin":               bin,
+		"simulation":        "podinfo.BasicSimulation",
+		"simulationsFolder": "/simulations",
+	}, "podinfo.default", logger)
+	require.NoError(t, err)
+	assert.Equal(t, bin+" -nr -s podinfo.BasicSimulation -sf /simulations", task.String())
+
+	result, err := task.Run(context.TODO())
+	require.Error(t, err)
+	assert.False(t, result.ok)
+	assert.Contains(t, err.Error(), "exit status 2")
+	assert.Contains(t, err.Error(), "percentage of successful events is greater than 99.0 : false")
+}
@@ -78,8 +78,15 @@ func (tr *TaskRunner) runAll() {
 
 				tr.logger.With("canary", t.Canary()).Infof("task starting %s", t)
 
+				typ := taskType(t)
+				tasksActive.WithLabelValues(t.Canary(), typ).Inc()
+				start := time.Now()
+
 				// run task with the timeout context
-				t.Run(ctx)
+				result := t.Run(ctx)
+
+				recordTaskMetrics(t, result, time.Since(start))
+				tasksActive.WithLabelValues(t.Canary(), typ).Dec()
 
 				// remove task from the running list
 				tr.runningTasks.Delete(t.Hash())
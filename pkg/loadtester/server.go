@@ -31,7 +31,7 @@ import (
 )
 
 // ListenAndServe starts a web server and waits for SIGTERM
-func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner, gate *GateStorage, authorizer *Authorizer, stopCh <-chan struct{}) {
+func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogger, taskRunner *TaskRunner, gate *GateStorage, flags *FeatureFlagGate, slack *SlackInteractions, authorizer *Authorizer, stopCh <-chan struct{}) {
 	mux := http.DefaultServeMux
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/healthz", HandleHealthz)
@@ -79,6 +79,9 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		logger.Infof("%s gate check: approved %v", canaryName, approved)
 	})
 
+	mux.HandleFunc("/gate/flag", HandleFlagGate(logger, flags, authorizer))
+	mux.HandleFunc("/slack/interactions", HandleSlackInteraction(logger, slack, gate, authorizer))
+
 	mux.HandleFunc("/gate/open", func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
@@ -141,6 +144,80 @@ func ListenAndServe(port string, timeout time.Duration, logger *zap.SugaredLogge
 		logger.Infof("%s gate closed", canaryName)
 	})
 
+	mux.HandleFunc("/gate/step/check", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("reading the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		canary := &flaggerv1.CanaryWebhookPayload{}
+		err = json.Unmarshal(body, canary)
+		if err != nil {
+			logger.Error("decoding the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !authorizer.Authorize(canary) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+
+		// each step approval is valid for a single traffic increase
+		stepName := fmt.Sprintf("%s.%s/%d", canary.Name, canary.Namespace, canary.NextWeight)
+		approved := gate.consume(stepName)
+		if approved {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Approved"))
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		}
+
+		logger.Infof("%s step gate check: approved %v", stepName, approved)
+	})
+
+	mux.HandleFunc("/gate/step/open", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("reading the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		canary := &flaggerv1.CanaryWebhookPayload{}
+		err = json.Unmarshal(body, canary)
+		if err != nil {
+			logger.Error("decoding the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if canary.NextWeight <= 0 {
+			logger.Error("the nextWeight field is required")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !authorizer.Authorize(canary) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+
+		stepName := fmt.Sprintf("%s.%s/%d", canary.Name, canary.Namespace, canary.NextWeight)
+		gate.open(stepName)
+
+		w.WriteHeader(http.StatusAccepted)
+
+		logger.Infof("%s step gate opened", stepName)
+	})
+
 	mux.HandleFunc("/rollback/check", func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
@@ -394,6 +471,161 @@ func HandleNewTask(logger *zap.SugaredLogger, taskRunner TaskRunnerInterface, au
 				return
 			}
 
+			// create or delete chaos experiment (blocking task)
+			if typ == TaskTypeChaos {
+				chaos, err := NewChaosTask(payload.Metadata, payload.Namespace, payload.CanaryWeight,
+					fmt.Sprintf("%s.%s", payload.Name, payload.Namespace), logger)
+				if err != nil {
+					logger.With("canary", payload.Name).Errorf("chaos task init error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result, err := chaos.Run(ctx)
+				if !result.ok {
+					logger.With("canary", payload.Name).Errorf("chaos task error: %s", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			// evaluate OPA policy (blocking task)
+			if typ == TaskTypeOPA {
+				opa, err := NewOPATask(payload.Metadata, payload.Name, payload.Namespace, logger)
+				if err != nil {
+					logger.With("canary", payload.Name).Errorf("opa task init error: %s", err)
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result, err := opa.Run(ctx)
+				if !result.ok {
+					logger.With("canary", payload.Name).Errorf("opa task error: %s", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
+			// run JMeter test plan or Gatling simulation (blocking task)
+			if typ == TaskTypeJMeter || typ == TaskTypeGatling {
+				perfTest, err := NewPerfTestTask(typ, payload.Metadata, fmt.Sprintf("%s.%s", payload.Name, payload.Namespace), logger)
+				if err != nil {
+					logger.With("canary", payload.Name).Errorf("%s task init error: %s", typ, err)
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), taskRunner.Timeout())
+				defer cancel()
+
+				result, err := perfTest.Run(ctx)
+				if !result.ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
+			// run cmd task and wait for the result (blocking task)
+			if blocking, _ := strconv.ParseBool(metadata["blocking"]); typ == TaskTypeShell && blocking {
+				timeout := taskRunner.Timeout()
+				if v, ok := metadata["timeout"]; ok {
+					d, err := time.ParseDuration(v)
+					if err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(fmt.Sprintf("invalid timeout %s: %s", v, err)))
+						return
+					}
+					if d < timeout {
+						timeout = d
+					}
+				}
+
+				if _, ok := metadata["cmd"]; !ok {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("cmd not found in metadata"))
+					return
+				}
+				cmd, err := renderShardCommand(metadata["cmd"], metadata)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(err.Error()))
+					return
+				}
+				logger.With("canary", payload.Name).Infof("cmd %s", cmd)
+
+				logCmdOutput, _ := strconv.ParseBool(metadata["logCmdOutput"])
+				cmdTask := BashTask{
+					command:      cmd,
+					logCmdOutput: logCmdOutput,
+					shell:        "sh",
+					splitOutput:  true,
+					TaskBase: TaskBase{
+						canary: fmt.Sprintf("%s.%s", payload.Name, payload.Namespace),
+						logger: logger,
+					},
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+
+				result, err := cmdTask.Run(ctx)
+				if !result.ok {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				if rtnCmdOutput {
+					w.Write(result.out)
+				}
+				return
+			}
+
+			// fan out the task to the load tester replicas (non-blocking task)
+			if typ == TaskTypeShell && isDistributed(metadata) {
+				if !authorizer.AuthorizePeers(metadata["peers"]) {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte("Forbidden"))
+					return
+				}
+				if err := distributeTask(r.Context(), payload, logger); err != nil {
+					logger.With("canary", payload.Name).Errorf("distributed task error: %s", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(err.Error()))
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+
 			taskFactory, ok := GetTaskFactory(typ)
 			if !ok {
 				w.WriteHeader(http.StatusBadRequest)
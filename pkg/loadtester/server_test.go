@@ -46,7 +46,7 @@ func TestServer_HandleNewBashTaskCmdExitZero(t *testing.T) {
 			"cmd":  "echo some-output-not-to-be-returned",
 		},
 	})
-	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil))(resp, req)
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.Empty(t, resp.Body.String())
@@ -62,7 +62,7 @@ func TestServer_HandleNewBashTaskCmdExitZeroReturnCmdOutput(t *testing.T) {
 			"returnCmdOutput": "true",
 		},
 	})
-	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil))(resp, req)
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.Equal(t, "some-output-to-be-returned\n", resp.Body.String())
@@ -78,12 +78,60 @@ func TestServer_HandleNewBashTaskCmdExitNonZero(t *testing.T) {
 		},
 	})
 
-	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil))(resp, req)
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
 
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	assert.Equal(t, "command false failed: : exit status 1", resp.Body.String())
 }
 
+func TestServer_HandleNewBlockingCmdTaskReturnCmdOutput(t *testing.T) {
+	mocks := newServerFixture()
+	resp := mocks.resp
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Metadata: map[string]string{
+			"type":            TaskTypeShell,
+			"cmd":             "echo out; echo err >&2",
+			"blocking":        "true",
+			"returnCmdOutput": "true",
+		},
+	})
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "stdout:\nout\nstderr:\nerr\n", resp.Body.String())
+}
+
+func TestServer_HandleNewBlockingCmdTaskExitNonZero(t *testing.T) {
+	mocks := newServerFixture()
+	resp := mocks.resp
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Metadata: map[string]string{
+			"cmd":      "echo assertion failed >&2; exit 3",
+			"blocking": "true",
+		},
+	})
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, "command echo assertion failed >&2; exit 3 failed: exit status 3\nstderr:\nassertion failed\n", resp.Body.String())
+}
+
+func TestServer_HandleNewBlockingCmdTaskTimeout(t *testing.T) {
+	mocks := newServerFixture()
+	resp := mocks.resp
+	req := newJsonRequest("POST", "/", &flaggerv1.CanaryWebhookPayload{
+		Metadata: map[string]string{
+			"cmd":      "sleep 10",
+			"blocking": "true",
+			"timeout":  "100ms",
+		},
+	})
+	HandleNewTask(mocks.logger, mocks.taskRunner, NewAuthorizer(nil, ""))(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "timed out")
+}
+
 func newJsonRequest(method string, url string, v interface{}) *http.Request {
 	payload, _ := json.Marshal(v)
 	req, _ := http.NewRequest(method, url, bytes.NewReader(payload))
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+// SlackInteractions verifies the interactive message requests sent by Slack
+type SlackInteractions struct {
+	signingSecret string
+	maxAge        time.Duration
+}
+
+// slackInteraction holds the fields of a Slack interactive message payload used by the gate
+// https://api.slack.com/legacy/interactive-messages
+type slackInteraction struct {
+	CallbackID string `json:"callback_id"`
+	Actions    []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"actions"`
+	User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+// NewSlackInteractions returns a handler configuration for the given Slack app signing secret
+func NewSlackInteractions(signingSecret string) *SlackInteractions {
+	return &SlackInteractions{
+		signingSecret: signingSecret,
+		maxAge:        5 * time.Minute,
+	}
+}
+
+// verify checks the request signature computed by Slack with the app signing secret
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func (s *SlackInteractions) verify(header http.Header, body []byte) error {
+	if s.signingSecret == "" {
+		return errors.New("Slack signing secret not set")
+	}
+
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp %q", timestamp)
+	}
+	if time.Since(time.Unix(ts, 0)).Abs() > s.maxAge {
+		return fmt.Errorf("request timestamp %q expired", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("invalid request signature")
+	}
+	return nil
+}
+
+// HandleSlackInteraction opens the canary gate or the rollback gate
+// when the Approve or Reject button of a Flagger Slack message is clicked
+func HandleSlackInteraction(logger *zap.SugaredLogger, slack *SlackInteractions, gate *GateStorage, authorizer *Authorizer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("reading the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if err := slack.verify(r.Header, body); err != nil {
+			logger.Errorf("Slack request verification failed: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			logger.Error("decoding the request body failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		interaction := &slackInteraction{}
+		if err := json.Unmarshal([]byte(form.Get("payload")), interaction); err != nil {
+			logger.Error("decoding the Slack payload failed", zap.Error(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if interaction.CallbackID != notifier.SlackGateCallbackID || len(interaction.Actions) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the action value is set to <canary name>.<namespace>
+		action := interaction.Actions[0]
+		i := strings.LastIndex(action.Value, ".")
+		if i < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		canary := &flaggerv1.CanaryWebhookPayload{Name: action.Value[:i], Namespace: action.Value[i+1:]}
+		if !authorizer.Authorize(canary) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+			return
+		}
+
+		var text string
+		switch action.Name {
+		case "approve":
+			gate.open(action.Value)
+			text = fmt.Sprintf("Canary %s approved by <@%s>", action.Value, interaction.User.ID)
+			logger.Infof("%s gate opened by Slack user %s", action.Value, interaction.User.Name)
+		case "reject":
+			gate.open(fmt.Sprintf("rollback.%s", action.Value))
+			text = fmt.Sprintf("Canary %s rejected by <@%s>", action.Value, interaction.User.ID)
+			logger.Infof("%s rollback opened by Slack user %s", action.Value, interaction.User.Name)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(map[string]interface{}{
+			"replace_original": false,
+			"response_type":    "in_channel",
+			"text":             text,
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlackRequest(secret, payload string, ts time.Time) *http.Request {
+	body := url.Values{"payload": {payload}}.Encode()
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+	req, _ := http.NewRequest("POST", "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestServer_HandleSlackInteraction(t *testing.T) {
+	slack := NewSlackInteractions("signing-secret")
+	gate := NewGateStorage("in-memory")
+	approve := `{"callback_id":"flagger-gate","actions":[{"name":"approve","value":"podinfo.test"}],"user":{"id":"U1","name":"jane"}}`
+	reject := `{"callback_id":"flagger-gate","actions":[{"name":"reject","value":"podinfo.test"}],"user":{"id":"U1","name":"jane"}}`
+
+	// invalid signature
+	mocks := newServerFixture()
+	HandleSlackInteraction(mocks.logger, slack, gate, NewAuthorizer(nil, ""))(mocks.resp,
+		newSlackRequest("wrong-secret", approve, time.Now()))
+	assert.Equal(t, http.StatusUnauthorized, mocks.resp.Code)
+	assert.False(t, gate.isOpen("podinfo.test"))
+
+	// expired request
+	mocks = newServerFixture()
+	HandleSlackInteraction(mocks.logger, slack, gate, NewAuthorizer(nil, ""))(mocks.resp,
+		newSlackRequest("signing-secret", approve, time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusUnauthorized, mocks.resp.Code)
+
+	// approve opens the gate
+	mocks = newServerFixture()
+	HandleSlackInteraction(mocks.logger, slack, gate, NewAuthorizer(nil, ""))(mocks.resp,
+		newSlackRequest("signing-secret", approve, time.Now()))
+	assert.Equal(t, http.StatusOK, mocks.resp.Code)
+	assert.Contains(t, mocks.resp.Body.String(), "approved by <@U1>")
+	assert.True(t, gate.isOpen("podinfo.test"))
+
+	// reject opens the rollback gate
+	mocks = newServerFixture()
+	gate = NewGateStorage("in-memory")
+	HandleSlackInteraction(mocks.logger, slack, gate, NewAuthorizer(nil, ""))(mocks.resp,
+		newSlackRequest("signing-secret", reject, time.Now()))
+	assert.Equal(t, http.StatusOK, mocks.resp.Code)
+	assert.True(t, gate.isOpen("rollback.podinfo.test"))
+	assert.False(t, gate.isOpen("podinfo.test"))
+}
@@ -33,6 +33,10 @@ func init() {
 		if !ok {
 			return nil, errors.New("cmd not found in metadata")
 		}
+		cmd, err := renderShardCommand(cmd, metadata)
+		if err != nil {
+			return nil, err
+		}
 		logCmdOutput, _ := strconv.ParseBool(metadata["logCmdOutput"])
 		return &CmdTask{TaskBase{canary, logger}, cmd, logCmdOutput}, nil
 	})
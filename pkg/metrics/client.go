@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// ClientRecorder records the requests sent by the Kubernetes clients as Prometheus metrics
+type ClientRecorder struct {
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	rateLimiter *prometheus.HistogramVec
+}
+
+// NewClientRecorder creates a new client recorder and registers the Prometheus metrics
+func NewClientRecorder(controller string, register bool) ClientRecorder {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: controller,
+		Name:      "kube_api_requests_total",
+		Help:      "Total number of Kubernetes API requests by verb, resource and status code",
+	}, []string{"verb", "group", "resource", "code"})
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "kube_api_request_duration_seconds",
+		Help:      "Seconds spent performing Kubernetes API requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"verb", "group", "resource"})
+
+	rateLimiter := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: controller,
+		Name:      "kube_api_rate_limiter_duration_seconds",
+		Help:      "Seconds Kubernetes API requests are delayed by the client side rate limiter.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"verb", "group", "resource"})
+
+	if register {
+		requests = mustRegister(requests)
+		duration = mustRegister(duration)
+		rateLimiter = mustRegister(rateLimiter)
+		clientmetrics.Register(clientmetrics.RegisterOpts{
+			RateLimiterLatency: rateLimiterLatency{rateLimiter},
+		})
+	}
+
+	return ClientRecorder{
+		requests:    requests,
+		duration:    duration,
+		rateLimiter: rateLimiter,
+	}
+}
+
+// WrapTransport instruments the transport of a Kubernetes client config,
+// meant to be used with rest.Config.Wrap
+func (cr ClientRecorder) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &clientTransport{recorder: cr, next: rt}
+}
+
+type clientTransport struct {
+	recorder ClientRecorder
+	next     http.RoundTripper
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, group, resource := requestInfo(req.Method, req.URL)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.recorder.duration.WithLabelValues(verb, group, resource).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.recorder.requests.WithLabelValues(verb, group, resource, code).Inc()
+	return resp, err
+}
+
+// rateLimiterLatency implements the client-go rate limiter latency metric
+type rateLimiterLatency struct {
+	histogram *prometheus.HistogramVec
+}
+
+func (r rateLimiterLatency) Observe(_ context.Context, method string, u url.URL, latency time.Duration) {
+	verb, group, resource := requestInfo(method, &u)
+	r.histogram.WithLabelValues(verb, group, resource).Observe(latency.Seconds())
+}
+
+// requestInfo returns the Kubernetes API verb, group and resource of a request
+// e.g. PUT /apis/networking.istio.io/v1alpha3/namespaces/test/virtualservices/podinfo
+// returns update, networking.istio.io and virtualservices
+func requestInfo(method string, u *url.URL) (verb string, group string, resource string) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return strings.ToLower(method), "", ""
+	}
+
+	// namespaced resources, a namespace is addressed as /namespaces/<name>
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	resource = parts[0]
+	hasName := len(parts) >= 2
+	if len(parts) >= 3 {
+		resource = resource + "/" + parts[2]
+	}
+
+	switch method {
+	case http.MethodGet:
+		switch {
+		case u.Query().Get("watch") == "true" || u.Query().Get("watch") == "1":
+			verb = "watch"
+		case hasName:
+			verb = "get"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+		if !hasName {
+			verb = "deletecollection"
+		}
+	default:
+		verb = strings.ToLower(method)
+	}
+	return verb, group, resource
+}
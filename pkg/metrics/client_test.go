@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestInfo(t *testing.T) {
+	tests := []struct {
+		method   string
+		url      string
+		verb     string
+		group    string
+		resource string
+	}{
+		{"GET", "/api/v1/namespaces/test/services/podinfo", "get", "", "services"},
+		{"GET", "/api/v1/namespaces/test/services", "list", "", "services"},
+		{"GET", "/api/v1/namespaces/test", "get", "", "namespaces"},
+		{"GET", "/apis/flagger.app/v1beta1/canaries?watch=true", "watch", "flagger.app", "canaries"},
+		{"PUT", "/apis/networking.istio.io/v1alpha3/namespaces/test/virtualservices/podinfo", "update", "networking.istio.io", "virtualservices"},
+		{"PUT", "/apis/flagger.app/v1beta1/namespaces/test/canaries/podinfo/status", "update", "flagger.app", "canaries/status"},
+		{"POST", "/apis/apps/v1/namespaces/test/deployments", "create", "apps", "deployments"},
+		{"PATCH", "/apis/apps/v1/namespaces/test/deployments/podinfo", "patch", "apps", "deployments"},
+		{"DELETE", "/api/v1/namespaces/test/pods", "deletecollection", "", "pods"},
+		{"DELETE", "/api/v1/namespaces/test/pods/podinfo", "delete", "", "pods"},
+		{"GET", "/version", "get", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			verb, group, resource := requestInfo(tt.method, u)
+			assert.Equal(t, tt.verb, verb)
+			assert.Equal(t, tt.group, group)
+			assert.Equal(t, tt.resource, resource)
+		})
+	}
+}
+
+func TestClientRecorder_WrapTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer ts.Close()
+
+	recorder := NewClientRecorder("test", false)
+	client := &http.Client{Transport: recorder.WrapTransport(http.DefaultTransport)}
+
+	path := ts.URL + "/apis/networking.istio.io/v1alpha3/namespaces/test/virtualservices/podinfo"
+	_, err := client.Get(path)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, path, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.requests.WithLabelValues("get", "networking.istio.io", "virtualservices", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.requests.WithLabelValues("update", "networking.istio.io", "virtualservices", "409")))
+	assert.Equal(t, 2, testutil.CollectAndCount(recorder.duration))
+
+	// transport errors
+	ts.Close()
+	_, err = client.Get(path)
+	require.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.requests.WithLabelValues("get", "networking.istio.io", "virtualservices", "error")))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// Emissary names the Envoy clusters after the mapping service with dots and dashes replaced by underscores
+// e.g. envoy_cluster_name="cluster_podinfo_canary_test_9898_test"
+var emissaryQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				envoy_cluster_name=~"cluster_{{ service }}_canary_{{ namespace }}_.*",
+				envoy_response_code!~"5.*"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			envoy_cluster_upstream_rq{
+				envoy_cluster_name=~"cluster_{{ service }}_canary_{{ namespace }}_.*"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	histogram_quantile(
+		0.99,
+		sum(
+			rate(
+				envoy_cluster_upstream_rq_time_bucket{
+					envoy_cluster_name=~"cluster_{{ service }}_canary_{{ namespace }}_.*"
+				}[{{ interval }}]
+			)
+		) by (le)
+	)`,
+}
+
+// EmissaryObserver implementation for Emissary-ingress
+type EmissaryObserver struct {
+	client providers.Interface
+}
+
+func (ob *EmissaryObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(emissaryQueries["request-success-rate"], emissaryModel(model))
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+func (ob *EmissaryObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(emissaryQueries["request-duration"], emissaryModel(model))
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
+
+// emissaryModel escapes the service and namespace the same way Emissary does in the cluster names
+func emissaryModel(model flaggerv1.MetricTemplateModel) flaggerv1.MetricTemplateModel {
+	model.Service = strings.ReplaceAll(model.Service, "-", "_")
+	model.Namespace = strings.ReplaceAll(model.Namespace, "-", "_")
+	return model
+}
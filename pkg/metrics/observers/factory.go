@@ -68,7 +68,13 @@ func (factory Factory) Observer(provider string) Interface {
 		return &NginxObserver{
 			client: factory.Client,
 		}
-	case provider == flaggerv1.KubernetesProvider || strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider):
+	case strings.HasPrefix(provider, flaggerv1.KnativeProvider):
+		return &KnativeObserver{
+			client: factory.Client,
+		}
+	case provider == flaggerv1.KubernetesProvider || provider == flaggerv1.SelectorSwitchProvider ||
+		strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider) || provider == flaggerv1.GKEProvider ||
+		provider == flaggerv1.ExternalDNSProvider || provider == flaggerv1.HAProxyProvider:
 		return &HttpObserver{
 			client: factory.Client,
 		}
@@ -92,6 +98,14 @@ func (factory Factory) Observer(provider string) Interface {
 		return &ApisixObserver{
 			client: factory.Client,
 		}
+	case provider == flaggerv1.OpenShiftProvider:
+		return &OpenShiftObserver{
+			client: factory.Client,
+		}
+	case provider == flaggerv1.EmissaryProvider:
+		return &EmissaryObserver{
+			client: factory.Client,
+		}
 	default:
 		return &IstioObserver{
 			client: factory.Client,
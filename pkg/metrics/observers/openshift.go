@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// the OpenShift HAProxy router reports the response codes by class e.g. code="5xx"
+// and the server latency as an average over the last 1024 requests
+var openshiftQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			haproxy_server_http_responses_total{
+				namespace="{{ namespace }}",
+				service="{{ service }}-canary",
+				code!="5xx"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			haproxy_server_http_responses_total{
+				namespace="{{ namespace }}",
+				service="{{ service }}-canary"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	avg(
+		avg_over_time(
+			haproxy_server_http_average_response_latency_milliseconds{
+				namespace="{{ namespace }}",
+				service="{{ service }}-canary"
+			}[{{ interval }}]
+		)
+	)`,
+}
+
+// OpenShiftObserver implementation for the OpenShift HAProxy router
+type OpenShiftObserver struct {
+	client providers.Interface
+}
+
+func (ob *OpenShiftObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(openshiftQueries["request-success-rate"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+func (ob *OpenShiftObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(openshiftQueries["request-duration"], model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// NormalizeQuery collapses the whitespace of a rendered query
+func NormalizeQuery(query string) string {
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+// queryRecorder captures the queries of an observer without running them
+type queryRecorder struct {
+	queries []string
+}
+
+func (r *queryRecorder) RunQuery(query string) (float64, error) {
+	r.queries = append(r.queries, query)
+	return 0, nil
+}
+
+func (r *queryRecorder) IsOnline() (bool, error) {
+	return true, nil
+}
+
+// RenderQueries returns the builtin metric queries of the provider rendered for the model
+func RenderQueries(provider string, model flaggerv1.MetricTemplateModel) (map[string]string, error) {
+	recorder := &queryRecorder{}
+	observer := Factory{Client: recorder}.Observer(provider)
+
+	if _, err := observer.GetRequestSuccessRate(model); err != nil {
+		return nil, err
+	}
+	if _, err := observer.GetRequestDuration(model); err != nil {
+		return nil, err
+	}
+	if len(recorder.queries) != 2 {
+		return nil, fmt.Errorf("observer for %s ran %d queries, expected 2", provider, len(recorder.queries))
+	}
+
+	return map[string]string{
+		flaggerv1.BuiltinMetricRequestSuccessRate: NormalizeQuery(recorder.queries[0]),
+		flaggerv1.BuiltinMetricRequestDuration:    NormalizeQuery(recorder.queries[1]),
+	}, nil
+}
+
+// RecordedClient reads the series recorded by a Prometheus recording rule
+// instead of running the query the rule was generated from
+type RecordedClient struct {
+	client providers.Interface
+	// records maps the normalized queries to the recorded series selectors
+	records map[string]string
+}
+
+// NewRecordedClient returns a client that reads the recorded series of the given queries
+func NewRecordedClient(client providers.Interface, records map[string]string) *RecordedClient {
+	return &RecordedClient{
+		client:  client,
+		records: records,
+	}
+}
+
+// RunQuery reads the recorded series of the query, falling back to
+// the query when the series has no samples e.g. the rule was not yet evaluated
+func (c *RecordedClient) RunQuery(query string) (float64, error) {
+	selector, ok := c.records[NormalizeQuery(query)]
+	if !ok {
+		return c.client.RunQuery(query)
+	}
+
+	val, err := c.client.RunQuery(selector)
+	if errors.Is(err, providers.ErrNoValuesFound) {
+		return c.client.RunQuery(query)
+	}
+	return val, err
+}
+
+func (c *RecordedClient) IsOnline() (bool, error) {
+	return c.client.IsOnline()
+}
+
+// WithRecords returns a factory whose observers read the recorded series of the given queries
+func (factory Factory) WithRecords(records map[string]string) *Factory {
+	return &Factory{
+		Client: NewRecordedClient(factory.Client, records),
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRenderQueries(t *testing.T) {
+	queries, err := RenderQueries(flaggerv1.IstioProvider, flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo", response_code!~"5.*" }[1m] ) ) / sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo" }[1m] ) ) * 100`,
+		queries[flaggerv1.BuiltinMetricRequestSuccessRate])
+	assert.Contains(t, queries[flaggerv1.BuiltinMetricRequestDuration], "histogram_quantile( 0.99")
+}
+
+func TestRecordedClient_RunQuery(t *testing.T) {
+	model := flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	}
+	queries, err := RenderQueries(flaggerv1.IstioProvider, model)
+	require.NoError(t, err)
+	selector := `flagger:canary_request_duration{namespace="default",canary="podinfo",interval="1m"}`
+
+	recorded, failing := true, false
+	var ran []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		promql := r.URL.Query()["query"][0]
+		ran = append(ran, promql)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"200"]}]}}`
+		if promql == selector && !recorded {
+			json = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+		}
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	factory, err := NewFactory(ts.URL)
+	require.NoError(t, err)
+	observer := factory.WithRecords(map[string]string{
+		queries[flaggerv1.BuiltinMetricRequestDuration]: selector,
+	}).Observer(flaggerv1.IstioProvider)
+
+	t.Run("recorded", func(t *testing.T) {
+		ran = nil
+		val, err := observer.GetRequestDuration(model)
+		require.NoError(t, err)
+		assert.Equal(t, 200*time.Millisecond, val)
+		assert.Equal(t, []string{selector}, ran)
+
+		// queries without a recording rule run as is
+		ran = nil
+		_, err = observer.GetRequestSuccessRate(model)
+		require.NoError(t, err)
+		require.Len(t, ran, 1)
+		assert.NotEqual(t, selector, ran[0])
+	})
+
+	t.Run("not evaluated", func(t *testing.T) {
+		recorded = false
+		ran = nil
+		val, err := observer.GetRequestDuration(model)
+		require.NoError(t, err)
+		assert.Equal(t, 200*time.Millisecond, val)
+		require.Len(t, ran, 2)
+		assert.Equal(t, selector, ran[0])
+		assert.Equal(t, queries[flaggerv1.BuiltinMetricRequestDuration], NormalizeQuery(ran[1]))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		failing = true
+		ran = nil
+		_, err := observer.GetRequestDuration(model)
+		require.Error(t, err)
+		assert.Equal(t, []string{selector}, ran)
+	})
+}
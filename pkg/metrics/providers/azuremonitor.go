@@ -0,0 +1,287 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://learn.microsoft.com/en-us/azure/azure-monitor/logs/api/request-format
+const (
+	azureMonitorQueryPath      = "query"
+	azureMonitorOnlineQuery    = "print 1"
+	azureDefaultAuthorityHost  = "https://login.microsoftonline.com/"
+	azureTenantIDSecretKey     = "tenantId"
+	azureClientIDSecretKey     = "clientId"
+	azureClientSecretSecretKey = "clientSecret"
+)
+
+// azureIMDSEndpoint is the managed identity token endpoint of the Azure instance metadata service
+var azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// AzureMonitorProvider executes KQL queries against a Log Analytics workspace
+type AzureMonitorProvider struct {
+	timeout       time.Duration
+	url           url.URL
+	timespan      string
+	client        *http.Client
+	authorityHost string
+	tenantID      string
+	clientID      string
+	clientSecret  string
+	tokenFile     string
+	token         string
+	tokenExpiry   time.Time
+}
+
+type azureMonitorResponse struct {
+	Tables []struct {
+		Columns []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"tables"`
+}
+
+type azureTokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+}
+
+// NewAzureMonitorProvider takes a metric interval, a provider spec and the credentials map, and
+// returns an Azure Monitor client ready to execute KQL queries against the workspace in the address.
+// The client authenticates with the service principal from the credentials, with the AKS workload
+// identity or with the managed identity of the node, in this order.
+func NewAzureMonitorProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*AzureMonitorProvider, error) {
+	u, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	md, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	az := AzureMonitorProvider{
+		timeout:       5 * time.Second,
+		url:           *u,
+		timespan:      fmt.Sprintf("PT%dS", int64(md.Seconds())),
+		client:        http.DefaultClient,
+		authorityHost: azureDefaultAuthorityHost,
+		tenantID:      os.Getenv("AZURE_TENANT_ID"),
+		clientID:      os.Getenv("AZURE_CLIENT_ID"),
+		tokenFile:     os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+	}
+	if v := os.Getenv("AZURE_AUTHORITY_HOST"); v != "" {
+		az.authorityHost = v
+	}
+
+	if v, ok := credentials[azureTenantIDSecretKey]; ok {
+		az.tenantID = string(v)
+	}
+	if v, ok := credentials[azureClientIDSecretKey]; ok {
+		az.clientID = string(v)
+	}
+	if v, ok := credentials[azureClientSecretSecretKey]; ok {
+		az.clientSecret = string(v)
+		if az.tenantID == "" || az.clientID == "" {
+			return nil, fmt.Errorf("%s credentials with a clientSecret must contain a tenantId and a clientId", provider.Type)
+		}
+	}
+
+	return &az, nil
+}
+
+// RunQuery executes the KQL query in the metric interval timespan
+// and returns the first numeric value of the first row as float64
+func (p *AzureMonitorProvider) RunQuery(query string) (float64, error) {
+	token, err := p.getToken()
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"query":    query,
+		"timespan": p.timespan,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling query: %w", err)
+	}
+
+	u := p.url
+	u.Path = strings.TrimSuffix(p.url.Path, "/") + "/" + azureMonitorQueryPath
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var res azureMonitorResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(res.Tables) < 1 || len(res.Tables[0].Rows) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	table := res.Tables[0]
+	for i, v := range table.Rows[0] {
+		switch value := v.(type) {
+		case float64:
+			return value, nil
+		case string:
+			// decimal values are serialized as strings
+			if i < len(table.Columns) && table.Columns[i].Type == "decimal" {
+				return strconv.ParseFloat(value, 64)
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+}
+
+// IsOnline runs a simple KQL query and returns an error if the API is unreachable
+func (p *AzureMonitorProvider) IsOnline() (bool, error) {
+	value, err := p.RunQuery(azureMonitorOnlineQuery)
+	if err != nil {
+		return false, fmt.Errorf("running query failed: %w", err)
+	}
+
+	if value != float64(1) {
+		return false, fmt.Errorf("value is not 1 for query: %s", azureMonitorOnlineQuery)
+	}
+
+	return true, nil
+}
+
+// getToken returns a cached access token for the Log Analytics API or requests a new one
+func (p *AzureMonitorProvider) getToken() (string, error) {
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	resource := fmt.Sprintf("%s://%s", p.url.Scheme, p.url.Host)
+
+	var req *http.Request
+	var err error
+	switch {
+	case p.clientSecret != "":
+		req, err = p.newTokenRequest(resource, url.Values{
+			"grant_type":    []string{"client_credentials"},
+			"client_secret": []string{p.clientSecret},
+		})
+	case p.tokenFile != "" && p.tenantID != "" && p.clientID != "":
+		assertion, rerr := os.ReadFile(p.tokenFile)
+		if rerr != nil {
+			return "", fmt.Errorf("error reading federated token: %w", rerr)
+		}
+		req, err = p.newTokenRequest(resource, url.Values{
+			"grant_type":            []string{"client_credentials"},
+			"client_assertion_type": []string{"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"client_assertion":      []string{strings.TrimSpace(string(assertion))},
+		})
+	default:
+		q := url.Values{
+			"api-version": []string{"2018-02-01"},
+			"resource":    []string{resource},
+		}
+		if p.clientID != "" {
+			q.Set("client_id", p.clientID)
+		}
+		req, err = http.NewRequest("GET", azureIMDSEndpoint+"?"+q.Encode(), nil)
+		if req != nil {
+			req.Header.Set("Metadata", "true")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	b, err := p.do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+
+	var res azureTokenResponse
+	if err := json.Unmarshal(b, &res); err != nil || res.AccessToken == "" {
+		return "", fmt.Errorf("invalid token response: %s", string(b))
+	}
+
+	expiresIn, _ := res.ExpiresIn.Int64()
+	p.token = res.AccessToken
+	// refresh the token one minute before it expires
+	p.tokenExpiry = time.Now().Add(time.Duration(expiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
+
+// newTokenRequest returns a Microsoft Entra ID client credentials request for the resource
+func (p *AzureMonitorProvider) newTokenRequest(resource string, form url.Values) (*http.Request, error) {
+	form.Set("client_id", p.clientID)
+	form.Set("scope", resource+"/.default")
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(p.authorityHost, "/"), p.tenantID)
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+func (p *AzureMonitorProvider) do(req *http.Request) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewAzureMonitorProvider(t *testing.T) {
+	_, err := NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{Type: "azuremonitor"}, nil)
+	require.Error(t, err)
+
+	_, err = NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "azuremonitor",
+		Address: "https://api.loganalytics.io/v1/workspaces/ws",
+	}, map[string][]byte{"clientSecret": []byte("secret")})
+	require.Error(t, err)
+
+	ap, err := NewAzureMonitorProvider("5m", flaggerv1.MetricTemplateProvider{
+		Type:    "azuremonitor",
+		Address: "https://api.loganalytics.io/v1/workspaces/ws",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "PT300S", ap.timespan)
+}
+
+func TestAzureMonitorProvider_RunQuery(t *testing.T) {
+	query := `AppRequests | where Success == false | summarize count()`
+
+	newServer := func(t *testing.T, checkToken func(r *http.Request), result string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/tenant/oauth2/v2.0/token", "/imds":
+				checkToken(r)
+				w.Write([]byte(`{"access_token": "token", "expires_in": "3600"}`))
+			case "/v1/workspaces/ws/query":
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				var body map[string]string
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, query, body["query"])
+				assert.Equal(t, "PT60S", body["timespan"])
+				w.Write([]byte(result))
+			default:
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+		}))
+	}
+
+	t.Run("client secret", func(t *testing.T) {
+		ts := newServer(t, func(r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "client", r.PostForm.Get("client_id"))
+			assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+		}, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"TimeGenerated","type":"datetime"},{"name":"count_","type":"long"}],"rows":[["2023-01-01T00:00:00Z",5]]}]}`)
+		defer ts.Close()
+		t.Setenv("AZURE_AUTHORITY_HOST", ts.URL)
+
+		ap, err := NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "azuremonitor",
+			Address: ts.URL + "/v1/workspaces/ws",
+		}, map[string][]byte{
+			"tenantId":     []byte("tenant"),
+			"clientId":     []byte("client"),
+			"clientSecret": []byte("secret"),
+		})
+		require.NoError(t, err)
+
+		f, err := ap.RunQuery(query)
+		require.NoError(t, err)
+		assert.Equal(t, float64(5), f)
+	})
+
+	t.Run("workload identity", func(t *testing.T) {
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("federated\n"), 0600))
+
+		ts := newServer(t, func(r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "federated", r.PostForm.Get("client_assertion"))
+			assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		}, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"rate","type":"decimal"}],"rows":[["1.5"]]}]}`)
+		defer ts.Close()
+		t.Setenv("AZURE_AUTHORITY_HOST", ts.URL)
+		t.Setenv("AZURE_TENANT_ID", "tenant")
+		t.Setenv("AZURE_CLIENT_ID", "client")
+		t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+
+		ap, err := NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "azuremonitor",
+			Address: ts.URL + "/v1/workspaces/ws",
+		}, nil)
+		require.NoError(t, err)
+
+		f, err := ap.RunQuery(query)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, f)
+	})
+
+	t.Run("managed identity", func(t *testing.T) {
+		var tokenRequests int
+		ts := newServer(t, func(r *http.Request) {
+			tokenRequests++
+			assert.Equal(t, "true", r.Header.Get("Metadata"))
+			assert.Equal(t, "identity", r.URL.Query().Get("client_id"))
+		}, `{"tables":[{"name":"PrimaryResult","columns":[{"name":"count_","type":"long"}],"rows":[]}]}`)
+		defer ts.Close()
+
+		imds := azureIMDSEndpoint
+		azureIMDSEndpoint = ts.URL + "/imds"
+		defer func() { azureIMDSEndpoint = imds }()
+
+		ap, err := NewAzureMonitorProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "azuremonitor",
+			Address: ts.URL + "/v1/workspaces/ws",
+		}, map[string][]byte{"clientId": []byte("identity")})
+		require.NoError(t, err)
+
+		_, err = ap.RunQuery(query)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+
+		// the token is cached
+		_, err = ap.RunQuery(query)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+		assert.Equal(t, 1, tokenRequests)
+	})
+}
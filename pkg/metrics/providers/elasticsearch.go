@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-aggregations.html
+const (
+	elasticsearchSearchPath      = "_search"
+	elasticsearchResultAggName   = "result"
+	elasticsearchAPIKeySecretKey = "apiKey"
+)
+
+// ElasticsearchProvider executes Elasticsearch and OpenSearch aggregation queries
+type ElasticsearchProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	username string
+	password string
+	apiKey   string
+	client   *http.Client
+}
+
+type elasticsearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value float64 `json:"value"`
+		} `json:"total"`
+	} `json:"hits"`
+	Aggregations map[string]elasticsearchAggregation `json:"aggregations"`
+}
+
+type elasticsearchAggregation struct {
+	Value    *float64            `json:"value"`
+	Values   map[string]*float64 `json:"values"`
+	DocCount *float64            `json:"doc_count"`
+}
+
+// NewElasticsearchProvider takes a provider spec and the credentials map,
+// validates the address, extracts the API key or username and password values if provided and
+// returns an Elasticsearch client ready to execute search queries against the index in the address path
+func NewElasticsearchProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*ElasticsearchProvider, error) {
+	esURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	es := ElasticsearchProvider{
+		timeout: 5 * time.Second,
+		url:     *esURL,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		es.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if apiKey, ok := credentials[elasticsearchAPIKeySecretKey]; ok {
+			es.apiKey = string(apiKey)
+		} else {
+			if username, ok := credentials["username"]; ok {
+				es.username = string(username)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain an apiKey or a username", provider.Type)
+			}
+
+			if password, ok := credentials["password"]; ok {
+				es.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		}
+	}
+
+	return &es, nil
+}
+
+// RunQuery executes the search request body and returns the value of the aggregation
+// named result or of the only aggregation, when the query has no aggregations the total hits are returned
+func (p *ElasticsearchProvider) RunQuery(query string) (float64, error) {
+	if !json.Valid([]byte(query)) {
+		return 0, fmt.Errorf("the query must be a JSON search request body")
+	}
+
+	u := p.url
+	u.Path = path.Join(p.url.Path, elasticsearchSearchPath)
+	b, err := p.do("POST", u.String(), []byte(query))
+	if err != nil {
+		return 0, err
+	}
+
+	var res elasticsearchResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if len(res.Aggregations) == 0 {
+		return res.Hits.Total.Value, nil
+	}
+
+	agg, ok := res.Aggregations[elasticsearchResultAggName]
+	if !ok {
+		if len(res.Aggregations) > 1 {
+			return 0, fmt.Errorf("the query contains multiple aggregations, name one of them %s", elasticsearchResultAggName)
+		}
+		for _, a := range res.Aggregations {
+			agg = a
+		}
+	}
+
+	return agg.result()
+}
+
+// IsOnline calls the cluster info endpoint and returns an error if the API is unreachable
+func (p *ElasticsearchProvider) IsOnline() (bool, error) {
+	u := p.url
+	u.Path = "/"
+	if _, err := p.do("GET", u.String(), nil); err != nil {
+		return false, fmt.Errorf("cluster info query failed: %w", err)
+	}
+	return true, nil
+}
+
+func (p *ElasticsearchProvider) do(method string, address string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.apiKey != "" {
+		req.Header.Add("Authorization", "ApiKey "+p.apiKey)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
+
+// result returns the value of a single-value metric aggregation, the first percentile
+// of a percentiles aggregation or the document count of a single bucket aggregation
+func (a elasticsearchAggregation) result() (float64, error) {
+	switch {
+	case a.Value != nil:
+		return *a.Value, nil
+	case len(a.Values) > 0:
+		keys := make([]string, 0, len(a.Values))
+		for k := range a.Values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if v := a.Values[keys[0]]; v != nil {
+			return *v, nil
+		}
+	case a.DocCount != nil:
+		return *a.DocCount, nil
+	}
+	return 0, fmt.Errorf("%w", ErrNoValuesFound)
+}
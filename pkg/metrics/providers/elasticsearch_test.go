@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewElasticsearchProvider(t *testing.T) {
+	_, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{Type: "elasticsearch"}, nil)
+	require.Error(t, err)
+
+	_, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "elasticsearch",
+		Address:   "https://es:9200/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "es"},
+	}, map[string][]byte{"username": []byte("elastic")})
+	require.Error(t, err)
+
+	ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "elasticsearch",
+		Address:   "https://es:9200/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "es"},
+	}, map[string][]byte{"apiKey": []byte("key")})
+	require.NoError(t, err)
+	assert.Equal(t, "key", ep.apiKey)
+}
+
+func TestElasticsearchProvider_RunQuery(t *testing.T) {
+	query := `{"size": 0, "query": {"term": {"level": "error"}}, "aggs": {"result": {"avg": {"field": "duration"}}}}`
+
+	tests := []struct {
+		name     string
+		response string
+		expected float64
+		err      error
+	}{
+		{
+			name:     "value",
+			response: `{"hits": {"total": {"value": 10}}, "aggregations": {"result": {"value": 12.5}}}`,
+			expected: 12.5,
+		},
+		{
+			name:     "named result",
+			response: `{"hits": {"total": {"value": 10}}, "aggregations": {"errors": {"doc_count": 3}, "result": {"value": 1}}}`,
+			expected: 1,
+		},
+		{
+			name:     "percentiles",
+			response: `{"hits": {"total": {"value": 10}}, "aggregations": {"latency": {"values": {"99.0": 250}}}}`,
+			expected: 250,
+		},
+		{
+			name:     "doc count",
+			response: `{"hits": {"total": {"value": 10}}, "aggregations": {"errors": {"doc_count": 3}}}`,
+			expected: 3,
+		},
+		{
+			name:     "hits",
+			response: `{"hits": {"total": {"value": 10}}}`,
+			expected: 10,
+		},
+		{
+			name:     "no values",
+			response: `{"hits": {"total": {"value": 0}}, "aggregations": {"result": {"value": null}}}`,
+			err:      ErrNoValuesFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "POST", r.Method)
+				assert.Equal(t, "/logs-*/_search", r.URL.Path)
+				user, pass, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "elastic", user)
+				assert.Equal(t, "secret", pass)
+				b, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, query, string(b))
+				w.Write([]byte(tt.response))
+			}))
+			defer ts.Close()
+
+			ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+				Type:      "elasticsearch",
+				Address:   ts.URL + "/logs-*",
+				SecretRef: &corev1.LocalObjectReference{Name: "es"},
+			}, map[string][]byte{"username": []byte("elastic"), "password": []byte("secret")})
+			require.NoError(t, err)
+
+			f, err := ep.RunQuery(query)
+			if tt.err != nil {
+				require.True(t, errors.Is(err, tt.err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+
+	t.Run("multiple aggregations", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"aggregations": {"a": {"value": 1}, "b": {"value": 2}}}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{Type: "opensearch", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = ep.RunQuery(`{"aggs": {}}`)
+		require.Error(t, err)
+
+		_, err = ep.RunQuery(`level:error`)
+		require.Error(t, err)
+	})
+}
+
+func TestElasticsearchProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/", r.URL.Path)
+		assert.Equal(t, "ApiKey key", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"cluster_name": "logs"}`))
+	}))
+	defer ts.Close()
+
+	ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "elasticsearch",
+		Address:   ts.URL + "/logs-*",
+		SecretRef: &corev1.LocalObjectReference{Name: "es"},
+	}, map[string][]byte{"apiKey": []byte("key")})
+	require.NoError(t, err)
+
+	ok, err := ep.IsOnline()
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
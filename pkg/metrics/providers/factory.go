@@ -27,6 +27,11 @@ func (factory Factory) Provider(
 	provider flaggerv1.MetricTemplateProvider,
 	credentials map[string][]byte,
 ) (Interface, error) {
+	// the address from the secret takes precedence over the address in the template
+	if address, ok := credentials["address"]; ok {
+		provider.Address = string(address)
+	}
+
 	switch provider.Type {
 	case "prometheus":
 		return NewPrometheusProvider(provider, credentials)
@@ -44,6 +49,16 @@ func (factory Factory) Provider(
 		return NewInfluxdbProvider(provider, credentials)
 	case "dynatrace":
 		return NewDynatraceProvider(metricInterval, provider, credentials)
+	case "jaeger":
+		return NewJaegerProvider(metricInterval, provider, credentials)
+	case "zipkin":
+		return NewZipkinProvider(metricInterval, provider, credentials)
+	case "loki":
+		return NewLokiProvider(provider, credentials)
+	case "elasticsearch", "opensearch":
+		return NewElasticsearchProvider(provider, credentials)
+	case "azuremonitor":
+		return NewAzureMonitorProvider(metricInterval, provider, credentials)
 	default:
 		return NewPrometheusProvider(provider, credentials)
 	}
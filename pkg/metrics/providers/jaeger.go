@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://www.jaegertracing.io/docs/latest/apis/#http-json-internal
+const (
+	jaegerTracesPath   = "/api/traces"
+	jaegerServicesPath = "/api/services"
+)
+
+// JaegerProvider searches the Jaeger traces and computes metrics from the matching spans
+type JaegerProvider struct {
+	client *tracingClient
+}
+
+type jaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type jaegerResponse struct {
+	Data []struct {
+		Spans []struct {
+			OperationName string           `json:"operationName"`
+			Duration      int64            `json:"duration"`
+			Tags          []jaegerKeyValue `json:"tags"`
+			ProcessID     string           `json:"processID"`
+		} `json:"spans"`
+		Processes map[string]struct {
+			ServiceName string           `json:"serviceName"`
+			Tags        []jaegerKeyValue `json:"tags"`
+		} `json:"processes"`
+	} `json:"data"`
+}
+
+// NewJaegerProvider takes a metric interval, a provider spec and the credentials map, and
+// returns a Jaeger client ready to execute trace searches against the query API
+func NewJaegerProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*JaegerProvider, error) {
+	client, err := newTracingClient(metricInterval, provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &JaegerProvider{client: client}, nil
+}
+
+// RunQuery searches the traces of the service in the metric interval
+// and computes the query metric from the spans matching the service, operation and tags
+func (p *JaegerProvider) RunQuery(query string) (float64, error) {
+	tq, err := parseTracingQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	service := tq.params["service"]
+	if service == "" {
+		return 0, fmt.Errorf("jaeger query requires the service parameter")
+	}
+	tags := map[string]string{}
+	if v, ok := tq.params["tags"]; ok {
+		if err := json.Unmarshal([]byte(v), &tags); err != nil {
+			return 0, fmt.Errorf("error unmarshaling tags %s: %w", v, err)
+		}
+	}
+
+	end := time.Now()
+	params := map[string]string{
+		"start": strconv.FormatInt(end.Add(-p.client.lookback).UnixMicro(), 10),
+		"end":   strconv.FormatInt(end.UnixMicro(), 10),
+	}
+	for k, v := range tq.params {
+		params[k] = v
+	}
+
+	b, err := p.client.get(jaegerTracesPath, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var res jaegerResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var spans []tracingSpan
+	for _, trace := range res.Data {
+		for _, span := range trace.Spans {
+			process := trace.Processes[span.ProcessID]
+			if process.ServiceName != service {
+				continue
+			}
+			if op := tq.params["operation"]; op != "" && span.OperationName != op {
+				continue
+			}
+			if !jaegerTagsMatch(tags, span.Tags, process.Tags) {
+				continue
+			}
+			spans = append(spans, tracingSpan{
+				duration: time.Duration(span.Duration) * time.Microsecond,
+				err:      jaegerSpanError(span.Tags),
+			})
+		}
+	}
+
+	return tq.aggregate(spans)
+}
+
+// IsOnline lists the Jaeger services and returns an error if the API is unreachable
+func (p *JaegerProvider) IsOnline() (bool, error) {
+	if _, err := p.client.get(jaegerServicesPath, nil); err != nil {
+		return false, fmt.Errorf("listing services failed: %w", err)
+	}
+	return true, nil
+}
+
+// jaegerTagsMatch returns true if the span or its process has all the tags,
+// the process tags hold the OpenTelemetry resource attributes e.g. service.version
+func jaegerTagsMatch(tags map[string]string, spanTags []jaegerKeyValue, processTags []jaegerKeyValue) bool {
+	for k, v := range tags {
+		found := false
+		for _, kv := range append(spanTags, processTags...) {
+			if kv.Key == k && fmt.Sprint(kv.Value) == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// jaegerSpanError returns true if the span has the error tag or an OpenTelemetry error status
+func jaegerSpanError(tags []jaegerKeyValue) bool {
+	for _, kv := range tags {
+		switch {
+		case kv.Key == "error" && fmt.Sprint(kv.Value) == "true":
+			return true
+		case kv.Key == "otel.status_code" && fmt.Sprint(kv.Value) == "ERROR":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const jaegerTestResponse = `
+{
+  "data": [
+    {
+      "traceID": "a1",
+      "spans": [
+        {"operationName": "GET /api", "duration": 10000, "processID": "p1", "tags": [{"key": "http.status_code", "type": "int64", "value": 200}]},
+        {"operationName": "GET /api", "duration": 30000, "processID": "p1", "tags": [{"key": "error", "type": "bool", "value": true}]},
+        {"operationName": "GET /api", "duration": 20000, "processID": "p2", "tags": []},
+        {"operationName": "GET /db", "duration": 50000, "processID": "p3", "tags": []}
+      ],
+      "processes": {
+        "p1": {"serviceName": "podinfo", "tags": [{"key": "service.version", "type": "string", "value": "canary"}]},
+        "p2": {"serviceName": "podinfo", "tags": [{"key": "service.version", "type": "string", "value": "primary"}]},
+        "p3": {"serviceName": "postgres", "tags": []}
+      }
+    }
+  ]
+}`
+
+func TestNewJaegerProvider(t *testing.T) {
+	_, err := NewJaegerProvider("1m", flaggerv1.MetricTemplateProvider{Type: "jaeger"}, nil)
+	require.Error(t, err)
+
+	_, err = NewJaegerProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:      "jaeger",
+		Address:   "http://jaeger-query:16686",
+		SecretRef: &corev1.LocalObjectReference{Name: "jaeger"},
+	}, map[string][]byte{"username": []byte("user")})
+	require.Error(t, err)
+
+	jp, err := NewJaegerProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "jaeger",
+		Address: "http://jaeger-query:16686",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "jaeger-query:16686", jp.client.url.Host)
+}
+
+func TestJaegerProvider_RunQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, jaegerTracesPath, r.URL.Path)
+		assert.Equal(t, "podinfo", r.URL.Query().Get("service"))
+		assert.Equal(t, `{"service.version":"canary"}`, r.URL.Query().Get("tags"))
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+		assert.NotEmpty(t, r.URL.Query().Get("start"))
+		assert.NotEmpty(t, r.URL.Query().Get("end"))
+		assert.Empty(t, r.URL.Query().Get("metric"))
+		w.Write([]byte(jaegerTestResponse))
+	}))
+	defer ts.Close()
+
+	jp, err := NewJaegerProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "jaeger",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		metric   string
+		expected float64
+	}{
+		{metric: "error_rate", expected: 50},
+		{metric: "request_count", expected: 2},
+		{metric: "duration_avg", expected: 20},
+		{metric: "duration_p99", expected: 30},
+		{metric: "duration_p50", expected: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			query := `
+				metric=` + tt.metric + `
+				service=podinfo
+				tags={"service.version":"canary"}`
+			f, err := jp.RunQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+
+	t.Run("no spans", func(t *testing.T) {
+		_, err := jp.RunQuery(`metric=error_rate&service=podinfo&tags={"service.version":"canary"}&operation=POST /api`)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("invalid metric", func(t *testing.T) {
+		_, err := jp.RunQuery(`metric=duration_p200&service=podinfo`)
+		require.Error(t, err)
+	})
+
+	t.Run("missing service", func(t *testing.T) {
+		_, err := jp.RunQuery(`metric=error_rate`)
+		require.Error(t, err)
+	})
+}
+
+func TestJaegerProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, jaegerServicesPath, r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data": ["podinfo"]}`))
+	}))
+	defer ts.Close()
+
+	jp, err := NewJaegerProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:      "jaeger",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "jaeger"},
+	}, map[string][]byte{"token": []byte("token")})
+	require.NoError(t, err)
+
+	ok, err := jp.IsOnline()
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
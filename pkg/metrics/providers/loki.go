@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://grafana.com/docs/loki/latest/reference/api/
+const (
+	lokiQueryPath  = "/loki/api/v1/query"
+	lokiLabelsPath = "/loki/api/v1/labels"
+
+	lokiTenantSecretKey = "tenant"
+	lokiTenantHeader    = "X-Scope-OrgID"
+)
+
+// LokiProvider executes LogQL metric queries
+type LokiProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	username string
+	password string
+	token    string
+	tenant   string
+	client   *http.Client
+}
+
+type lokiResponse struct {
+	Data struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewLokiProvider takes a provider spec and the credentials map,
+// validates the address, extracts the bearer token or username and password values
+// and the tenant ID if provided, and returns a Loki client ready to execute LogQL queries
+func NewLokiProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*LokiProvider, error) {
+	lokiURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	loki := LokiProvider{
+		timeout: 5 * time.Second,
+		url:     *lokiURL,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		loki.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if tenant, ok := credentials[lokiTenantSecretKey]; ok {
+			loki.tenant = string(tenant)
+		}
+
+		if token, ok := credentials["token"]; ok {
+			loki.token = string(token)
+		} else if username, ok := credentials["username"]; ok {
+			loki.username = string(username)
+			if password, ok := credentials["password"]; ok {
+				loki.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		} else if loki.tenant == "" {
+			return nil, fmt.Errorf("%s credentials does not contain a token, username or tenant", provider.Type)
+		}
+	}
+
+	return &loki, nil
+}
+
+// RunQuery executes the LogQL instant query and returns the first result as float64
+func (p *LokiProvider) RunQuery(query string) (float64, error) {
+	b, err := p.get(lokiQueryPath, url.Values{"query": []string{p.trimQuery(query)}})
+	if err != nil {
+		return 0, err
+	}
+
+	var result lokiResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	if t := result.Data.ResultType; t != "vector" {
+		return 0, fmt.Errorf("invalid result type '%s', the query must be a LogQL metric query", t)
+	}
+
+	var value *float64
+	for _, v := range result.Data.Result {
+		if len(v.Value) < 2 {
+			continue
+		}
+		if s, ok := v.Value[1].(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, err
+			}
+			value = &f
+		}
+	}
+	if value == nil || math.IsNaN(*value) {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return *value, nil
+}
+
+// IsOnline lists the Loki labels and returns an error if the API is unreachable
+func (p *LokiProvider) IsOnline() (bool, error) {
+	if _, err := p.get(lokiLabelsPath, nil); err != nil {
+		return false, fmt.Errorf("listing labels failed: %w", err)
+	}
+	return true, nil
+}
+
+func (p *LokiProvider) get(apiPath string, params url.Values) ([]byte, error) {
+	u := p.url
+	u.Path = path.Join(p.url.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if p.token != "" {
+		req.Header.Add("Authorization", "Bearer "+p.token)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	if p.tenant != "" {
+		req.Header.Set(lokiTenantHeader, p.tenant)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
+
+// trimQuery takes a LogQL query and removes whitespace
+func (p *LokiProvider) trimQuery(query string) string {
+	space := regexp.MustCompile(`\s+`)
+	return space.ReplaceAllString(query, " ")
+}
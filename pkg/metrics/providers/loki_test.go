@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewLokiProvider(t *testing.T) {
+	_, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki"}, nil)
+	require.Error(t, err)
+
+	_, err = NewLokiProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "loki",
+		Address:   "http://loki:3100",
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}, map[string][]byte{"username": []byte("user")})
+	require.Error(t, err)
+
+	lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "loki",
+		Address:   "http://loki:3100",
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}, map[string][]byte{"tenant": []byte("team-a")})
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", lp.tenant)
+}
+
+func TestLokiProvider_RunQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, lokiQueryPath, r.URL.Path)
+			assert.Equal(t, `sum(count_over_time({app="podinfo"} |= "ERROR" [1m]))`, r.URL.Query().Get("query"))
+			assert.Equal(t, "team-a", r.Header.Get(lokiTenantHeader))
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1680000000.000,"12"]}]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+			Type:      "loki",
+			Address:   ts.URL,
+			SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+		}, map[string][]byte{"tenant": []byte("team-a")})
+		require.NoError(t, err)
+
+		f, err := lp.RunQuery(`sum(count_over_time({app="podinfo"}
+			|= "ERROR" [1m]))`)
+		require.NoError(t, err)
+		assert.Equal(t, float64(12), f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = lp.RunQuery(`sum(count_over_time({app="podinfo"} |= "ERROR" [1m]))`)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("log query", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+		}))
+		defer ts.Close()
+
+		lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = lp.RunQuery(`{app="podinfo"} |= "ERROR"`)
+		require.Error(t, err)
+	})
+}
+
+func TestLokiProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, lokiLabelsPath, r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"status":"success","data":["app"]}`))
+	}))
+	defer ts.Close()
+
+	lp, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "loki",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}, map[string][]byte{"token": []byte("token")})
+	require.NoError(t, err)
+
+	ok, err := lp.IsOnline()
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
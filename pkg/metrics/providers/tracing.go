@@ -0,0 +1,252 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// tracing query aggregations, the durations are reported in milliseconds
+const (
+	tracingMetricKey          = "metric"
+	tracingErrorRate          = "error_rate"
+	tracingRequestCount       = "request_count"
+	tracingDurationAvg        = "duration_avg"
+	tracingDurationPercentile = "duration_p"
+	tracingDefaultLimit       = "1000"
+)
+
+// tracingQuery is a trace search, the metric is computed from the matching spans
+// and the params are sent to the tracing backend API
+type tracingQuery struct {
+	metric string
+	params map[string]string
+}
+
+// tracingSpan holds the span fields used to compute the metrics
+type tracingSpan struct {
+	duration time.Duration
+	err      bool
+}
+
+// tracingClient executes HTTP queries against a tracing backend
+type tracingClient struct {
+	timeout  time.Duration
+	url      url.URL
+	username string
+	password string
+	token    string
+	client   *http.Client
+	lookback time.Duration
+}
+
+// newTracingClient validates the address, extracts the bearer token or username and password values if provided
+// and uses the metric interval as the lookback window of the trace searches
+func newTracingClient(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*tracingClient, error) {
+	u, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	lookback, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	c := tracingClient{
+		timeout:  5 * time.Second,
+		url:      *u,
+		client:   http.DefaultClient,
+		lookback: lookback,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		c.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if token, ok := credentials["token"]; ok {
+			c.token = string(token)
+		} else {
+			if username, ok := credentials["username"]; ok {
+				c.username = string(username)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a username", provider.Type)
+			}
+
+			if password, ok := credentials["password"]; ok {
+				c.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		}
+	}
+
+	return &c, nil
+}
+
+// get queries the API path and returns the response body
+func (c *tracingClient) get(apiPath string, params map[string]string) ([]byte, error) {
+	u := c.url
+	u.Path = path.Join(c.url.Path, apiPath)
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if c.token != "" {
+		req.Header.Add("Authorization", "Bearer "+c.token)
+	} else if c.username != "" && c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	defer cancel()
+
+	r, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
+
+// parseTracingQuery parses the key=value pairs separated by new lines or ampersands,
+// the metric key selects the aggregation and the other keys are API params
+func parseTracingQuery(query string) (*tracingQuery, error) {
+	tq := &tracingQuery{params: make(map[string]string)}
+	for _, line := range strings.Split(query, "\n") {
+		for _, pair := range strings.Split(line, "&") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid query parameter %s, the format is key=value", pair)
+			}
+			key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			if key == tracingMetricKey {
+				tq.metric = value
+				continue
+			}
+			tq.params[key] = value
+		}
+	}
+
+	switch {
+	case tq.metric == tracingErrorRate, tq.metric == tracingRequestCount, tq.metric == tracingDurationAvg:
+	case strings.HasPrefix(tq.metric, tracingDurationPercentile):
+		if _, err := tracingPercentile(tq.metric); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid metric %q, can be %s, %s, %s or %sNN",
+			tq.metric, tracingErrorRate, tracingRequestCount, tracingDurationAvg, tracingDurationPercentile)
+	}
+
+	if _, ok := tq.params["limit"]; !ok {
+		tq.params["limit"] = tracingDefaultLimit
+	}
+
+	return tq, nil
+}
+
+func tracingPercentile(metric string) (float64, error) {
+	p, err := strconv.ParseFloat(strings.TrimPrefix(metric, tracingDurationPercentile), 64)
+	if err != nil || p <= 0 || p > 100 {
+		return 0, fmt.Errorf("invalid percentile metric %q, the format is %sNN", metric, tracingDurationPercentile)
+	}
+	return p, nil
+}
+
+// aggregate computes the metric from the matching spans
+func (tq *tracingQuery) aggregate(spans []tracingSpan) (float64, error) {
+	if len(spans) == 0 {
+		return 0, fmt.Errorf("no spans found: %w", ErrNoValuesFound)
+	}
+
+	switch tq.metric {
+	case tracingRequestCount:
+		return float64(len(spans)), nil
+	case tracingErrorRate:
+		var errs int
+		for _, s := range spans {
+			if s.err {
+				errs++
+			}
+		}
+		return float64(errs) / float64(len(spans)) * 100, nil
+	case tracingDurationAvg:
+		var sum time.Duration
+		for _, s := range spans {
+			sum += s.duration
+		}
+		return toMilliseconds(sum) / float64(len(spans)), nil
+	}
+
+	p, err := tracingPercentile(tq.metric)
+	if err != nil {
+		return 0, err
+	}
+	durations := make([]time.Duration, len(spans))
+	for i, s := range spans {
+		durations[i] = s.duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	// nearest-rank percentile
+	rank := int(math.Ceil(p/100*float64(len(durations)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return toMilliseconds(durations[rank]), nil
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
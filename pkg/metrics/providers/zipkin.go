@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://zipkin.io/zipkin-api/#/default/get_traces
+const (
+	zipkinTracesPath   = "/api/v2/traces"
+	zipkinServicesPath = "/api/v2/services"
+)
+
+// ZipkinProvider searches the Zipkin traces and computes metrics from the matching spans
+type ZipkinProvider struct {
+	client *tracingClient
+}
+
+type zipkinSpan struct {
+	Name          string `json:"name"`
+	Duration      int64  `json:"duration"`
+	LocalEndpoint struct {
+		ServiceName string `json:"serviceName"`
+	} `json:"localEndpoint"`
+	Tags map[string]string `json:"tags"`
+}
+
+// NewZipkinProvider takes a metric interval, a provider spec and the credentials map, and
+// returns a Zipkin client ready to execute trace searches against the API
+func NewZipkinProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*ZipkinProvider, error) {
+	client, err := newTracingClient(metricInterval, provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &ZipkinProvider{client: client}, nil
+}
+
+// RunQuery searches the traces of the service in the metric interval
+// and computes the query metric from the spans matching the service, span name and annotation query
+func (p *ZipkinProvider) RunQuery(query string) (float64, error) {
+	tq, err := parseTracingQuery(query)
+	if err != nil {
+		return 0, err
+	}
+
+	service := tq.params["serviceName"]
+	if service == "" {
+		return 0, fmt.Errorf("zipkin query requires the serviceName parameter")
+	}
+
+	params := map[string]string{
+		"endTs":    strconv.FormatInt(time.Now().UnixMilli(), 10),
+		"lookback": strconv.FormatInt(p.client.lookback.Milliseconds(), 10),
+	}
+	for k, v := range tq.params {
+		params[k] = v
+	}
+
+	b, err := p.client.get(zipkinTracesPath, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var traces [][]zipkinSpan
+	if err := json.Unmarshal(b, &traces); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var spans []tracingSpan
+	for _, trace := range traces {
+		for _, span := range trace {
+			// Zipkin stores the service and span names in lowercase
+			if !strings.EqualFold(span.LocalEndpoint.ServiceName, service) {
+				continue
+			}
+			if name := tq.params["spanName"]; name != "" && !strings.EqualFold(span.Name, name) {
+				continue
+			}
+			if !zipkinAnnotationsMatch(tq.params["annotationQuery"], span.Tags) {
+				continue
+			}
+			_, isErr := span.Tags["error"]
+			spans = append(spans, tracingSpan{
+				duration: time.Duration(span.Duration) * time.Microsecond,
+				err:      isErr,
+			})
+		}
+	}
+
+	return tq.aggregate(spans)
+}
+
+// IsOnline lists the Zipkin services and returns an error if the API is unreachable
+func (p *ZipkinProvider) IsOnline() (bool, error) {
+	if _, err := p.client.get(zipkinServicesPath, nil); err != nil {
+		return false, fmt.Errorf("listing services failed: %w", err)
+	}
+	return true, nil
+}
+
+// zipkinAnnotationsMatch returns true if the span tags match the annotation query,
+// e.g. "version=v2 and http.method=GET" where a term without a value matches the tag key
+func zipkinAnnotationsMatch(annotationQuery string, tags map[string]string) bool {
+	if annotationQuery == "" {
+		return true
+	}
+	for _, term := range strings.Split(annotationQuery, " and ") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		kv := strings.SplitN(term, "=", 2)
+		v, ok := tags[kv[0]]
+		if !ok || (len(kv) == 2 && v != kv[1]) {
+			return false
+		}
+	}
+	return true
+}
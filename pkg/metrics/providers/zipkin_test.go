@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const zipkinTestResponse = `
+[
+  [
+    {"name": "get /api", "duration": 10000, "localEndpoint": {"serviceName": "podinfo"}, "tags": {"version": "canary"}},
+    {"name": "get /api", "duration": 30000, "localEndpoint": {"serviceName": "podinfo"}, "tags": {"version": "canary", "error": "500"}},
+    {"name": "get /api", "duration": 20000, "localEndpoint": {"serviceName": "podinfo"}, "tags": {"version": "primary"}}
+  ],
+  [
+    {"name": "get /api", "duration": 20000, "localEndpoint": {"serviceName": "podinfo"}, "tags": {"version": "canary"}},
+    {"name": "select", "duration": 50000, "localEndpoint": {"serviceName": "postgres"}, "tags": {}}
+  ]
+]`
+
+func TestZipkinProvider_RunQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, zipkinTracesPath, r.URL.Path)
+		assert.Equal(t, "podinfo", r.URL.Query().Get("serviceName"))
+		assert.Equal(t, "version=canary", r.URL.Query().Get("annotationQuery"))
+		assert.Equal(t, "60000", r.URL.Query().Get("lookback"))
+		assert.NotEmpty(t, r.URL.Query().Get("endTs"))
+		w.Write([]byte(zipkinTestResponse))
+	}))
+	defer ts.Close()
+
+	zp, err := NewZipkinProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "zipkin",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		metric   string
+		expected float64
+	}{
+		{metric: "error_rate", expected: float64(1) / 3 * 100},
+		{metric: "request_count", expected: 3},
+		{metric: "duration_avg", expected: 20},
+		{metric: "duration_p95", expected: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			f, err := zp.RunQuery("metric=" + tt.metric + "&serviceName=podinfo&annotationQuery=version=canary")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+
+	t.Run("no spans", func(t *testing.T) {
+		_, err := zp.RunQuery("metric=error_rate&serviceName=podinfo&annotationQuery=version=canary&spanName=post /api")
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestZipkinProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, zipkinServicesPath, r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	zp, err := NewZipkinProvider("1m", flaggerv1.MetricTemplateProvider{
+		Type:    "zipkin",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	ok, err := zp.IsOnline()
+	require.Error(t, err)
+	assert.False(t, ok)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package metrics
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -32,10 +33,25 @@ type Recorder struct {
 	status   *prometheus.GaugeVec
 	weight   *prometheus.GaugeVec
 	analysis *prometheus.GaugeVec
+	cluster  string
+	extended bool
 }
 
-// NewRecorder creates a new recorder and registers the Prometheus metrics
-func NewRecorder(controller string, register bool) Recorder {
+// NewRecorder creates a new recorder and registers the Prometheus metrics,
+// the extended labels add the cluster name to the canary metrics and
+// the canary name and evaluation window to the metric analysis
+func NewRecorder(controller string, register bool, extendedLabels bool) Recorder {
+	canaryLabels := func(labels ...string) []string {
+		if extendedLabels {
+			return append(labels, "cluster")
+		}
+		return labels
+	}
+	analysisLabels := []string{"name", "namespace", "metric"}
+	if extendedLabels {
+		analysisLabels = append(analysisLabels, "canary", "interval", "cluster")
+	}
+
 	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Subsystem: controller,
 		Name:      "info",
@@ -47,40 +63,40 @@ func NewRecorder(controller string, register bool) Recorder {
 		Name:      "canary_duration_seconds",
 		Help:      "Seconds spent performing canary analysis.",
 		Buckets:   prometheus.DefBuckets,
-	}, []string{"name", "namespace"})
+	}, canaryLabels("name", "namespace"))
 
 	total := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Subsystem: controller,
 		Name:      "canary_total",
 		Help:      "Total number of canary object",
-	}, []string{"namespace"})
+	}, canaryLabels("namespace"))
 
 	// 0 - running, 1 - successful, 2 - failed
 	status := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Subsystem: controller,
 		Name:      "canary_status",
 		Help:      "Last canary analysis result",
-	}, []string{"name", "namespace"})
+	}, canaryLabels("name", "namespace"))
 
 	weight := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Subsystem: controller,
 		Name:      "canary_weight",
 		Help:      "The virtual service destination weight current value",
-	}, []string{"workload", "namespace"})
+	}, canaryLabels("workload", "namespace"))
 
 	analysis := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Subsystem: controller,
 		Name:      "canary_metric_analysis",
 		Help:      "Last canary analysis result per metric",
-	}, []string{"name", "namespace", "metric"})
+	}, analysisLabels)
 
 	if register {
-		prometheus.MustRegister(info)
-		prometheus.MustRegister(duration)
-		prometheus.MustRegister(total)
-		prometheus.MustRegister(status)
-		prometheus.MustRegister(weight)
-		prometheus.MustRegister(analysis)
+		info = mustRegister(info)
+		duration = mustRegister(duration)
+		total = mustRegister(total)
+		status = mustRegister(status)
+		weight = mustRegister(weight)
+		analysis = mustRegister(analysis)
 	}
 
 	return Recorder{
@@ -90,9 +106,46 @@ func NewRecorder(controller string, register bool) Recorder {
 		status:   status,
 		weight:   weight,
 		analysis: analysis,
+		extended: extendedLabels,
 	}
 }
 
+// mustRegister registers the collector or returns the already registered one,
+// allowing multiple controllers to share the same metrics
+func mustRegister[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector.(T)
+		}
+		panic(err)
+	}
+	return c
+}
+
+// WithCluster returns a recorder that labels the canary metrics with the cluster name when the
+// extended labels are enabled, so that the results of the controllers running against different clusters can be told apart
+func (cr Recorder) WithCluster(cluster string) Recorder {
+	cr.cluster = cluster
+	return cr
+}
+
+// labelValues appends the cluster name to the label values when the extended labels are enabled
+func (cr *Recorder) labelValues(values ...string) []string {
+	if cr.extended {
+		return append(values, cr.cluster)
+	}
+	return values
+}
+
+// labels adds the cluster name to the labels when the extended labels are enabled
+func (cr *Recorder) labels(labels prometheus.Labels) prometheus.Labels {
+	if cr.extended {
+		labels["cluster"] = cr.cluster
+	}
+	return labels
+}
+
 // SetInfo sets the version and mesh provider labels
 func (cr *Recorder) SetInfo(version string, meshProvider string) {
 	cr.info.WithLabelValues(version, meshProvider).Set(1)
@@ -100,18 +153,32 @@ func (cr *Recorder) SetInfo(version string, meshProvider string) {
 
 // SetDuration sets the time spent in seconds performing canary analysis
 func (cr *Recorder) SetDuration(cd *flaggerv1.Canary, duration time.Duration) {
-	cr.duration.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Observe(duration.Seconds())
+	cr.duration.WithLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...).Observe(duration.Seconds())
 }
 
 // SetTotal sets the total number of canaries per namespace
 func (cr *Recorder) SetTotal(namespace string, total int) {
-	cr.total.WithLabelValues(namespace).Set(float64(total))
+	cr.total.WithLabelValues(cr.labelValues(namespace)...).Set(float64(total))
 }
 
-func (cr *Recorder) SetAnalysis(cd *flaggerv1.Canary, metricTemplateName string, val float64) {
+// SetAnalysis sets the last metric value, per canary and evaluation window when the extended labels are enabled
+func (cr *Recorder) SetAnalysis(cd *flaggerv1.Canary, metricTemplateName string, interval string, val float64) {
+	if cr.extended {
+		cr.analysis.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, metricTemplateName, cd.Name, interval, cr.cluster).Set(val)
+		return
+	}
 	cr.analysis.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace, metricTemplateName).Set(val)
 }
 
+// DeleteCanary removes all the series of a deleted canary
+func (cr *Recorder) DeleteCanary(cd *flaggerv1.Canary) {
+	cr.analysis.DeletePartialMatch(cr.labels(prometheus.Labels{"name": cd.Spec.TargetRef.Name, "namespace": cd.Namespace}))
+	cr.duration.DeleteLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...)
+	cr.status.DeleteLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...)
+	cr.weight.DeleteLabelValues(cr.labelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace)...)
+	cr.weight.DeleteLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...)
+}
+
 // SetStatus sets the last known canary analysis status
 func (cr *Recorder) SetStatus(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) {
 	var status int
@@ -123,11 +190,11 @@ func (cr *Recorder) SetStatus(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase)
 	default:
 		status = 1
 	}
-	cr.status.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(status))
+	cr.status.WithLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...).Set(float64(status))
 }
 
 // SetWeight sets the weight values for primary and canary destinations
 func (cr *Recorder) SetWeight(cd *flaggerv1.Canary, primary int, canary int) {
-	cr.weight.WithLabelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace).Set(float64(primary))
-	cr.weight.WithLabelValues(cd.Spec.TargetRef.Name, cd.Namespace).Set(float64(canary))
+	cr.weight.WithLabelValues(cr.labelValues(fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name), cd.Namespace)...).Set(float64(primary))
+	cr.weight.WithLabelValues(cr.labelValues(cd.Spec.TargetRef.Name, cd.Namespace)...).Set(float64(canary))
 }
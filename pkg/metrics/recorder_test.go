@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestRecorder_Labels(t *testing.T) {
+	podinfo := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec:       flaggerv1.CanarySpec{TargetRef: flaggerv1.LocalObjectReference{Name: "podinfo"}},
+	}
+
+	recorder := NewRecorder("test", false, false).WithCluster("prod-eu")
+	recorder.SetAnalysis(podinfo, "request-success-rate", "1m", 99)
+	recorder.SetStatus(podinfo, flaggerv1.CanaryPhaseSucceeded)
+	assert.Equal(t, float64(99), testutil.ToFloat64(recorder.analysis.WithLabelValues("podinfo", "default", "request-success-rate")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.status.WithLabelValues("podinfo", "default")))
+
+	extended := NewRecorder("test", false, true).WithCluster("prod-eu")
+	extended.SetAnalysis(podinfo, "request-success-rate", "1m", 98)
+	extended.SetStatus(podinfo, flaggerv1.CanaryPhaseFailed)
+	assert.Equal(t, float64(98), testutil.ToFloat64(extended.analysis.WithLabelValues("podinfo", "default", "request-success-rate", "podinfo", "1m", "prod-eu")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(extended.status.WithLabelValues("podinfo", "default", "prod-eu")))
+}
+
+func TestRecorder_DeleteCanary(t *testing.T) {
+	for _, extendedLabels := range []bool{false, true} {
+		recorder := NewRecorder("test", false, extendedLabels)
+		podinfo := &flaggerv1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+			Spec:       flaggerv1.CanarySpec{TargetRef: flaggerv1.LocalObjectReference{Name: "podinfo"}},
+		}
+		worker := &flaggerv1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+			Spec:       flaggerv1.CanarySpec{TargetRef: flaggerv1.LocalObjectReference{Name: "worker"}},
+		}
+
+		recorder.SetAnalysis(podinfo, "request-success-rate", "1m", 99)
+		recorder.SetAnalysis(podinfo, "request-duration", "1m", 0.5)
+		recorder.SetAnalysis(worker, "request-success-rate", "1m", 98)
+		recorder.SetStatus(worker, flaggerv1.CanaryPhaseSucceeded)
+		recorder.SetWeight(worker, 100, 0)
+		assert.Equal(t, 3, testutil.CollectAndCount(recorder.analysis))
+
+		// only the series of the deleted canary are removed
+		recorder.DeleteCanary(worker)
+		assert.Equal(t, 2, testutil.CollectAndCount(recorder.analysis))
+		assert.Equal(t, 0, testutil.CollectAndCount(recorder.status))
+		assert.Equal(t, 0, testutil.CollectAndCount(recorder.weight))
+	}
+}
@@ -30,6 +30,22 @@ import (
 )
 
 func postMessage(address, token, proxy string, payload interface{}) error {
+	authorization := ""
+	if token != "" {
+		authorization = fmt.Sprintf("Bearer %s", token)
+	}
+	return postMessageWithAuthorization(address, authorization, proxy, payload)
+}
+
+func postMessageWithAuthorization(address, authorization, proxy string, payload interface{}) error {
+	headers := make(map[string]string)
+	if authorization != "" {
+		headers["Authorization"] = authorization
+	}
+	return postMessageWithHeaders(address, headers, proxy, payload)
+}
+
+func postMessageWithHeaders(address string, headers map[string]string, proxy string, payload interface{}) error {
 	var httpClient = &http.Client{}
 
 	if proxy != "" {
@@ -63,9 +79,8 @@ func postMessage(address, token, proxy string, payload interface{}) error {
 		return fmt.Errorf("http.NewRequest failed: %w", err)
 	}
 	req.Header.Set("Content-type", "application/json")
-
-	if token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	for k, v := range headers {
+		req.Header.Set(k, v)
 	}
 
 	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
@@ -78,7 +93,7 @@ func postMessage(address, token, proxy string, payload interface{}) error {
 
 	defer res.Body.Close()
 	statusCode := res.StatusCode
-	if statusCode != 200 {
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(res.Body)
 		return fmt.Errorf("sending notification failed: %s", string(body))
 	}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Datadog holds the Events API address and the tags added to the events
+type Datadog struct {
+	URL      string
+	APIKey   string
+	Env      string
+	Tags     []string
+	ProxyURL string
+}
+
+// DatadogEvent holds the event fields
+// https://docs.datadoghq.com/api/latest/events/#post-an-event
+type DatadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+	Tags           []string `json:"tags"`
+}
+
+const (
+	datadogMaxTitle = 100
+	datadogMaxText  = 4000
+)
+
+// NewDatadog validates the Datadog URL and returns a Datadog object,
+// the address format is https://api.datadoghq.com/?env=<env>&tags=<key:value,key:value>
+func NewDatadog(address string, apiKey string, proxyURL string) (*Datadog, error) {
+	ddURL, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datadog URL %s", address)
+	}
+
+	if apiKey == "" {
+		return nil, errors.New("empty Datadog API key")
+	}
+
+	query := ddURL.Query()
+	var tags []string
+	if t := query.Get("tags"); t != "" {
+		tags = strings.Split(t, ",")
+	}
+
+	ddURL.RawQuery = ""
+	if ddURL.Path == "" || ddURL.Path == "/" {
+		ddURL.Path = "/api/v1/events"
+	}
+
+	return &Datadog{
+		URL:      ddURL.String(),
+		APIKey:   apiKey,
+		Env:      query.Get("env"),
+		Tags:     tags,
+		ProxyURL: proxyURL,
+	}, nil
+}
+
+// Post sends the alert to the Datadog Events API tagged with the unified service tags,
+// the canary target name is used as service and the image tag as version
+func (s *Datadog) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	tags := []string{
+		"source:flagger",
+		fmt.Sprintf("service:%s", workload),
+		fmt.Sprintf("kube_namespace:%s", namespace),
+	}
+	if s.Env != "" {
+		tags = append(tags, fmt.Sprintf("env:%s", s.Env))
+	}
+
+	var text strings.Builder
+	text.WriteString(message)
+	text.WriteString("\n")
+	for _, f := range fields {
+		text.WriteString(fmt.Sprintf("\n%s: %s", f.Name, f.Value))
+		switch f.Name {
+		case "Images":
+			if version := imageTag(strings.Split(f.Value, ", ")[0]); version != "" {
+				tags = append(tags, fmt.Sprintf("version:%s", version))
+			}
+		case "Cluster":
+			tags = append(tags, fmt.Sprintf("kube_cluster_name:%s", f.Value))
+		}
+	}
+	tags = append(tags, s.Tags...)
+
+	event := DatadogEvent{
+		Title:          truncate(fmt.Sprintf("%s.%s: %s", workload, namespace, message), datadogMaxTitle),
+		Text:           truncate(text.String(), datadogMaxText),
+		AlertType:      datadogAlertType(fields, severity),
+		AggregationKey: fmt.Sprintf("flagger-%s.%s", workload, namespace),
+		SourceTypeName: "flagger",
+		Tags:           tags,
+	}
+
+	err := postMessageWithHeaders(s.URL, map[string]string{"DD-API-KEY": s.APIKey}, s.ProxyURL, event)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
+
+// datadogAlertType maps the alert severity to the Datadog event alert type
+func datadogAlertType(fields []Field, severity string) string {
+	switch {
+	case severity == "error":
+		return "error"
+	case severity == "warn":
+		return "warning"
+	case isPromotion(fields):
+		return "success"
+	default:
+		return "info"
+	}
+}
+
+// imageTag returns the tag of the image reference or an empty string if the image is referenced by digest only
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatadog_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Cluster", Value: "prod-eu"},
+		{Name: "Images", Value: "ghcr.io/stefanprodan/podinfo:6.0.1, busybox:1.36"},
+		{Name: PhaseField, Value: "Succeeded"},
+	}
+
+	var event DatadogEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/events", r.URL.Path)
+		require.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &event))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	datadog, err := NewDatadog(ts.URL+"?env=production&tags=team:sre", "api-key", "")
+	require.NoError(t, err)
+
+	err = datadog.Post("podinfo", "test", "Canary analysis completed successfully, promotion finished.", fields, "info")
+	require.NoError(t, err)
+
+	assert.Equal(t, "podinfo.test: Canary analysis completed successfully, promotion finished.", event.Title)
+	assert.Equal(t, "success", event.AlertType)
+	assert.Equal(t, "flagger-podinfo.test", event.AggregationKey)
+	assert.Contains(t, event.Text, "Images: ghcr.io/stefanprodan/podinfo:6.0.1")
+	assert.Equal(t, []string{
+		"source:flagger",
+		"service:podinfo",
+		"kube_namespace:test",
+		"env:production",
+		"kube_cluster_name:prod-eu",
+		"version:6.0.1",
+		"team:sre",
+	}, event.Tags)
+
+	err = datadog.Post("podinfo", "test", "Failed checks threshold reached 5", nil, "error")
+	require.NoError(t, err)
+	assert.Equal(t, "error", event.AlertType)
+
+	_, err = NewDatadog(ts.URL, "", "")
+	require.Error(t, err)
+}
+
+func TestImageTag(t *testing.T) {
+	assert.Equal(t, "6.0.1", imageTag("ghcr.io/stefanprodan/podinfo:6.0.1"))
+	assert.Equal(t, "6.0.1", imageTag("localhost:5000/podinfo:6.0.1@sha256:abc"))
+	assert.Equal(t, "", imageTag("localhost:5000/podinfo"))
+	assert.Equal(t, "", imageTag("podinfo@sha256:abc"))
+}
@@ -56,6 +56,20 @@ func (f Factory) Notifier(provider string) (Interface, error) {
 		n, err = NewMSTeams(f.URL, f.ProxyURL)
 	case "gchat":
 		n, err = NewGChat(f.URL, f.ProxyURL)
+	case "flux":
+		n, err = NewFlux(f.URL, f.ProxyURL)
+	case "github":
+		n, err = NewGitHub(f.URL, f.Token, f.ProxyURL)
+	case "gitlab":
+		n, err = NewGitLab(f.URL, f.Token, f.ProxyURL)
+	case "jira":
+		n, err = NewJira(f.URL, f.Token, f.ProxyURL)
+	case "sns":
+		n, err = NewSNS(f.URL, f.ProxyURL)
+	case "sqs":
+		n, err = NewSQS(f.URL, f.ProxyURL)
+	case "datadog":
+		n, err = NewDatadog(f.URL, f.Token, f.ProxyURL)
 	default:
 		err = fmt.Errorf("provider %s not supported", provider)
 	}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Flux holds the notification-controller events address
+type Flux struct {
+	URL      string
+	ProxyURL string
+}
+
+// FluxEvent is the event format accepted by the Flux notification-controller
+// https://fluxcd.io/flux/components/notification/events/
+type FluxEvent struct {
+	InvolvedObject      corev1.ObjectReference `json:"involvedObject"`
+	Severity            string                 `json:"severity"`
+	Timestamp           string                 `json:"timestamp"`
+	Message             string                 `json:"message"`
+	Reason              string                 `json:"reason"`
+	Metadata            map[string]string      `json:"metadata,omitempty"`
+	ReportingController string                 `json:"reportingController"`
+	ReportingInstance   string                 `json:"reportingInstance,omitempty"`
+}
+
+// NewFlux validates the notification-controller URL and returns a Flux object
+func NewFlux(address string, proxyURL string) (*Flux, error) {
+	_, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Flux notification-controller URL %s", address)
+	}
+
+	return &Flux{
+		URL:      address,
+		ProxyURL: proxyURL,
+	}, nil
+}
+
+// Post Flux event
+func (s *Flux) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	// the notification-controller only knows about info and error events
+	fluxSeverity := "info"
+	if severity == "error" {
+		fluxSeverity = "error"
+	}
+
+	metadata := make(map[string]string, len(fields))
+	for _, f := range fields {
+		metadata[f.Name] = f.Value
+	}
+
+	payload := FluxEvent{
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "flagger.app/v1beta1",
+			Kind:       "Canary",
+			Name:       workload,
+			Namespace:  namespace,
+		},
+		Severity:            fluxSeverity,
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Message:             message,
+		Reason:              fluxReason(fields),
+		Metadata:            metadata,
+		ReportingController: "flagger",
+	}
+
+	err := postMessage(s.URL, "", s.ProxyURL, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
+
+// fluxReason maps the alert to the event reason, the promotion and rollback
+// alerts are reported with the phase the canary transitioned to
+func fluxReason(fields []Field) string {
+	switch {
+	case isRollback(fields):
+		return "Failed"
+	case isPromotion(fields):
+		return "Succeeded"
+	default:
+		return "Progressing"
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlux_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "name1", Value: "value1"},
+		{Name: "name2", Value: "value2"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = FluxEvent{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+		require.Equal(t, "Canary", payload.InvolvedObject.Kind)
+		require.Equal(t, "podinfo", payload.InvolvedObject.Name)
+		require.Equal(t, "test", payload.InvolvedObject.Namespace)
+		require.Equal(t, "error", payload.Severity)
+		require.Equal(t, "flagger", payload.ReportingController)
+		require.Equal(t, "value1", payload.Metadata["name1"])
+		require.Equal(t, "Progressing", payload.Reason)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	flux, err := NewFlux(ts.URL, "")
+	require.NoError(t, err)
+
+	err = flux.Post("podinfo", "test", "test", fields, "error")
+	require.NoError(t, err)
+}
+
+func TestFlux_Reason(t *testing.T) {
+	require.Equal(t, "Succeeded", fluxReason([]Field{{Name: PhaseField, Value: "Succeeded"}}))
+	require.Equal(t, "Failed", fluxReason([]Field{{Name: PhaseField, Value: "Failed"}}))
+	require.Equal(t, "Progressing", fluxReason(nil))
+}
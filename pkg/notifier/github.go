@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CommitField is the name of the alert field holding the Git commit SHA
+const CommitField = "Commit"
+
+// GitHub holds the repository API address and the access token
+type GitHub struct {
+	URL      string
+	Token    string
+	ProxyURL string
+}
+
+// GitHubStatusPayload holds the commit status
+// https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+type GitHubStatusPayload struct {
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+// NewGitHub validates the repository URL and returns a GitHub object,
+// the address format is https://api.github.com/repos/<owner>/<repository>
+func NewGitHub(address string, token string, proxyURL string) (*GitHub, error) {
+	repo, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub repository URL %s", address)
+	}
+
+	if !strings.Contains(repo.Path, "/repos/") {
+		return nil, fmt.Errorf("invalid GitHub repository URL %s, expected format https://api.github.com/repos/<owner>/<repository>", address)
+	}
+
+	if token == "" {
+		return nil, errors.New("empty GitHub token")
+	}
+
+	return &GitHub{
+		URL:      strings.TrimSuffix(address, "/"),
+		Token:    token,
+		ProxyURL: proxyURL,
+	}, nil
+}
+
+// Post GitHub commit status, alerts without a commit field are ignored
+func (s *GitHub) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	var sha string
+	for _, f := range fields {
+		if f.Name == CommitField {
+			sha = f.Value
+		}
+	}
+	if sha == "" {
+		return nil
+	}
+
+	// GitHub limits the description to 140 characters
+	description := message
+	if len(description) > 140 {
+		description = description[:137] + "..."
+	}
+
+	payload := GitHubStatusPayload{
+		State:       gitHubState(fields),
+		Description: description,
+		Context:     fmt.Sprintf("flagger/%s.%s", workload, namespace),
+	}
+
+	err := postMessage(fmt.Sprintf("%s/statuses/%s", s.URL, sha), s.Token, s.ProxyURL, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
+
+// gitHubState maps the alert to a commit status state
+func gitHubState(fields []Field) string {
+	switch {
+	case isRollback(fields):
+		return "failure"
+	case isPromotion(fields):
+		return "success"
+	default:
+		return "pending"
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHub_Post(t *testing.T) {
+	fields := []Field{
+		{Name: CommitField, Value: "2b7c0b4"},
+		{Name: PhaseField, Value: "Succeeded"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/org/podinfo/statuses/2b7c0b4", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = GitHubStatusPayload{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+		require.Equal(t, "success", payload.State)
+		require.Equal(t, "flagger/podinfo.test", payload.Context)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	github, err := NewGitHub(ts.URL+"/repos/org/podinfo", "token", "")
+	require.NoError(t, err)
+
+	err = github.Post("podinfo", "test", "Canary analysis completed successfully, promotion finished.", fields, "info")
+	require.NoError(t, err)
+}
+
+func TestGitHub_State(t *testing.T) {
+	assert.Equal(t, "pending", gitHubState(nil))
+	assert.Equal(t, "pending", gitHubState([]Field{{Name: PhaseField, Value: "Progressing"}}))
+	assert.Equal(t, "success", gitHubState([]Field{{Name: PhaseField, Value: "Succeeded"}}))
+	assert.Equal(t, "failure", gitHubState([]Field{{Name: PhaseField, Value: "Failed"}}))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitLab holds the pipeline trigger URL and token
+type GitLab struct {
+	URL      string
+	Ref      string
+	Token    string
+	ProxyURL string
+}
+
+// GitLabTriggerPayload holds the pipeline trigger parameters
+// https://docs.gitlab.com/ee/ci/triggers/
+type GitLabTriggerPayload struct {
+	Token     string            `json:"token"`
+	Ref       string            `json:"ref"`
+	Variables map[string]string `json:"variables"`
+}
+
+// NewGitLab validates the trigger URL and returns a GitLab object,
+// the address format is https://gitlab.com/api/v4/projects/<id>/trigger/pipeline?ref=<branch>
+func NewGitLab(address string, token string, proxyURL string) (*GitLab, error) {
+	trigger, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab trigger URL %s", address)
+	}
+
+	if token == "" {
+		return nil, errors.New("empty GitLab trigger token")
+	}
+
+	ref := trigger.Query().Get("ref")
+	if ref == "" {
+		ref = "main"
+	}
+	trigger.RawQuery = ""
+
+	return &GitLab{
+		URL:      trigger.String(),
+		Ref:      ref,
+		Token:    token,
+		ProxyURL: proxyURL,
+	}, nil
+}
+
+// Post triggers a GitLab pipeline when the canary has been rolled back,
+// the other alerts are ignored
+func (s *GitLab) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	if !isRollback(fields) {
+		return nil
+	}
+
+	variables := map[string]string{
+		"FLAGGER_CANARY_NAME":      workload,
+		"FLAGGER_CANARY_NAMESPACE": namespace,
+		"FLAGGER_MESSAGE":          message,
+	}
+	for _, f := range fields {
+		variables[gitLabVariable(f.Name)] = f.Value
+	}
+
+	payload := GitLabTriggerPayload{
+		Token:     s.Token,
+		Ref:       s.Ref,
+		Variables: variables,
+	}
+
+	// the trigger token is sent in the payload, GitLab rejects it as a bearer token
+	err := postMessage(s.URL, "", s.ProxyURL, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
+
+// gitLabVariable formats an alert field name as a CI variable name
+func gitLabVariable(name string) string {
+	return "FLAGGER_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitLab_Post(t *testing.T) {
+	fields := []Field{
+		{Name: CommitField, Value: "2b7c0b4"},
+	}
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/api/v4/projects/42/trigger/pipeline", r.URL.Path)
+		require.Empty(t, r.Header.Get("Authorization"))
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = GitLabTriggerPayload{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+		require.Equal(t, "token", payload.Token)
+		require.Equal(t, "release", payload.Ref)
+		require.Equal(t, "podinfo", payload.Variables["FLAGGER_CANARY_NAME"])
+		require.Equal(t, "2b7c0b4", payload.Variables["FLAGGER_COMMIT"])
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	gitlab, err := NewGitLab(ts.URL+"/api/v4/projects/42/trigger/pipeline?ref=release", "token", "")
+	require.NoError(t, err)
+
+	err = gitlab.Post("podinfo", "test", "New revision detected, progressing canary analysis.", fields, "info")
+	require.NoError(t, err)
+	require.Equal(t, 0, calls)
+
+	err = gitlab.Post("podinfo", "test", "Failed checks threshold reached 5",
+		append(fields, Field{Name: PhaseField, Value: "Failed"}), "error")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Jira holds the issue tracker address and the issue settings
+type Jira struct {
+	URL       string
+	Project   string
+	IssueType string
+	Labels    []string
+	Token     string
+	ProxyURL  string
+}
+
+// JiraPayload holds the issue fields
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-post
+type JiraPayload struct {
+	Fields JiraFields `json:"fields"`
+}
+
+type JiraFields struct {
+	Project     JiraKey  `json:"project"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	IssueType   JiraName `json:"issuetype"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+type JiraKey struct {
+	Key string `json:"key"`
+}
+
+type JiraName struct {
+	Name string `json:"name"`
+}
+
+// NewJira validates the Jira URL and returns a Jira object,
+// the address format is https://<domain>/?project=<key>&issuetype=<name>&labels=<label1,label2>
+func NewJira(address string, token string, proxyURL string) (*Jira, error) {
+	jiraURL, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jira URL %s", address)
+	}
+
+	query := jiraURL.Query()
+	project := query.Get("project")
+	if project == "" {
+		return nil, fmt.Errorf("invalid Jira URL %s, the project query parameter is required", address)
+	}
+
+	issueType := query.Get("issuetype")
+	if issueType == "" {
+		issueType = "Bug"
+	}
+
+	var labels []string
+	if l := query.Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+
+	if token == "" {
+		return nil, errors.New("empty Jira token")
+	}
+
+	jiraURL.RawQuery = ""
+	jiraURL.Path = strings.TrimSuffix(jiraURL.Path, "/") + "/rest/api/2/issue"
+
+	return &Jira{
+		URL:       jiraURL.String(),
+		Project:   project,
+		IssueType: issueType,
+		Labels:    labels,
+		Token:     token,
+		ProxyURL:  proxyURL,
+	}, nil
+}
+
+// Post creates a Jira issue when the canary has failed,
+// the other alerts are ignored
+func (s *Jira) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	if !isRollback(fields) {
+		return nil
+	}
+
+	payload := JiraPayload{
+		Fields: JiraFields{
+			Project:     JiraKey{Key: s.Project},
+			Summary:     fmt.Sprintf("Canary %s.%s failed", workload, namespace),
+			Description: jiraReport(workload, namespace, message, fields),
+			IssueType:   JiraName{Name: s.IssueType},
+			Labels:      s.Labels,
+		},
+	}
+
+	err := postMessageWithAuthorization(s.URL, s.authorization(), s.ProxyURL, payload)
+	if err != nil {
+		return fmt.Errorf("postMessage failed: %w", err)
+	}
+
+	return nil
+}
+
+// jiraReport formats the rollout report of the failed canary with the Jira wiki markup
+func jiraReport(workload string, namespace string, message string, fields []Field) string {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ")
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("Canary *%s.%s* has been rolled back: %s\n\n", workload, namespace, message))
+	report.WriteString("h3. Rollout report\n")
+	report.WriteString("||Field||Value||\n")
+	for _, f := range fields {
+		if f.Name == PhaseField {
+			continue
+		}
+		report.WriteString(fmt.Sprintf("|%s|%s|\n", escape.Replace(f.Name), escape.Replace(f.Value)))
+	}
+	return report.String()
+}
+
+// authorization uses basic auth for Jira Cloud <email>:<api-token> credentials
+// and bearer auth for Jira Server personal access tokens
+func (s *Jira) authorization() string {
+	if strings.Contains(s.Token, ":") {
+		return fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(s.Token)))
+	}
+	return fmt.Sprintf("Bearer %s", s.Token)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJira_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Target", Value: "Deployment/podinfo.test"},
+		{Name: "Metrics", Value: "request-success-rate: 92.00, request-duration: 510.00"},
+	}
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/rest/api/2/issue", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "ops@example.com", user)
+		require.Equal(t, "token", pass)
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload = JiraPayload{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+		require.Equal(t, "OPS", payload.Fields.Project.Key)
+		require.Equal(t, "Incident", payload.Fields.IssueType.Name)
+		require.Equal(t, []string{"canary", "flagger"}, payload.Fields.Labels)
+		require.Equal(t, "Canary podinfo.test failed", payload.Fields.Summary)
+		require.Equal(t, "Canary *podinfo.test* has been rolled back: Failed checks threshold reached 5\n\n"+
+			"h3. Rollout report\n"+
+			"||Field||Value||\n"+
+			"|Target|Deployment/podinfo.test|\n"+
+			"|Metrics|request-success-rate: 92.00, request-duration: 510.00|\n", payload.Fields.Description)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	jira, err := NewJira(ts.URL+"?project=OPS&issuetype=Incident&labels=canary,flagger", "ops@example.com:token", "")
+	require.NoError(t, err)
+
+	err = jira.Post("podinfo", "test", "Canary is waiting for approval.", fields, "warn")
+	require.NoError(t, err)
+	require.Equal(t, 0, calls)
+
+	err = jira.Post("podinfo", "test", "Failed checks threshold reached 5",
+		append(fields, Field{Name: PhaseField, Value: "Failed"}), "error")
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+}
@@ -24,3 +24,51 @@ type Field struct {
 	Name  string
 	Value string
 }
+
+// GateField is the name of the alert field holding the type of the load tester gate
+// the canary is waiting on, notifiers supporting interactive messages use it to add approval actions
+const GateField = "Gate"
+
+// PhaseField is the name of the alert field holding the phase the canary transitions to,
+// it is set on the promotion and rollback alerts only
+const PhaseField = "Phase"
+
+// isRollback returns true if the alert was sent because the canary has been rolled back
+func isRollback(fields []Field) bool {
+	return fieldValue(fields, PhaseField) == "Failed"
+}
+
+// isPromotion returns true if the alert was sent because the canary has been promoted
+func isPromotion(fields []Field) bool {
+	return fieldValue(fields, PhaseField) == "Succeeded"
+}
+
+// TracksRollouts returns true if the provider reports the rollout state of the Git commit,
+// only these providers receive the commit and phase fields
+func TracksRollouts(provider string) bool {
+	switch provider {
+	case "github", "gitlab", "jira", "datadog", "flux":
+		return true
+	}
+	return false
+}
+
+// WithoutRolloutFields returns the fields without the commit and phase fields
+func WithoutRolloutFields(fields []Field) []Field {
+	var out []Field
+	for _, f := range fields {
+		if f.Name != CommitField && f.Name != PhaseField {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func fieldValue(fields []Field, name string) string {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithoutRolloutFields(t *testing.T) {
+	fields := []Field{
+		{Name: "Target", Value: "Deployment/podinfo.test"},
+		{Name: CommitField, Value: "2b7c0b4"},
+		{Name: PhaseField, Value: "Succeeded"},
+	}
+
+	assert.True(t, TracksRollouts("github"))
+	assert.False(t, TracksRollouts("slack"))
+	assert.Equal(t, []Field{{Name: "Target", Value: "Deployment/podinfo.test"}}, WithoutRolloutFields(fields))
+}
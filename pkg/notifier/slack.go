@@ -22,6 +22,9 @@ import (
 	"net/url"
 )
 
+// SlackGateCallbackID identifies the interactive messages used to approve or reject a gated canary
+const SlackGateCallbackID = "flagger-gate"
+
 // Slack holds the hook URL
 type Slack struct {
 	URL      string
@@ -43,11 +46,31 @@ type SlackPayload struct {
 
 // SlackAttachment holds the markdown message body
 type SlackAttachment struct {
-	Color      string       `json:"color"`
-	AuthorName string       `json:"author_name"`
-	Text       string       `json:"text"`
-	MrkdwnIn   []string     `json:"mrkdwn_in"`
-	Fields     []SlackField `json:"fields"`
+	Color      string        `json:"color"`
+	AuthorName string        `json:"author_name"`
+	Text       string        `json:"text"`
+	MrkdwnIn   []string      `json:"mrkdwn_in"`
+	Fields     []SlackField  `json:"fields"`
+	CallbackID string        `json:"callback_id,omitempty"`
+	Actions    []SlackAction `json:"actions,omitempty"`
+}
+
+// SlackAction holds an interactive message button
+type SlackAction struct {
+	Name    string        `json:"name"`
+	Text    string        `json:"text"`
+	Type    string        `json:"type"`
+	Value   string        `json:"value"`
+	Style   string        `json:"style,omitempty"`
+	Confirm *SlackConfirm `json:"confirm,omitempty"`
+}
+
+// SlackConfirm holds the confirmation dialog of a button
+type SlackConfirm struct {
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+	OkText      string `json:"ok_text"`
+	DismissText string `json:"dismiss_text"`
 }
 
 type SlackField struct {
@@ -93,8 +116,12 @@ func (s *Slack) Post(workload string, namespace string, message string, fields [
 		color = "danger"
 	}
 
+	gated := false
 	sfields := make([]SlackField, 0, len(fields))
 	for _, f := range fields {
+		if f.Name == GateField {
+			gated = true
+		}
 		sfields = append(sfields, SlackField{f.Name, f.Value, false})
 	}
 
@@ -106,6 +133,34 @@ func (s *Slack) Post(workload string, namespace string, message string, fields [
 		Fields:     sfields,
 	}
 
+	// add the approve and reject buttons handled by the load tester gate API
+	if gated {
+		value := fmt.Sprintf("%s.%s", workload, namespace)
+		a.CallbackID = SlackGateCallbackID
+		a.Actions = []SlackAction{
+			{
+				Name:  "approve",
+				Text:  "Approve",
+				Type:  "button",
+				Value: value,
+				Style: "primary",
+			},
+			{
+				Name:  "reject",
+				Text:  "Reject",
+				Type:  "button",
+				Value: value,
+				Style: "danger",
+				Confirm: &SlackConfirm{
+					Title:       "Reject canary",
+					Text:        fmt.Sprintf("Roll back %s?", value),
+					OkText:      "Reject",
+					DismissText: "Cancel",
+				},
+			},
+		}
+	}
+
 	payload.Attachments = []SlackAttachment{a}
 
 	err := postMessage(s.URL, s.Token, s.ProxyURL, payload)
@@ -51,3 +51,30 @@ func TestSlack_Post(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestSlack_PostGate(t *testing.T) {
+	fields := []Field{
+		{Name: GateField, Value: "confirm-promotion"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload = SlackPayload{}
+		err = json.Unmarshal(b, &payload)
+		require.NoError(t, err)
+		require.Equal(t, SlackGateCallbackID, payload.Attachments[0].CallbackID)
+		require.Len(t, payload.Attachments[0].Actions, 2)
+		require.Equal(t, "approve", payload.Attachments[0].Actions[0].Name)
+		require.Equal(t, "podinfo.test", payload.Attachments[0].Actions[0].Value)
+		require.Equal(t, "reject", payload.Attachments[0].Actions[1].Name)
+	}))
+	defer ts.Close()
+
+	slack, err := NewSlack(ts.URL, "", "", "test", "test")
+	require.NoError(t, err)
+
+	err = slack.Post("podinfo", "test", "Canary promotion is waiting for approval.", fields, "warn")
+	require.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+)
+
+// SNS holds the topic ARN and the AWS client
+type SNS struct {
+	TopicARN string
+	client   snsClient
+}
+
+// for the testing purpose
+type snsClient interface {
+	Publish(input *sns.PublishInput) (*sns.PublishOutput, error)
+}
+
+// AWSEvent is the canary event published to SNS topics and SQS queues
+type AWSEvent struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Message   string            `json:"message"`
+	Severity  string            `json:"severity"`
+	Timestamp string            `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// NewSNS validates the topic ARN and returns a SNS object,
+// the AWS credentials are loaded from the environment e.g. IAM roles for service accounts
+func NewSNS(address string, proxyURL string) (*SNS, error) {
+	topic, err := arn.Parse(address)
+	if err != nil || topic.Service != "sns" {
+		return nil, fmt.Errorf("invalid SNS topic ARN %s", address)
+	}
+
+	sess, err := newAWSSession(topic.Region, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SNS{
+		TopicARN: address,
+		client:   sns.New(sess),
+	}, nil
+}
+
+// Post SNS message
+func (s *SNS) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	body, err := newAWSEvent(workload, namespace, message, fields, severity)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Publish(&sns.PublishInput{
+		TopicArn: aws.String(s.TopicARN),
+		Subject:  aws.String(fmt.Sprintf("Flagger canary %s.%s", workload, namespace)),
+		Message:  aws.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("publishing to SNS failed: %w", err)
+	}
+
+	return nil
+}
+
+// newAWSEvent returns the JSON encoded canary event
+func newAWSEvent(workload string, namespace string, message string, fields []Field, severity string) (string, error) {
+	metadata := make(map[string]string, len(fields))
+	for _, f := range fields {
+		metadata[f.Name] = f.Value
+	}
+
+	data, err := json.Marshal(AWSEvent{
+		Name:      workload,
+		Namespace: namespace,
+		Message:   message,
+		Severity:  severity,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshalling notification payload failed: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// newAWSSession creates a session for the given region using the default credentials chain
+func newAWSSession(region string, proxy string) (*session.Session, error) {
+	config := aws.NewConfig().WithRegion(region)
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse proxy URL '%s', error: %w", proxy, err)
+		}
+		config = config.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		})
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aws session: %w", err)
+	}
+
+	return sess, nil
+}
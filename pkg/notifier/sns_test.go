@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSNSClient struct {
+	input *sns.PublishInput
+}
+
+func (c *fakeSNSClient) Publish(input *sns.PublishInput) (*sns.PublishOutput, error) {
+	c.input = input
+	return &sns.PublishOutput{}, nil
+}
+
+func TestSNS_Post(t *testing.T) {
+	fields := []Field{
+		{Name: "Target", Value: "Deployment/podinfo.test"},
+	}
+
+	_, err := NewSNS("https://sns.us-east-1.amazonaws.com", "")
+	require.Error(t, err)
+
+	topic, err := NewSNS("arn:aws:sns:us-east-1:123456789012:flagger", "")
+	require.NoError(t, err)
+
+	client := &fakeSNSClient{}
+	topic.client = client
+
+	err = topic.Post("podinfo", "test", "Canary analysis completed successfully, promotion finished.", fields, "info")
+	require.NoError(t, err)
+	require.Equal(t, "arn:aws:sns:us-east-1:123456789012:flagger", aws.StringValue(client.input.TopicArn))
+
+	var event = AWSEvent{}
+	err = json.Unmarshal([]byte(aws.StringValue(client.input.Message)), &event)
+	require.NoError(t, err)
+	require.Equal(t, "podinfo", event.Name)
+	require.Equal(t, "test", event.Namespace)
+	require.Equal(t, "info", event.Severity)
+	require.Equal(t, "Deployment/podinfo.test", event.Metadata["Target"])
+}
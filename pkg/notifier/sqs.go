@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// SQS holds the queue URL and the AWS client
+type SQS struct {
+	QueueURL string
+	client   sqsClient
+}
+
+// for the testing purpose
+type sqsClient interface {
+	SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error)
+}
+
+// NewSQS validates the queue URL and returns a SQS object,
+// the address format is https://sqs.<region>.amazonaws.com/<account-id>/<queue>
+func NewSQS(address string, proxyURL string) (*SQS, error) {
+	queue, err := url.ParseRequestURI(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQS queue URL %s", address)
+	}
+
+	parts := strings.Split(queue.Hostname(), ".")
+	if len(parts) < 3 || parts[0] != "sqs" {
+		return nil, fmt.Errorf("invalid SQS queue URL %s, expected format https://sqs.<region>.amazonaws.com/<account-id>/<queue>", address)
+	}
+
+	sess, err := newAWSSession(parts[1], proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQS{
+		QueueURL: address,
+		client:   sqs.New(sess),
+	}, nil
+}
+
+// Post SQS message
+func (s *SQS) Post(workload string, namespace string, message string, fields []Field, severity string) error {
+	body, err := newAWSEvent(workload, namespace, message, fields, severity)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.QueueURL),
+		MessageBody: aws.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("sending to SQS failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSQSClient struct {
+	input *sqs.SendMessageInput
+}
+
+func (c *fakeSQSClient) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	c.input = input
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSQS_Post(t *testing.T) {
+	_, err := NewSQS("https://example.com/123456789012/flagger", "")
+	require.Error(t, err)
+
+	queue, err := NewSQS("https://sqs.eu-west-1.amazonaws.com/123456789012/flagger", "")
+	require.NoError(t, err)
+
+	client := &fakeSQSClient{}
+	queue.client = client
+
+	err = queue.Post("podinfo", "test", "Failed checks threshold reached 5", nil, "error")
+	require.NoError(t, err)
+	require.Equal(t, "https://sqs.eu-west-1.amazonaws.com/123456789012/flagger", aws.StringValue(client.input.QueueUrl))
+
+	var event = AWSEvent{}
+	err = json.Unmarshal([]byte(aws.StringValue(client.input.MessageBody)), &event)
+	require.NoError(t, err)
+	require.Equal(t, "podinfo", event.Name)
+	require.Equal(t, "error", event.Severity)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	emissaryv3 "github.com/fluxcd/flagger/pkg/apis/emissary/v3alpha1"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// EmissaryRouter is managing Emissary-ingress Mapping objects,
+// the apex mapping routes to the primary service and the canary mapping
+// with the same host and prefix routes to the canary service, the traffic
+// is split by the weights of the two mappings
+type EmissaryRouter struct {
+	emissaryClient clientset.Interface
+	logger         *zap.SugaredLogger
+	setOwnerRefs   bool
+}
+
+// Reconcile creates or updates the primary and canary mappings
+func (er *EmissaryRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	total := 100
+	if err := er.reconcileMapping(canary, apexName, primaryName, &total); err != nil {
+		return err
+	}
+
+	zero := 0
+	if err := er.reconcileMapping(canary, fmt.Sprintf("%s-canary", apexName), canaryName, &zero); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (er *EmissaryRouter) reconcileMapping(canary *flaggerv1.Canary, name string, target string, weight *int) error {
+	newSpec := er.makeSpec(canary, target, weight)
+
+	newMetadata := canary.Spec.Service.Apex
+	if newMetadata == nil {
+		newMetadata = &flaggerv1.CustomMetadata{}
+	}
+	if newMetadata.Labels == nil {
+		newMetadata.Labels = make(map[string]string)
+	}
+	if newMetadata.Annotations == nil {
+		newMetadata.Annotations = make(map[string]string)
+	}
+	newMetadata.Annotations = filterMetadata(newMetadata.Annotations)
+
+	mapping, err := er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		mapping = &emissaryv3.Mapping{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   canary.Namespace,
+				Labels:      newMetadata.Labels,
+				Annotations: newMetadata.Annotations,
+			},
+			Spec: newSpec,
+		}
+		if er.setOwnerRefs {
+			mapping.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
+
+		_, err = er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Create(context.TODO(), mapping, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("Mapping %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Mapping %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Mapping %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	// update Mapping but keep the original weight,
+	// the mappings created without a weight get the default one
+	specDiff := cmp.Diff(newSpec, mapping.Spec, cmpopts.IgnoreFields(emissaryv3.MappingSpec{}, "Weight"))
+	labelsDiff := cmp.Diff(newMetadata.Labels, mapping.Labels, cmpopts.EquateEmpty())
+	annotationsDiff := cmp.Diff(newMetadata.Annotations, mapping.Annotations, cmpopts.EquateEmpty())
+	if specDiff != "" || labelsDiff != "" || annotationsDiff != "" || mapping.Spec.Weight == nil {
+		clone := mapping.DeepCopy()
+		if mapping.Spec.Weight != nil {
+			newSpec.Weight = mapping.Spec.Weight
+		}
+		clone.Spec = newSpec
+		clone.ObjectMeta.Annotations = newMetadata.Annotations
+		clone.ObjectMeta.Labels = newMetadata.Labels
+
+		_, err = er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("Mapping %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Mapping %s.%s updated", name, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the weights of the primary and canary mappings
+func (er *EmissaryRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, _, _ := canary.GetServiceNames()
+
+	primaryWeight, err = er.getWeight(canary, apexName)
+	if err != nil {
+		return
+	}
+	canaryWeight, err = er.getWeight(canary, fmt.Sprintf("%s-canary", apexName))
+	return
+}
+
+// SetRoutes updates the weights of the primary and canary mappings
+func (er *EmissaryRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("Mapping %s.%s update failed: no valid weights", apexName, canary.Namespace)
+	}
+
+	if err := er.setWeight(canary, apexName, primaryWeight); err != nil {
+		return err
+	}
+	return er.setWeight(canary, fmt.Sprintf("%s-canary", apexName), canaryWeight)
+}
+
+func (er *EmissaryRouter) getWeight(canary *flaggerv1.Canary, name string) (int, error) {
+	mapping, err := er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("Mapping %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	if mapping.Spec.Weight == nil {
+		return 0, fmt.Errorf("Mapping %s.%s has no weight", name, canary.Namespace)
+	}
+	return *mapping.Spec.Weight, nil
+}
+
+func (er *EmissaryRouter) setWeight(canary *flaggerv1.Canary, name string, weight int) error {
+	mapping, err := er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Mapping %s.%s query error: %w", name, canary.Namespace, err)
+	}
+
+	clone := mapping.DeepCopy()
+	clone.Spec.Weight = &weight
+
+	_, err = er.emissaryClient.EmissaryV3alpha1().Mappings(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("Mapping %s.%s update error: %w", name, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (er *EmissaryRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// makeSpec builds the mapping spec targeting the service port,
+// the hostname defaults to the first host of the canary service
+func (er *EmissaryRouter) makeSpec(canary *flaggerv1.Canary, target string, weight *int) emissaryv3.MappingSpec {
+	hostname := "*"
+	if len(canary.Spec.Service.Hosts) > 0 {
+		hostname = canary.Spec.Service.Hosts[0]
+	}
+
+	spec := emissaryv3.MappingSpec{
+		Hostname: hostname,
+		Prefix:   "/",
+		Service:  fmt.Sprintf("%s.%s:%d", target, canary.Namespace, canary.Spec.Service.Port),
+		Weight:   weight,
+	}
+
+	if canary.Spec.Service.Rewrite != nil && canary.Spec.Service.Rewrite.Uri != "" {
+		rewrite := canary.Spec.Service.Rewrite.Uri
+		spec.Rewrite = &rewrite
+	}
+
+	if canary.Spec.Service.Timeout != "" {
+		if d, err := time.ParseDuration(canary.Spec.Service.Timeout); err == nil {
+			spec.TimeoutMs = int(d.Milliseconds())
+		}
+	}
+
+	return spec
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEmissaryRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &EmissaryRouter{
+		logger:         mocks.logger,
+		emissaryClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	primary, err := router.emissaryClient.EmissaryV3alpha1().Mappings("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary.default:9898", primary.Spec.Service)
+	assert.Equal(t, "/", primary.Spec.Prefix)
+	assert.Equal(t, 100, *primary.Spec.Weight)
+
+	canary, err := router.emissaryClient.EmissaryV3alpha1().Mappings("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-canary.default:9898", canary.Spec.Service)
+	assert.Equal(t, primary.Spec.Hostname, canary.Spec.Hostname)
+	assert.Equal(t, 0, *canary.Spec.Weight)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// test update
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cdClone := cd.DeepCopy()
+	cdClone.Spec.Service.Timeout = "30s"
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(cd)
+	require.NoError(t, err)
+
+	canary, err = router.emissaryClient.EmissaryV3alpha1().Mappings("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 30000, canary.Spec.TimeoutMs)
+	assert.Equal(t, 40, *canary.Spec.Weight)
+
+	primary, err = router.emissaryClient.EmissaryV3alpha1().Mappings("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 60, *primary.Spec.Weight)
+
+	// the primary mapping created without a weight gets the default one
+	primary.Spec.Weight = nil
+	_, err = router.emissaryClient.EmissaryV3alpha1().Mappings("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(cd)
+	require.NoError(t, err)
+
+	primary, err = router.emissaryClient.EmissaryV3alpha1().Mappings("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 100, *primary.Spec.Weight)
+}
+
+func TestEmissaryRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &EmissaryRouter{
+		logger:         mocks.logger,
+		emissaryClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.False(t, m)
+
+	err = router.SetRoutes(mocks.canary, 70, 30, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	err = router.SetRoutes(mocks.canary, 0, 0, false)
+	require.Error(t, err)
+}
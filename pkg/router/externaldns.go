@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	externaldnsv1 "github.com/fluxcd/flagger/pkg/apis/externaldns/v1alpha1"
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// ExternalDNSRouter is managing external-dns DNSEndpoint objects,
+// the traffic is split between the primary and canary targets with Route53 weighted records
+type ExternalDNSRouter struct {
+	dnsClient    clientset.Interface
+	logger       *zap.SugaredLogger
+	setOwnerRefs bool
+}
+
+const (
+	externalDNSTargetAnnotation = "external-dns.alpha.kubernetes.io/target"
+	externalDNSTTLAnnotation    = "external-dns.alpha.kubernetes.io/ttl"
+	externalDNSWeightProperty   = "aws/weight"
+	externalDNSDefaultTTL       = 60
+)
+
+// Reconcile creates or updates the DNSEndpoint
+func (er *ExternalDNSRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	newSpec, err := er.makeSpec(canary, 100, 0)
+	if err != nil {
+		return err
+	}
+
+	newMetadata := canary.Spec.Service.Apex
+	if newMetadata == nil {
+		newMetadata = &flaggerv1.CustomMetadata{}
+	}
+	if newMetadata.Labels == nil {
+		newMetadata.Labels = make(map[string]string)
+	}
+	if newMetadata.Annotations == nil {
+		newMetadata.Annotations = make(map[string]string)
+	}
+	newMetadata.Annotations = filterMetadata(newMetadata.Annotations)
+
+	endpoint, err := er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		endpoint = &externaldnsv1.DNSEndpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Labels:      newMetadata.Labels,
+				Annotations: newMetadata.Annotations,
+			},
+			Spec: newSpec,
+		}
+		if er.setOwnerRefs {
+			endpoint.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
+
+		_, err = er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Create(context.TODO(), endpoint, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s created", endpoint.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// update DNSEndpoint but keep the original record weights
+	specDiff := cmp.Diff(
+		newSpec,
+		endpoint.Spec,
+		cmpopts.IgnoreFields(externaldnsv1.Endpoint{}, "ProviderSpecific", "Labels"),
+	)
+	labelsDiff := cmp.Diff(newMetadata.Labels, endpoint.Labels, cmpopts.EquateEmpty())
+	annotationsDiff := cmp.Diff(newMetadata.Annotations, endpoint.Annotations, cmpopts.EquateEmpty())
+	if specDiff != "" || labelsDiff != "" || annotationsDiff != "" {
+		primaryWeight, canaryWeight := er.getWeights(canary, endpoint)
+		if primaryWeight == 0 && canaryWeight == 0 {
+			primaryWeight = 100
+		}
+		newSpec, err = er.makeSpec(canary, primaryWeight, canaryWeight)
+		if err != nil {
+			return err
+		}
+
+		clone := endpoint.DeepCopy()
+		clone.Spec = newSpec
+		clone.ObjectMeta.Annotations = newMetadata.Annotations
+		clone.ObjectMeta.Labels = newMetadata.Labels
+
+		_, err = er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("DNSEndpoint %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DNSEndpoint %s.%s updated", endpoint.GetName(), canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the record weights for primary and canary
+func (er *ExternalDNSRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	endpoint, err := er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("DNSEndpoint %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	var primaryFound, canaryFound bool
+	for _, ep := range endpoint.Spec.Endpoints {
+		switch ep.SetIdentifier {
+		case primaryName:
+			primaryFound = true
+		case canaryName:
+			canaryFound = true
+		}
+	}
+	if !primaryFound || !canaryFound {
+		err = fmt.Errorf("DNSEndpoint %s.%s weighted records not found", apexName, canary.Namespace)
+		return
+	}
+
+	primaryWeight, canaryWeight = er.getWeights(canary, endpoint)
+	return
+}
+
+// SetRoutes updates the record weights for primary and canary
+func (er *ExternalDNSRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	// Route53 splits the traffic evenly when all the records of a set have weight zero
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("DNSEndpoint %s.%s update failed: no valid weights", apexName, canary.Namespace)
+	}
+
+	endpoint, err := er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s query error: %w", apexName, canary.Namespace, err)
+	}
+
+	spec, err := er.makeSpec(canary, primaryWeight, canaryWeight)
+	if err != nil {
+		return err
+	}
+	clone := endpoint.DeepCopy()
+	clone.Spec = spec
+
+	_, err = er.dnsClient.ExternalDNSV1alpha1().DNSEndpoints(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("DNSEndpoint %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (er *ExternalDNSRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// getWeights reads the aws/weight property of the primary and canary records
+func (er *ExternalDNSRouter) getWeights(canary *flaggerv1.Canary, endpoint *externaldnsv1.DNSEndpoint) (int, int) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	var primaryWeight, canaryWeight int
+	for _, ep := range endpoint.Spec.Endpoints {
+		for _, p := range ep.ProviderSpecific {
+			if p.Name != externalDNSWeightProperty {
+				continue
+			}
+			w, err := strconv.Atoi(p.Value)
+			if err != nil {
+				continue
+			}
+			switch ep.SetIdentifier {
+			case primaryName:
+				primaryWeight = w
+			case canaryName:
+				canaryWeight = w
+			}
+		}
+	}
+	return primaryWeight, canaryWeight
+}
+
+// makeSpec builds a weighted record set for each canary host,
+// the records point to the targets set with the external-dns target annotation
+// on the primary and canary service metadata
+func (er *ExternalDNSRouter) makeSpec(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) (externaldnsv1.DNSEndpointSpec, error) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	if len(canary.Spec.Service.Hosts) == 0 {
+		return externaldnsv1.DNSEndpointSpec{},
+			fmt.Errorf("DNSEndpoint %s.%s spec.service.hosts is required", apexName, canary.Namespace)
+	}
+
+	primaryTargets := externalDNSTargets(canary.Spec.Service.Primary)
+	if len(primaryTargets) == 0 {
+		return externaldnsv1.DNSEndpointSpec{},
+			fmt.Errorf("DNSEndpoint %s.%s spec.service.primary.annotations %s is required",
+				apexName, canary.Namespace, externalDNSTargetAnnotation)
+	}
+	canaryTargets := externalDNSTargets(canary.Spec.Service.Canary)
+	if len(canaryTargets) == 0 {
+		return externaldnsv1.DNSEndpointSpec{},
+			fmt.Errorf("DNSEndpoint %s.%s spec.service.canary.annotations %s is required",
+				apexName, canary.Namespace, externalDNSTargetAnnotation)
+	}
+
+	ttl := int64(externalDNSDefaultTTL)
+	if canary.Spec.Service.Apex != nil {
+		if v, ok := canary.Spec.Service.Apex.Annotations[externalDNSTTLAnnotation]; ok {
+			t, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || t < 1 {
+				return externaldnsv1.DNSEndpointSpec{},
+					fmt.Errorf("DNSEndpoint %s.%s invalid %s annotation %q", apexName, canary.Namespace, externalDNSTTLAnnotation, v)
+			}
+			ttl = t
+		}
+	}
+
+	var endpoints []*externaldnsv1.Endpoint
+	for _, host := range canary.Spec.Service.Hosts {
+		endpoints = append(endpoints,
+			newWeightedEndpoint(host, primaryName, primaryTargets, ttl, primaryWeight),
+			newWeightedEndpoint(host, canaryName, canaryTargets, ttl, canaryWeight),
+		)
+	}
+
+	return externaldnsv1.DNSEndpointSpec{Endpoints: endpoints}, nil
+}
+
+func newWeightedEndpoint(host string, setIdentifier string, targets []string, ttl int64, weight int) *externaldnsv1.Endpoint {
+	return &externaldnsv1.Endpoint{
+		DNSName:       host,
+		Targets:       targets,
+		RecordType:    recordType(targets),
+		SetIdentifier: setIdentifier,
+		RecordTTL:     ttl,
+		ProviderSpecific: []externaldnsv1.ProviderSpecificProperty{
+			{
+				Name:  externalDNSWeightProperty,
+				Value: strconv.Itoa(weight),
+			},
+		},
+	}
+}
+
+// externalDNSTargets returns the comma separated targets of the external-dns target annotation
+func externalDNSTargets(metadata *flaggerv1.CustomMetadata) []string {
+	if metadata == nil {
+		return nil
+	}
+	var targets []string
+	for _, t := range strings.Split(metadata.Annotations[externalDNSTargetAnnotation], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// recordType returns A or AAAA for IP targets and CNAME for host names
+func recordType(targets []string) string {
+	ip := net.ParseIP(targets[0])
+	switch {
+	case ip == nil:
+		return "CNAME"
+	case ip.To4() == nil:
+		return "AAAA"
+	default:
+		return "A"
+	}
+}
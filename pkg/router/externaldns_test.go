@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestExternalDNSCanary(canary *flaggerv1.Canary) *flaggerv1.Canary {
+	cd := canary.DeepCopy()
+	cd.Spec.Service.Hosts = []string{"app.example.com"}
+	cd.Spec.Service.Primary = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{externalDNSTargetAnnotation: "lb-blue.elb.amazonaws.com"},
+	}
+	cd.Spec.Service.Canary = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{externalDNSTargetAnnotation: "10.0.0.1, 10.0.0.2"},
+	}
+	return cd
+}
+
+func TestExternalDNSRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ExternalDNSRouter{
+		logger:    mocks.logger,
+		dnsClient: mocks.meshClient,
+	}
+	canary := newTestExternalDNSCanary(mocks.canary)
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	endpoint, err := router.dnsClient.ExternalDNSV1alpha1().DNSEndpoints("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, endpoint.Spec.Endpoints, 2)
+
+	primary := endpoint.Spec.Endpoints[0]
+	assert.Equal(t, "app.example.com", primary.DNSName)
+	assert.Equal(t, "podinfo-primary", primary.SetIdentifier)
+	assert.Equal(t, "CNAME", primary.RecordType)
+	assert.Equal(t, []string{"lb-blue.elb.amazonaws.com"}, primary.Targets)
+	assert.Equal(t, int64(60), primary.RecordTTL)
+	assert.Equal(t, "100", primary.ProviderSpecific[0].Value)
+
+	cr := endpoint.Spec.Endpoints[1]
+	assert.Equal(t, "podinfo-canary", cr.SetIdentifier)
+	assert.Equal(t, "A", cr.RecordType)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, cr.Targets)
+	assert.Equal(t, "0", cr.ProviderSpecific[0].Value)
+
+	err = router.SetRoutes(canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// test update keeps the weights
+	canary.Spec.Service.Apex = &flaggerv1.CustomMetadata{
+		Annotations: map[string]string{externalDNSTTLAnnotation: "30"},
+	}
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	endpoint, err = router.dnsClient.ExternalDNSV1alpha1().DNSEndpoints("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), endpoint.Spec.Endpoints[0].RecordTTL)
+	assert.Equal(t, "60", endpoint.Spec.Endpoints[0].ProviderSpecific[0].Value)
+	assert.Equal(t, "40", endpoint.Spec.Endpoints[1].ProviderSpecific[0].Value)
+
+	// test missing targets
+	canary.Spec.Service.Canary = nil
+	err = router.Reconcile(canary)
+	require.Error(t, err)
+}
+
+func TestExternalDNSRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ExternalDNSRouter{
+		logger:    mocks.logger,
+		dnsClient: mocks.meshClient,
+	}
+	canary := newTestExternalDNSCanary(mocks.canary)
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.False(t, m)
+
+	err = router.SetRoutes(canary, 50, 50, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 50, p)
+	assert.Equal(t, 50, c)
+
+	err = router.SetRoutes(canary, 0, 0, false)
+	require.Error(t, err)
+}
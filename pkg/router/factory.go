@@ -18,8 +18,10 @@ package router
 
 import (
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 
@@ -31,11 +33,16 @@ type Factory struct {
 	kubeConfig               *restclient.Config
 	kubeClient               kubernetes.Interface
 	meshClient               clientset.Interface
+	istioClient              istioclientset.Interface
 	flaggerClient            clientset.Interface
 	ingressAnnotationsPrefix string
 	ingressClass             string
 	logger                   *zap.SugaredLogger
 	setOwnerRefs             bool
+	remoteIstioClients       []istioclientset.Interface
+	remoteIstioClientsMu     sync.RWMutex
+	meshEastWestGateway      string
+	meshNetwork              string
 }
 
 func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
@@ -44,25 +51,54 @@ func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 	ingressClass string,
 	logger *zap.SugaredLogger,
 	meshClient clientset.Interface,
-	setOwnerRefs bool) *Factory {
+	istioClient istioclientset.Interface,
+	setOwnerRefs bool,
+	remoteIstioClients []istioclientset.Interface) *Factory {
 	return &Factory{
 		kubeConfig:               kubeConfig,
 		meshClient:               meshClient,
+		istioClient:              istioClient,
 		kubeClient:               kubeClient,
 		flaggerClient:            flaggerClient,
 		ingressAnnotationsPrefix: ingressAnnotationsPrefix,
 		ingressClass:             ingressClass,
 		logger:                   logger,
 		setOwnerRefs:             setOwnerRefs,
+		remoteIstioClients:       remoteIstioClients,
 	}
 }
 
+// AddRemoteIstioClient registers the Istio client of a remote cluster that became reachable after startup
+func (factory *Factory) AddRemoteIstioClient(client istioclientset.Interface) {
+	factory.remoteIstioClientsMu.Lock()
+	defer factory.remoteIstioClientsMu.Unlock()
+	factory.remoteIstioClients = append(factory.remoteIstioClients, client)
+}
+
+// SetMeshEastWestGateway sets the address and network of the local east-west gateway,
+// the routers generate service entries for it in the other primary clusters of the mesh
+func (factory *Factory) SetMeshEastWestGateway(address string, network string) {
+	factory.meshEastWestGateway = address
+	factory.meshNetwork = network
+}
+
+func (factory *Factory) getRemoteIstioClients() []istioclientset.Interface {
+	factory.remoteIstioClientsMu.RLock()
+	defer factory.remoteIstioClientsMu.RUnlock()
+	return append([]istioclientset.Interface(nil), factory.remoteIstioClients...)
+}
+
+// MeshClient returns the client of the cluster running the mesh or ingress control plane
+func (factory *Factory) MeshClient() clientset.Interface {
+	return factory.meshClient
+}
+
 // KubernetesRouter returns a KubernetesRouter interface implementation
 func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labelValue string, ports map[string]int32) KubernetesRouter {
 	switch kind {
 	case "Service":
 		return &KubernetesNoopRouter{}
-	default: // Daemonset or Deployment
+	default: // Daemonset, StatefulSet or Deployment
 		return &KubernetesDefaultRouter{
 			logger:        factory.logger,
 			flaggerClient: factory.flaggerClient,
@@ -75,7 +111,7 @@ func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labe
 }
 
 // MeshRouter returns a service mesh router
-func (factory *Factory) MeshRouter(provider string, labelSelector string) Interface {
+func (factory *Factory) MeshRouter(provider string, labelSelector string, labelValue string) Interface {
 	switch {
 	case strings.HasPrefix(provider, flaggerv1.AppMeshProvider+":v1beta2"):
 		return &AppMeshv1beta2Router{
@@ -105,11 +141,16 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 		}
 	case provider == flaggerv1.IstioProvider:
 		return &IstioRouter{
-			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
-			kubeClient:    factory.kubeClient,
-			istioClient:   factory.meshClient,
-			setOwnerRefs:  factory.setOwnerRefs,
+			logger:             factory.logger,
+			flaggerClient:      factory.flaggerClient,
+			kubeClient:         factory.kubeClient,
+			istioClient:        factory.istioClient,
+			setOwnerRefs:       factory.setOwnerRefs,
+			remoteIstioClients: factory.getRemoteIstioClients(),
+			labelSelector:      labelSelector,
+			labelValue:         labelValue,
+			eastWestGateway:    factory.meshEastWestGateway,
+			meshNetwork:        factory.meshNetwork,
 		}
 	case strings.HasPrefix(provider, flaggerv1.SMIProvider+":v1alpha1"):
 		mesh := strings.TrimPrefix(provider, flaggerv1.SMIProvider+":v1alpha1:")
@@ -165,6 +206,13 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			annotationsPrefix: factory.ingressAnnotationsPrefix,
 			setOwnerRefs:      factory.setOwnerRefs,
 		}
+	case provider == flaggerv1.HAProxyProvider:
+		return &HAProxyRouter{
+			logger:        factory.logger,
+			kubeClient:    factory.kubeClient,
+			labelSelector: labelSelector,
+			labelValue:    labelValue,
+		}
 	case provider == flaggerv1.SkipperProvider:
 		return &SkipperRouter{
 			logger:       factory.logger,
@@ -213,15 +261,58 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			gatewayAPIClient: factory.meshClient,
 			setOwnerRefs:     factory.setOwnerRefs,
 		}
+	case provider == flaggerv1.OpenShiftProvider:
+		return &OpenShiftRouter{
+			logger:       factory.logger,
+			routeClient:  factory.meshClient,
+			setOwnerRefs: factory.setOwnerRefs,
+		}
+	case provider == flaggerv1.EmissaryProvider:
+		return &EmissaryRouter{
+			logger:         factory.logger,
+			emissaryClient: factory.meshClient,
+			setOwnerRefs:   factory.setOwnerRefs,
+		}
+	case provider == flaggerv1.KnativeProvider:
+		return &KnativeRouter{
+			logger:        factory.logger,
+			knativeClient: factory.flaggerClient,
+		}
+	case provider == flaggerv1.GKEProvider:
+		return &GKEGatewayRouter{
+			GatewayAPIV1Beta1Router: &GatewayAPIV1Beta1Router{
+				logger:           factory.logger,
+				kubeClient:       factory.kubeClient,
+				gatewayAPIClient: factory.meshClient,
+				setOwnerRefs:     factory.setOwnerRefs,
+			},
+		}
+	case provider == flaggerv1.ExternalDNSProvider:
+		return &ExternalDNSRouter{
+			logger:       factory.logger,
+			dnsClient:    factory.meshClient,
+			setOwnerRefs: factory.setOwnerRefs,
+		}
 	case provider == flaggerv1.KubernetesProvider:
 		return &NopRouter{}
-	default:
-		return &IstioRouter{
+	case provider == flaggerv1.SelectorSwitchProvider:
+		return &SelectorSwitchRouter{
 			logger:        factory.logger,
-			flaggerClient: factory.flaggerClient,
 			kubeClient:    factory.kubeClient,
-			istioClient:   factory.meshClient,
-			setOwnerRefs:  factory.setOwnerRefs,
+			labelSelector: labelSelector,
+		}
+	default:
+		return &IstioRouter{
+			logger:             factory.logger,
+			flaggerClient:      factory.flaggerClient,
+			kubeClient:         factory.kubeClient,
+			istioClient:        factory.istioClient,
+			setOwnerRefs:       factory.setOwnerRefs,
+			remoteIstioClients: factory.getRemoteIstioClients(),
+			labelSelector:      labelSelector,
+			labelValue:         labelValue,
+			eastWestGateway:    factory.meshEastWestGateway,
+			meshNetwork:        factory.meshNetwork,
 		}
 	}
 }
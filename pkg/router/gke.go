@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// GKEGatewayRouter is managing HTTPRoutes attached to GKE Gateways,
+// since the GKE Gateway controller takes minutes to program the Google Cloud load balancers,
+// the canary weight is increased only after the previous route change was reconciled
+type GKEGatewayRouter struct {
+	*GatewayAPIV1Beta1Router
+}
+
+// SetRoutes updates the destinations weight for primary and canary,
+// routing traffic away from the canary is never delayed so that rollbacks are applied immediately
+func (gr *GKEGatewayRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	_, currentCanaryWeight, _, err := gr.GetRoutes(canary)
+	if err != nil {
+		return err
+	}
+
+	if canaryWeight > currentCanaryWeight {
+		if err := gr.checkRouteReconciled(canary); err != nil {
+			return err
+		}
+	}
+
+	return gr.GatewayAPIV1Beta1Router.SetRoutes(canary, primaryWeight, canaryWeight, mirrored)
+}
+
+// checkRouteReconciled returns an error if the GKE Gateway controller
+// didn't report all the route conditions as true for the current generation
+func (gr *GKEGatewayRouter) checkRouteReconciled(canary *flaggerv1.Canary) error {
+	apexSvcName, _, _ := canary.GetServiceNames()
+	httpRoute, err := gr.gatewayAPIClient.GatewayapiV1beta1().HTTPRoutes(canary.Namespace).Get(context.TODO(), apexSvcName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("HTTPRoute %s.%s get error: %w", apexSvcName, canary.Namespace, err)
+	}
+
+	if len(httpRoute.Status.Parents) == 0 {
+		return fmt.Errorf("HTTPRoute %s.%s not yet reconciled by the GKE Gateway controller", apexSvcName, canary.Namespace)
+	}
+	for _, parent := range httpRoute.Status.Parents {
+		for _, condition := range parent.Conditions {
+			if condition.ObservedGeneration < httpRoute.Generation {
+				return fmt.Errorf("HTTPRoute %s.%s generation %d not yet reconciled by the GKE Gateway controller",
+					apexSvcName, canary.Namespace, httpRoute.Generation)
+			}
+			if condition.Status != metav1.ConditionTrue {
+				return fmt.Errorf("HTTPRoute %s.%s condition %s is %s: %s",
+					apexSvcName, canary.Namespace, condition.Type, condition.Status, condition.Message)
+			}
+		}
+	}
+	return nil
+}
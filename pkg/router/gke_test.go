@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
+)
+
+func TestGKEGatewayRouter_SetRoutes(t *testing.T) {
+	canary := newTestGatewayAPICanary()
+	mocks := newFixture(canary)
+	router := &GKEGatewayRouter{
+		GatewayAPIV1Beta1Router: &GatewayAPIV1Beta1Router{
+			gatewayAPIClient: mocks.meshClient,
+			kubeClient:       mocks.kubeClient,
+			logger:           mocks.logger,
+		},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	// the route was not reconciled by the GKE Gateway controller
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.Error(t, err)
+
+	setStatus := func(generation int64, observedGeneration int64) {
+		httpRoute, err := mocks.meshClient.GatewayapiV1beta1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		httpRoute.Generation = generation
+		httpRoute.Status.Parents = []v1beta1.RouteParentStatus{
+			{
+				ControllerName: "networking.gke.io/gateway",
+				Conditions: []metav1.Condition{
+					{Type: "Accepted", Status: metav1.ConditionTrue, ObservedGeneration: observedGeneration},
+					{Type: "Reconciled", Status: metav1.ConditionTrue, ObservedGeneration: observedGeneration},
+				},
+			},
+		}
+		_, err = mocks.meshClient.GatewayapiV1beta1().HTTPRoutes("default").Update(context.TODO(), httpRoute, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	setStatus(1, 1)
+	err = router.SetRoutes(canary, 90, 10, false)
+	require.NoError(t, err)
+
+	// the previous weight change is still being propagated
+	setStatus(2, 1)
+	err = router.SetRoutes(canary, 80, 20, false)
+	require.Error(t, err)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	// rollback is not delayed
+	err = router.SetRoutes(canary, 100, 0, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	haproxyBlueGreenBalanceAnnotation = "haproxy-ingress.github.io/blue-green-balance"
+	haproxyBlueGreenModeAnnotation    = "haproxy-ingress.github.io/blue-green-mode"
+)
+
+/*
+HAProxy ingress principles:
+* the blue/green balance weighs the endpoints of a backend service by their pod labels
+* the mode deploy distributes the weight of a group among its pods
+
+Implementation:
+* the ingress backend is a service that selects both the primary and canary pods
+* the primary and canary pods are told apart by the workload label selector
+* the weights are set with the blue/green balance annotation of the ingress
+*/
+
+// HAProxyRouter is managing the blue/green balance of the HAProxy ingress controller
+type HAProxyRouter struct {
+	kubeClient    kubernetes.Interface
+	logger        *zap.SugaredLogger
+	labelSelector string
+	labelValue    string
+}
+
+// Reconcile sets the blue/green annotations of the ingress,
+// the weights of an ongoing rollout are kept
+func (hr *HAProxyRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return fmt.Errorf("ingress selector is empty")
+	}
+
+	ingress, err := hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	if _, _, err := hr.parseBalance(ingress.Annotations); err == nil &&
+		ingress.Annotations[haproxyBlueGreenModeAnnotation] == "deploy" {
+		return nil
+	}
+
+	if err := hr.SetRoutes(canary, 100, 0, false); err != nil {
+		return err
+	}
+	hr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Ingress %s.%s blue/green balance initialized", ingress.Name, canary.Namespace)
+	return nil
+}
+
+// GetRoutes returns the primary and canary weights of the blue/green balance
+func (hr *HAProxyRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		err = fmt.Errorf("ingress selector is empty")
+		return
+	}
+
+	ingress, err := hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+		return
+	}
+
+	primaryWeight, canaryWeight, err = hr.parseBalance(ingress.Annotations)
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s: %w", ingress.Name, canary.Namespace, err)
+	}
+	return
+}
+
+// SetRoutes updates the blue/green balance of the ingress
+func (hr *HAProxyRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return fmt.Errorf("ingress selector is empty")
+	}
+
+	ingress, err := hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("ingress %s.%s update failed: no valid weights", ingress.Name, canary.Namespace)
+	}
+
+	iClone := ingress.DeepCopy()
+	if iClone.Annotations == nil {
+		iClone.Annotations = make(map[string]string)
+	}
+	iClone.Annotations[haproxyBlueGreenBalanceAnnotation] = hr.makeBalance(primaryWeight, canaryWeight)
+	iClone.Annotations[haproxyBlueGreenModeAnnotation] = "deploy"
+
+	_, err = hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+	return nil
+}
+
+// Finalize routes all the traffic to the reverted target pods,
+// the annotations are kept since the backend service selects both the primary and target pods
+// until the primary workload is garbage collected
+func (hr *HAProxyRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return nil
+	}
+
+	return hr.SetRoutes(canary, 0, 100, false)
+}
+
+// makeBalance returns the blue/green balance e.g. "app=podinfo-primary=90,app=podinfo=10"
+func (hr *HAProxyRouter) makeBalance(primaryWeight int, canaryWeight int) string {
+	return fmt.Sprintf("%s=%s-primary=%d,%s=%s=%d",
+		hr.labelSelector, hr.labelValue, primaryWeight, hr.labelSelector, hr.labelValue, canaryWeight)
+}
+
+// parseBalance returns the primary and canary weights of the blue/green balance
+func (hr *HAProxyRouter) parseBalance(annotations map[string]string) (int, int, error) {
+	balance, ok := annotations[haproxyBlueGreenBalanceAnnotation]
+	if !ok {
+		return 0, 0, fmt.Errorf("annotation %s not found", haproxyBlueGreenBalanceAnnotation)
+	}
+
+	primaryWeight, canaryWeight := -1, -1
+	for _, group := range strings.Split(balance, ",") {
+		i := strings.LastIndex(group, "=")
+		if i < 0 {
+			return 0, 0, fmt.Errorf("annotation %s: invalid group %q", haproxyBlueGreenBalanceAnnotation, group)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(group[i+1:]))
+		if err != nil {
+			return 0, 0, fmt.Errorf("annotation %s: invalid weight in group %q", haproxyBlueGreenBalanceAnnotation, group)
+		}
+		switch strings.TrimSpace(group[:i]) {
+		case fmt.Sprintf("%s=%s-primary", hr.labelSelector, hr.labelValue):
+			primaryWeight = weight
+		case fmt.Sprintf("%s=%s", hr.labelSelector, hr.labelValue):
+			canaryWeight = weight
+		}
+	}
+
+	if primaryWeight < 0 || canaryWeight < 0 {
+		return 0, 0, fmt.Errorf("annotation %s doesn't select the %s=%s pods",
+			haproxyBlueGreenBalanceAnnotation, hr.labelSelector, hr.labelValue)
+	}
+	return primaryWeight, canaryWeight, nil
+}
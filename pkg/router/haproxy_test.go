@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestHAProxyRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, "", "", mocks.logger, mocks.meshClient, mocks.istioClient, false, nil).
+		MeshRouter(flaggerv1.HAProxyProvider, "app", "podinfo")
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	ingress, err := mocks.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "app=podinfo-primary=100,app=podinfo=0", ingress.Annotations[haproxyBlueGreenBalanceAnnotation])
+	assert.Equal(t, "deploy", ingress.Annotations[haproxyBlueGreenModeAnnotation])
+
+	err = router.SetRoutes(mocks.ingressCanary, 70, 30, false)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+	assert.False(t, m)
+
+	// the weights are kept on reconcile
+	err = router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	err = router.SetRoutes(mocks.ingressCanary, 0, 0, false)
+	require.Error(t, err)
+
+	// the traffic is routed to the reverted target on finalize
+	err = router.Finalize(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	ingress, err = mocks.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "app=podinfo-primary=0,app=podinfo=100", ingress.Annotations[haproxyBlueGreenBalanceAnnotation])
+	assert.Equal(t, "deploy", ingress.Annotations[haproxyBlueGreenModeAnnotation])
+}
+
+func TestHAProxyRouter_parseBalance(t *testing.T) {
+	router := &HAProxyRouter{labelSelector: "app", labelValue: "podinfo"}
+
+	p, c, err := router.parseBalance(map[string]string{
+		haproxyBlueGreenBalanceAnnotation: "app=podinfo=25, app=podinfo-primary=75",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 75, p)
+	assert.Equal(t, 25, c)
+
+	_, _, err = router.parseBalance(map[string]string{
+		haproxyBlueGreenBalanceAnnotation: "group=blue=1,group=green=4",
+	})
+	require.Error(t, err)
+
+	_, _, err = router.parseBalance(map[string]string{})
+	require.Error(t, err)
+}
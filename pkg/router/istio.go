@@ -21,12 +21,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/testing/protocmp"
+	networkingv1beta1 "istio.io/api/networking/v1beta1"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,53 +46,474 @@ import (
 // IstioRouter is managing Istio virtual services
 type IstioRouter struct {
 	kubeClient    kubernetes.Interface
-	istioClient   clientset.Interface
+	istioClient   istioclientset.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
 	setOwnerRefs  bool
+	labelSelector string
+	labelValue    string
+	// Istio clients of the other primary clusters in a multi-primary mesh
+	remoteIstioClients []istioclientset.Interface
+	// remote is set for the routers managing the objects of the other primary clusters
+	remote bool
+	// address and network of the local east-west gateway, the other primary clusters
+	// reach the primary and canary workloads through it when set
+	eastWestGateway string
+	meshNetwork     string
 }
 
 const cookieHeader = "Cookie"
 const setCookieHeader = "Set-Cookie"
 const stickyRouteName = "sticky-route"
 const maxAgeAttr = "Max-Age"
+const primarySubset = "primary"
+const canarySubset = "canary"
+
+// gatewayOwnerAnnotation marks the Istio gateways generated by Flagger with the canary name
+const gatewayOwnerAnnotation = "flagger.app/gateway-owner"
+
+// serviceEntryOwnerAnnotation marks the Istio service entries generated by Flagger with the canary name
+const serviceEntryOwnerAnnotation = "flagger.app/service-entry-owner"
+
+// eastWestGatewayPort is the port on which the Istio east-west gateway routes the cross-network mTLS traffic
+const eastWestGatewayPort = 15443
 
 var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 // Reconcile creates or updates the Istio virtual service and destination rules
 func (ir *IstioRouter) Reconcile(canary *flaggerv1.Canary) error {
-	_, primaryName, canaryName := canary.GetServiceNames()
+	apexName, primaryName, canaryName := canary.GetServiceNames()
 
-	if err := ir.reconcileDestinationRule(canary, canaryName); err != nil {
-		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+	if canary.Spec.Service.Subsets != nil {
+		if err := ir.reconcileDestinationRule(canary, apexName, ir.makeSubsets()); err != nil {
+			return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+		}
+	} else {
+		if err := ir.reconcileDestinationRule(canary, canaryName, nil); err != nil {
+			return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+		}
+
+		if err := ir.reconcileDestinationRule(canary, primaryName, nil); err != nil {
+			return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+		}
 	}
 
-	if err := ir.reconcileDestinationRule(canary, primaryName); err != nil {
-		return fmt.Errorf("reconcileDestinationRule failed: %w", err)
+	if err := ir.deleteStaleDestinationRules(canary); err != nil {
+		return fmt.Errorf("deleteStaleDestinationRules failed: %w", err)
+	}
+
+	if err := ir.reconcileGateway(canary); err != nil {
+		return fmt.Errorf("reconcileGateway failed: %w", err)
 	}
 
 	if err := ir.reconcileVirtualService(canary); err != nil {
 		return fmt.Errorf("reconcileVirtualService failed: %w", err)
 	}
+
+	if err := ir.reconcileServiceEntry(canary); err != nil {
+		return fmt.Errorf("reconcileServiceEntry failed: %w", err)
+	}
+
+	for _, rr := range ir.remoteRouters() {
+		if err := rr.Reconcile(canary); err != nil {
+			return fmt.Errorf("remote cluster %w", err)
+		}
+	}
+	return nil
+}
+
+// remoteRouters returns a router for each remote primary cluster of the mesh,
+// the routing objects are replicated so that the traffic split is honored
+// for the requests originating from the remote clusters
+func (ir *IstioRouter) remoteRouters() []*IstioRouter {
+	routers := make([]*IstioRouter, 0, len(ir.remoteIstioClients))
+	for _, client := range ir.remoteIstioClients {
+		routers = append(routers, &IstioRouter{
+			kubeClient:      ir.kubeClient,
+			istioClient:     client,
+			flaggerClient:   ir.flaggerClient,
+			logger:          ir.logger,
+			labelSelector:   ir.labelSelector,
+			labelValue:      ir.labelValue,
+			remote:          true,
+			eastWestGateway: ir.eastWestGateway,
+			meshNetwork:     ir.meshNetwork,
+		})
+	}
+	return routers
+}
+
+// HasRemoteClusters returns true if the routing objects are replicated to other primary clusters
+func (ir *IstioRouter) HasRemoteClusters() bool {
+	return len(ir.remoteIstioClients) > 0
+}
+
+// FinalizeRemote deletes the routing objects replicated to the other primary clusters,
+// they have no owner reference to the canary and are not garbage collected
+func (ir *IstioRouter) FinalizeRemote(canary *flaggerv1.Canary) error {
+	for _, rr := range ir.remoteRouters() {
+		if err := rr.Finalize(canary); err != nil {
+			return fmt.Errorf("remote cluster %w", err)
+		}
+	}
+	return nil
+}
+
+// reconcileServiceEntry registers the primary and canary hosts behind the east-west gateway
+// of the local cluster, so that the traffic split is honored for the cross-network requests
+func (ir *IstioRouter) reconcileServiceEntry(canary *flaggerv1.Canary) error {
+	if !ir.remote || ir.eastWestGateway == "" {
+		return nil
+	}
+
+	name := serviceEntryName(canary)
+	newSpec := ir.makeServiceEntrySpec(canary)
+
+	serviceEntry, err := ir.istioClient.NetworkingV1beta1().ServiceEntries(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	// insert
+	if errors.IsNotFound(err) {
+		serviceEntry = &istiov1beta1.ServiceEntry{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   canary.Namespace,
+				Annotations: map[string]string{serviceEntryOwnerAnnotation: canary.Name},
+			},
+		}
+		newSpec.DeepCopyInto(&serviceEntry.Spec)
+		_, err = ir.istioClient.NetworkingV1beta1().ServiceEntries(canary.Namespace).Create(context.TODO(), serviceEntry, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceEntry %s.%s create error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceEntry %s.%s created", name, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("ServiceEntry %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+
+	if serviceEntry.Annotations[serviceEntryOwnerAnnotation] != canary.Name {
+		return fmt.Errorf("ServiceEntry %s.%s exists and is not managed by canary %s", name, canary.Namespace, canary.Name)
+	}
+
+	// update
+	if diff := cmp.Diff(newSpec, &serviceEntry.Spec, protocmp.Transform()); diff != "" {
+		clone := serviceEntry.DeepCopy()
+		newSpec.DeepCopyInto(&clone.Spec)
+		_, err = ir.istioClient.NetworkingV1beta1().ServiceEntries(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("ServiceEntry %s.%s update error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("ServiceEntry %s.%s updated", name, canary.Namespace)
+	}
 	return nil
 }
 
-func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name string) error {
-	newSpec := istiov1alpha3.DestinationRuleSpec{
+func serviceEntryName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-east-west", apexName)
+}
+
+func (ir *IstioRouter) makeServiceEntrySpec(canary *flaggerv1.Canary) *networkingv1beta1.ServiceEntry {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	portName := canary.Spec.Service.PortName
+	if portName == "" {
+		portName = "http"
+	}
+
+	resolution := networkingv1beta1.ServiceEntry_DNS
+	if net.ParseIP(ir.eastWestGateway) != nil {
+		resolution = networkingv1beta1.ServiceEntry_STATIC
+	}
+
+	return &networkingv1beta1.ServiceEntry{
+		Hosts: []string{
+			fmt.Sprintf("%s.%s.svc.cluster.local", primaryName, canary.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", canaryName, canary.Namespace),
+		},
+		Ports: []*networkingv1beta1.ServicePort{
+			{Number: uint32(canary.Spec.Service.Port), Protocol: "HTTP", Name: portName},
+		},
+		Location:   networkingv1beta1.ServiceEntry_MESH_INTERNAL,
+		Resolution: resolution,
+		Endpoints: []*networkingv1beta1.WorkloadEntry{
+			{
+				Address: ir.eastWestGateway,
+				Network: ir.meshNetwork,
+				Ports:   map[string]uint32{portName: eastWestGatewayPort},
+			},
+		},
+	}
+}
+
+// deleteRemoteObjects deletes the virtual service, destination rules, gateway
+// and service entry generated for the canary in a remote cluster
+func (ir *IstioRouter) deleteRemoteObjects(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	if err := ir.deleteGateway(canary); err != nil {
+		return err
+	}
+
+	name := serviceEntryName(canary)
+	serviceEntry, err := ir.istioClient.NetworkingV1beta1().ServiceEntries(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("ServiceEntry %s.%s get query error: %w", name, canary.Namespace, err)
+	}
+	if err == nil && serviceEntry.Annotations[serviceEntryOwnerAnnotation] == canary.Name {
+		err = ir.istioClient.NetworkingV1beta1().ServiceEntries(canary.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("ServiceEntry %s.%s delete error: %w", name, canary.Namespace, err)
+		}
+	}
+
+	err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("VirtualService %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+
+	destinationRules := []string{primaryName, canaryName}
+	if canary.Spec.Service.Subsets != nil {
+		destinationRules = []string{apexName}
+	}
+	for _, dr := range destinationRules {
+		err = ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Delete(context.TODO(), dr, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("DestinationRule %s.%s delete error: %w", dr, canary.Namespace, err)
+		}
+	}
+
+	ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Remote routing objects of %s.%s deleted", apexName, canary.Namespace)
+	return nil
+}
+
+// hasGeneratedGateway returns true if Flagger should generate the Istio gateway
+func hasGeneratedGateway(canary *flaggerv1.Canary) bool {
+	return canary.Spec.Service.Gateway != nil && len(canary.Spec.Service.Gateways) == 0 && !canary.Spec.Service.Delegation
+}
+
+func (ir *IstioRouter) reconcileGateway(canary *flaggerv1.Canary) error {
+	if !hasGeneratedGateway(canary) {
+		return nil
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+	if len(canary.Spec.Service.Hosts) == 0 {
+		return fmt.Errorf("Gateway %s.%s requires at least one host in the canary service spec", apexName, canary.Namespace)
+	}
+
+	newSpec, err := makeGatewaySpec(canary)
+	if err != nil {
+		return fmt.Errorf("Gateway %s.%s %w", apexName, canary.Namespace, err)
+	}
+
+	gateway, err := ir.istioClient.NetworkingV1beta1().Gateways(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	// insert
+	if errors.IsNotFound(err) {
+		gateway = &istiov1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Annotations: map[string]string{gatewayOwnerAnnotation: canary.Name},
+			},
+		}
+		newSpec.DeepCopyInto(&gateway.Spec)
+		if ir.setOwnerRefs {
+			gateway.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
+		_, err = ir.istioClient.NetworkingV1beta1().Gateways(canary.Namespace).Create(context.TODO(), gateway, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("Gateway %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Gateway %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Gateway %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	// don't overwrite a gateway that wasn't generated for this canary
+	if !isGatewayOwner(gateway, canary) {
+		return fmt.Errorf("Gateway %s.%s already exists and is not managed by canary %s",
+			apexName, canary.Namespace, canary.Name)
+	}
+
+	// update
+	if diff := cmp.Diff(newSpec, &gateway.Spec, protocmp.Transform()); diff != "" {
+		clone := gateway.DeepCopy()
+		newSpec.DeepCopyInto(&clone.Spec)
+		_, err = ir.istioClient.NetworkingV1beta1().Gateways(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("Gateway %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Gateway %s.%s updated", apexName, canary.Namespace)
+	}
+
+	return nil
+}
+
+// deleteGateway removes the gateway generated for the canary, gateways managed by others are left in place
+func (ir *IstioRouter) deleteGateway(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	gateway, err := ir.istioClient.NetworkingV1beta1().Gateways(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Gateway %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+	if !isGatewayOwner(gateway, canary) {
+		return nil
+	}
+
+	err = ir.istioClient.NetworkingV1beta1().Gateways(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("Gateway %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+	ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Gateway %s.%s deleted", apexName, canary.Namespace)
+	return nil
+}
+
+// isGatewayOwner returns true if the gateway was generated for the canary
+func isGatewayOwner(gateway *istiov1beta1.Gateway, canary *flaggerv1.Canary) bool {
+	return gateway.Annotations[gatewayOwnerAnnotation] == canary.Name || metav1.IsControlledBy(gateway, canary)
+}
+
+func makeGatewaySpec(canary *flaggerv1.Canary) (*networkingv1beta1.Gateway, error) {
+	gw := canary.Spec.Service.Gateway
+
+	selector := gw.Selector
+	if len(selector) == 0 {
+		selector = map[string]string{"istio": "ingressgateway"}
+	}
+
+	if gw.TLS == nil || gw.TLS.Mode == "" {
+		port := 80
+		if gw.Port > 0 {
+			port = gw.Port
+		}
+		return &networkingv1beta1.Gateway{
+			Selector: selector,
+			Servers: []*networkingv1beta1.Server{
+				{
+					Port:  &networkingv1beta1.Port{Number: uint32(port), Protocol: "HTTP", Name: "http"},
+					Hosts: canary.Spec.Service.Hosts,
+				},
+			},
+		}, nil
+	}
+
+	mode, ok := networkingv1beta1.ServerTLSSettings_TLSmode_value[gw.TLS.Mode]
+	if !ok {
+		return nil, fmt.Errorf("TLS mode %s is not supported", gw.TLS.Mode)
+	}
+
+	port := 443
+	if gw.Port > 0 {
+		port = gw.Port
+	}
+	servers := []*networkingv1beta1.Server{
+		{
+			Port:  &networkingv1beta1.Port{Number: uint32(port), Protocol: "HTTPS", Name: "https"},
+			Hosts: canary.Spec.Service.Hosts,
+			Tls: &networkingv1beta1.ServerTLSSettings{
+				Mode:           networkingv1beta1.ServerTLSSettings_TLSmode(mode),
+				CredentialName: gw.TLS.CredentialName,
+			},
+		},
+	}
+	if gw.TLS.HttpsRedirect {
+		servers = append(servers, &networkingv1beta1.Server{
+			Port:  &networkingv1beta1.Port{Number: 80, Protocol: "HTTP", Name: "http"},
+			Hosts: canary.Spec.Service.Hosts,
+			Tls:   &networkingv1beta1.ServerTLSSettings{HttpsRedirect: true},
+		})
+	}
+	return &networkingv1beta1.Gateway{
+		Selector: selector,
+		Servers:  servers,
+	}, nil
+}
+
+// makeSubsets returns the primary and canary subsets selecting the pods by the workload label
+func (ir *IstioRouter) makeSubsets() []*networkingv1beta1.Subset {
+	return []*networkingv1beta1.Subset{
+		{
+			Name:   primarySubset,
+			Labels: map[string]string{ir.labelSelector: fmt.Sprintf("%s-primary", ir.labelValue)},
+		},
+		{
+			Name:   canarySubset,
+			Labels: map[string]string{ir.labelSelector: ir.labelValue},
+		},
+	}
+}
+
+// deleteStaleDestinationRules deletes the destination rules of the previous layout
+// when the routing by subsets is enabled or disabled
+func (ir *IstioRouter) deleteStaleDestinationRules(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	stale := []string{primaryName, canaryName}
+	if canary.Spec.Service.Subsets == nil {
+		stale = []string{apexName}
+	}
+
+	for _, name := range stale {
+		dr, err := ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("DestinationRule %s.%s get query error: %w", name, canary.Namespace, err)
+		}
+		// the apex destination rule may be managed by the user, only the one with the generated subsets is deleted
+		if name == apexName && !hasGeneratedSubsets(dr.Spec.Subsets) {
+			continue
+		}
+		err = ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("DestinationRule %s.%s delete error: %w", name, canary.Namespace, err)
+		}
+		ir.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("DestinationRule %s.%s deleted", name, canary.Namespace)
+	}
+	return nil
+}
+
+// hasGeneratedSubsets returns true if the subsets are the primary and canary subsets generated by Flagger
+func hasGeneratedSubsets(subsets []*networkingv1beta1.Subset) bool {
+	return len(subsets) == 2 && subsets[0].Name == primarySubset && subsets[1].Name == canarySubset
+}
+
+func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name string, subsets []*networkingv1beta1.Subset) error {
+	var trafficPolicy *networkingv1beta1.TrafficPolicy
+	if err := toIstioAPI(canary.Spec.Service.TrafficPolicy, &trafficPolicy); err != nil {
+		return fmt.Errorf("DestinationRule %s.%s traffic policy conversion error: %w", name, canary.Namespace, err)
+	}
+	newSpec := &networkingv1beta1.DestinationRule{
 		Host:          name,
-		TrafficPolicy: canary.Spec.Service.TrafficPolicy,
+		TrafficPolicy: trafficPolicy,
+		Subsets:       subsets,
 	}
 
-	destinationRule, err := ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	destinationRule, err := ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
-		destinationRule = &istiov1alpha3.DestinationRule{
+		destinationRule = &istiov1beta1.DestinationRule{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
 				Namespace: canary.Namespace,
 			},
-			Spec: newSpec,
 		}
+		newSpec.DeepCopyInto(&destinationRule.Spec)
 		if ir.setOwnerRefs {
 			destinationRule.OwnerReferences = []metav1.OwnerReference{
 				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
@@ -97,7 +523,7 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 				}),
 			}
 		}
-		_, err = ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Create(context.TODO(), destinationRule, metav1.CreateOptions{})
+		_, err = ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Create(context.TODO(), destinationRule, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("DestinationRule %s.%s create error: %w", name, canary.Namespace, err)
 		}
@@ -110,10 +536,10 @@ func (ir *IstioRouter) reconcileDestinationRule(canary *flaggerv1.Canary, name s
 
 	// update
 	if destinationRule != nil {
-		if diff := cmp.Diff(newSpec, destinationRule.Spec); diff != "" {
+		if diff := cmp.Diff(newSpec, &destinationRule.Spec, protocmp.Transform()); diff != "" {
 			clone := destinationRule.DeepCopy()
-			clone.Spec = newSpec
-			_, err = ir.istioClient.NetworkingV1alpha3().DestinationRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+			newSpec.DeepCopyInto(&clone.Spec)
+			_, err = ir.istioClient.NetworkingV1beta1().DestinationRules(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("DestinationRule %s.%s update error: %w", name, canary.Namespace, err)
 			}
@@ -160,11 +586,14 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 
 	// set default mesh gateway if no gateway is specified
 	if !hasMeshGateway && len(canary.Spec.Service.Gateways) == 0 {
+		if hasGeneratedGateway(canary) {
+			gateways = append(gateways, apexName)
+		}
 		gateways = append(gateways, "mesh")
 	}
 
 	// create destinations with primary weight 100% and canary weight 0%
-	canaryRoute := []istiov1alpha3.HTTPRouteDestination{
+	canaryRoute := []*networkingv1beta1.HTTPRouteDestination{
 		makeDestination(canary, primaryName, 100),
 		makeDestination(canary, canaryName, 0),
 	}
@@ -175,20 +604,14 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 		gateways = []string{}
 	}
 
-	newSpec := istiov1alpha3.VirtualServiceSpec{
+	route, err := makeHTTPRoute(canary, canary.Spec.Service.Match, canaryRoute)
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
+	}
+	newSpec := &networkingv1beta1.VirtualService{
 		Hosts:    hosts,
 		Gateways: gateways,
-		Http: []istiov1alpha3.HTTPRoute{
-			{
-				Match:      canary.Spec.Service.Match,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route:      canaryRoute,
-			},
-		},
+		Http:     []*networkingv1beta1.HTTPRoute{route},
 	}
 
 	newMetadata := canary.Spec.Service.Apex
@@ -204,43 +627,27 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 	newMetadata.Annotations = filterMetadata(newMetadata.Annotations)
 
 	if len(canary.GetAnalysis().Match) > 0 {
-		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
-		newSpec.Http = []istiov1alpha3.HTTPRoute{
-			{
-				Match:      canaryMatch,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route:      canaryRoute,
-			},
-			{
-				Match:      canary.Spec.Service.Match,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route: []istiov1alpha3.HTTPRouteDestination{
-					makeDestination(canary, primaryName, 100),
-				},
-			},
-		}
+		canaryMatch := mergeMatchConditions(renderClaimConditions(canary.GetAnalysis().Match), canary.Spec.Service.Match)
+		newSpec.Http, err = makeABTestRoutes(canary, canaryMatch, canaryRoute, 100)
+	} else if len(canary.GetAnalysis().Localities) > 0 {
+		newSpec.Http, err = makeLocalityRoutes(canary, 100, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
 	}
 
-	virtualService, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	virtualService, err := ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	// insert
 	if errors.IsNotFound(err) {
-		virtualService = &istiov1alpha3.VirtualService{
+		virtualService = &istiov1beta1.VirtualService{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        apexName,
 				Namespace:   canary.Namespace,
 				Labels:      newMetadata.Labels,
 				Annotations: newMetadata.Annotations,
 			},
-			Spec: newSpec,
 		}
+		newSpec.DeepCopyInto(&virtualService.Spec)
 		if ir.setOwnerRefs {
 			virtualService.OwnerReferences = []metav1.OwnerReference{
 				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
@@ -250,7 +657,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 				}),
 			}
 		}
-		_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Create(context.TODO(), virtualService, metav1.CreateOptions{})
+		_, err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Create(context.TODO(), virtualService, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("VirtualService %s.%s create error: %w", apexName, canary.Namespace, err)
 		}
@@ -268,20 +675,18 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 	}
 
 	ignoreCmpOptions := []cmp.Option{
-		cmpopts.IgnoreFields(istiov1alpha3.HTTPRouteDestination{}, "Weight"),
-		cmpopts.IgnoreFields(istiov1alpha3.HTTPRoute{}, "Mirror", "MirrorPercentage"),
+		protocmp.Transform(),
+		protocmp.IgnoreFields(&networkingv1beta1.HTTPRouteDestination{}, "weight"),
+		protocmp.IgnoreFields(&networkingv1beta1.HTTPRoute{}, "mirror", "mirror_percentage"),
 	}
 	if canary.Spec.Analysis.SessionAffinity != nil {
 		// We ignore this route as this does not do weighted routing and is handled exclusively
 		// by SetRoutes().
-		ignoreSlice := cmpopts.IgnoreSliceElements(func(t istiov1alpha3.HTTPRoute) bool {
-			if t.Name == stickyRouteName {
-				return true
-			}
-			return false
+		ignoreSlice := cmpopts.IgnoreSliceElements(func(t protocmp.Message) bool {
+			return t.Descriptor() == (&networkingv1beta1.HTTPRoute{}).ProtoReflect().Descriptor() && t["name"] == stickyRouteName
 		})
 		ignoreCmpOptions = append(ignoreCmpOptions, ignoreSlice)
-		ignoreCmpOptions = append(ignoreCmpOptions, cmpopts.IgnoreFields(istiov1alpha3.HTTPRouteDestination{}, "Headers"))
+		ignoreCmpOptions = append(ignoreCmpOptions, protocmp.IgnoreFields(&networkingv1beta1.HTTPRouteDestination{}, "headers"))
 	}
 	if v, ok := virtualService.Annotations[kubectlAnnotation]; ok {
 		newMetadata.Annotations[kubectlAnnotation] = v
@@ -293,14 +698,14 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 	if virtualService != nil {
 		specDiff := cmp.Diff(
 			newSpec,
-			virtualService.Spec,
+			&virtualService.Spec,
 			ignoreCmpOptions...,
 		)
 		labelsDiff := cmp.Diff(newMetadata.Labels, virtualService.Labels, cmpopts.EquateEmpty())
 		annotationsDiff := cmp.Diff(newMetadata.Annotations, virtualService.Annotations, cmpopts.EquateEmpty())
 		if specDiff != "" || labelsDiff != "" || annotationsDiff != "" {
 			vtClone := virtualService.DeepCopy()
-			vtClone.Spec = newSpec
+			newSpec.DeepCopyInto(&vtClone.Spec)
 			vtClone.ObjectMeta.Annotations = newMetadata.Annotations
 			vtClone.ObjectMeta.Labels = newMetadata.Labels
 
@@ -308,7 +713,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 			//serialization.  If not present store the serialized object in annotation
 			//flagger.kubernetes.app/original-configuration
 			if _, ok := vtClone.Annotations[kubectlAnnotation]; !ok && specDiff != "" {
-				b, err := json.Marshal(virtualService.Spec)
+				b, err := json.Marshal(&virtualService.Spec)
 				if err != nil {
 					ir.logger.Warnf("Unable to marshal VS %s for orig-configuration annotation", virtualService.Name)
 				}
@@ -322,7 +727,7 @@ func (ir *IstioRouter) reconcileVirtualService(canary *flaggerv1.Canary) error {
 				vtClone.ObjectMeta.Annotations[configAnnotation] = string(b)
 			}
 
-			_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vtClone, metav1.UpdateOptions{})
+			_, err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Update(context.TODO(), vtClone, metav1.UpdateOptions{})
 			if err != nil {
 				return fmt.Errorf("VirtualService %s.%s update error: %w", apexName, canary.Namespace, err)
 			}
@@ -342,32 +747,37 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 	err error,
 ) {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
-	vs := &istiov1alpha3.VirtualService{}
-	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs := &istiov1beta1.VirtualService{}
+	vs, err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		err = fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
 		return
 	}
 
-	var httpRoute istiov1alpha3.HTTPRoute
+	var httpRoute *networkingv1beta1.HTTPRoute
 	for _, http := range vs.Spec.Http {
 		for _, r := range http.Route {
-			if r.Destination.Host == canaryName {
+			if destinationService(canary, r.Destination) == canaryName {
 				httpRoute = http
 				break
 			}
 		}
 	}
 
-	for _, route := range httpRoute.Route {
-		if route.Destination.Host == primaryName {
-			primaryWeight = route.Weight
+	baselineName := baselineServiceName(canary)
+	for _, route := range httpRoute.GetRoute() {
+		if destinationService(canary, route.Destination) == primaryName {
+			primaryWeight += int(route.Weight)
 		}
-		if route.Destination.Host == canaryName {
-			canaryWeight = route.Weight
+		if destinationService(canary, route.Destination) == canaryName {
+			canaryWeight = int(route.Weight)
+		}
+		// the baseline share is taken from the primary
+		if destinationService(canary, route.Destination) == baselineName {
+			primaryWeight += int(route.Weight)
 		}
 	}
-	if httpRoute.Mirror != nil && httpRoute.Mirror.Host != "" {
+	if httpRoute.GetMirror().GetHost() != "" {
 		mirrored = true
 	}
 
@@ -377,11 +787,11 @@ func (ir *IstioRouter) GetRoutes(canary *flaggerv1.Canary) (
 				// we are interested in the route that sets the cookie as that's the route
 				// that does weighted routing.
 				if routeDest.Headers != nil {
-					if routeDest.Destination.Host == primaryName {
-						primaryWeight = routeDest.Weight
+					if destinationService(canary, routeDest.Destination) == primaryName {
+						primaryWeight = int(routeDest.Weight)
 					}
-					if routeDest.Destination.Host == canaryName {
-						canaryWeight = routeDest.Weight
+					if destinationService(canary, routeDest.Destination) == canaryName {
+						canaryWeight = int(routeDest.Weight)
 					}
 				}
 			}
@@ -405,7 +815,7 @@ func (ir *IstioRouter) SetRoutes(
 ) error {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
-	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs, err := ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s get query error %v", apexName, canary.Namespace, err)
 	}
@@ -413,19 +823,11 @@ func (ir *IstioRouter) SetRoutes(
 	vsCopy := vs.DeepCopy()
 
 	// weighted routing (progressive canary)
-	weightedRoute := istiov1alpha3.HTTPRoute{
-		Match:      canary.Spec.Service.Match,
-		Rewrite:    canary.Spec.Service.Rewrite,
-		Timeout:    canary.Spec.Service.Timeout,
-		Retries:    canary.Spec.Service.Retries,
-		CorsPolicy: canary.Spec.Service.CorsPolicy,
-		Headers:    canary.Spec.Service.Headers,
-		Route: []istiov1alpha3.HTTPRouteDestination{
-			makeDestination(canary, primaryName, primaryWeight),
-			makeDestination(canary, canaryName, canaryWeight),
-		},
+	weightedRoute, err := makeHTTPRoute(canary, canary.Spec.Service.Match, makeWeightedDestinations(canary, primaryWeight, canaryWeight))
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
 	}
-	vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+	vsCopy.Spec.Http = []*networkingv1beta1.HTTPRoute{
 		weightedRoute,
 	}
 
@@ -433,18 +835,17 @@ func (ir *IstioRouter) SetRoutes(
 		// If a canary run is active, we want all responses corresponding to requests hitting the canary deployment
 		// (due to weighted routing) to include a `Set-Cookie` header. All requests that have the `Cookie` header
 		// and match the value of the `Set-Cookie` header will be routed to the canary deployment.
-		stickyRoute := weightedRoute
-		stickyRoute.Name = stickyRouteName
+		stickyRoute := weightedRoute.DeepCopy()
 		if canaryWeight != 0 {
 			if canary.Status.SessionAffinityCookie == "" {
 				canary.Status.SessionAffinityCookie = fmt.Sprintf("%s=%s", canary.Spec.Analysis.SessionAffinity.CookieName, randSeq())
 			}
 
-			for i, routeDest := range weightedRoute.Route {
-				if routeDest.Destination.Host == canaryName {
+			for _, routeDest := range weightedRoute.Route {
+				if destinationService(canary, routeDest.Destination) == canaryName {
 					if routeDest.Headers == nil {
-						routeDest.Headers = &istiov1alpha3.Headers{
-							Response: &istiov1alpha3.HeaderOperations{},
+						routeDest.Headers = &networkingv1beta1.Headers{
+							Response: &networkingv1beta1.Headers_HeaderOperations{},
 						}
 					}
 					routeDest.Headers.Response.Add = map[string]string{
@@ -453,7 +854,6 @@ func (ir *IstioRouter) SetRoutes(
 						),
 					}
 				}
-				weightedRoute.Route[i] = routeDest
 			}
 
 			cookieKeyAndVal := strings.Split(canary.Status.SessionAffinityCookie, "=")
@@ -465,10 +865,12 @@ func (ir *IstioRouter) SetRoutes(
 				},
 			}
 			canaryMatch := mergeMatchConditions([]istiov1alpha3.HTTPMatchRequest{cookieMatch}, canary.Spec.Service.Match)
-			stickyRoute.Match = canaryMatch
-			stickyRoute.Route = []istiov1alpha3.HTTPRouteDestination{
+			stickyRoute, err = makeHTTPRoute(canary, canaryMatch, []*networkingv1beta1.HTTPRouteDestination{
 				makeDestination(canary, primaryName, 0),
 				makeDestination(canary, canaryName, 100),
+			})
+			if err != nil {
+				return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
 			}
 		} else {
 			// If canary weight is 0 and SessionAffinityCookie is non-blank, then it belongs to a previous canary run.
@@ -488,19 +890,18 @@ func (ir *IstioRouter) SetRoutes(
 					},
 				}
 				canaryMatch := mergeMatchConditions([]istiov1alpha3.HTTPMatchRequest{cookieMatch}, canary.Spec.Service.Match)
-				stickyRoute.Match = canaryMatch
+				stickyRoute, err = makeHTTPRoute(canary, canaryMatch, stickyRoute.Route)
+				if err != nil {
+					return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
+				}
 
 				if stickyRoute.Headers == nil {
-					stickyRoute.Headers = &istiov1alpha3.Headers{
-						Response: &istiov1alpha3.HeaderOperations{
-							Add: map[string]string{},
-						},
-					}
-				} else if stickyRoute.Headers.Response == nil {
-					stickyRoute.Headers.Response = &istiov1alpha3.HeaderOperations{
-						Add: map[string]string{},
-					}
-				} else if stickyRoute.Headers.Response.Add == nil {
+					stickyRoute.Headers = &networkingv1beta1.Headers{}
+				}
+				if stickyRoute.Headers.Response == nil {
+					stickyRoute.Headers.Response = &networkingv1beta1.Headers_HeaderOperations{}
+				}
+				if stickyRoute.Headers.Response.Add == nil {
 					stickyRoute.Headers.Response.Add = map[string]string{}
 				}
 				stickyRoute.Headers.Response.Add[setCookieHeader] = fmt.Sprintf("%s; %s=%d", previousCookie, maxAgeAttr, -1)
@@ -508,78 +909,85 @@ func (ir *IstioRouter) SetRoutes(
 
 			canary.Status.SessionAffinityCookie = ""
 		}
-		vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
+		stickyRoute.Name = stickyRouteName
+		vsCopy.Spec.Http = []*networkingv1beta1.HTTPRoute{
 			stickyRoute, weightedRoute,
 		}
 	}
 
 	if mirrored {
-		vsCopy.Spec.Http[0].Mirror = &istiov1alpha3.Destination{
-			Host: canaryName,
+		host, subset := destinationHost(canary, canaryName)
+		vsCopy.Spec.Http[0].Mirror = &networkingv1beta1.Destination{
+			Host:   host,
+			Subset: subset,
 		}
 
 		if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
-			vsCopy.Spec.Http[0].MirrorPercentage = &istiov1alpha3.Percent{Value: float64(mw)}
+			vsCopy.Spec.Http[0].MirrorPercentage = &networkingv1beta1.Percent{Value: float64(mw)}
 		}
 	}
 
 	// fix routing (A/B testing)
 	if len(canary.GetAnalysis().Match) > 0 {
 		// merge the common routes with the canary ones
-		canaryMatch := mergeMatchConditions(canary.GetAnalysis().Match, canary.Spec.Service.Match)
-		vsCopy.Spec.Http = []istiov1alpha3.HTTPRoute{
-			{
-				Match:      canaryMatch,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route: []istiov1alpha3.HTTPRouteDestination{
-					makeDestination(canary, primaryName, primaryWeight),
-					makeDestination(canary, canaryName, canaryWeight),
-				},
-			},
-			{
-				Match:      canary.Spec.Service.Match,
-				Rewrite:    canary.Spec.Service.Rewrite,
-				Timeout:    canary.Spec.Service.Timeout,
-				Retries:    canary.Spec.Service.Retries,
-				CorsPolicy: canary.Spec.Service.CorsPolicy,
-				Headers:    canary.Spec.Service.Headers,
-				Route: []istiov1alpha3.HTTPRouteDestination{
-					makeDestination(canary, primaryName, primaryWeight),
-				},
-			},
-		}
+		canaryMatch := mergeMatchConditions(renderClaimConditions(canary.GetAnalysis().Match), canary.Spec.Service.Match)
+		vsCopy.Spec.Http, err = makeABTestRoutes(canary, canaryMatch, []*networkingv1beta1.HTTPRouteDestination{
+			makeDestination(canary, primaryName, primaryWeight),
+			makeDestination(canary, canaryName, canaryWeight),
+		}, primaryWeight)
+	} else if len(canary.GetAnalysis().Localities) > 0 {
+		// shift traffic only for the localities the canary was expanded to
+		vsCopy.Spec.Http, err = makeLocalityRoutes(canary, primaryWeight, canaryWeight)
+	}
+	if err != nil {
+		return fmt.Errorf("VirtualService %s.%s %w", apexName, canary.Namespace, err)
 	}
 
-	vs, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
+	vs, err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Update(context.TODO(), vsCopy, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s update failed: %w", apexName, canary.Namespace, err)
 	}
+
+	for _, rr := range ir.remoteRouters() {
+		if err := rr.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
+			return fmt.Errorf("remote cluster %w", err)
+		}
+	}
 	return nil
 }
 
 func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
+	if ir.remote {
+		return ir.deleteRemoteObjects(canary)
+	}
+
+	if err := ir.FinalizeRemote(canary); err != nil {
+		return err
+	}
+
+	if err := ir.deleteGateway(canary); err != nil {
+		return err
+	}
+
 	// Need to see if I can get the annotation orig-configuration
 	apexName, _, _ := canary.GetServiceNames()
 
-	vs, err := ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	vs, err := ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s get query error: %w", apexName, canary.Namespace, err)
 	}
 
-	var storedSpec istiov1alpha3.VirtualServiceSpec
+	var storedSpec *networkingv1beta1.VirtualService
 	if a, ok := vs.ObjectMeta.Annotations[kubectlAnnotation]; ok {
-		var storedVS istiov1alpha3.VirtualService
+		var storedVS istiov1beta1.VirtualService
 		if err := json.Unmarshal([]byte(a), &storedVS); err != nil {
 			return fmt.Errorf("VirtualService %s.%s failed to unMarshal annotation %s",
 				apexName, canary.Namespace, kubectlAnnotation)
 		}
-		storedSpec = storedVS.Spec
+		storedSpec = &storedVS.Spec
 	} else if a, ok := vs.ObjectMeta.Annotations[configAnnotation]; ok {
-		if err := json.Unmarshal([]byte(a), &storedSpec); err != nil {
+		storedSpec = &networkingv1beta1.VirtualService{}
+		if err := json.Unmarshal([]byte(a), storedSpec); err != nil {
 			return fmt.Errorf("VirtualService %s.%s failed to unMarshal annotation %s",
 				apexName, canary.Namespace, configAnnotation)
 		}
@@ -589,15 +997,34 @@ func (ir *IstioRouter) Finalize(canary *flaggerv1.Canary) error {
 	}
 
 	clone := vs.DeepCopy()
-	clone.Spec = storedSpec
+	storedSpec.DeepCopyInto(&clone.Spec)
 
-	_, err = ir.istioClient.NetworkingV1alpha3().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	_, err = ir.istioClient.NetworkingV1beta1().VirtualServices(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("VirtualService %s.%s update error: %w", apexName, canary.Namespace, err)
 	}
 	return nil
 }
 
+// renderClaimConditions converts the JWT claims match rules to Istio header match conditions
+func renderClaimConditions(conditions []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
+	rendered := make([]istiov1alpha3.HTTPMatchRequest, len(conditions))
+	for i, c := range conditions {
+		rendered[i] = *c.DeepCopy()
+		if len(c.Claims) == 0 {
+			continue
+		}
+		if rendered[i].Headers == nil {
+			rendered[i].Headers = make(map[string]istiov1alpha1.StringMatch, len(c.Claims))
+		}
+		for name, match := range c.Claims {
+			rendered[i].Headers[fmt.Sprintf("@request.auth.claims.%s", name)] = match
+		}
+		rendered[i].Claims = nil
+	}
+	return rendered
+}
+
 // mergeMatchConditions appends the URI match rules to canary conditions
 func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []istiov1alpha3.HTTPMatchRequest {
 	if len(defaults) == 0 {
@@ -622,27 +1049,161 @@ func mergeMatchConditions(canary, defaults []istiov1alpha3.HTTPMatchRequest) []i
 	return merged
 }
 
+// makeHTTPRoute returns a route with the match conditions and destinations,
+// the rewrite, timeout, retries, CORS policy and headers are set from the canary service
+func makeHTTPRoute(canary *flaggerv1.Canary, match []istiov1alpha3.HTTPMatchRequest,
+	destinations []*networkingv1beta1.HTTPRouteDestination) (*networkingv1beta1.HTTPRoute, error) {
+	route := &networkingv1beta1.HTTPRoute{}
+	err := toIstioAPI(istiov1alpha3.HTTPRoute{
+		Match:      match,
+		Rewrite:    canary.Spec.Service.Rewrite,
+		Timeout:    canary.Spec.Service.Timeout,
+		Retries:    canary.Spec.Service.Retries,
+		CorsPolicy: canary.Spec.Service.CorsPolicy,
+		Headers:    canary.Spec.Service.Headers,
+	}, route)
+	if err != nil {
+		return nil, fmt.Errorf("route conversion error: %w", err)
+	}
+	route.Route = destinations
+	return route, nil
+}
+
+// makeABTestRoutes returns a route to the destinations for the requests matching the canary
+// conditions, and a route to primary for the rest of the requests
+func makeABTestRoutes(canary *flaggerv1.Canary, canaryMatch []istiov1alpha3.HTTPMatchRequest,
+	destinations []*networkingv1beta1.HTTPRouteDestination, primaryWeight int) ([]*networkingv1beta1.HTTPRoute, error) {
+	_, primaryName, _ := canary.GetServiceNames()
+
+	canaryRoute, err := makeHTTPRoute(canary, canaryMatch, destinations)
+	if err != nil {
+		return nil, err
+	}
+	primaryRoute, err := makeHTTPRoute(canary, canary.Spec.Service.Match, []*networkingv1beta1.HTTPRouteDestination{
+		makeDestination(canary, primaryName, primaryWeight),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*networkingv1beta1.HTTPRoute{canaryRoute, primaryRoute}, nil
+}
+
+// makeLocalityRoutes returns a weighted route for the requests coming from the current
+// and previous localities, and a route to primary for the requests from everywhere else
+func makeLocalityRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) ([]*networkingv1beta1.HTTPRoute, error) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	localities := canary.GetAnalysis().Localities
+	current := canary.Status.Locality
+	if current >= len(localities) {
+		current = len(localities) - 1
+	}
+
+	var localityMatch []istiov1alpha3.HTTPMatchRequest
+	for _, l := range localities[:current+1] {
+		localityMatch = append(localityMatch, l.Match...)
+	}
+
+	return makeABTestRoutes(canary, mergeMatchConditions(localityMatch, canary.Spec.Service.Match),
+		[]*networkingv1beta1.HTTPRouteDestination{
+			makeDestination(canary, primaryName, primaryWeight),
+			makeDestination(canary, canaryName, canaryWeight),
+		}, 100)
+}
+
+// toIstioAPI converts a value of the Canary API to the matching Istio API type,
+// the Canary API embeds the Istio routing types with the same JSON representation
+func toIstioAPI(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// destinationHost returns the host and subset of the primary or canary service,
+// when routing by subsets the destination is the apex service
+func destinationHost(canary *flaggerv1.Canary, name string) (string, string) {
+	if canary.Spec.Service.Subsets == nil {
+		return name, ""
+	}
+	apexName, primaryName, _ := canary.GetServiceNames()
+	if name == primaryName {
+		return apexName, primarySubset
+	}
+	return apexName, canarySubset
+}
+
+// destinationService returns the primary or canary service name of a destination
+func destinationService(canary *flaggerv1.Canary, dest *networkingv1beta1.Destination) string {
+	if canary.Spec.Service.Subsets == nil {
+		return dest.GetHost()
+	}
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	if dest.GetHost() != apexName {
+		return dest.GetHost()
+	}
+	switch dest.GetSubset() {
+	case primarySubset:
+		return primaryName
+	case canarySubset:
+		return canaryName
+	}
+	return dest.GetHost()
+}
+
+// baselineServiceName returns the name of the baseline service,
+// empty if the canary doesn't route traffic to a baseline
+func baselineServiceName(canary *flaggerv1.Canary) string {
+	if canary.GetAnalysis() == nil || !canary.GetAnalysis().Baseline || canary.Spec.Service.Subsets != nil {
+		return ""
+	}
+	if kind := canary.Spec.TargetRef.Kind; kind != "Deployment" && kind != "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-baseline", canary.Spec.TargetRef.Name)
+}
+
+// makeWeightedDestinations returns the primary and canary destinations,
+// when a baseline runs it receives the same share as the canary taken from the primary weight
+func makeWeightedDestinations(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) []*networkingv1beta1.HTTPRouteDestination {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	baselineName := baselineServiceName(canary)
+	if baselineName == "" || canaryWeight == 0 {
+		return []*networkingv1beta1.HTTPRouteDestination{
+			makeDestination(canary, primaryName, primaryWeight),
+			makeDestination(canary, canaryName, canaryWeight),
+		}
+	}
+
+	baselineWeight := canaryWeight
+	if baselineWeight > primaryWeight {
+		baselineWeight = primaryWeight
+	}
+	return []*networkingv1beta1.HTTPRouteDestination{
+		makeDestination(canary, primaryName, primaryWeight-baselineWeight),
+		makeDestination(canary, baselineName, baselineWeight),
+		makeDestination(canary, canaryName, canaryWeight),
+	}
+}
+
 // makeDestination returns a an destination weight for the specified host
-func makeDestination(canary *flaggerv1.Canary, host string, weight int) istiov1alpha3.HTTPRouteDestination {
-	dest := istiov1alpha3.HTTPRouteDestination{
-		Destination: istiov1alpha3.Destination{
-			Host: host,
+func makeDestination(canary *flaggerv1.Canary, name string, weight int) *networkingv1beta1.HTTPRouteDestination {
+	host, subset := destinationHost(canary, name)
+	dest := &networkingv1beta1.HTTPRouteDestination{
+		Destination: &networkingv1beta1.Destination{
+			Host:   host,
+			Subset: subset,
 		},
-		Weight: weight,
+		Weight: int32(weight),
 	}
 
 	// set destination port when an ingress gateway is specified
 	if canary.Spec.Service.PortDiscovery &&
 		(len(canary.Spec.Service.Gateways) > 0 &&
 			canary.Spec.Service.Gateways[0] != "mesh" || canary.Spec.Service.Delegation) {
-		dest = istiov1alpha3.HTTPRouteDestination{
-			Destination: istiov1alpha3.Destination{
-				Host: host,
-				Port: &istiov1alpha3.PortSelector{
-					Number: uint32(canary.Spec.Service.Port),
-				},
-			},
-			Weight: weight,
+		dest.Destination.Port = &networkingv1beta1.PortSelector{
+			Number: uint32(canary.Spec.Service.Port),
 		}
 	}
 
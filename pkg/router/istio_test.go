@@ -22,10 +22,17 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+	networkingv1beta1 "istio.io/api/networking/v1beta1"
+	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	fakeIstio "istio.io/client-go/pkg/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -38,7 +45,7 @@ func TestIstioRouter_Sync(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
@@ -46,13 +53,13 @@ func TestIstioRouter_Sync(t *testing.T) {
 	require.NoError(t, err)
 
 	// test insert
-	_, err = mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	_, err = mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
 
-	_, err = mocks.meshClient.NetworkingV1alpha3().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	_, err = mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
 	require.NoError(t, err)
 
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	require.Len(t, vs.Spec.Http, 1)
 	require.Len(t, vs.Spec.Http[0].Route, 2)
@@ -73,7 +80,7 @@ func TestIstioRouter_Sync(t *testing.T) {
 	require.NoError(t, err)
 
 	// verify
-	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Len(t, vs.Spec.Hosts, 2)
 
@@ -84,7 +91,7 @@ func TestIstioRouter_Sync(t *testing.T) {
 	vsClone.Spec.Gateways = gateways
 	totalGateways := len(mocks.canary.Spec.Service.Gateways)
 
-	vsGateways, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Update(context.TODO(), vsClone, metav1.UpdateOptions{})
+	vsGateways, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Update(context.TODO(), vsClone, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
 	totalGateways++
@@ -96,7 +103,7 @@ func TestIstioRouter_Sync(t *testing.T) {
 	require.NoError(t, err)
 
 	// verify
-	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Len(t, vs.Spec.Gateways, totalGateways)
 }
@@ -106,7 +113,7 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
@@ -121,11 +128,11 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		err := router.SetRoutes(mocks.canary, p, c, false)
 		require.NoError(t, err)
 
-		vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
-		var pRoute, cRoute istiov1alpha3.HTTPRouteDestination
-		var mirror *istiov1alpha3.Destination
+		var pRoute, cRoute *networkingv1beta1.HTTPRouteDestination
+		var mirror *networkingv1beta1.Destination
 		for _, http := range vs.Spec.Http {
 			for _, route := range http.Route {
 				if route.Destination.Host == pHost {
@@ -138,8 +145,8 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 			}
 		}
 
-		assert.Equal(t, p, pRoute.Weight)
-		assert.Equal(t, c, cRoute.Weight)
+		assert.Equal(t, int32(p), pRoute.Weight)
+		assert.Equal(t, int32(c), cRoute.Weight)
 		assert.Nil(t, mirror)
 
 	})
@@ -154,7 +161,7 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		}
 		err := router.SetRoutes(canary, 0, 10, false)
 
-		vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
 		assert.Len(t, vs.Spec.Http, 2)
@@ -166,13 +173,13 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		for _, match := range stickyRoute.Match {
 			if val, ok := match.Headers[cookieHeader]; ok {
 				found = true
-				assert.True(t, strings.Contains(val.Regex, cookieKey))
+				assert.True(t, strings.Contains(val.GetRegex(), cookieKey))
 				for _, routeDest := range stickyRoute.Route {
 					if routeDest.Destination.Host == pHost {
-						assert.Equal(t, 0, routeDest.Weight)
+						assert.Equal(t, int32(0), routeDest.Weight)
 					}
 					if routeDest.Destination.Host == cHost {
-						assert.Equal(t, 100, routeDest.Weight)
+						assert.Equal(t, int32(100), routeDest.Weight)
 					}
 				}
 			}
@@ -183,10 +190,10 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		// for all responses returned from the canary deployment.
 		for _, routeDest := range weightedRoute.Route {
 			if routeDest.Destination.Host == pHost {
-				assert.Equal(t, 0, routeDest.Weight)
+				assert.Equal(t, int32(0), routeDest.Weight)
 			}
 			if routeDest.Destination.Host == cHost {
-				assert.Equal(t, 10, routeDest.Weight)
+				assert.Equal(t, int32(10), routeDest.Weight)
 				val, ok := routeDest.Headers.Response.Add[setCookieHeader]
 				assert.True(t, ok)
 				assert.True(t, strings.HasPrefix(val, cookieKey))
@@ -199,20 +206,20 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		err = router.Reconcile(canary)
 		require.NoError(t, err)
 
-		reconciledVS, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		reconciledVS, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
 		// routes should not be changed.
 		assert.Len(t, vs.Spec.Http, 2)
 		assert.NotNil(t, reconciledVS)
-		assert.Equal(t, cmp.Diff(reconciledVS.Spec.Http[0], stickyRoute), "")
-		assert.Equal(t, cmp.Diff(reconciledVS.Spec.Http[1], weightedRoute), "")
+		assert.Equal(t, cmp.Diff(reconciledVS.Spec.Http[0], stickyRoute, protocmp.Transform()), "")
+		assert.Equal(t, cmp.Diff(reconciledVS.Spec.Http[1], weightedRoute, protocmp.Transform()), "")
 
 		// further continue the canary run
 		err = router.SetRoutes(canary, 50, 50, false)
 		require.NoError(t, err)
 
-		vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
 		assert.Len(t, vs.Spec.Http, 2)
@@ -223,13 +230,13 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		for _, match := range stickyRoute.Match {
 			if val, ok := match.Headers[cookieHeader]; ok {
 				found = true
-				assert.True(t, strings.Contains(val.Regex, cookieKey))
+				assert.True(t, strings.Contains(val.GetRegex(), cookieKey))
 				for _, routeDest := range stickyRoute.Route {
 					if routeDest.Destination.Host == pHost {
-						assert.Equal(t, 0, routeDest.Weight)
+						assert.Equal(t, int32(0), routeDest.Weight)
 					}
 					if routeDest.Destination.Host == cHost {
-						assert.Equal(t, 100, routeDest.Weight)
+						assert.Equal(t, int32(100), routeDest.Weight)
 					}
 				}
 			}
@@ -238,10 +245,10 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 
 		for _, routeDest := range weightedRoute.Route {
 			if routeDest.Destination.Host == pHost {
-				assert.Equal(t, 50, routeDest.Weight)
+				assert.Equal(t, int32(50), routeDest.Weight)
 			}
 			if routeDest.Destination.Host == cHost {
-				assert.Equal(t, 50, routeDest.Weight)
+				assert.Equal(t, int32(50), routeDest.Weight)
 				val, ok := routeDest.Headers.Response.Add[setCookieHeader]
 				assert.True(t, ok)
 				assert.True(t, strings.HasPrefix(val, cookieKey))
@@ -255,7 +262,7 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		err = router.SetRoutes(canary, 100, 0, false)
 		require.NoError(t, err)
 
-		vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
 		assert.Len(t, vs.Spec.Http, 2)
@@ -266,13 +273,13 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		for _, match := range stickyRoute.Match {
 			if val, ok := match.Headers[cookieHeader]; ok {
 				found = true
-				assert.True(t, strings.Contains(val.Regex, cookieKey))
+				assert.True(t, strings.Contains(val.GetRegex(), cookieKey))
 				for _, routeDest := range stickyRoute.Route {
 					if routeDest.Destination.Host == pHost {
-						assert.Equal(t, 100, routeDest.Weight)
+						assert.Equal(t, int32(100), routeDest.Weight)
 					}
 					if routeDest.Destination.Host == cHost {
-						assert.Equal(t, 0, routeDest.Weight)
+						assert.Equal(t, int32(0), routeDest.Weight)
 					}
 				}
 			}
@@ -290,10 +297,10 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 		// delete the Set-Cookie header from responses returned by the weighted route
 		for _, routeDest := range weightedRoute.Route {
 			if routeDest.Destination.Host == pHost {
-				assert.Equal(t, 100, routeDest.Weight)
+				assert.Equal(t, int32(100), routeDest.Weight)
 			}
 			if routeDest.Destination.Host == cHost {
-				assert.Equal(t, 0, routeDest.Weight)
+				assert.Equal(t, int32(0), routeDest.Weight)
 				if routeDest.Headers != nil && routeDest.Headers.Response != nil {
 					_, ok := routeDest.Headers.Response.Add[setCookieHeader]
 					assert.False(t, ok)
@@ -311,12 +318,12 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 			err := router.SetRoutes(mocks.canary, p, c, true)
 			require.NoError(t, err)
 
-			vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+			vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 			require.NoError(t, err)
 
-			var pRoute, cRoute istiov1alpha3.HTTPRouteDestination
-			var mirror *istiov1alpha3.Destination
-			var mirrorWeight *istiov1alpha3.Percent
+			var pRoute, cRoute *networkingv1beta1.HTTPRouteDestination
+			var mirror *networkingv1beta1.Destination
+			var mirrorWeight *networkingv1beta1.Percent
 			for _, http := range vs.Spec.Http {
 				for _, route := range http.Route {
 					if route.Destination.Host == pHost {
@@ -330,8 +337,8 @@ func TestIstioRouter_SetRoutes(t *testing.T) {
 				}
 			}
 
-			assert.Equal(t, p, pRoute.Weight)
-			assert.Equal(t, c, cRoute.Weight)
+			assert.Equal(t, int32(p), pRoute.Weight)
+			assert.Equal(t, int32(c), cRoute.Weight)
 			if assert.NotNil(t, mirror) {
 				assert.Equal(t, cHost, mirror.Host)
 			}
@@ -350,7 +357,7 @@ func TestIstioRouter_GetRoutes(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
@@ -378,20 +385,20 @@ func TestIstioRouter_GetRoutes(t *testing.T) {
 	assert.False(t, m)
 
 	// Adjust vs to activate mirroring.
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 
 	cHost := fmt.Sprintf("%s-canary", mocks.canary.Spec.TargetRef.Name)
 	for i, http := range vs.Spec.Http {
 		for _, route := range http.Route {
 			if route.Destination.Host == cHost {
-				vs.Spec.Http[i].Mirror = &istiov1alpha3.Destination{
+				vs.Spec.Http[i].Mirror = &networkingv1beta1.Destination{
 					Host: cHost,
 				}
 			}
 		}
 	}
-	_, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices(mocks.canary.Namespace).Update(context.TODO(), vs, metav1.UpdateOptions{})
+	_, err = mocks.istioClient.NetworkingV1beta1().VirtualServices(mocks.canary.Namespace).Update(context.TODO(), vs, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
 	p, c, m, err = router.GetRoutes(mocks.canary)
@@ -406,14 +413,14 @@ func TestIstioRouter_HTTPRequestHeaders(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
 	err := router.Reconcile(mocks.canary)
 	require.NoError(t, err)
 
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	require.Len(t, vs.Spec.Http, 1)
 	assert.Equal(t, "15000", vs.Spec.Http[0].Headers.Request.Add["x-envoy-upstream-rq-timeout-ms"])
@@ -426,14 +433,14 @@ func TestIstioRouter_CORS(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
 	err := router.Reconcile(mocks.canary)
 	require.NoError(t, err)
 
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 	require.NoError(t, err)
 	require.Len(t, vs.Spec.Http, 1)
@@ -441,12 +448,124 @@ func TestIstioRouter_CORS(t *testing.T) {
 	assert.Len(t, vs.Spec.Http[0].CorsPolicy.AllowMethods, 2)
 }
 
+func TestIstioRouter_Retries(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	retryRemoteLocalities := true
+	mocks.canary.Spec.Service.Timeout = "1m"
+	mocks.canary.Spec.Service.Retries = &istiov1alpha3.HTTPRetry{
+		Attempts:              3,
+		PerTryTimeout:         "1m30s",
+		RetryOn:               "gateway-error",
+		RetryRemoteLocalities: &retryRemoteLocalities,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 1)
+	assert.Equal(t, time.Minute, vs.Spec.Http[0].Timeout.AsDuration())
+	require.NotNil(t, vs.Spec.Http[0].Retries)
+	assert.Equal(t, int32(3), vs.Spec.Http[0].Retries.Attempts)
+	assert.Equal(t, 90*time.Second, vs.Spec.Http[0].Retries.PerTryTimeout.AsDuration())
+	assert.True(t, vs.Spec.Http[0].Retries.RetryRemoteLocalities.GetValue())
+}
+
+func TestIstioRouter_Gateway(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Service.Hosts = []string{"app.example.com"}
+	mocks.canary.Spec.Service.Gateways = nil
+	mocks.canary.Spec.Service.Gateway = &v1beta1.IstioGateway{
+		TLS: &istiov1alpha3.ServerTLSSettings{
+			Mode:           "SIMPLE",
+			CredentialName: "app-cert",
+			HttpsRedirect:  true,
+		},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	gw, err := mocks.istioClient.NetworkingV1beta1().Gateways("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ingressgateway", gw.Spec.Selector["istio"])
+	require.Len(t, gw.Spec.Servers, 2)
+	assert.Equal(t, uint32(443), gw.Spec.Servers[0].Port.Number)
+	assert.Equal(t, "app-cert", gw.Spec.Servers[0].Tls.CredentialName)
+	assert.Equal(t, []string{"app.example.com"}, gw.Spec.Servers[0].Hosts)
+	assert.True(t, gw.Spec.Servers[1].Tls.HttpsRedirect)
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"podinfo", "mesh"}, vs.Spec.Gateways)
+
+	// update hosts
+	mocks.canary.Spec.Service.Hosts = []string{"app.example.com", "www.example.com"}
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	gw, err = mocks.istioClient.NetworkingV1beta1().Gateways("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, gw.Spec.Servers[0].Hosts, 2)
+
+	// the finalizer removes the generated gateway
+	err = router.Finalize(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.istioClient.NetworkingV1beta1().Gateways("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestIstioRouter_GatewayNotOwned(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Service.Hosts = []string{"app.example.com"}
+	mocks.canary.Spec.Service.Gateways = nil
+	mocks.canary.Spec.Service.Gateway = &v1beta1.IstioGateway{}
+
+	_, err := mocks.istioClient.NetworkingV1beta1().Gateways("default").Create(context.TODO(), &istiov1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: networkingv1beta1.Gateway{
+			Selector: map[string]string{"istio": "custom"},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(mocks.canary)
+	require.Error(t, err)
+
+	gw, err := mocks.istioClient.NetworkingV1beta1().Gateways("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", gw.Spec.Selector["istio"])
+}
+
 func TestIstioRouter_ABTest(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
@@ -454,7 +573,7 @@ func TestIstioRouter_ABTest(t *testing.T) {
 	require.NoError(t, err)
 
 	// test insert
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Len(t, vs.Spec.Http, 2)
 
@@ -465,14 +584,13 @@ func TestIstioRouter_ABTest(t *testing.T) {
 	err = router.SetRoutes(mocks.abtest, p, c, m)
 	require.NoError(t, err)
 
-	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
 	require.NoError(t, err)
 
 	pHost := fmt.Sprintf("%s-primary", mocks.abtest.Spec.TargetRef.Name)
 	cHost := fmt.Sprintf("%s-canary", mocks.abtest.Spec.TargetRef.Name)
-	pRoute := istiov1alpha3.HTTPRouteDestination{}
-	cRoute := istiov1alpha3.HTTPRouteDestination{}
-	var mirror *istiov1alpha3.Destination
+	var pRoute, cRoute *networkingv1beta1.HTTPRouteDestination
+	var mirror *networkingv1beta1.Destination
 
 	for _, http := range vs.Spec.Http {
 		for _, route := range http.Route {
@@ -486,24 +604,304 @@ func TestIstioRouter_ABTest(t *testing.T) {
 		}
 	}
 
-	assert.Equal(t, p, pRoute.Weight)
-	assert.Equal(t, c, cRoute.Weight)
+	assert.Equal(t, int32(p), pRoute.Weight)
+	assert.Equal(t, int32(c), cRoute.Weight)
 	assert.Nil(t, mirror)
 }
 
+func TestIstioRouter_Localities(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Analysis.Localities = []v1beta1.CanaryLocality{
+		{
+			Name: "us-east-1a",
+			Match: []istiov1alpha3.HTTPMatchRequest{
+				{SourceLabels: map[string]string{"topology.kubernetes.io/zone": "us-east-1a"}},
+			},
+		},
+		{
+			Name: "us-east-1b",
+			Match: []istiov1alpha3.HTTPMatchRequest{
+				{SourceLabels: map[string]string{"topology.kubernetes.io/zone": "us-east-1b"}},
+			},
+		},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	assert.Len(t, vs.Spec.Http[0].Match, 1)
+
+	// expand to the second locality
+	mocks.canary.Status.Locality = 1
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	assert.Len(t, vs.Spec.Http[0].Match, 2)
+	assert.Equal(t, int32(40), vs.Spec.Http[0].Route[1].Weight)
+	assert.Equal(t, int32(100), vs.Spec.Http[1].Route[0].Weight)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestIstioRouter_Baseline(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.canary.Spec.Analysis.Baseline = true
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 80, 20, false)
+	require.NoError(t, err)
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http[0].Route, 3)
+	assert.Equal(t, "podinfo-primary", vs.Spec.Http[0].Route[0].Destination.Host)
+	assert.Equal(t, int32(60), vs.Spec.Http[0].Route[0].Weight)
+	assert.Equal(t, "podinfo-baseline", vs.Spec.Http[0].Route[1].Destination.Host)
+	assert.Equal(t, int32(20), vs.Spec.Http[0].Route[1].Weight)
+	assert.Equal(t, int32(20), vs.Spec.Http[0].Route[2].Weight)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 80, p)
+	assert.Equal(t, 20, c)
+
+	// the baseline is removed from the routes when the canary gets no traffic
+	err = router.SetRoutes(mocks.canary, 100, 0, false)
+	require.NoError(t, err)
+
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http[0].Route, 2)
+	assert.Equal(t, int32(100), vs.Spec.Http[0].Route[0].Weight)
+}
+
+func TestIstioRouter_ABTestClaims(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	mocks.abtest.Spec.Analysis.Match = []istiov1alpha3.HTTPMatchRequest{
+		{
+			Claims: map[string]istiov1alpha1.StringMatch{
+				"groups": {Exact: "beta-testers"},
+			},
+		},
+	}
+
+	err := router.Reconcile(mocks.abtest)
+	require.NoError(t, err)
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http, 2)
+	require.Len(t, vs.Spec.Http[0].Match, 1)
+	assert.Equal(t, "beta-testers", vs.Spec.Http[0].Match[0].Headers["@request.auth.claims.groups"].GetExact())
+}
+
+func TestIstioRouter_RemoteClusters(t *testing.T) {
+	mocks := newFixture(nil)
+	remoteClient := fakeIstio.NewSimpleClientset()
+	router := &IstioRouter{
+		logger:             mocks.logger,
+		flaggerClient:      mocks.flaggerClient,
+		istioClient:        mocks.istioClient,
+		kubeClient:         mocks.kubeClient,
+		setOwnerRefs:       true,
+		remoteIstioClients: []istioclientset.Interface{remoteClient},
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = remoteClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = remoteClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	vs, err := remoteClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, vs.OwnerReferences)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	remoteRouter := router.remoteRouters()[0]
+	p, c, _, err := remoteRouter.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	// the remote objects are deleted on finalize
+	err = router.Finalize(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = remoteClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = remoteClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = remoteClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestIstioRouter_RemoteClustersServiceEntry(t *testing.T) {
+	mocks := newFixture(nil)
+	remoteClient := fakeIstio.NewSimpleClientset()
+	router := &IstioRouter{
+		logger:             mocks.logger,
+		flaggerClient:      mocks.flaggerClient,
+		istioClient:        mocks.istioClient,
+		kubeClient:         mocks.kubeClient,
+		setOwnerRefs:       true,
+		remoteIstioClients: []istioclientset.Interface{remoteClient},
+		eastWestGateway:    "10.0.0.10",
+		meshNetwork:        "network1",
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// service entries are generated only in the remote clusters
+	_, err = mocks.istioClient.NetworkingV1beta1().ServiceEntries("default").Get(context.TODO(), "podinfo-east-west", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	se, err := remoteClient.NetworkingV1beta1().ServiceEntries("default").Get(context.TODO(), "podinfo-east-west", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"podinfo-primary.default.svc.cluster.local", "podinfo-canary.default.svc.cluster.local"}, se.Spec.Hosts)
+	assert.Equal(t, networkingv1beta1.ServiceEntry_MESH_INTERNAL, se.Spec.Location)
+	assert.Equal(t, networkingv1beta1.ServiceEntry_STATIC, se.Spec.Resolution)
+	require.Len(t, se.Spec.Endpoints, 1)
+	assert.Equal(t, "10.0.0.10", se.Spec.Endpoints[0].Address)
+	assert.Equal(t, "network1", se.Spec.Endpoints[0].Network)
+	assert.Equal(t, uint32(eastWestGatewayPort), se.Spec.Endpoints[0].Ports["http"])
+
+	// update the gateway address
+	router.eastWestGateway = "eastwest.cluster1.example.com"
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	se, err = remoteClient.NetworkingV1beta1().ServiceEntries("default").Get(context.TODO(), "podinfo-east-west", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, networkingv1beta1.ServiceEntry_DNS, se.Spec.Resolution)
+	assert.Equal(t, "eastwest.cluster1.example.com", se.Spec.Endpoints[0].Address)
+
+	err = router.FinalizeRemote(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = remoteClient.NetworkingV1beta1().ServiceEntries("default").Get(context.TODO(), "podinfo-east-west", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestIstioRouter_Subsets(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IstioRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		istioClient:   mocks.istioClient,
+		kubeClient:    mocks.kubeClient,
+		labelSelector: "app.kubernetes.io/name",
+		labelValue:    "podinfo-app",
+	}
+
+	// reconcile the per-service destination rules before switching to subsets
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	mocks.canary.Spec.Service.Subsets = &v1beta1.IstioSubsets{
+		Selector: map[string]string{"app.kubernetes.io/name": "podinfo"},
+	}
+
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	// the subsets select the pods by the workload label value
+	dr, err := mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", dr.Spec.Host)
+	require.Len(t, dr.Spec.Subsets, 2)
+	assert.Equal(t, map[string]string{"app.kubernetes.io/name": "podinfo-app-primary"}, dr.Spec.Subsets[0].Labels)
+	assert.Equal(t, map[string]string{"app.kubernetes.io/name": "podinfo-app"}, dr.Spec.Subsets[1].Labels)
+
+	// the destination rules of the previous layout are deleted
+	_, err = mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+	_, err = mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, vs.Spec.Http[0].Route, 2)
+	assert.Equal(t, "podinfo", vs.Spec.Http[0].Route[0].Destination.Host)
+	assert.Equal(t, primarySubset, vs.Spec.Http[0].Route[0].Destination.Subset)
+	assert.Equal(t, "podinfo", vs.Spec.Http[0].Route[1].Destination.Host)
+	assert.Equal(t, canarySubset, vs.Spec.Http[0].Route[1].Destination.Subset)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, true)
+	require.NoError(t, err)
+
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, vs.Spec.Http[0].Mirror)
+	assert.Equal(t, canarySubset, vs.Spec.Http[0].Mirror.Subset)
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+	assert.True(t, m)
+
+	// the generated apex destination rule is deleted when the subsets are disabled
+	mocks.canary.Spec.Service.Subsets = nil
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+	_, err = mocks.istioClient.NetworkingV1beta1().DestinationRules("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+}
+
 func TestIstioRouter_GatewayPort(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
 	err := router.Reconcile(mocks.canary)
 	require.NoError(t, err)
 
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
 
 	port := vs.Spec.Http[0].Route[0].Destination.Port.Number
@@ -520,14 +918,14 @@ func TestIstioRouter_Delegate(t *testing.T) {
 		router := &IstioRouter{
 			logger:        mocks.logger,
 			flaggerClient: mocks.flaggerClient,
-			istioClient:   mocks.meshClient,
+			istioClient:   mocks.istioClient,
 			kubeClient:    mocks.kubeClient,
 		}
 
 		err := router.Reconcile(mocks.canary)
 		require.NoError(t, err)
 
-		vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 		require.NoError(t, err)
 
 		assert.Equal(t, 0, len(vs.Spec.Hosts))
@@ -551,7 +949,7 @@ func TestIstioRouter_Delegate(t *testing.T) {
 		router := &IstioRouter{
 			logger:        mocks.logger,
 			flaggerClient: mocks.flaggerClient,
-			istioClient:   mocks.meshClient,
+			istioClient:   mocks.istioClient,
 			kubeClient:    mocks.kubeClient,
 		}
 
@@ -565,31 +963,40 @@ func TestIstioRouter_Finalize(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
-	flaggerSpec := &istiov1alpha3.VirtualServiceSpec{
-		Http: []istiov1alpha3.HTTPRoute{
+	flaggerSpec := &networkingv1beta1.VirtualService{
+		Hosts: []string{"podinfo"},
+		Http: []*networkingv1beta1.HTTPRoute{
 			{
-				Match:      mocks.canary.Spec.Service.Match,
-				Rewrite:    mocks.canary.Spec.Service.Rewrite,
-				Timeout:    mocks.canary.Spec.Service.Timeout,
-				Retries:    mocks.canary.Spec.Service.Retries,
-				CorsPolicy: mocks.canary.Spec.Service.CorsPolicy,
+				Match: []*networkingv1beta1.HTTPMatchRequest{
+					{
+						Uri: &networkingv1beta1.StringMatch{
+							MatchType: &networkingv1beta1.StringMatch_Prefix{Prefix: "/podinfo"},
+						},
+					},
+				},
+				Retries: &networkingv1beta1.HTTPRetry{Attempts: 10, RetryOn: "connect-failure"},
+				Route: []*networkingv1beta1.HTTPRouteDestination{
+					{
+						Destination: &networkingv1beta1.Destination{Host: "podinfo"},
+					},
+				},
 			},
 		},
 	}
 
-	kubectlSpec := &istiov1alpha3.VirtualServiceSpec{
+	kubectlSpec := &networkingv1beta1.VirtualService{
 		Hosts:    []string{"podinfo"},
 		Gateways: []string{"ingressgateway.istio-system.svc.cluster.local"},
-		Http: []istiov1alpha3.HTTPRoute{
+		Http: []*networkingv1beta1.HTTPRoute{
 			{
 				Match: nil,
-				Route: []istiov1alpha3.HTTPRouteDestination{
+				Route: []*networkingv1beta1.HTTPRouteDestination{
 					{
-						Destination: istiov1alpha3.Destination{Host: "podinfo"},
+						Destination: &networkingv1beta1.Destination{Host: "podinfo"},
 					},
 				},
 			},
@@ -598,7 +1005,7 @@ func TestIstioRouter_Finalize(t *testing.T) {
 
 	tables := []struct {
 		router        *IstioRouter
-		spec          *istiov1alpha3.VirtualServiceSpec
+		spec          *networkingv1beta1.VirtualService
 		shouldError   bool
 		createVS      bool
 		canary        *v1beta1.Canary
@@ -618,7 +1025,7 @@ func TestIstioRouter_Finalize(t *testing.T) {
 	for _, table := range tables {
 		var err error
 		if table.createVS {
-			vs, err := router.istioClient.NetworkingV1alpha3().VirtualServices(table.canary.Namespace).Get(context.TODO(), table.canary.Name, metav1.GetOptions{})
+			vs, err := router.istioClient.NetworkingV1beta1().VirtualServices(table.canary.Namespace).Get(context.TODO(), table.canary.Name, metav1.GetOptions{})
 			require.NoError(t, err)
 
 			if vs.Annotations == nil {
@@ -631,9 +1038,9 @@ func TestIstioRouter_Finalize(t *testing.T) {
 				require.NoError(t, err)
 				vs.Annotations[configAnnotation] = string(b)
 			case "kubectl":
-				vs.Annotations[kubectlAnnotation] = `{"apiVersion": "networking.istio.io/v1alpha3","kind": "VirtualService","metadata": {"annotations": {},"name": "podinfo","namespace": "test"},  "spec": {"gateways": ["ingressgateway.istio-system.svc.cluster.local"],"hosts": ["podinfo"],"http": [{"route": [{"destination": {"host": "podinfo"}}]}]}}`
+				vs.Annotations[kubectlAnnotation] = `{"apiVersion": "networking.istio.io/v1beta1","kind": "VirtualService","metadata": {"annotations": {},"name": "podinfo","namespace": "test"},  "spec": {"gateways": ["ingressgateway.istio-system.svc.cluster.local"],"hosts": ["podinfo"],"http": [{"route": [{"destination": {"host": "podinfo"}}]}]}}`
 			}
-			_, err = router.istioClient.NetworkingV1alpha3().VirtualServices(table.canary.Namespace).Update(context.TODO(), vs, metav1.UpdateOptions{})
+			_, err = router.istioClient.NetworkingV1beta1().VirtualServices(table.canary.Namespace).Update(context.TODO(), vs, metav1.UpdateOptions{})
 			require.NoError(t, err)
 		}
 
@@ -650,9 +1057,9 @@ func TestIstioRouter_Finalize(t *testing.T) {
 		}
 
 		if table.spec != nil {
-			vs, err := router.istioClient.NetworkingV1alpha3().VirtualServices(table.canary.Namespace).Get(context.TODO(), table.canary.Name, metav1.GetOptions{})
+			vs, err := router.istioClient.NetworkingV1beta1().VirtualServices(table.canary.Namespace).Get(context.TODO(), table.canary.Name, metav1.GetOptions{})
 			require.NoError(t, err)
-			require.Equal(t, *table.spec, vs.Spec)
+			require.Empty(t, cmp.Diff(table.spec, &vs.Spec, protocmp.Transform()))
 		}
 	}
 }
@@ -662,7 +1069,7 @@ func TestIstioRouter_Match(t *testing.T) {
 	router := &IstioRouter{
 		logger:        mocks.logger,
 		flaggerClient: mocks.flaggerClient,
-		istioClient:   mocks.meshClient,
+		istioClient:   mocks.istioClient,
 		kubeClient:    mocks.kubeClient,
 	}
 
@@ -671,11 +1078,11 @@ func TestIstioRouter_Match(t *testing.T) {
 	require.NoError(t, err)
 
 	// test insert
-	vs, err := mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	vs, err := mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Len(t, vs.Spec.Http, 2)
 	assert.Len(t, vs.Spec.Http[0].Match, 1) // check for abtest-canary
-	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-user-type"].Exact, "test")
+	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-user-type"].GetExact(), "test")
 	assert.Len(t, vs.Spec.Http[1].Match, 0) // check for abtest-primary
 
 	// Test Case that is service.match exists and multiple analysis.match
@@ -715,15 +1122,15 @@ func TestIstioRouter_Match(t *testing.T) {
 	err = router.Reconcile(mocks.abtest)
 	require.NoError(t, err)
 
-	vs, err = mocks.meshClient.NetworkingV1alpha3().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	vs, err = mocks.istioClient.NetworkingV1beta1().VirtualServices("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Len(t, vs.Spec.Http, 2)
 	assert.Len(t, vs.Spec.Http[0].Match, 2) // check for abtest-canary
-	require.Equal(t, vs.Spec.Http[0].Match[0].Uri.Prefix, "/podinfo")
-	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-user-type"].Exact, "test")
-	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-auth-test"].Exact, "test")
-	require.Equal(t, vs.Spec.Http[0].Match[1].Uri.Prefix, "/podinfo")
-	require.Equal(t, vs.Spec.Http[0].Match[1].Headers["x-session-id"].Exact, "test")
+	require.Equal(t, vs.Spec.Http[0].Match[0].Uri.GetPrefix(), "/podinfo")
+	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-user-type"].GetExact(), "test")
+	require.Equal(t, vs.Spec.Http[0].Match[0].Headers["x-auth-test"].GetExact(), "test")
+	require.Equal(t, vs.Spec.Http[0].Match[1].Uri.GetPrefix(), "/podinfo")
+	require.Equal(t, vs.Spec.Http[0].Match[1].Headers["x-session-id"].GetExact(), "test")
 	assert.Len(t, vs.Spec.Http[1].Match, 1) // check for abtest-primary
-	require.Equal(t, vs.Spec.Http[1].Match[0].Uri.Prefix, "/podinfo")
+	require.Equal(t, vs.Spec.Http[1].Match[0].Uri.GetPrefix(), "/podinfo")
 }
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// KnativeRouter is managing the traffic targets of Knative services,
+// the traffic is split between the primary revision and the latest revision
+type KnativeRouter struct {
+	knativeClient clientset.Interface
+	logger        *zap.SugaredLogger
+}
+
+// Reconcile routes all the traffic to the primary revision
+// if the service traffic is not managed by Flagger yet
+func (kr *KnativeRouter) Reconcile(canary *flaggerv1.Canary) error {
+	service, err := kr.getService(canary)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := kr.weights(service); err == nil {
+		return nil
+	}
+
+	if err := kr.setTraffic(canary, 100, 0); err != nil {
+		return err
+	}
+
+	kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Knative service %s.%s traffic routed to the primary revision", service.Name, canary.Namespace)
+	return nil
+}
+
+// GetRoutes returns the traffic percentages of the primary and latest revisions
+func (kr *KnativeRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	service, err := kr.getService(canary)
+	if err != nil {
+		return
+	}
+
+	primaryWeight, canaryWeight, err = kr.weights(service)
+	return
+}
+
+// SetRoutes updates the traffic percentages of the primary and latest revisions
+func (kr *KnativeRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("knative service %s.%s update failed: no valid weights", canary.Spec.TargetRef.Name, canary.Namespace)
+	}
+
+	return kr.setTraffic(canary, primaryWeight, canaryWeight)
+}
+
+// Finalize routes all the traffic to the latest revision
+func (kr *KnativeRouter) Finalize(canary *flaggerv1.Canary) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		service, err := kr.getService(canary)
+		if err != nil {
+			return err
+		}
+
+		latest := true
+		percent := int64(100)
+		clone := service.DeepCopy()
+		clone.Spec.Traffic = []knativev1.TrafficTarget{
+			{LatestRevision: &latest, Percent: &percent},
+		}
+
+		_, err = kr.knativeClient.KnativeV1().Services(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s update error: %w", canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (kr *KnativeRouter) setTraffic(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		service, err := kr.getService(canary)
+		if err != nil {
+			return err
+		}
+
+		primaryRevision := service.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+		if primaryRevision == "" {
+			return fmt.Errorf("knative service %s.%s primary revision not found", service.Name, canary.Namespace)
+		}
+
+		latest := true
+		pw := int64(primaryWeight)
+		cw := int64(canaryWeight)
+		clone := service.DeepCopy()
+		clone.Spec.Traffic = []knativev1.TrafficTarget{
+			{RevisionName: primaryRevision, Percent: &pw},
+			{LatestRevision: &latest, Percent: &cw},
+		}
+
+		_, err = kr.knativeClient.KnativeV1().Services(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("knative service %s.%s update error: %w", canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// weights returns the percentages of the traffic targets set by Flagger
+func (kr *KnativeRouter) weights(service *knativev1.Service) (primaryWeight int, canaryWeight int, err error) {
+	primaryRevision := service.Annotations[flaggerv1.KnativePrimaryRevisionAnnotation]
+
+	var primaryFound, canaryFound bool
+	for _, target := range service.Spec.Traffic {
+		switch {
+		case target.LatestRevision != nil && *target.LatestRevision:
+			canaryFound = true
+			if target.Percent != nil {
+				canaryWeight = int(*target.Percent)
+			}
+		case primaryRevision != "" && target.RevisionName == primaryRevision:
+			primaryFound = true
+			if target.Percent != nil {
+				primaryWeight = int(*target.Percent)
+			}
+		}
+	}
+
+	if !primaryFound || !canaryFound {
+		err = fmt.Errorf("knative service %s.%s traffic targets not found", service.Name, service.Namespace)
+	}
+	return
+}
+
+func (kr *KnativeRouter) getService(canary *flaggerv1.Canary) (*knativev1.Service, error) {
+	service, err := kr.knativeClient.KnativeV1().Services(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("knative service %s.%s get query error: %w", canary.Spec.TargetRef.Name, canary.Namespace, err)
+	}
+	return service, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	knativev1 "github.com/fluxcd/flagger/pkg/apis/knative/v1"
+)
+
+func TestKnativeRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.TargetRef = flaggerv1.LocalObjectReference{
+		Name:       "podinfo",
+		APIVersion: "serving.knative.dev/v1",
+		Kind:       "Service",
+	}
+	_, err := mocks.flaggerClient.KnativeV1().Services("default").Create(context.TODO(), &knativev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "podinfo",
+			Namespace:   "default",
+			Annotations: map[string]string{flaggerv1.KnativePrimaryRevisionAnnotation: "podinfo-00001"},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &KnativeRouter{
+		logger:        mocks.logger,
+		knativeClient: mocks.flaggerClient,
+	}
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	svc, err := mocks.flaggerClient.KnativeV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Traffic, 2)
+	assert.Equal(t, "podinfo-00001", svc.Spec.Traffic[0].RevisionName)
+	assert.True(t, *svc.Spec.Traffic[1].LatestRevision)
+
+	p, c, m, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.False(t, m)
+
+	err = router.SetRoutes(canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// the weights are kept on reconciliation
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+
+	err = router.SetRoutes(canary, 0, 0, false)
+	require.Error(t, err)
+
+	err = router.Finalize(canary)
+	require.NoError(t, err)
+
+	svc, err = mocks.flaggerClient.KnativeV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, svc.Spec.Traffic, 1)
+	assert.Equal(t, int64(100), *svc.Spec.Traffic[0].Percent)
+}
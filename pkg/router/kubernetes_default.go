@@ -49,14 +49,19 @@ type KubernetesDefaultRouter struct {
 func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 	_, primaryName, canaryName := canary.GetServiceNames()
 
+	// the mesh routes to the pods with destination rule subsets of the apex service
+	if canary.Spec.Service.Subsets != nil {
+		return nil
+	}
+
 	// canary svc
-	err := c.reconcileService(canary, canaryName, c.labelValue, canary.Spec.Service.Canary)
+	err := c.reconcileService(canary, canaryName, map[string]string{c.labelSelector: c.labelValue}, canary.Spec.Service.Canary)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
 
 	// primary svc
-	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Primary)
+	err = c.reconcileService(canary, primaryName, c.primarySelector(), canary.Spec.Service.Primary)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
@@ -68,8 +73,12 @@ func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 func (c *KubernetesDefaultRouter) Reconcile(canary *flaggerv1.Canary) error {
 	apexName, _, _ := canary.GetServiceNames()
 
-	// main svc
-	err := c.reconcileService(canary, apexName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Apex)
+	// main svc, selects both the primary and canary pods when routing by subsets
+	selector := c.primarySelector()
+	if canary.Spec.Service.Subsets != nil {
+		selector = canary.Spec.Service.Subsets.Selector
+	}
+	err := c.reconcileService(canary, apexName, selector, canary.Spec.Service.Apex)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
@@ -85,7 +94,11 @@ func (c *KubernetesDefaultRouter) GetRoutes(_ *flaggerv1.Canary) (primaryRoute i
 	return 0, 0, nil
 }
 
-func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, name string, podSelector string, metadata *flaggerv1.CustomMetadata) error {
+func (c *KubernetesDefaultRouter) primarySelector() map[string]string {
+	return map[string]string{c.labelSelector: fmt.Sprintf("%s-primary", c.labelValue)}
+}
+
+func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, name string, selector map[string]string, metadata *flaggerv1.CustomMetadata) error {
 	portName := canary.Spec.Service.PortName
 	if portName == "" {
 		portName = "http"
@@ -103,7 +116,7 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 	// set pod selector and apex port
 	svcSpec := corev1.ServiceSpec{
 		Type:     corev1.ServiceTypeClusterIP,
-		Selector: map[string]string{c.labelSelector: podSelector},
+		Selector: selector,
 		Ports: []corev1.ServicePort{
 			{
 				Name:       portName,
@@ -262,7 +275,7 @@ func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
 				return fmt.Errorf("service %s update error: %w", clone.Name, err)
 			}
 		} else {
-			err = c.reconcileService(canary, apexName, canary.Spec.TargetRef.Name, nil)
+			err = c.reconcileService(canary, apexName, map[string]string{c.labelSelector: canary.Spec.TargetRef.Name}, nil)
 			if err != nil {
 				return fmt.Errorf("reconcileService failed: %w", err)
 			}
@@ -60,6 +60,36 @@ func TestServiceRouter_Create(t *testing.T) {
 	assert.Equal(t, int32(9898), primarySvc.Spec.Ports[0].Port)
 }
 
+func TestServiceRouter_Subsets(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+
+	mocks.canary.Spec.Service.Subsets = &flaggerv1.IstioSubsets{
+		Selector: map[string]string{"app.kubernetes.io/name": "podinfo"},
+	}
+
+	err := router.Initialize(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.Error(t, err)
+	_, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.Error(t, err)
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app.kubernetes.io/name": "podinfo"}, apexSvc.Spec.Selector)
+}
+
 func TestServiceRouter_Update(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &KubernetesDefaultRouter{
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	routev1 "github.com/fluxcd/flagger/pkg/apis/openshift/v1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// OpenShiftRouter is managing OpenShift Route objects,
+// the traffic is split between the primary service in spec.to and the canary alternate backend
+type OpenShiftRouter struct {
+	routeClient  clientset.Interface
+	logger       *zap.SugaredLogger
+	setOwnerRefs bool
+}
+
+const openShiftTimeoutAnnotation = "haproxy.router.openshift.io/timeout"
+
+// Reconcile creates or updates the OpenShift Route
+func (osr *OpenShiftRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	newSpec := osr.makeSpec(canary, primaryName, canaryName, 100, 0)
+
+	newMetadata := canary.Spec.Service.Apex
+	if newMetadata == nil {
+		newMetadata = &flaggerv1.CustomMetadata{}
+	}
+	if newMetadata.Labels == nil {
+		newMetadata.Labels = make(map[string]string)
+	}
+	if newMetadata.Annotations == nil {
+		newMetadata.Annotations = make(map[string]string)
+	}
+	newMetadata.Annotations = filterMetadata(newMetadata.Annotations)
+	if canary.Spec.Service.Timeout != "" {
+		newMetadata.Annotations[openShiftTimeoutAnnotation] = canary.Spec.Service.Timeout
+	}
+
+	route, err := osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		route = &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Labels:      newMetadata.Labels,
+				Annotations: newMetadata.Annotations,
+			},
+			Spec: newSpec,
+		}
+		if osr.setOwnerRefs {
+			route.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
+
+		_, err = osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("Route %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		osr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Route %s.%s created", route.GetName(), canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Route %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	keepUnmanagedFields(&newSpec, route)
+
+	// update Route but keep the original backend weights
+	specDiff := cmp.Diff(
+		newSpec,
+		route.Spec,
+		cmpopts.IgnoreFields(routev1.RouteTargetReference{}, "Weight"),
+	)
+	labelsDiff := cmp.Diff(newMetadata.Labels, route.Labels, cmpopts.EquateEmpty())
+	annotationsDiff := cmp.Diff(newMetadata.Annotations, route.Annotations, cmpopts.EquateEmpty())
+	if specDiff != "" || labelsDiff != "" || annotationsDiff != "" {
+		clone := route.DeepCopy()
+		newSpec.To.Weight = route.Spec.To.Weight
+		if len(route.Spec.AlternateBackends) > 0 {
+			newSpec.AlternateBackends[0].Weight = route.Spec.AlternateBackends[0].Weight
+		}
+		clone.Spec = newSpec
+		clone.ObjectMeta.Annotations = newMetadata.Annotations
+		clone.ObjectMeta.Labels = newMetadata.Labels
+
+		_, err = osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("Route %s.%s update error: %w", apexName, canary.Namespace, err)
+		}
+		osr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Route %s.%s updated", route.GetName(), canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the backend weights for primary and canary
+func (osr *OpenShiftRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	route, err := osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("Route %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	if route.Spec.To.Name != primaryName || len(route.Spec.AlternateBackends) < 1 ||
+		route.Spec.AlternateBackends[0].Name != canaryName {
+		err = fmt.Errorf("Route %s.%s backends not found", apexName, canary.Namespace)
+		return
+	}
+
+	if route.Spec.To.Weight != nil {
+		primaryWeight = int(*route.Spec.To.Weight)
+	}
+	if w := route.Spec.AlternateBackends[0].Weight; w != nil {
+		canaryWeight = int(*w)
+	}
+	return
+}
+
+// SetRoutes updates the backend weights for primary and canary
+func (osr *OpenShiftRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	if primaryWeight == 0 && canaryWeight == 0 {
+		return fmt.Errorf("Route %s.%s update failed: no valid weights", apexName, canary.Namespace)
+	}
+
+	route, err := osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Route %s.%s query error: %w", apexName, canary.Namespace, err)
+	}
+
+	clone := route.DeepCopy()
+	spec := osr.makeSpec(canary, primaryName, canaryName, primaryWeight, canaryWeight)
+	keepUnmanagedFields(&spec, route)
+	clone.Spec = spec
+
+	_, err = osr.routeClient.OpenShiftRouteV1().Routes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("Route %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+func (osr *OpenShiftRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// makeSpec builds the route spec targeting the service port,
+// the host defaults to the first host of the canary service
+func (osr *OpenShiftRouter) makeSpec(canary *flaggerv1.Canary, primaryName string, canaryName string,
+	primaryWeight int, canaryWeight int) routev1.RouteSpec {
+	portName := canary.Spec.Service.PortName
+	if portName == "" {
+		portName = "http"
+	}
+
+	host := ""
+	if len(canary.Spec.Service.Hosts) > 0 {
+		host = canary.Spec.Service.Hosts[0]
+	}
+
+	pw := int32(primaryWeight)
+	cw := int32(canaryWeight)
+	return routev1.RouteSpec{
+		Host: host,
+		To: routev1.RouteTargetReference{
+			Kind:   "Service",
+			Name:   primaryName,
+			Weight: &pw,
+		},
+		AlternateBackends: []routev1.RouteTargetReference{
+			{
+				Kind:   "Service",
+				Name:   canaryName,
+				Weight: &cw,
+			},
+		},
+		Port: &routev1.RoutePort{
+			TargetPort: intstr.FromString(portName),
+		},
+	}
+}
+
+// keepUnmanagedFields copies the fields that are not managed by Flagger from the existing route,
+// the TLS config set on the cluster, the generated host and the defaulted wildcard policy
+func keepUnmanagedFields(spec *routev1.RouteSpec, route *routev1.Route) {
+	spec.TLS = route.Spec.TLS
+	spec.WildcardPolicy = route.Spec.WildcardPolicy
+	if spec.Host == "" {
+		spec.Host = route.Spec.Host
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	routev1 "github.com/fluxcd/flagger/pkg/apis/openshift/v1"
+)
+
+func TestOpenShiftRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &OpenShiftRouter{
+		logger:      mocks.logger,
+		routeClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	route, err := router.routeClient.OpenShiftRouteV1().Routes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", route.Spec.To.Name)
+	assert.Equal(t, int32(100), *route.Spec.To.Weight)
+	require.Len(t, route.Spec.AlternateBackends, 1)
+	assert.Equal(t, "podinfo-canary", route.Spec.AlternateBackends[0].Name)
+	assert.Equal(t, int32(0), *route.Spec.AlternateBackends[0].Weight)
+	assert.Equal(t, "http", route.Spec.Port.TargetPort.String())
+
+	// the generated host and the TLS config set on the cluster are kept
+	route.Spec.Host = "podinfo-default.apps.example.com"
+	route.Spec.TLS = &routev1.TLSConfig{Termination: "edge"}
+	_, err = router.routeClient.OpenShiftRouteV1().Routes("default").Update(context.TODO(), route, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+
+	// test update
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cdClone := cd.DeepCopy()
+	cdClone.Spec.Service.Timeout = "30s"
+	canary, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cdClone, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	route, err = router.routeClient.OpenShiftRouteV1().Routes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "30s", route.Annotations[openShiftTimeoutAnnotation])
+	assert.Equal(t, "podinfo-default.apps.example.com", route.Spec.Host)
+	assert.Equal(t, "edge", route.Spec.TLS.Termination)
+	assert.Equal(t, int32(60), *route.Spec.To.Weight)
+	assert.Equal(t, int32(40), *route.Spec.AlternateBackends[0].Weight)
+}
+
+func TestOpenShiftRouter_Routes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &OpenShiftRouter{
+		logger:      mocks.logger,
+		routeClient: mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.False(t, m)
+
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 50, p)
+	assert.Equal(t, 50, c)
+
+	err = router.SetRoutes(mocks.canary, 0, 0, false)
+	require.Error(t, err)
+}
@@ -19,6 +19,8 @@ package router
 import (
 	a6v2 "github.com/fluxcd/flagger/pkg/apis/apisix/v2"
 	"go.uber.org/zap"
+	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	fakeIstio "istio.io/client-go/pkg/clientset/versioned/fake"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
@@ -44,6 +46,7 @@ type fixture struct {
 	ingressCanary *flaggerv1.Canary
 	kubeClient    kubernetes.Interface
 	meshClient    clientset.Interface
+	istioClient   istioclientset.Interface
 	flaggerClient clientset.Interface
 	logger        *zap.SugaredLogger
 }
@@ -73,6 +76,7 @@ func newFixture(c *flaggerv1.Canary) fixture {
 	)
 
 	meshClient := fakeFlagger.NewSimpleClientset()
+	istioClient := fakeIstio.NewSimpleClientset()
 
 	logger, _ := logger.NewLogger("debug")
 	return fixture{
@@ -82,6 +86,7 @@ func newFixture(c *flaggerv1.Canary) fixture {
 		ingressCanary: ingressCanary,
 		kubeClient:    kubeClient,
 		meshClient:    meshClient,
+		istioClient:   istioClient,
 		flaggerClient: flaggerClient,
 		logger:        logger,
 	}
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// selectorSwitchAnnotation records the service the apex selector points to
+const selectorSwitchAnnotation = "flagger.app/selector-switch"
+
+// SelectorSwitchRouter performs blue/green deployments without a service mesh or ingress controller
+// by switching the apex service selector between the primary and canary pods
+type SelectorSwitchRouter struct {
+	kubeClient    kubernetes.Interface
+	logger        *zap.SugaredLogger
+	labelSelector string
+}
+
+// Reconcile creates the apex service or takes over an existing one,
+// the pod selector is set to the service recorded in the apex annotation, primary by default
+func (sr *SelectorSwitchRouter) Reconcile(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	primarySvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", primaryName, canary.Namespace, err)
+	}
+
+	apexSvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		metadata := canary.Spec.Service.Apex
+		if metadata == nil {
+			metadata = &flaggerv1.CustomMetadata{}
+		}
+		labels := make(map[string]string)
+		for k, v := range metadata.Labels {
+			labels[k] = v
+		}
+		labels[sr.labelSelector] = apexName
+		annotations := make(map[string]string)
+		for k, v := range metadata.Annotations {
+			annotations[k] = v
+		}
+		annotations = filterMetadata(annotations)
+		annotations[selectorSwitchAnnotation] = primaryName
+
+		apexSvc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        apexName,
+				Namespace:   canary.Namespace,
+				Labels:      labels,
+				Annotations: annotations,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeClusterIP,
+				Selector: primarySvc.Spec.Selector,
+				Ports:    primarySvc.Spec.Ports,
+			},
+		}
+
+		_, err = sr.kubeClient.CoreV1().Services(canary.Namespace).Create(context.TODO(), apexSvc, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("service %s.%s create error: %w", apexName, canary.Namespace, err)
+		}
+		sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Service %s.%s created", apexName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	target := apexSvc.Annotations[selectorSwitchAnnotation]
+	if target != canaryName {
+		target = primaryName
+	}
+	return sr.switchSelector(canary, apexSvc, primarySvc, target)
+}
+
+// SetRoutes points the apex service to the canary pods when the canary weight is
+// greater than zero, otherwise to the primary pods
+func (sr *SelectorSwitchRouter) SetRoutes(canary *flaggerv1.Canary, _ int, canaryWeight int, _ bool) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	primarySvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", primaryName, canary.Namespace, err)
+	}
+
+	apexSvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	target := primaryName
+	if canaryWeight > 0 {
+		target = canaryName
+	}
+	return sr.switchSelector(canary, apexSvc, primarySvc, target)
+}
+
+// GetRoutes returns the full weight for the service the apex selector points to
+func (sr *SelectorSwitchRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
+	apexName, _, canaryName := canary.GetServiceNames()
+
+	apexSvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("service %s.%s get query error: %w", apexName, canary.Namespace, err)
+		return
+	}
+
+	if apexSvc.Annotations[selectorSwitchAnnotation] == canaryName {
+		return 0, 100, false, nil
+	}
+	return 100, 0, false, nil
+}
+
+// Finalize removes the selector switch annotation, the apex selector is reverted by the Kubernetes router
+func (sr *SelectorSwitchRouter) Finalize(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+
+	apexSvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	if _, ok := apexSvc.Annotations[selectorSwitchAnnotation]; !ok {
+		return nil
+	}
+
+	svcClone := apexSvc.DeepCopy()
+	delete(svcClone.Annotations, selectorSwitchAnnotation)
+	_, err = sr.kubeClient.CoreV1().Services(canary.Namespace).Update(context.TODO(), svcClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s update error: %w", apexName, canary.Namespace, err)
+	}
+	return nil
+}
+
+// switchSelector sets the apex pod selector to the selector of the target service
+// and keeps the apex ports in sync with the primary service
+func (sr *SelectorSwitchRouter) switchSelector(canary *flaggerv1.Canary, apexSvc *corev1.Service,
+	primarySvc *corev1.Service, target string) error {
+	selector := primarySvc.Spec.Selector
+	if target != primarySvc.Name {
+		targetSvc, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), target, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("service %s.%s get query error: %w", target, canary.Namespace, err)
+		}
+		selector = targetSvc.Spec.Selector
+	}
+
+	// copy node ports from the existing apex service
+	ports := make([]corev1.ServicePort, len(primarySvc.Spec.Ports))
+	copy(ports, primarySvc.Spec.Ports)
+	for _, port := range apexSvc.Spec.Ports {
+		for i, servicePort := range ports {
+			if port.Name == servicePort.Name && port.NodePort > 0 {
+				ports[i].NodePort = port.NodePort
+				break
+			}
+		}
+	}
+
+	sortPorts := func(a, b interface{}) bool {
+		return a.(corev1.ServicePort).Port < b.(corev1.ServicePort).Port
+	}
+	if cmp.Diff(selector, apexSvc.Spec.Selector) == "" &&
+		cmp.Diff(ports, apexSvc.Spec.Ports, cmpopts.SortSlices(sortPorts)) == "" &&
+		apexSvc.Annotations[selectorSwitchAnnotation] == target {
+		return nil
+	}
+
+	svcClone := apexSvc.DeepCopy()
+	svcClone.Spec.Selector = selector
+	svcClone.Spec.Ports = ports
+	if svcClone.Annotations == nil {
+		svcClone.Annotations = make(map[string]string)
+	}
+	svcClone.Annotations[selectorSwitchAnnotation] = target
+
+	_, err := sr.kubeClient.CoreV1().Services(canary.Namespace).Update(context.TODO(), svcClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("service %s.%s update error: %w", apexSvc.Name, canary.Namespace, err)
+	}
+	sr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Service %s.%s selector switched to %s", apexSvc.Name, canary.Namespace, target)
+	return nil
+}
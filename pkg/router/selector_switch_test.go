@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectorSwitchRouter_SetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	kubeRouter := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+	}
+	router := &SelectorSwitchRouter{
+		kubeClient:    mocks.kubeClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+	}
+
+	require.NoError(t, kubeRouter.Initialize(mocks.canary))
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", apexSvc.Spec.Selector["app"])
+	assert.Equal(t, int32(9898), apexSvc.Spec.Ports[0].Port)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+
+	// switch to the canary pods
+	require.NoError(t, router.SetRoutes(mocks.canary, 0, 100, false))
+
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", apexSvc.Spec.Selector["app"])
+
+	p, c, _, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// reconcile keeps the canary selector
+	require.NoError(t, router.Reconcile(mocks.canary))
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", apexSvc.Spec.Selector["app"])
+
+	// switch back to the primary pods
+	require.NoError(t, router.SetRoutes(mocks.canary, 100, 0, false))
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-primary", apexSvc.Spec.Selector["app"])
+
+	require.NoError(t, router.Finalize(mocks.canary))
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, apexSvc.Annotations, selectorSwitchAnnotation)
+}
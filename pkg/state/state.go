@@ -0,0 +1,377 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// RedactedAnnotation marks the secrets exported without their data
+const RedactedAnnotation = "flagger.app/redacted"
+
+// Snapshot holds the state of the canaries and of the primary objects generated by Flagger
+type Snapshot struct {
+	// Version of Flagger that exported the snapshot
+	Version string `json:"version"`
+
+	// Created is the time the snapshot was exported
+	Created metav1.Time `json:"created"`
+
+	Canaries []CanaryState `json:"canaries"`
+}
+
+// CanaryState holds a canary including its status and the primary objects of its target
+type CanaryState struct {
+	Canary flaggerv1.Canary `json:"canary"`
+
+	// +optional
+	PrimaryDeployment *appsv1.Deployment `json:"primaryDeployment,omitempty"`
+
+	// +optional
+	PrimaryDaemonSet *appsv1.DaemonSet `json:"primaryDaemonSet,omitempty"`
+
+	// +optional
+	PrimaryStatefulSet *appsv1.StatefulSet `json:"primaryStatefulSet,omitempty"`
+
+	// +optional
+	PrimaryConfigMaps []corev1.ConfigMap `json:"primaryConfigMaps,omitempty"`
+
+	// +optional
+	PrimarySecrets []corev1.Secret `json:"primarySecrets,omitempty"`
+}
+
+// Export returns the state of the canaries in the namespace, all namespaces if empty,
+// the data of the primary secrets is redacted unless includeSecrets is set
+func Export(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, namespace string, version string, includeSecrets bool) (*Snapshot, error) {
+	list, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("canaries list query failed: %w", err)
+	}
+
+	snapshot := &Snapshot{
+		Version:  version,
+		Created:  metav1.Now(),
+		Canaries: []CanaryState{},
+	}
+	for _, cd := range list.Items {
+		state, err := exportCanary(kubeClient, cd, includeSecrets)
+		if err != nil {
+			return nil, fmt.Errorf("canary %s.%s export failed: %w", cd.Name, cd.Namespace, err)
+		}
+		snapshot.Canaries = append(snapshot.Canaries, *state)
+	}
+	return snapshot, nil
+}
+
+func exportCanary(kubeClient kubernetes.Interface, cd flaggerv1.Canary, includeSecrets bool) (*CanaryState, error) {
+	state := &CanaryState{Canary: cd}
+	state.Canary.ObjectMeta = cleanMeta(cd.ObjectMeta)
+	state.Canary.ManagedFields = nil
+
+	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
+	var podSpec *corev1.PodSpec
+	switch cd.Spec.TargetRef.Kind {
+	case "Deployment":
+		dep, err := kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return state, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("deployment %s.%s get query failed: %w", primaryName, cd.Namespace, err)
+		}
+		dep.ObjectMeta = cleanMeta(dep.ObjectMeta)
+		dep.Status = appsv1.DeploymentStatus{}
+		state.PrimaryDeployment = dep
+		podSpec = &dep.Spec.Template.Spec
+	case "DaemonSet":
+		ds, err := kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return state, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("daemonset %s.%s get query failed: %w", primaryName, cd.Namespace, err)
+		}
+		ds.ObjectMeta = cleanMeta(ds.ObjectMeta)
+		ds.Status = appsv1.DaemonSetStatus{}
+		state.PrimaryDaemonSet = ds
+		podSpec = &ds.Spec.Template.Spec
+	case "StatefulSet":
+		sts, err := kubeClient.AppsV1().StatefulSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return state, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("statefulset %s.%s get query failed: %w", primaryName, cd.Namespace, err)
+		}
+		sts.ObjectMeta = cleanMeta(sts.ObjectMeta)
+		sts.Status = appsv1.StatefulSetStatus{}
+		state.PrimaryStatefulSet = sts
+		podSpec = &sts.Spec.Template.Spec
+	default:
+		return state, nil
+	}
+
+	configMaps, secrets := primaryConfigs(podSpec)
+	for _, name := range configMaps {
+		cm, err := kubeClient.CoreV1().ConfigMaps(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("configmap %s.%s get query failed: %w", name, cd.Namespace, err)
+		}
+		cm.ObjectMeta = cleanMeta(cm.ObjectMeta)
+		state.PrimaryConfigMaps = append(state.PrimaryConfigMaps, *cm)
+	}
+	for _, name := range secrets {
+		secret, err := kubeClient.CoreV1().Secrets(cd.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("secret %s.%s get query failed: %w", name, cd.Namespace, err)
+		}
+		secret.ObjectMeta = cleanMeta(secret.ObjectMeta)
+		if !includeSecrets {
+			secret = redactSecret(secret)
+		}
+		state.PrimarySecrets = append(state.PrimarySecrets, *secret)
+	}
+	return state, nil
+}
+
+// Import restores the canaries and their primary objects, the primary objects
+// are restored before the canary status to prevent Flagger from promoting the target
+func Import(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, snapshot *Snapshot, logger *zap.SugaredLogger) error {
+	for _, state := range snapshot.Canaries {
+		if err := importCanary(kubeClient, flaggerClient, state); err != nil {
+			return fmt.Errorf("canary %s.%s import failed: %w", state.Canary.Name, state.Canary.Namespace, err)
+		}
+		logger.With("canary", fmt.Sprintf("%s.%s", state.Canary.Name, state.Canary.Namespace)).
+			Infof("Canary %s.%s imported with phase %s", state.Canary.Name, state.Canary.Namespace, state.Canary.Status.Phase)
+	}
+	return nil
+}
+
+func importCanary(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, state CanaryState) error {
+	ns := state.Canary.Namespace
+	cd, err := flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), state.Canary.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Create(context.TODO(), &flaggerv1.Canary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        state.Canary.Name,
+				Namespace:   ns,
+				Labels:      state.Canary.Labels,
+				Annotations: state.Canary.Annotations,
+			},
+			Spec: state.Canary.Spec,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("canary create failed: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("canary get query failed: %w", err)
+	}
+
+	ownerRefs := []metav1.OwnerReference{
+		*metav1.NewControllerRef(cd, schema.GroupVersionKind{
+			Group:   flaggerv1.SchemeGroupVersion.Group,
+			Version: flaggerv1.SchemeGroupVersion.Version,
+			Kind:    flaggerv1.CanaryKind,
+		}),
+	}
+
+	for _, cm := range state.PrimaryConfigMaps {
+		cm := cm
+		cm.OwnerReferences = ownerRefs
+		_, err := kubeClient.CoreV1().ConfigMaps(ns).Create(context.TODO(), &cm, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = kubeClient.CoreV1().ConfigMaps(ns).Update(context.TODO(), &cm, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("configmap %s.%s restore failed: %w", cm.Name, ns, err)
+		}
+	}
+	for _, secret := range state.PrimarySecrets {
+		secret := secret
+		if secret.Annotations[RedactedAnnotation] == "true" {
+			restored, err := restoreRedactedSecret(kubeClient, secret)
+			if err != nil {
+				return err
+			}
+			if restored == nil {
+				continue
+			}
+			secret = *restored
+		}
+		secret.OwnerReferences = ownerRefs
+		_, err := kubeClient.CoreV1().Secrets(ns).Create(context.TODO(), &secret, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = kubeClient.CoreV1().Secrets(ns).Update(context.TODO(), &secret, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("secret %s.%s restore failed: %w", secret.Name, ns, err)
+		}
+	}
+	if dep := state.PrimaryDeployment; dep != nil {
+		dep.OwnerReferences = ownerRefs
+		_, err := kubeClient.AppsV1().Deployments(ns).Create(context.TODO(), dep, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = kubeClient.AppsV1().Deployments(ns).Update(context.TODO(), dep, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("deployment %s.%s restore failed: %w", dep.Name, ns, err)
+		}
+	}
+	if ds := state.PrimaryDaemonSet; ds != nil {
+		ds.OwnerReferences = ownerRefs
+		_, err := kubeClient.AppsV1().DaemonSets(ns).Create(context.TODO(), ds, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = kubeClient.AppsV1().DaemonSets(ns).Update(context.TODO(), ds, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("daemonset %s.%s restore failed: %w", ds.Name, ns, err)
+		}
+	}
+	if sts := state.PrimaryStatefulSet; sts != nil {
+		sts.OwnerReferences = ownerRefs
+		_, err := kubeClient.AppsV1().StatefulSets(ns).Create(context.TODO(), sts, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			_, err = kubeClient.AppsV1().StatefulSets(ns).Update(context.TODO(), sts, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("statefulset %s.%s restore failed: %w", sts.Name, ns, err)
+		}
+	}
+
+	cdCopy := cd.DeepCopy()
+	cdCopy.Status = state.Canary.Status
+	if _, err := flaggerClient.FlaggerV1beta1().Canaries(ns).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("canary status update failed: %w", err)
+	}
+	return nil
+}
+
+// redactSecret returns a copy of the secret without its data
+func redactSecret(secret *corev1.Secret) *corev1.Secret {
+	redacted := &corev1.Secret{
+		ObjectMeta: *secret.ObjectMeta.DeepCopy(),
+		Type:       secret.Type,
+	}
+	if redacted.Annotations == nil {
+		redacted.Annotations = make(map[string]string)
+	}
+	redacted.Annotations[RedactedAnnotation] = "true"
+	return redacted
+}
+
+// restoreRedactedSecret returns the primary secret to create from the data of the target secret,
+// nil is returned if the primary secret exists since its data can't be restored from the export
+func restoreRedactedSecret(kubeClient kubernetes.Interface, secret corev1.Secret) (*corev1.Secret, error) {
+	ns := secret.Namespace
+	_, err := kubeClient.CoreV1().Secrets(ns).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	if err == nil {
+		return nil, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("secret %s.%s get query failed: %w", secret.Name, ns, err)
+	}
+
+	sourceName := strings.TrimSuffix(secret.Name, "-primary")
+	source, err := kubeClient.CoreV1().Secrets(ns).Get(context.TODO(), sourceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secret %s.%s was exported without its data and can't be restored from %s: %w",
+			secret.Name, ns, sourceName, err)
+	}
+
+	restored := secret.DeepCopy()
+	delete(restored.Annotations, RedactedAnnotation)
+	restored.Data = source.Data
+	return restored, nil
+}
+
+// cleanMeta removes the cluster specific metadata fields
+func cleanMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+}
+
+// primaryConfigs returns the names of the primary ConfigMaps and Secrets referenced by the pod spec
+func primaryConfigs(spec *corev1.PodSpec) (configMaps []string, secrets []string) {
+	seen := make(map[string]bool)
+	add := func(list *[]string, kind string, name string) {
+		if name == "" || !strings.HasSuffix(name, "-primary") || seen[kind+"/"+name] {
+			return
+		}
+		seen[kind+"/"+name] = true
+		*list = append(*list, name)
+	}
+
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			add(&configMaps, "configmap", v.ConfigMap.Name)
+		}
+		if v.Secret != nil {
+			add(&secrets, "secret", v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil {
+					add(&configMaps, "configmap", s.ConfigMap.Name)
+				}
+				if s.Secret != nil {
+					add(&secrets, "secret", s.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add(&configMaps, "configmap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add(&secrets, "secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				add(&configMaps, "configmap", envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				add(&secrets, "secret", envFrom.SecretRef.Name)
+			}
+		}
+	}
+	return configMaps, secrets
+}
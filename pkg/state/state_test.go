@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func newTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "podinfo",
+			Namespace:       "default",
+			UID:             "old-uid",
+			ResourceVersion: "42",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.LocalObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+		},
+		Status: flaggerv1.CanaryStatus{
+			Phase:           flaggerv1.CanaryPhaseProgressing,
+			CanaryWeight:    30,
+			Iterations:      3,
+			FailedChecks:    1,
+			LastAppliedSpec: "5978589476",
+		},
+	}
+}
+
+func newTestPrimary() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "podinfo-primary",
+			Namespace:       "default",
+			ResourceVersion: "7",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "podinfo",
+						Image: "ghcr.io/stefanprodan/podinfo:6.0.0",
+						EnvFrom: []corev1.EnvFromSource{{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-config-env-primary"},
+							},
+						}},
+					}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "secret",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: "podinfo-secret-vol-primary"},
+							},
+						},
+						{
+							Name: "untracked",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "podinfo-untracked"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		newTestPrimary(),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-config-env-primary", Namespace: "default"},
+			Data:       map[string]string{"color": "blue"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-secret-vol-primary", Namespace: "default"},
+			Data:       map[string][]byte{"apiKey": []byte("test")},
+		},
+	)
+	flaggerClient := fakeFlagger.NewSimpleClientset(newTestCanary())
+
+	snapshot, err := Export(kubeClient, flaggerClient, "", "1.0.0", true)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Canaries, 1)
+
+	exported := snapshot.Canaries[0]
+	assert.Empty(t, exported.Canary.UID)
+	assert.Empty(t, exported.Canary.ResourceVersion)
+	require.NotNil(t, exported.PrimaryDeployment)
+	assert.Empty(t, exported.PrimaryDeployment.ResourceVersion)
+	require.Len(t, exported.PrimaryConfigMaps, 1)
+	require.Len(t, exported.PrimarySecrets, 1)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var imported Snapshot
+	require.NoError(t, json.Unmarshal(data, &imported))
+
+	// restore into an empty cluster
+	newKubeClient := fake.NewSimpleClientset()
+	newFlaggerClient := fakeFlagger.NewSimpleClientset()
+	require.NoError(t, Import(newKubeClient, newFlaggerClient, &imported, zap.S()))
+
+	cd, err := newFlaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, cd.Status.Phase)
+	assert.Equal(t, 30, cd.Status.CanaryWeight)
+	assert.Equal(t, 3, cd.Status.Iterations)
+	assert.Equal(t, 1, cd.Status.FailedChecks)
+	assert.Equal(t, "5978589476", cd.Status.LastAppliedSpec)
+
+	dep, err := newKubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/stefanprodan/podinfo:6.0.0", dep.Spec.Template.Spec.Containers[0].Image)
+	require.Len(t, dep.OwnerReferences, 1)
+	assert.Equal(t, "podinfo", dep.OwnerReferences[0].Name)
+
+	cm, err := newKubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "podinfo-config-env-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "blue", cm.Data["color"])
+
+	secret, err := newKubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-secret-vol-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test"), secret.Data["apiKey"])
+
+	// import over the existing objects
+	require.NoError(t, Import(newKubeClient, newFlaggerClient, &imported, zap.S()))
+}
+
+func TestExportImportRedacted(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		newTestPrimary(),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo-secret-vol-primary", Namespace: "default"},
+			Data:       map[string][]byte{"apiKey": []byte("test")},
+		},
+	)
+	flaggerClient := fakeFlagger.NewSimpleClientset(newTestCanary())
+
+	snapshot, err := Export(kubeClient, flaggerClient, "", "1.0.0", false)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Canaries[0].PrimarySecrets, 1)
+	redacted := snapshot.Canaries[0].PrimarySecrets[0]
+	assert.Empty(t, redacted.Data)
+	assert.Equal(t, "true", redacted.Annotations[RedactedAnnotation])
+
+	// the redacted secret can't be restored without the target secret
+	newKubeClient := fake.NewSimpleClientset()
+	newFlaggerClient := fakeFlagger.NewSimpleClientset()
+	require.Error(t, Import(newKubeClient, newFlaggerClient, snapshot, zap.S()))
+
+	// the redacted secret is restored from the target secret
+	_, err = newKubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-secret-vol", Namespace: "default"},
+		Data:       map[string][]byte{"apiKey": []byte("new")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, Import(newKubeClient, newFlaggerClient, snapshot, zap.S()))
+
+	secret, err := newKubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-secret-vol-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), secret.Data["apiKey"])
+	assert.Empty(t, secret.Annotations[RedactedAnnotation])
+
+	// an existing primary secret is left untouched
+	secret.Data["apiKey"] = []byte("current")
+	_, err = newKubeClient.CoreV1().Secrets("default").Update(context.TODO(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, Import(newKubeClient, newFlaggerClient, snapshot, zap.S()))
+
+	secret, err = newKubeClient.CoreV1().Secrets("default").Get(context.TODO(), "podinfo-secret-vol-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("current"), secret.Data["apiKey"])
+}
+
+func TestPrimaryConfigs(t *testing.T) {
+	configMaps, secrets := primaryConfigs(&newTestPrimary().Spec.Template.Spec)
+	assert.Equal(t, []string{"podinfo-config-env-primary"}, configMaps)
+	assert.Equal(t, []string{"podinfo-secret-vol-primary"}, secrets)
+}